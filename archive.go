@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/vearutop/photo-blog/internal/infra/archive"
+	"github.com/vearutop/photo-blog/internal/infra/service"
+	"github.com/vearutop/photo-blog/pkg/backup"
+)
+
+func exportSite(sl *service.Locator, fn string, opt archive.ExportOptions) {
+	f, err := os.Create(fn)
+	if err != nil {
+		log.Fatalf("failed to create archive: %v", err)
+	}
+
+	if err := sl.Archive().Export(context.Background(), f, opt); err != nil {
+		log.Fatalf("failed to export site: %v", err)
+	}
+
+	if err := f.Close(); err != nil {
+		log.Fatalf("failed to close archive: %v", err)
+	}
+
+	log.Printf("site exported to %s", fn)
+}
+
+func importSite(sl *service.Locator, fn string, originals bool, remap string) {
+	paths, err := backup.ParsePathMap(strings.Split(remap, ",")...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	f, err := os.Open(fn)
+	if err != nil {
+		log.Fatalf("failed to open archive: %v", err)
+	}
+	defer f.Close()
+
+	st, err := f.Stat()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	res, err := sl.Archive().Import(context.Background(), f, st.Size(), archive.ImportOptions{
+		Paths:     paths,
+		Originals: originals,
+	})
+	if err != nil {
+		log.Fatalf("failed to import site: %v", err)
+	}
+
+	log.Printf("site imported from %s (exported %s from %s): tables %v, files %d, skipped files %d",
+		fn, res.Manifest.CreatedAt.Format("2006-01-02 15:04"), res.Manifest.Source, res.Tables, res.Files, res.SkippedFiles)
+}
@@ -1,13 +1,20 @@
-// Package main provides a tool to populate stats.sqlite from access.log.
+// Package main provides a tool to populate stats.sqlite from access logs.
 //
-// rm photo-blog-data/stats.sqlite && catp photo-blog-data/access.log.zst | go run ./cmd/log2stats
+// Logs of this app, nginx/Apache combined log format and Caddy JSON logs are supported,
+// lines that were imported before are skipped, so overlapping logs can be replayed again.
+//
+//	catp photo-blog-data/access.log.zst | go run ./cmd/log2stats
+//	go run ./cmd/log2stats -format combined -from 2023-01-01 -own-hosts example.org /var/log/nginx/access.log.1.gz
 package main
 
 import (
 	"bufio"
+	"compress/gzip"
 	"context"
-	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"net/url"
@@ -18,201 +25,466 @@ import (
 	"github.com/bool64/brick/database"
 	"github.com/bool64/stats"
 	"github.com/bool64/zapctxd"
+	"github.com/cespare/xxhash/v2"
 	"github.com/swaggest/rest/request"
 	"github.com/vearutop/netrie"
 	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/auth"
 	"github.com/vearutop/photo-blog/internal/infra/storage"
 	"github.com/vearutop/photo-blog/internal/infra/storage/sqlite"
 	"github.com/vearutop/photo-blog/internal/infra/storage/sqlite_stats"
 	"github.com/vearutop/photo-blog/internal/infra/storage/visitor"
+	"github.com/vearutop/photo-blog/pkg/accesslog"
 	"github.com/vearutop/photo-blog/pkg/webstats"
 	"go.uber.org/zap"
 	_ "modernc.org/sqlite" // SQLite3 driver.
 )
 
+type flags struct {
+	db             string
+	mainDB         string
+	cityDB         string
+	format         string
+	from, to       time.Time
+	dryRun         bool
+	ownHosts       []string
+	trustedProxies []string
+	legacyBefore   time.Time
+}
+
+// counters describe import results.
+type counters struct {
+	lines        int
+	invalid      int
+	skipped      int
+	outOfRange   int
+	duplicates   int
+	bots         int
+	admins       int
+	statsReqs    int
+	refers       int
+	legacyPages  int
+	firstEntry   time.Time
+	lastEntry    time.Time
+	refererHosts map[string]int
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+
+	return time.Parse(time.RFC3339, s)
+}
+
+func splitList(s string) []string {
+	var res []string
+
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			res = append(res, v)
+		}
+	}
+
+	return res
+}
+
+func parseFlags() flags {
+	var (
+		f                        flags
+		from, to, legacy         string
+		ownHosts, trustedProxies string
+		err                      error
+	)
+
+	flag.StringVar(&f.db, "db", "photo-blog-data/stats.sqlite", "Path to stats database.")
+	flag.StringVar(&f.mainDB, "main-db", "photo-blog-data/db.sqlite", "Path to main database to find albums.")
+	flag.StringVar(&f.cityDB, "city-db", "", "Path to city location DB, location is not resolved if empty.")
+	flag.StringVar(&f.format, "format", string(accesslog.Auto), "Log format: auto, app, combined (nginx/Apache) or caddy.")
+	flag.StringVar(&from, "from", "", "Skip entries before this time, date (2006-01-02) or RFC3339.")
+	flag.StringVar(&to, "to", "", "Skip entries at or after this time, date (2006-01-02) or RFC3339.")
+	flag.BoolVar(&f.dryRun, "dry-run", false, "Parse logs and report counts without writing to database.")
+	flag.StringVar(&ownHosts, "own-hosts", "", "Comma-separated hosts of the site, referrers from them are not external.")
+	flag.StringVar(&trustedProxies, "trusted-proxies", "", "Comma-separated IP addresses of trusted proxies.")
+	flag.StringVar(&legacy, "legacy-before", "2024-02-10T02:08:27Z",
+		"Page views are derived from page requests for entries before this time, when stats requests were not available.")
+
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: log2stats [flags] [file ...]")
+		fmt.Fprintln(flag.CommandLine.Output(), "Logs are read from files (plain or .gz) or from STDIN.")
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	switch accesslog.Format(f.format) {
+	case accesslog.Auto, accesslog.App, accesslog.Combined, accesslog.Caddy:
+	default:
+		log.Fatalf("unknown log format: %s", f.format)
+	}
+
+	if f.from, err = parseTime(from); err != nil {
+		log.Fatalf("invalid -from: %v", err)
+	}
+
+	if f.to, err = parseTime(to); err != nil {
+		log.Fatalf("invalid -to: %v", err)
+	}
+
+	if f.legacyBefore, err = parseTime(legacy); err != nil {
+		log.Fatalf("invalid -legacy-before: %v", err)
+	}
+
+	f.ownHosts = splitList(ownHosts)
+	f.trustedProxies = splitList(trustedProxies)
+
+	return f
+}
+
+func openInput(name string) (io.ReadCloser, error) {
+	if name == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasSuffix(name, ".gz") {
+		return f, nil
+	}
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("open %s: %w", name, err), f.Close())
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{
+		Reader: gz,
+		Closer: f,
+	}, nil
+}
+
+type importer struct {
+	f      flags
+	vs     *visitor.StatsRepository
+	albums map[string]int
+	dec    request.DecoderFunc
+	c      counters
+}
+
 func main() {
+	f := parseFlags()
+
 	l := zapctxd.New(zapctxd.Config{Level: zap.WarnLevel})
+	ctx := context.Background()
+
 	cfg := database.Config{}
 	cfg.DriverName = "sqlite"
 	cfg.MaxOpen = 1
 	cfg.MaxIdle = 1
-	cfg.DSN = "photo-blog-data/stats.sqlite" + "?_time_format=sqlite"
-	cfg.ApplyMigrations = true
+	cfg.DSN = f.mainDB + "?_time_format=sqlite"
+	cfg.ApplyMigrations = !f.dryRun
 
-	st, err := database.SetupStorageDSN(cfg, l.CtxdLogger(), stats.NoOp{}, sqlite_stats.Migrations)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	cfg.DSN = "photo-blog-data/db.sqlite" + "?_time_format=sqlite"
 	stm, err := database.SetupStorageDSN(cfg, l.CtxdLogger(), stats.NoOp{}, sqlite.Migrations)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	ctx := context.Background()
-
 	as := storage.NewAlbumRepository(stm, storage.NewImageRepository(stm), storage.NewMetaRepository(stm))
+
 	al, err := as.FindAll(ctx)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	albums := map[string]int{}
+	imp := importer{
+		f:      f,
+		albums: map[string]int{},
+		dec:    request.NewDecoderFactory().MakeDecoder(http.MethodGet, visitor.CollectStats{}, nil).Decode,
+		c:      counters{refererHosts: map[string]int{}},
+	}
+
 	for _, a := range al {
-		albums[a.Name] = 0
+		imp.albums[a.Name] = 0
 	}
 
-	cityLoc, err := netrie.OpenFile("photo-blog-data/city-loc-lite.bin")
-	if err != nil {
-		log.Fatal(err)
+	// Dry run only checks for duplicates in existing stats database.
+	if _, err := os.Stat(f.db); err == nil || !f.dryRun {
+		var cityLoc netrie.IPLookuper
+
+		if f.cityDB != "" {
+			cl, err := netrie.OpenFile(f.cityDB)
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer cl.Close()
+
+			cityLoc = cl
+		}
+
+		cfg.DSN = f.db + "?_time_format=sqlite"
+
+		st, err := database.SetupStorageDSN(cfg, l.CtxdLogger(), stats.NoOp{}, sqlite_stats.Migrations)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if imp.vs, err = visitor.NewStats(st, l, cityLoc); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	files := flag.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	for _, name := range files {
+		if err := imp.importFile(ctx, name); err != nil {
+			log.Fatal(err)
+		}
 	}
-	defer cityLoc.Close()
 
-	vs, err := visitor.NewStats(st, l, cityLoc)
+	imp.report()
+}
+
+func (imp *importer) importFile(ctx context.Context, name string) error {
+	r, err := openInput(name)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
+	defer r.Close()
 
-	scanner := bufio.NewScanner(os.Stdin)
+	scanner := bufio.NewScanner(r)
 	scanner.Buffer(make([]byte, 1e6), 1e6)
 
-	cnt := 0
+	for scanner.Scan() {
+		imp.c.lines++
 
-	type Row struct {
-		Ts           string    `json:"time"`
-		Time         time.Time `json:"-"`
-		Visitor      uniq.Hash `json:"visitor"`
-		Host         string    `json:"host"`
-		URL          string    `json:"url"`
-		UserAgent    string    `json:"user_agent"`
-		Device       string    `json:"device"`
-		Referer      string    `json:"referer"`
-		ForwardedFor string    `json:"forwarded_for"`
-		Admin        bool      `json:"admin"`
-		Lang         string    `json:"lang"`
+		if err := imp.importLine(ctx, scanner.Bytes()); err != nil {
+			return fmt.Errorf("%s:%d: %w", name, imp.c.lines, err)
+		}
 	}
 
-	botCnt := 0
-	admins := map[uniq.Hash]bool{}
-	adminCnt := 0
-	refCnt := 0
-	refHosts := map[string]int{}
-	statsCnt := 0
+	return scanner.Err()
+}
 
-	dec := request.NewDecoderFactory().MakeDecoder(http.MethodGet, visitor.CollectStats{}, nil)
+func (imp *importer) importLine(ctx context.Context, line []byte) error {
+	e, err := accesslog.Parse(line, accesslog.Format(imp.f.format))
+	if err != nil {
+		if !errors.Is(err, accesslog.ErrSkip) {
+			imp.c.invalid++
+		}
 
-	for scanner.Scan() {
-		cnt++
+		return nil
+	}
 
-		var row Row
+	if (!imp.f.from.IsZero() && e.Time.Before(imp.f.from)) || (!imp.f.to.IsZero() && !e.Time.Before(imp.f.to)) {
+		imp.c.outOfRange++
 
-		err := json.Unmarshal(scanner.Bytes(), &row)
-		if err != nil {
-			println(err.Error())
-			continue
-		}
+		return nil
+	}
 
-		row.Time, err = time.Parse("2006-01-02T15:04:05Z0700", row.Ts)
-		if err != nil {
-			println(err.Error())
-			continue
-		}
+	// Only successful page requests are counted.
+	if (e.Method != http.MethodGet && e.Method != http.MethodHead) || e.Status >= 400 {
+		imp.c.skipped++
 
-		if webstats.IsBot(row.UserAgent) {
-			botCnt++
-			continue
-		}
+		return nil
+	}
 
-		if row.Admin && !admins[row.Visitor] {
-			admins[row.Visitor] = true
+	if webstats.IsBot(e.UserAgent) {
+		imp.c.bots++
+
+		return nil
+	}
+
+	if imp.vs != nil {
+		lineHash := xxhash.Sum64(line)
+
+		var isNew bool
+
+		if imp.f.dryRun {
+			imported, err := imp.vs.IsImported(ctx, lineHash)
+			isNew = err == nil && !imported
+		} else if isNew, err = imp.vs.MarkImported(ctx, lineHash); err != nil {
+			return err
 		}
 
-		req, err := http.NewRequest(http.MethodGet, row.URL, nil)
-		if err != nil {
-			log.Fatal(err.Error())
+		if !isNew {
+			imp.c.duplicates++
+
+			return nil
 		}
+	}
+
+	if imp.c.firstEntry.IsZero() || e.Time.Before(imp.c.firstEntry) {
+		imp.c.firstEntry = e.Time
+	}
+
+	if e.Time.After(imp.c.lastEntry) {
+		imp.c.lastEntry = e.Time
+	}
+
+	req, err := http.NewRequest(http.MethodGet, e.URL, nil)
+	if err != nil {
+		imp.c.invalid++
+
+		return nil
+	}
+
+	req.RemoteAddr = e.IP
+	req.Header.Set("User-Agent", e.UserAgent)
+	req.Header.Set("Accept-Language", e.Lang)
+	req.Header.Set("X-Forwarded-For", e.ForwardedFor)
+	req.Header.Set("Referer", e.Referer)
+	req.Header.Set("Sec-Ch-Ua-Model", e.Device)
+
+	ip := auth.ClientIP(req, imp.f.trustedProxies)
+
+	var h uniq.Hash
+	if e.Visitor == "" || h.UnmarshalText([]byte(e.Visitor)) != nil || h == 0 {
+		// Visitors are not tagged in logs of other servers.
+		h = uniq.Hash(xxhash.Sum64String(ip + e.UserAgent))
+	}
 
-		ip := strings.TrimSuffix(row.ForwardedFor, ", 45.89.66.149")
+	isAdmin := e.Admin || (imp.vs != nil && imp.vs.IsAdmin(h))
 
-		req.Header.Set("User-Agent", row.UserAgent)
-		req.Header.Set("Accept-Language", row.Lang)
-		req.Header.Set("X-Forwarded-For", ip)
-		req.Header.Set("Referer", row.Referer)
-		req.Header.Set("Sec-Ch-Ua-Model", row.Device)
+	if imp.f.dryRun {
+		imp.count(e, isAdmin)
 
-		vs.CollectVisitor(row.Visitor, false, admins[row.Visitor], ip, row.Time, req)
+		return nil
+	}
+
+	imp.vs.CollectVisitor(h, false, isAdmin, ip, e.Time, req)
 
-		if admins[row.Visitor] {
-			adminCnt++
+	if !imp.count(e, isAdmin) {
+		return nil
+	}
 
-			continue
+	if strings.HasPrefix(e.URL, "/stats?") {
+		var inp visitor.CollectStats
+
+		if err := imp.dec(req, &inp, nil); err != nil {
+			return err
 		}
 
-		if strings.HasPrefix(row.URL, "/stats?") {
-			statsCnt++
+		imp.vs.CollectRequest(ctx, inp, e.Time)
+	}
 
-			var inp visitor.CollectStats
+	extRef := imp.externalReferer(e)
+	if extRef {
+		imp.vs.CollectRefer(ctx, h, e.Time, e.Referer, e.URL)
+	}
 
-			err = dec.Decode(req, &inp, nil)
-			if err != nil {
-				log.Fatal(err.Error())
-			}
+	if e.Time.Before(imp.f.legacyBefore) {
+		if !extRef {
+			e.Referer = ""
+		}
 
-			vs.CollectRequest(ctx, inp, row.Time)
+		if e.URL == "/" {
+			imp.vs.CollectMain(ctx, h, e.Referer, e.Time)
 		}
 
-		extRef := false
-		if row.Referer != "" {
-			ru, err := url.Parse(row.Referer)
-			if err == nil {
-				h := ru.Hostname()
-
-				if h != row.Host {
-					if h == "vearutop.p1cs.art" || h == "p1cs.1337.cx" || h == "mildly.photogenic.hk" ||
-						h == "gen.ix.tc" || h == "bigpi.cc" || h == "127.0.0.1" || h == "144.24.188.96" {
-					} else {
-						refHosts[h]++
-						refCnt++
-						extRef = true
-
-						vs.CollectRefer(ctx, row.Visitor, row.Time, row.Referer, row.URL)
-					}
-				}
+		p := strings.Split(e.URL, "/")
+		if len(p) == 3 {
+			if _, ok := imp.albums[p[1]]; ok { // Album exists.
+				imp.vs.CollectAlbum(ctx, h, p[1], e.Referer, e.Time)
 			}
 		}
+	}
 
-		// Support for legacy logs.
-		if row.Ts < "2024-02-10T02:08:27" {
-			if !extRef {
-				row.Referer = ""
-			}
+	return nil
+}
 
-			if row.URL == "/" {
-				vs.CollectMain(ctx, row.Visitor, row.Referer, row.Time)
-			}
+// count updates counters and tells if visit should be collected.
+func (imp *importer) count(e accesslog.Entry, isAdmin bool) bool {
+	if isAdmin {
+		imp.c.admins++
 
-			p := strings.Split(row.URL, "/")
-			if len(p) == 3 {
-				if _, ok := albums[p[1]]; ok { // Album exists.
-					albums[p[1]]++
-					vs.CollectAlbum(ctx, row.Visitor, p[1], row.Referer, row.Time)
+		return false
+	}
+
+	if strings.HasPrefix(e.URL, "/stats?") {
+		imp.c.statsReqs++
+	}
+
+	if imp.externalReferer(e) {
+		ru, _ := url.Parse(e.Referer)
+		imp.c.refererHosts[ru.Hostname()]++
+		imp.c.refers++
+	}
+
+	if e.Time.Before(imp.f.legacyBefore) {
+		p := strings.Split(e.URL, "/")
+		if e.URL == "/" || len(p) == 3 {
+			if _, ok := imp.albums[p[1]]; ok || e.URL == "/" {
+				imp.c.legacyPages++
+
+				if ok {
+					imp.albums[p[1]]++
 				}
 			}
 		}
+	}
+
+	return true
+}
+
+func (imp *importer) externalReferer(e accesslog.Entry) bool {
+	if e.Referer == "" {
+		return false
+	}
+
+	ru, err := url.Parse(e.Referer)
+	if err != nil {
+		return false
+	}
 
+	h := ru.Hostname()
+	if h == "" || h == e.Host || h == "127.0.0.1" || h == "localhost" {
+		return false
 	}
 
-	println("cnt", cnt)
-	println("statsCnt", statsCnt)
-	println("botCnt", botCnt)
-	println("adminCnt", adminCnt)
-	println("admins", len(admins))
-	println("refCnt", refCnt)
-	fmt.Println(refHosts)
-	fmt.Println("ALBUMS", albums)
+	for _, own := range imp.f.ownHosts {
+		if h == own {
+			return false
+		}
+	}
+
+	return true
+}
 
-	if scanner.Err() != nil {
-		log.Fatal(scanner.Err())
+func (imp *importer) report() {
+	c := imp.c
+
+	if imp.f.dryRun {
+		fmt.Println("Dry run, nothing was written.")
+	}
+
+	fmt.Println("lines", c.lines)
+	fmt.Println("invalid", c.invalid)
+	fmt.Println("out of range", c.outOfRange)
+	fmt.Println("skipped (method or status)", c.skipped)
+	fmt.Println("bots", c.bots)
+	fmt.Println("already imported", c.duplicates)
+	fmt.Println("admin requests", c.admins)
+	fmt.Println("stats requests", c.statsReqs)
+	fmt.Println("external refers", c.refers)
+	fmt.Println("legacy page views", c.legacyPages)
+
+	if !c.firstEntry.IsZero() {
+		fmt.Println("time range", c.firstEntry.Format(time.RFC3339), "-", c.lastEntry.Format(time.RFC3339))
 	}
+
+	fmt.Println("referer hosts", c.refererHosts)
+	fmt.Println("albums", imp.albums)
 }
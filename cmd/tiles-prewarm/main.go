@@ -0,0 +1,159 @@
+// Package main provides a tool to prewarm map tiles cache for albums and GPX tracks.
+//
+// Bulk download is only allowed from a tile server configured in map settings,
+// default OpenStreetMap tile server prohibits it by usage policy.
+//
+// cd photo-blog-data && go run ../cmd/tiles-prewarm -max-zoom 14
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/bool64/brick/database"
+	"github.com/bool64/cache"
+	"github.com/bool64/stats"
+	"github.com/bool64/zapctxd"
+	"github.com/vearutop/photo-blog/internal/infra/geo/tiles"
+	"github.com/vearutop/photo-blog/internal/infra/settings"
+	"github.com/vearutop/photo-blog/internal/infra/storage"
+	"github.com/vearutop/photo-blog/internal/infra/storage/sqlite"
+	"github.com/vearutop/photo-blog/pkg/sqlitec"
+	"go.uber.org/zap"
+	_ "modernc.org/sqlite" // SQLite3 driver.
+)
+
+func main() {
+	minZoom := flag.Uint("min-zoom", 1, "min zoom level")
+	maxZoom := flag.Uint("max-zoom", 13, "max zoom level")
+	maxTiles := flag.Int("max-tiles", 20000, "max number of tiles to fetch")
+	delay := flag.Duration("delay", time.Second, "delay between remote requests, at least 100ms")
+	dryRun := flag.Bool("dry-run", false, "only count tiles")
+	flag.Parse()
+
+	l := zapctxd.New(zapctxd.Config{Level: zap.WarnLevel})
+	cfg := database.Config{}
+	cfg.DriverName = "sqlite"
+	cfg.MaxOpen = 1
+	cfg.MaxIdle = 1
+	cfg.DSN = "db.sqlite" + "?_time_format=sqlite"
+	cfg.ApplyMigrations = true
+
+	st, err := database.SetupStorageDSN(cfg, l.CtxdLogger(), stats.NoOp{}, sqlite.Migrations)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	cfg.DSN = "map-tiles.sqlite" + "?_time_format=sqlite"
+	tst, err := database.SetupStorageDSN(cfg, l.CtxdLogger(), stats.NoOp{}, sqlitec.Migrations)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	sm, err := settings.NewManager(storage.NewSettingsRepository(st), nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	maps := sm.Maps()
+	if maps.LocalTiles != "" {
+		log.Fatal("local tiles archive is configured, nothing to prewarm")
+	}
+
+	if tiles.IsDefault(maps.Tiles) {
+		log.Fatal("bulk download from OpenStreetMap tile server is not allowed, configure custom tiles in map settings")
+	}
+
+	if *delay < 100*time.Millisecond {
+		*delay = 100 * time.Millisecond
+	}
+
+	ctx := context.Background()
+
+	var boxes []tiles.BBox
+
+	// Bounding boxes of geotagged images in each album.
+	rows, err := st.DB().QueryContext(ctx, `SELECT MIN(g.latitude), MIN(g.longitude), MAX(g.latitude), MAX(g.longitude)
+FROM `+storage.AlbumImageTable+` ai JOIN `+storage.GpsTable+` g ON g.hash = ai.image_hash
+GROUP BY ai.album_hash`)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for rows.Next() {
+		var b tiles.BBox
+		if err := rows.Scan(&b.MinLat, &b.MinLon, &b.MaxLat, &b.MaxLon); err != nil {
+			log.Fatal(err)
+		}
+
+		boxes = append(boxes, b)
+	}
+
+	if err := rows.Close(); err != nil {
+		log.Fatal(err)
+	}
+
+	gpxs, err := storage.NewGpxRepository(st).FindAll(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, g := range gpxs {
+		s := g.Settings.Val
+		boxes = append(boxes, tiles.BBox{MinLat: s.MinLat, MinLon: s.MinLon, MaxLat: s.MaxLat, MaxLon: s.MaxLon})
+	}
+
+	c := sqlitec.NewDBMapOf[[]byte](tst, func(cfg *cache.ConfigOf[[]byte]) {
+		cfg.TimeToLive = 7 * 24 * time.Hour
+	})
+
+	seen := map[string]bool{}
+	fetched, skipped := 0, 0
+
+	for z := *minZoom; z <= *maxZoom; z++ {
+		zs := strconv.Itoa(int(z))
+
+		for _, b := range boxes {
+			for _, t := range tiles.Cover(b, uint8(z)) {
+				u := tiles.URL(maps.Tiles, "a", "", zs, strconv.Itoa(int(t.X)), strconv.Itoa(int(t.Y)))
+				if seen[u] {
+					continue
+				}
+
+				seen[u] = true
+
+				if _, err := c.Read(ctx, tiles.CacheKey(u)); err == nil {
+					skipped++
+
+					continue
+				}
+
+				if fetched >= *maxTiles {
+					log.Fatalf("max tiles limit reached at zoom %d, fetched %d, skipped %d", z, fetched, skipped)
+				}
+
+				fetched++
+
+				if *dryRun {
+					continue
+				}
+
+				d, err := tiles.Fetch(ctx, u, "")
+				if err != nil {
+					log.Fatalf("fetch %s: %v", u, err)
+				}
+
+				if err := c.Write(ctx, tiles.CacheKey(u), d); err != nil {
+					log.Fatal(err)
+				}
+
+				time.Sleep(*delay)
+			}
+		}
+
+		log.Printf("zoom %d: fetched %d, skipped %d", z, fetched, skipped)
+	}
+}
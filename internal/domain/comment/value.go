@@ -1,18 +1,38 @@
 package comment
 
 import (
+	"context"
 	"time"
 
 	"github.com/vearutop/photo-blog/internal/domain/uniq"
 )
 
+// Thread types.
+const (
+	ThreadImage = "image"
+	ThreadAlbum = "album"
+)
+
+// DeletedText replaces text of a deleted message that still has replies.
+const DeletedText = "[deleted]"
+
 type Message struct {
 	uniq.Head
 
-	ThreadHash  uniq.Hash `db:"thread_hash" json:"thread_hash"`
-	VisitorHash uniq.Hash `db:"visitor_hash" json:"visitor_hash"`
-	Approved    bool      `db:"approved" json:"approved"`
-	Text        string    `db:"text" json:"text"`
+	ThreadHash  uniq.Hash  `db:"thread_hash" json:"thread_hash"`
+	ParentHash  uniq.Hash  `db:"parent_hash" json:"parent_hash,omitempty" description:"Message this one replies to."`
+	VisitorHash uniq.Hash  `db:"visitor_hash" json:"visitor_hash"`
+	Approved    bool       `db:"approved" json:"approved"`
+	Rejected    bool       `db:"rejected" json:"rejected,omitempty"`
+	SpamScore   float64    `db:"spam_score" json:"spam_score,omitempty"`
+	IP          string     `db:"ip" json:"-"`
+	EditedAt    *time.Time `db:"edited_at" json:"edited_at,omitempty"`
+	Text        string     `db:"text" json:"text"`
+}
+
+// Pending is true for messages waiting for moderation.
+func (m Message) Pending() bool {
+	return !m.Approved && !m.Rejected
 }
 
 type Thread struct {
@@ -22,3 +42,25 @@ type Thread struct {
 	RelatedHash uniq.Hash  `db:"related_hash" json:"related_hash"`
 	RelatedAt   *time.Time `db:"related_at" json:"related_at"`
 }
+
+// ThreadHash identifies a thread of an entity.
+func ThreadHash(typ string, relatedHash uniq.Hash, relatedAt *time.Time) uniq.Hash {
+	th := typ + relatedHash.String()
+	if relatedAt != nil {
+		th += relatedAt.String()
+	}
+
+	return uniq.StringHash(th)
+}
+
+// SpamCandidate describes a message for spam scoring.
+type SpamCandidate struct {
+	Message Message
+	Name    string
+	Agent   string
+}
+
+// SpamScorer estimates how likely a message is spam, 0 is clean, 1 is certain spam.
+type SpamScorer interface {
+	SpamScore(ctx context.Context, c SpamCandidate) (float64, error)
+}
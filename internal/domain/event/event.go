@@ -0,0 +1,68 @@
+// Package event describes domain events published to webhooks and event stream.
+package event
+
+import (
+	"context"
+	"time"
+
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+)
+
+// Event types.
+const (
+	AlbumCreated    = "album.created"
+	AlbumUpdated    = "album.updated"
+	AlbumDeleted    = "album.deleted"
+	AlbumRestored   = "album.restored"
+	ImageAdded      = "image.added"
+	ImageRemoved    = "image.removed"
+	ImageRestored   = "image.restored"
+	ImageIndexed    = "image.indexed"
+	CommentApproved = "comment.approved"
+)
+
+// Types lists all event types.
+var Types = []string{
+	AlbumCreated, AlbumUpdated, AlbumDeleted, AlbumRestored,
+	ImageAdded, ImageRemoved, ImageRestored, ImageIndexed,
+	CommentApproved,
+}
+
+// Event describes a change of site content.
+type Event struct {
+	ID          string    `json:"id" description:"Unique and increasing event ID."`
+	Type        string    `json:"type"`
+	Time        time.Time `json:"time"`
+	AlbumName   string    `json:"album_name,omitempty"`
+	AlbumHash   uniq.Hash `json:"album_hash,omitempty"`
+	ImageHash   uniq.Hash `json:"image_hash,omitempty"`
+	MessageHash uniq.Hash `json:"message_hash,omitempty"`
+}
+
+// Album creates album event.
+func Album(typ string, name string) Event {
+	return Event{Type: typ, AlbumName: name, AlbumHash: photo.AlbumHash(name)}
+}
+
+// Image creates image event, album name is optional.
+func Image(typ string, albumName string, imageHash uniq.Hash) Event {
+	e := Event{Type: typ, ImageHash: imageHash}
+
+	if albumName != "" {
+		e.AlbumName = albumName
+		e.AlbumHash = photo.AlbumHash(albumName)
+	}
+
+	return e
+}
+
+// Comment creates comment event.
+func Comment(typ string, messageHash uniq.Hash) Event {
+	return Event{Type: typ, MessageHash: messageHash}
+}
+
+// Publisher delivers events to subscribers.
+type Publisher interface {
+	Publish(ctx context.Context, e Event)
+}
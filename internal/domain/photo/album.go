@@ -17,6 +17,9 @@ const (
 	Orphan   = "orphan-photos"
 	Broken   = "broken-photos"
 	Favorite = "favorite-photos"
+
+	// FavoriteListPrefix is followed by list hash in a read-only link to a named list of favorite photos.
+	FavoriteListPrefix = "favorite-list-"
 )
 
 type AlbumImageAdder interface {
@@ -35,16 +38,20 @@ type AlbumImageFinder interface {
 	SearchImages(ctx context.Context, query string) ([]Image, error)
 	FindBrokenImages(ctx context.Context) ([]Image, error)
 	FindRemoteImages(ctx context.Context) ([]Image, error)
+	FindRecentImages(ctx context.Context, limit uint64, albumHashes ...uniq.Hash) ([]Image, error)
 	FindImageAlbums(ctx context.Context, excludeAlbum uniq.Hash, imageHashes ...uniq.Hash) (map[uniq.Hash][]Album, error)
 }
 
 type AlbumSettings struct {
-	Description     string              `json:"description,omitempty" formType:"textarea" title:"Description" description:"Description of an album, can contain HTML."`
-	GpxTracksHashes []uniq.Hash         `json:"gpx_tracks_hashes,omitempty" items.title:"Hash" title:"GPX track hashes"`
-	NewestFirst     bool                `json:"newest_first,omitempty" noTitle:"true" inlineTitle:"Newest first" description:"Show newest images at the top."`
-	DailyRulers     bool                `json:"daily_rulers,omitempty" noTitle:"true" inlineTitle:"Daily rulers" description:"Show date splits between the photos."`
-	Texts           []txt.Chronological `json:"texts,omitempty" title:"Chronological texts"`
-	TextReplaces    txt.Replaces        `json:"text_replaces,omitempty" title:"Text replaces"`
+	Description       string              `json:"description,omitempty" formType:"textarea" title:"Description" description:"Description of an album, can contain HTML."`
+	GpxTracksHashes   []uniq.Hash         `json:"gpx_tracks_hashes,omitempty" items.title:"Hash" title:"GPX track hashes"`
+	GpxClockOffset    int                 `json:"gpx_clock_offset,omitempty" title:"Camera clock offset, seconds" description:"Added to image time to match GPX track time, e.g. -3600 if camera clock is an hour ahead."`
+	GpxMaxGap         int                 `json:"gpx_max_gap,omitempty" title:"Max GPX gap, seconds" description:"Images farther in time from track points are not geotagged, default 600."`
+	NewestFirst       bool                `json:"newest_first,omitempty" noTitle:"true" inlineTitle:"Newest first" description:"Show newest images at the top."`
+	MostEngagingFirst bool                `json:"most_engaging_first,omitempty" noTitle:"true" inlineTitle:"Most engaging first" description:"Sort images by visitor engagement score, overrides time order."`
+	DailyRulers       bool                `json:"daily_rulers,omitempty" noTitle:"true" inlineTitle:"Daily rulers" description:"Show date splits between the photos."`
+	Texts             []txt.Chronological `json:"texts,omitempty" title:"Chronological texts"`
+	TextReplaces      txt.Replaces        `json:"text_replaces,omitempty" title:"Text replaces"`
 
 	// Deprecated: TODO remove and implement as separate entity.
 	Redirect        string     `json:"redirect,omitempty" title:"Relative or absolute URL to redirect to with HTTP 301 status."`
@@ -61,7 +68,11 @@ type AlbumSettings struct {
 	MapMinLat float64 `json:"map_min_lat,omitempty" title:"Map min latitude" description:"Overrides map default boundary."`
 	MapMaxLat float64 `json:"map_max_lat,omitempty" title:"Map max latitude" description:"Overrides map default boundary."`
 
-	CollabKey string `json:"collab_key,omitempty" title:"Collaboration key, when provided, user can add/delete album content."`
+	CollabKey     string         `json:"collab_key,omitempty" title:"Collaboration key, when provided, user can add/delete album content."`
+	Collaborators []Collaborator `json:"collaborators,omitempty" title:"Collaborators" description:"Named keys with permissions, changes are recorded in audit log."`
+
+	ProofingKey      string     `json:"proofing_key,omitempty" title:"Proofing key" description:"Client with this key can select or reject images and submit a final selection."`
+	ProofingDeadline *time.Time `json:"proofing_deadline,omitempty" title:"Proofing deadline" description:"Selection can not be changed or submitted after this time."`
 
 	ShowPrivateSubAlbums bool     `json:"show_private_sub_albums,omitempty" title:"Show private sub albums"`
 	ShowHiddenSubAlbums  bool     `json:"show_hidden_sub_albums,omitempty" title:"Show hidden sub albums"`
@@ -0,0 +1,86 @@
+package photo
+
+import (
+	"time"
+
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+)
+
+// CollabPermission defines what collaborator can do with album content.
+type CollabPermission string
+
+// Collaborator permissions.
+const (
+	CollabAdd       CollabPermission = "add"
+	CollabAddDelete CollabPermission = "add_delete"
+)
+
+// Enum lists collaborator permissions.
+func (CollabPermission) Enum() []any {
+	return []any{CollabAdd, CollabAddDelete}
+}
+
+// LegacyCollaborator is a name of collaborator with AlbumSettings.CollabKey.
+const LegacyCollaborator = "collaborator"
+
+// Collaborator is a named access key to manage album content.
+type Collaborator struct {
+	Name       string           `json:"name" required:"true" title:"Name" description:"Shown in audit log."`
+	Key        string           `json:"key" required:"true" minLength:"8" title:"Key" description:"Secret value for collab_key URL parameter."`
+	Permission CollabPermission `json:"permission,omitempty" title:"Permission" default:"add"`
+	ExpiresAt  *time.Time       `json:"expires_at,omitempty" title:"Expires at" description:"Key is not accepted after this time."`
+}
+
+// CanDelete tells if collaborator can remove album content.
+func (c Collaborator) CanDelete() bool {
+	return c.Permission == CollabAddDelete
+}
+
+// Collaborator finds valid collaborator by key.
+func (s AlbumSettings) Collaborator(key string, now time.Time) (Collaborator, bool) {
+	if key == "" {
+		return Collaborator{}, false
+	}
+
+	if s.CollabKey == key {
+		return Collaborator{Name: LegacyCollaborator, Key: key, Permission: CollabAddDelete}, true
+	}
+
+	for _, c := range s.Collaborators {
+		if c.Key != key {
+			continue
+		}
+
+		if c.ExpiresAt != nil && now.After(*c.ExpiresAt) {
+			return Collaborator{}, false
+		}
+
+		return c, true
+	}
+
+	return Collaborator{}, false
+}
+
+// AuditAction is a kind of album content change.
+type AuditAction string
+
+// Audit actions.
+const (
+	AuditAdd     AuditAction = "add"
+	AuditRemove  AuditAction = "remove"
+	AuditRestore AuditAction = "restore"
+	AuditCreate  AuditAction = "create"
+	AuditUpdate  AuditAction = "update"
+	AuditDelete  AuditAction = "delete"
+)
+
+// AuditEntry records a change of album content with its author.
+type AuditEntry struct {
+	ID        int         `db:"id,omitempty" json:"id"`
+	CreatedAt time.Time   `db:"created_at" json:"created_at"`
+	AlbumHash uniq.Hash   `db:"album_hash" json:"album_hash"`
+	AlbumName string      `db:"album_name" json:"album_name"`
+	ImageHash uniq.Hash   `db:"image_hash" json:"image_hash,omitempty"`
+	Action    AuditAction `db:"action" json:"action"`
+	Actor     string      `db:"actor" json:"actor" description:"Admin, named collaborator, visitor or system."`
+}
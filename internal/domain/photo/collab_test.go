@@ -0,0 +1,51 @@
+package photo_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+)
+
+func TestAlbumSettings_Collaborator(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	s := photo.AlbumSettings{
+		CollabKey: "legacy-key",
+		Collaborators: []photo.Collaborator{
+			{Name: "ann", Key: "ann-key-1"},
+			{Name: "bob", Key: "bob-key-1", Permission: photo.CollabAddDelete, ExpiresAt: &future},
+			{Name: "eve", Key: "eve-key-1", Permission: photo.CollabAddDelete, ExpiresAt: &past},
+		},
+	}
+
+	c, ok := s.Collaborator("legacy-key", now)
+	assert.True(t, ok)
+	assert.Equal(t, photo.LegacyCollaborator, c.Name)
+	assert.True(t, c.CanDelete(), "legacy key keeps full access")
+
+	c, ok = s.Collaborator("ann-key-1", now)
+	assert.True(t, ok)
+	assert.Equal(t, "ann", c.Name)
+	assert.False(t, c.CanDelete(), "default permission only adds")
+
+	c, ok = s.Collaborator("bob-key-1", now)
+	assert.True(t, ok)
+	assert.Equal(t, "bob", c.Name)
+	assert.True(t, c.CanDelete())
+
+	_, ok = s.Collaborator("bob-key-1", future.Add(time.Second))
+	assert.False(t, ok, "expired key")
+
+	_, ok = s.Collaborator("eve-key-1", now)
+	assert.False(t, ok, "expired key")
+
+	_, ok = s.Collaborator("unknown", now)
+	assert.False(t, ok)
+
+	_, ok = photo.AlbumSettings{}.Collaborator("", now)
+	assert.False(t, ok, "empty key never matches")
+}
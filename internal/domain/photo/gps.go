@@ -13,4 +13,5 @@ type Gps struct {
 	Latitude  float64   `db:"latitude" title:"Latitude" json:"latitude"`
 	Longitude float64   `db:"longitude" title:"Longitude" json:"longitude"`
 	GpsTime   time.Time `db:"time" title:"GPS Timestamp" json:"time"`
+	Derived   bool      `db:"derived" title:"Derived" json:"derived,omitempty" description:"Position is interpolated from GPX tracks of an album."`
 }
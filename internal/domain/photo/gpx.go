@@ -9,6 +9,8 @@ import (
 	"github.com/bool64/sqluct"
 	"github.com/tkrajina/gpxgo/gpx"
 	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/pkg/trackfile"
+	"github.com/vearutop/photo-blog/pkg/trackstats"
 )
 
 type GpxSettings struct {
@@ -17,6 +19,14 @@ type GpxSettings struct {
 	MinLon float64 `json:"minLon"`
 	MaxLat float64 `json:"maxLat"`
 	MaxLon float64 `json:"maxLon"`
+
+	trackstats.Stats
+	Days []trackstats.Day `json:"days,omitempty" description:"Statistics by local days."`
+}
+
+// HasStats is false for tracks indexed before statistics were introduced.
+func (s GpxSettings) HasStats() bool {
+	return s.Distance != 0
 }
 
 func (s *GpxSettings) Scan(src any) error {
@@ -46,8 +56,9 @@ type Gpx struct {
 	Settings sqluct.JSON[GpxSettings] `db:"settings"`
 }
 
+// Load reads track file, FIT, TCX and GeoJSON files are converted to GPX.
 func (g *Gpx) Load() (*gpx.GPX, error) {
-	return gpx.ParseFile(g.Path)
+	return trackfile.ParseFile(g.Path)
 }
 
 func (g *Gpx) Index() error {
@@ -86,6 +97,9 @@ func (g *Gpx) Index() error {
 		}
 	}
 
+	s.Stats = trackstats.Compute(gpxFile)
+	s.Days = trackstats.Days(gpxFile)
+
 	g.Settings.Val = s
 
 	return nil
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/pkg/imgedit"
 )
 
 type IndexingFlags struct {
@@ -38,6 +39,9 @@ type ImageSettings struct {
 	HTTPSources []string  `json:"http_sources,omitempty"`
 	Rotate      int       `json:"rotate,omitempty"`
 	UpdatedAt   time.Time `json:"updated_at,omitzero"`
+
+	// Edits are applied to derivatives, original file stays untouched.
+	Edits *imgedit.Edits `json:"edits,omitempty" title:"Edits"`
 }
 
 // TODO: generalize scanner with generics.
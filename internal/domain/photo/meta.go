@@ -5,6 +5,7 @@ import (
 	"github.com/vearutop/image-prompt/multi"
 	"github.com/vearutop/photo-blog/internal/domain/uniq"
 	"github.com/vearutop/photo-blog/internal/infra/image/faces"
+	"github.com/vearutop/photo-blog/pkg/gazetteer"
 )
 
 type Meta struct {
@@ -43,6 +44,8 @@ type MetaData struct {
 	ImageClassification []ImageLabel        `json:"image_classification,omitempty"`
 	Faces               *[]faces.GoFaceFace `json:"faces,omitempty"`
 	GeoLabel            *string             `json:"geo_label,omitempty"`
+	GeoLabels           map[string]string   `json:"geo_labels,omitempty" description:"Localized geo labels keyed by language."`
+	Place               *gazetteer.Location `json:"place,omitempty"`
 	CFResnet50          *[]Label            `json:"cf_resnet_50,omitempty"`
 	FaceVectors         *[]Face             `json:"face_vectors,omitempty"`
 	ImageDescriptions   []multi.Result      `json:"image_descriptions,omitempty"`
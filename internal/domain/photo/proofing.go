@@ -0,0 +1,39 @@
+package photo
+
+import (
+	"time"
+
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+)
+
+// ProofingState is a client decision about an image.
+type ProofingState string
+
+// Proofing states.
+const (
+	ProofingSelected ProofingState = "selected"
+	ProofingRejected ProofingState = "rejected"
+)
+
+// Enum lists proofing states.
+func (ProofingState) Enum() []any {
+	return []any{ProofingSelected, ProofingRejected}
+}
+
+// ProofingMark is a client decision about an image in an album.
+type ProofingMark struct {
+	AlbumHash uniq.Hash     `db:"album_hash" json:"-"`
+	ImageHash uniq.Hash     `db:"image_hash" json:"image_hash"`
+	State     ProofingState `db:"state" json:"state,omitempty"`
+	Note      string        `db:"note" json:"note,omitempty"`
+	UpdatedAt time.Time     `db:"updated_at" json:"updated_at"`
+}
+
+// ProofingSubmission is a final client selection of an album, marks can not be changed after submission.
+type ProofingSubmission struct {
+	AlbumHash   uniq.Hash `db:"album_hash" json:"-"`
+	SubmittedAt time.Time `db:"submitted_at" json:"submitted_at"`
+	Name        string    `db:"name" json:"name,omitempty"`
+	Comment     string    `db:"comment" json:"comment,omitempty"`
+	Selected    int       `db:"selected" json:"selected"`
+}
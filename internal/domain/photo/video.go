@@ -0,0 +1,29 @@
+package photo
+
+import (
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+)
+
+// Video describes a video clip, it is paired with an Image of the same hash that holds poster frame.
+type Video struct {
+	uniq.File
+
+	Duration float64 `db:"duration" title:"Duration, seconds" json:"duration,omitempty" readOnly:"true"`
+	Width    int64   `db:"width" title:"Width, px" json:"width,omitempty" readOnly:"true"`
+	Height   int64   `db:"height" title:"Height, px" json:"height,omitempty" readOnly:"true"`
+	Mime     string  `db:"mime" title:"MIME type" json:"mime,omitempty" readOnly:"true"`
+}
+
+// VideoMimeType returns MIME type by file extension or empty string for unsupported files.
+func VideoMimeType(ext string) string {
+	switch ext {
+	case ".mp4", ".m4v":
+		return "video/mp4"
+	case ".mov":
+		return "video/quicktime"
+	case ".webm":
+		return "video/webm"
+	}
+
+	return ""
+}
@@ -0,0 +1,11 @@
+package site
+
+import "github.com/vearutop/photo-blog/internal/domain/uniq"
+
+// Follower is a remote ActivityPub actor following the site, hash is of actor IRI.
+type Follower struct {
+	uniq.Head
+
+	Actor string `db:"actor" json:"actor"`
+	Inbox string `db:"inbox" json:"inbox" description:"Shared inbox if available."`
+}
@@ -6,5 +6,6 @@ type Visitor struct {
 	uniq.Head
 
 	Approved bool   `db:"approved" json:"approved"`
+	Banned   bool   `db:"banned" json:"banned"`
 	Name     string `db:"name" json:"name"`
 }
@@ -4,4 +4,8 @@ const (
 	IndexImage   = "index_image"
 	AlbumChanged = "album_changed"
 	IndexRemote  = "index_remote"
+	ImageChanged = "image_changed"
+
+	WebhookDelivery     = "webhook_delivery"
+	ActivityPubDelivery = "activitypub_delivery"
 )
@@ -0,0 +1,318 @@
+// Package archive exports and imports site data as a portable backup.
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/bool64/ctxd"
+	"github.com/bool64/sqluct"
+	"github.com/vearutop/photo-blog/internal/infra/dep"
+	"github.com/vearutop/photo-blog/internal/infra/settings"
+	"github.com/vearutop/photo-blog/pkg/backup"
+)
+
+const app = "photo-blog"
+
+// Tables of main database in restore order.
+var tables = []string{
+	"settings",
+	"album",
+	"image",
+	"album_image",
+	"exif",
+	"gps",
+	"meta",
+	"gpx",
+	"video",
+	"favorite_image",
+	"favorite_list",
+	"visitor",
+	"thread",
+	"message",
+	"follower",
+	"proofing_mark",
+	"proofing_submission",
+	"audit_log",
+	"trash_album",
+	"trash_album_image",
+}
+
+// Tables with paths to original files.
+var pathTables = map[string]string{
+	"image": "path",
+	"gpx":   "path",
+	"video": "path",
+	"thumb": "file_path",
+}
+
+// ExportOptions controls archive contents.
+type ExportOptions struct {
+	Originals bool `query:"originals" description:"Include original files of images, videos and tracks."`
+	Thumbs    bool `query:"thumbs" description:"Include generated thumbnails."`
+}
+
+// ImportOptions controls restoration.
+type ImportOptions struct {
+	// Paths maps path prefixes of source machine to local ones.
+	Paths backup.PathMap
+
+	// Originals enables writing original files from archive, existing files are not overwritten.
+	Originals bool
+}
+
+// ImportResult describes restored data.
+type ImportResult struct {
+	Manifest     backup.Manifest `json:"manifest"`
+	Tables       map[string]int  `json:"tables"`
+	Files        int             `json:"files"`
+	SkippedFiles int             `json:"skipped_files"`
+}
+
+// Service exports and imports site data.
+type Service struct {
+	logger   ctxd.Logger
+	db       *sqluct.Storage
+	thumbs   *sqluct.Storage
+	settings *settings.Manager
+	dc       *dep.Cache
+}
+
+// NewService creates archive service.
+func NewService(logger ctxd.Logger, db, thumbs *sqluct.Storage, settings *settings.Manager, dc *dep.Cache) *Service {
+	return &Service{
+		logger:   logger,
+		db:       db,
+		thumbs:   thumbs,
+		settings: settings,
+		dc:       dc,
+	}
+}
+
+// Export writes archive.
+func (s *Service) Export(ctx context.Context, w io.Writer, opt ExportOptions) error {
+	source, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	bw := backup.NewWriter(w, app, source)
+
+	for _, t := range tables {
+		if err := bw.WriteTable(ctx, s.db.DB().DB, t); err != nil {
+			return err
+		}
+	}
+
+	if opt.Thumbs {
+		if err := bw.WriteTable(ctx, s.thumbs.DB().DB, "thumb"); err != nil {
+			return err
+		}
+	}
+
+	if opt.Originals {
+		if err := s.exportOriginals(ctx, bw); err != nil {
+			return err
+		}
+	}
+
+	if err := bw.Close(); err != nil {
+		return err
+	}
+
+	s.logger.Important(ctx, "site exported", "originals", opt.Originals, "thumbs", opt.Thumbs)
+
+	return nil
+}
+
+func (s *Service) exportOriginals(ctx context.Context, bw *backup.Writer) error {
+	var paths []string
+
+	if err := s.db.Select(ctx,
+		sqluct.Plain("SELECT path FROM image UNION SELECT path FROM gpx UNION SELECT path FROM video"),
+		&paths); err != nil {
+		return fmt.Errorf("find original files: %w", err)
+	}
+
+	for _, p := range paths {
+		if p == "" {
+			continue
+		}
+
+		if err := s.exportFile(bw, p); err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+
+			s.logger.Warn(ctx, "skipping missing original file", "path", p)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) exportFile(bw *backup.Writer, p string) error {
+	f, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	st, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	return bw.WriteFile(p, st.ModTime(), f)
+}
+
+// Import restores archive, existing rows with same keys are replaced.
+func (s *Service) Import(ctx context.Context, r io.ReaderAt, size int64, opt ImportOptions) (ImportResult, error) {
+	res := ImportResult{Tables: map[string]int{}}
+
+	br, err := backup.NewReader(r, size)
+	if err != nil {
+		return res, err
+	}
+
+	res.Manifest = br.Manifest
+
+	// Only files referenced by restored rows are written.
+	referenced := map[string]bool{}
+
+	restore := func(st *sqluct.Storage, table string) error {
+		if !br.HasTable(table) {
+			return nil
+		}
+
+		cnt, err := br.RestoreTable(ctx, st.DB().DB, table, func(row backup.Row) (skip bool) {
+			if col, ok := pathTables[table]; ok {
+				if p, ok := row[col].(string); ok && p != "" {
+					p = opt.Paths.Apply(p)
+					row[col] = p
+
+					if table != "thumb" {
+						referenced[p] = true
+					}
+				}
+			}
+
+			return false
+		})
+		if err != nil {
+			return err
+		}
+
+		res.Tables[table] = cnt
+
+		return nil
+	}
+
+	for _, t := range tables {
+		if err := restore(s.db, t); err != nil {
+			return res, err
+		}
+	}
+
+	if err := restore(s.thumbs, "thumb"); err != nil {
+		return res, err
+	}
+
+	if opt.Originals {
+		for _, f := range br.Files() {
+			p := opt.Paths.Apply(f.Name)
+
+			if !referenced[p] || !backup.IsSafePath(filepath.ToSlash(p)) {
+				s.logger.Warn(ctx, "skipping unexpected archive file", "name", f.Name, "path", p)
+
+				res.SkippedFiles++
+
+				continue
+			}
+
+			written, err := s.importFile(f, p)
+			if err != nil {
+				return res, err
+			}
+
+			if written {
+				res.Files++
+			} else {
+				res.SkippedFiles++
+			}
+		}
+	}
+
+	if err := s.invalidate(ctx); err != nil {
+		return res, err
+	}
+
+	s.logger.Important(ctx, "site imported", "source", br.Manifest.Source, "tables", res.Tables,
+		"files", res.Files, "skippedFiles", res.SkippedFiles)
+
+	return res, nil
+}
+
+// importFile writes file if it does not exist, relative paths are resolved against working directory.
+func (s *Service) importFile(f backup.File, p string) (written bool, err error) {
+	if _, err := os.Lstat(p); err == nil {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return false, err
+	}
+
+	src, err := f.Open()
+	if err != nil {
+		return false, err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(p, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := io.Copy(dst, src); err != nil {
+		_ = dst.Close()
+
+		return false, fmt.Errorf("write %s: %w", p, err)
+	}
+
+	if err := dst.Close(); err != nil {
+		return false, err
+	}
+
+	return true, os.Chtimes(p, f.Modified, f.Modified)
+}
+
+// invalidate reloads settings and drops cached pages.
+func (s *Service) invalidate(ctx context.Context) error {
+	if err := s.settings.Reload(ctx); err != nil {
+		return fmt.Errorf("reload settings: %w", err)
+	}
+
+	if err := s.dc.AlbumListChanged(ctx); err != nil {
+		return err
+	}
+
+	var names []string
+
+	if err := s.db.Select(ctx, s.db.QueryBuilder().Select("name").From("album"), &names); err != nil {
+		return fmt.Errorf("find albums: %w", err)
+	}
+
+	for _, name := range names {
+		if err := s.dc.AlbumChanged(ctx, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bool64/brick/database"
+	"github.com/bool64/ctxd"
+	"github.com/bool64/sqluct"
+	"github.com/bool64/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/photo-blog/internal/infra/dep"
+	"github.com/vearutop/photo-blog/internal/infra/settings"
+	"github.com/vearutop/photo-blog/internal/infra/storage"
+	"github.com/vearutop/photo-blog/internal/infra/storage/sqlite"
+	"github.com/vearutop/photo-blog/internal/infra/storage/sqlite_thumbs"
+	"github.com/vearutop/photo-blog/pkg/backup"
+	"github.com/vearutop/photo-blog/pkg/qlite"
+	"github.com/vearutop/photo-blog/pkg/sqlitec/invalidation"
+	_ "modernc.org/sqlite"
+)
+
+func testStorage(t *testing.T, migrations fs.FS) *sqluct.Storage {
+	t.Helper()
+
+	cfg := database.Config{
+		DriverName:      "sqlite",
+		DSN:             filepath.Join(t.TempDir(), "db.sqlite") + "?_time_format=sqlite",
+		ApplyMigrations: true,
+		MaxOpen:         1,
+		MaxIdle:         1,
+	}
+
+	st, err := database.SetupStorageDSN(cfg, ctxd.NoOpLogger{}, stats.NoOp{}, migrations)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, st.DB().DB.Close())
+	})
+
+	return st
+}
+
+type cacheDeps struct {
+	*storage.AlbumRepository
+
+	broker *qlite.Broker
+}
+
+func (cacheDeps) CtxdLogger() ctxd.Logger {
+	return ctxd.NoOpLogger{}
+}
+
+func (d cacheDeps) QueueBroker() *qlite.Broker {
+	return d.broker
+}
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+
+	db := testStorage(t, sqlite.Migrations)
+
+	d := cacheDeps{
+		AlbumRepository: storage.NewAlbumRepository(db, storage.NewImageRepository(db), storage.NewMetaRepository(db)),
+		broker:          qlite.NewBroker(testStorage(t, qlite.Migrations)),
+	}
+
+	t.Cleanup(d.broker.Close)
+
+	dc := dep.NewCache(d, testStorage(t, invalidation.Migrations))
+
+	sm, err := settings.NewManager(storage.NewSettingsRepository(db), dc)
+	require.NoError(t, err)
+
+	return NewService(ctxd.NoOpLogger{}, db, testStorage(t, sqlite_thumbs.Migrations), sm, dc)
+}
+
+func TestTables_coverSchema(t *testing.T) {
+	st := testStorage(t, sqlite.Migrations)
+
+	var names []string
+
+	require.NoError(t, st.Select(context.Background(), sqluct.Plain(
+		"SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name != 'goose_db_version'",
+	), &names))
+	require.NotEmpty(t, names)
+
+	// New tables have to be added to archive, otherwise their data is lost on restore.
+	for _, n := range names {
+		assert.Contains(t, tables, n, "table is missing in archive")
+	}
+}
+
+func TestService_roundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	// Uploaded files have paths relative to working directory.
+	srcDir := t.TempDir()
+	t.Chdir(srcDir)
+
+	origDir := t.TempDir()
+	require.NoError(t, os.MkdirAll("album/trip", 0o700))
+	require.NoError(t, os.WriteFile("album/trip/a.jpg", []byte("uploaded"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(origDir, "b.jpg"), []byte("original"), 0o600))
+
+	src := newTestService(t)
+
+	_, err := src.db.DB().ExecContext(ctx, "INSERT INTO image (hash, path) VALUES (1, ?), (2, ?)",
+		"album/trip/a.jpg", filepath.Join(origDir, "b.jpg"))
+	require.NoError(t, err)
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, src.Export(ctx, buf, ExportOptions{Originals: true}))
+
+	// Restore on another machine with different layout.
+	dstDir := t.TempDir()
+	t.Chdir(dstDir)
+
+	mappedDir := filepath.Join(t.TempDir(), "photos")
+
+	dst := newTestService(t)
+
+	res, err := dst.Import(ctx, bytes.NewReader(buf.Bytes()), int64(buf.Len()), ImportOptions{
+		Paths:     backup.PathMap{origDir: mappedDir},
+		Originals: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Tables["image"])
+	assert.Equal(t, 2, res.Files)
+	assert.Equal(t, 0, res.SkippedFiles)
+
+	var paths []string
+	require.NoError(t, dst.db.Select(ctx, sqluct.Plain("SELECT path FROM image ORDER BY hash"), &paths))
+	assert.Equal(t, []string{"album/trip/a.jpg", filepath.Join(mappedDir, "b.jpg")}, paths)
+
+	b, err := os.ReadFile(filepath.Join(dstDir, "album/trip/a.jpg"))
+	require.NoError(t, err)
+	assert.Equal(t, "uploaded", string(b))
+
+	_, err = os.Stat("/album/trip/a.jpg")
+	require.ErrorIs(t, err, os.ErrNotExist, "relative path is not written to filesystem root")
+
+	b, err = os.ReadFile(filepath.Join(mappedDir, "b.jpg"))
+	require.NoError(t, err)
+	assert.Equal(t, "original", string(b))
+
+	// Existing files are not overwritten.
+	res, err = dst.Import(ctx, bytes.NewReader(buf.Bytes()), int64(buf.Len()), ImportOptions{Originals: true})
+	require.NoError(t, err)
+	assert.Equal(t, 0, res.Files)
+	assert.Equal(t, 2, res.SkippedFiles)
+
+	_, err = os.Stat(filepath.Join(origDir, "b.jpg"))
+	require.NoError(t, err, "source file is intact")
+}
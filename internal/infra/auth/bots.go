@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bool64/ctxd"
+	"github.com/bool64/stats"
+	"github.com/vearutop/photo-blog/internal/infra/settings"
+	"github.com/vearutop/photo-blog/pkg/botpolicy"
+)
+
+type botNameCtxKey struct{}
+
+// SetBotName adds name of bot detected by network to context.
+func SetBotName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, botNameCtxKey{}, name)
+}
+
+// BotName returns name of bot detected by network or empty string.
+func BotName(ctx context.Context) string {
+	n, _ := ctx.Value(botNameCtxKey{}).(string)
+
+	return n
+}
+
+// Outcomes of bot requests.
+const (
+	BotAllowed = "allowed"
+	BotDenied  = "denied"
+	BotLimited = "limited"
+)
+
+// BotTraffic is a number of bot requests by outcome.
+type BotTraffic struct {
+	Bot      string `json:"bot"`
+	Outcome  string `json:"outcome"`
+	Requests int    `json:"requests"`
+}
+
+// BotPolicy applies bot rules from settings to requests.
+type BotPolicy struct {
+	logger  ctxd.Logger
+	cfg     settings.Values
+	tracker stats.Tracker
+	limiter botpolicy.Limiter
+
+	mu      sync.Mutex
+	since   time.Time
+	traffic map[[2]string]int
+}
+
+// NewBotPolicy creates bot policy.
+func NewBotPolicy(logger ctxd.Logger, cfg settings.Values, tracker stats.Tracker) *BotPolicy {
+	return &BotPolicy{
+		logger:  logger,
+		cfg:     cfg,
+		tracker: tracker,
+		since:   time.Now(),
+		traffic: map[[2]string]int{},
+	}
+}
+
+// isDownload tells if request serves full-size image, video clip or an archive.
+func isDownload(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, "/image/") || strings.HasPrefix(r.URL.Path, "/video/") ||
+		strings.HasSuffix(r.URL.Path, ".zip")
+}
+
+// Middleware denies or limits bots, it expects VisitorMiddleware to detect bots first.
+func (p *BotPolicy) Middleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if !IsBot(ctx) || IsAdmin(ctx) {
+			handler.ServeHTTP(w, r)
+
+			return
+		}
+
+		ua, name := r.UserAgent(), BotName(ctx)
+
+		rule, found := p.cfg.Bots().Policy().Match(ua, name)
+
+		bot := rule.Agent
+		if !found || bot == botpolicy.Any {
+			bot = name
+		}
+
+		if bot == "" {
+			bot = "other"
+		}
+
+		switch {
+		case !found || rule.Action == botpolicy.Allow:
+		case rule.Action == botpolicy.Deny:
+			p.count(ctx, bot, BotDenied)
+			p.logger.Debug(ctx, "bot denied", "bot", bot, "user_agent", ua)
+			http.Error(w, "Forbidden", http.StatusForbidden)
+
+			return
+		case rule.Action == botpolicy.Limit && isDownload(r):
+			// Each bot has its own bucket, unnamed bots are told apart by address.
+			key := bot
+			if bot == "other" {
+				key += " " + ClientIP(r, p.cfg.Visitors().TrustedProxies)
+			}
+
+			if rule.Rate <= 0 || !p.limiter.Allow(key, rule.Rate, rule.Burst, time.Now()) {
+				p.count(ctx, bot, BotLimited)
+				p.logger.Debug(ctx, "bot download limited", "bot", bot, "user_agent", ua)
+
+				if rule.Rate > 0 {
+					w.Header().Set("Retry-After", strconv.Itoa(int(60/rule.Rate+1)))
+				}
+
+				http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+
+				return
+			}
+		}
+
+		p.count(ctx, bot, BotAllowed)
+		handler.ServeHTTP(w, r)
+	})
+}
+
+func (p *BotPolicy) count(ctx context.Context, bot, outcome string) {
+	p.tracker.Add(ctx, "bot_requests", 1, "bot", bot, "outcome", outcome)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.traffic[[2]string{bot, outcome}]++
+}
+
+// Traffic returns bot requests counted since start, sorted by number of requests.
+func (p *BotPolicy) Traffic() (since time.Time, traffic []BotTraffic) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	traffic = make([]BotTraffic, 0, len(p.traffic))
+
+	for k, cnt := range p.traffic {
+		traffic = append(traffic, BotTraffic{Bot: k[0], Outcome: k[1], Requests: cnt})
+	}
+
+	sort.Slice(traffic, func(i, j int) bool {
+		if traffic[i].Requests != traffic[j].Requests {
+			return traffic[i].Requests > traffic[j].Requests
+		}
+
+		if traffic[i].Bot != traffic[j].Bot {
+			return traffic[i].Bot < traffic[j].Bot
+		}
+
+		return traffic[i].Outcome < traffic[j].Outcome
+	})
+
+	return p.since, traffic
+}
@@ -0,0 +1,34 @@
+package auth
+
+import "context"
+
+type collaboratorCtxKey struct{}
+
+// SetCollaborator adds name of album collaborator to context.
+func SetCollaborator(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, collaboratorCtxKey{}, name)
+}
+
+// Collaborator returns name of album collaborator or empty string.
+func Collaborator(ctx context.Context) string {
+	n, _ := ctx.Value(collaboratorCtxKey{}).(string)
+
+	return n
+}
+
+// Actor describes author of a change for audit log.
+func Actor(ctx context.Context) string {
+	if IsAdmin(ctx) {
+		return "admin"
+	}
+
+	if c := Collaborator(ctx); c != "" {
+		return "collaborator:" + c
+	}
+
+	if v := VisitorFromContext(ctx); v != 0 {
+		return "visitor:" + v.String()
+	}
+
+	return "system"
+}
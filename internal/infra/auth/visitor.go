@@ -36,6 +36,49 @@ func VisitorFromContext(ctx context.Context) uniq.Hash {
 	return 0
 }
 
+// dailySalt is shared by visitor hashes of cookieless mode and addresses of privacy mode.
+var dailySalt = &webstats.DailySalt{}
+
+// PrivateIP returns salted hash of address network, it can not be linked to address or across days.
+func PrivateIP(ip string) string {
+	return dailySalt.HashIP(ip)
+}
+
+// ClientIP returns IP address of the request client, taking proxy headers into account.
+func ClientIP(r *http.Request, trustedProxies []string) string {
+	if ip := forwardedIP(r.Header, trustedProxies); ip != "" {
+		return ip
+	}
+
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+
+	return r.RemoteAddr
+}
+
+func forwardedIP(hd http.Header, trustedProxies []string) string {
+	ip := hd.Get("X-Forwarded-For")
+	if ip == "" {
+		return ""
+	}
+
+	// Trusted proxies are removed from the IP chain.
+	for _, p := range trustedProxies {
+		if strings.HasSuffix(ip, ", "+p) {
+			ip = strings.TrimSuffix(ip, ", "+p)
+
+			break
+		}
+	}
+
+	if strings.Contains(ip, ", ") {
+		ip = ip[strings.LastIndex(ip, ", ")+2:]
+	}
+
+	return ip
+}
+
 func VisitorMiddleware(logger ctxd.Logger, cfg settings.Values, st *visitor.StatsRepository, asnBot netrie.IPLookuper) func(handler http.Handler) http.Handler {
 	recentVisitors := cache.NewFailoverOf[uniq.Hash](func(cfg *cache.FailoverConfigOf[uniq.Hash]) {
 		cfg.BackendConfig.TimeToLive = 15 * time.Minute
@@ -59,20 +102,7 @@ func VisitorMiddleware(logger ctxd.Logger, cfg settings.Values, st *visitor.Stat
 			isBot := webstats.IsBot(r.UserAgent())
 			botName := ""
 
-			ip := hd.Get("X-Forwarded-For")
-			if ip != "" {
-				// Trusted proxies are removed from the IP chain.
-				for _, p := range visitors.TrustedProxies {
-					if strings.HasSuffix(ip, ", "+p) {
-						ip = strings.TrimSuffix(ip, ", "+p)
-						break
-					}
-				}
-
-				if strings.Contains(ip, ", ") {
-					ip = ip[strings.LastIndex(ip, ", ")+2:]
-				}
-			}
+			ip := forwardedIP(hd, visitors.TrustedProxies)
 
 			if !isBot && ip != "" && asnBot != nil {
 				if botName, _ = asnBot.SafeLookupIP(net.ParseIP(ip)); botName != "" {
@@ -82,9 +112,19 @@ func VisitorMiddleware(logger ctxd.Logger, cfg settings.Values, st *visitor.Stat
 
 			if isBot {
 				ctx = SetBot(ctx)
+
+				if botName != "" {
+					ctx = SetBotName(ctx, botName)
+				}
+
 				r = r.WithContext(ctx)
 			}
 
+			cookieMaxAge := 3 * 365 * 86400 // Around 3 years.
+			if visitors.RetentionDays > 0 {
+				cookieMaxAge = visitors.RetentionDays * 86400
+			}
+
 			setNewVisitorCookie := func(ctx context.Context) (h uniq.Hash) {
 				if isBot {
 					h = uniq.Hash(xxhash.Sum64String(botName + r.UserAgent())) // Fixed value of visitor for bots.
@@ -97,33 +137,47 @@ func VisitorMiddleware(logger ctxd.Logger, cfg settings.Values, st *visitor.Stat
 
 				c := http.Cookie{
 					Name: "v", Value: h.String(),
-					SameSite: http.SameSiteStrictMode, MaxAge: 3 * 365 * 86400,
-				} // Around 3 years.
+					SameSite: http.SameSiteStrictMode, MaxAge: cookieMaxAge,
+				}
 
 				http.SetCookie(w, &c)
 
 				return h
 			}
 
-			if visitors.Tag {
+			if visitors.Cookieless || visitors.Tag {
 				var h uniq.Hash
 
 				c, err := r.Cookie("v")
-				if err == nil {
+
+				switch {
+				case visitors.Cookieless:
+					if isBot {
+						h = uniq.Hash(xxhash.Sum64String(botName + r.UserAgent()))
+					} else {
+						// Visitor can not be recognized on the next day.
+						h = uniq.Hash(dailySalt.Hash(ClientIP(r, visitors.TrustedProxies), r.UserAgent(), hd.Get("Accept-Language")))
+					}
+
+					// Previously issued cookie is removed.
+					if err == nil {
+						http.SetCookie(w, &http.Cookie{Name: "v", MaxAge: -1})
+					}
+				case err == nil:
 					if err = h.UnmarshalText([]byte(c.Value)); err != nil || h == 0 {
 						h = setNewVisitorCookie(ctx)
 					} else {
 						isNew = false
 					}
-				} else if errors.Is(err, http.ErrNoCookie) {
+				case errors.Is(err, http.ErrNoCookie):
 					if v := r.URL.Query().Get("v"); v != "" {
 						_ = h.UnmarshalText([]byte(v))
 						isNew = false
 
 						c := http.Cookie{
 							Name: "v", Value: h.String(),
-							SameSite: http.SameSiteStrictMode, MaxAge: 3 * 365 * 86400,
-						} // Around 3 years.
+							SameSite: http.SameSiteStrictMode, MaxAge: cookieMaxAge,
+						}
 
 						http.SetCookie(w, &c)
 					} else {
@@ -159,6 +213,11 @@ func VisitorMiddleware(logger ctxd.Logger, cfg settings.Values, st *visitor.Stat
 			}
 
 			if logger != nil && visitors.AccessLog {
+				logIP, forwardedFor := ip, hd.Get("X-Forwarded-For")
+				if visitors.PrivacyMode {
+					logIP, forwardedFor = PrivateIP(ip), ""
+				}
+
 				logger.Important(r.Context(), "access",
 					"new_visitor", isNew,
 					"host", r.Host,
@@ -166,8 +225,8 @@ func VisitorMiddleware(logger ctxd.Logger, cfg settings.Values, st *visitor.Stat
 					"user_agent", r.UserAgent(),
 					"device", device,
 					"referer", hd.Get("Referer"),
-					"ip", ip,
-					"forwarded_for", hd.Get("X-Forwarded-For"),
+					"ip", logIP,
+					"forwarded_for", forwardedFor,
 					"admin", isAdmin,
 					"bot", isBot,
 					"lang", r.Header.Get("Accept-Language"),
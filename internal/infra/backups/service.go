@@ -0,0 +1,270 @@
+// Package backups makes scheduled consistent copies of SQLite storages.
+package backups
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bool64/ctxd"
+	"github.com/vearutop/dbcon/dbcon"
+	"github.com/vearutop/photo-blog/internal/infra/settings"
+	"github.com/vearutop/photo-blog/pkg/sqlitebackup"
+)
+
+const (
+	defaultDir      = "backups"
+	defaultInterval = 24
+	defaultKeep     = 7
+	tickInterval    = time.Minute
+)
+
+// ErrRunning is returned when backup is already in progress.
+var ErrRunning = errors.New("backup is already running")
+
+// Verification is a result of backup restore check.
+type Verification struct {
+	Set    string               `json:"set"`
+	Time   time.Time            `json:"time"`
+	OK     bool                 `json:"ok"`
+	Checks []sqlitebackup.Check `json:"checks"`
+}
+
+// Status describes backups.
+type Status struct {
+	Enabled      bool               `json:"enabled"`
+	Dir          string             `json:"dir"`
+	Running      bool               `json:"running"`
+	LastRun      time.Time          `json:"last_run"`
+	LastError    string             `json:"last_error,omitempty"`
+	NextRun      time.Time          `json:"next_run"`
+	Sets         []sqlitebackup.Set `json:"sets"`
+	Verification *Verification      `json:"verification,omitempty"`
+}
+
+// Service makes backups of databases on schedule.
+type Service struct {
+	logger   ctxd.Logger
+	settings settings.Values
+	dbs      []dbcon.DBInstance
+
+	mu           sync.Mutex
+	running      bool
+	lastRun      time.Time
+	lastError    string
+	verification *Verification
+
+	done chan struct{}
+}
+
+// NewService creates backup service and starts scheduler.
+func NewService(logger ctxd.Logger, s settings.Values, dbs []dbcon.DBInstance) *Service {
+	b := &Service{
+		logger:   logger,
+		settings: s,
+		dbs:      dbs,
+		done:     make(chan struct{}),
+	}
+
+	go b.run()
+
+	return b
+}
+
+// Close stops scheduler.
+func (s *Service) Close() {
+	close(s.done)
+}
+
+func config(cfg settings.Backups) settings.Backups {
+	if cfg.Dir == "" {
+		cfg.Dir = defaultDir
+	}
+
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultInterval
+	}
+
+	if cfg.Keep <= 0 {
+		cfg.Keep = defaultKeep
+	}
+
+	return cfg
+}
+
+// Status returns current state and available backups.
+func (s *Service) Status() (Status, error) {
+	cfg := config(s.settings.Backups())
+
+	sets, err := sqlitebackup.List(cfg.Dir)
+	if err != nil {
+		return Status{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := Status{
+		Enabled:      cfg.Enabled,
+		Dir:          cfg.Dir,
+		Running:      s.running,
+		LastRun:      s.lastRun,
+		LastError:    s.lastError,
+		Sets:         sets,
+		Verification: s.verification,
+	}
+
+	if cfg.Enabled {
+		st.NextRun = time.Now()
+
+		if len(sets) > 0 {
+			st.NextRun = sets[0].Time.Add(time.Duration(cfg.Interval) * time.Hour)
+		}
+	}
+
+	return st, nil
+}
+
+// Run makes a backup of all databases and removes old backups.
+func (s *Service) Run(ctx context.Context) (sqlitebackup.Set, error) {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+
+		return sqlitebackup.Set{}, ErrRunning
+	}
+
+	s.running = true
+	s.mu.Unlock()
+
+	set, err := s.backup(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.running = false
+	s.lastRun = time.Now()
+	s.lastError = ""
+
+	if err != nil {
+		s.lastError = err.Error()
+		s.logger.Error(ctx, "backup failed", "error", err)
+	}
+
+	return set, err
+}
+
+func (s *Service) backup(ctx context.Context) (sqlitebackup.Set, error) {
+	cfg := config(s.settings.Backups())
+	dbs := map[string]*sql.DB{}
+
+	for _, d := range s.dbs {
+		if slices.Contains(cfg.Skip, d.Name) {
+			continue
+		}
+
+		dbs[d.Name] = d.Instance
+	}
+
+	start := time.Now()
+
+	set, err := sqlitebackup.Create(ctx, cfg.Dir, start, dbs)
+	if err != nil {
+		return set, err
+	}
+
+	s.logger.Important(ctx, "backup created", "name", set.Name, "size", set.Size,
+		"elapsed", time.Since(start).String())
+
+	removed, err := sqlitebackup.Prune(cfg.Dir, cfg.Keep)
+	if err != nil {
+		return set, fmt.Errorf("remove old backups: %w", err)
+	}
+
+	if len(removed) > 0 {
+		s.logger.Info(ctx, "old backups removed", "names", removed)
+	}
+
+	return set, nil
+}
+
+// Verify checks that the latest backup can be opened and is not corrupted.
+func (s *Service) Verify(ctx context.Context) (Verification, error) {
+	cfg := config(s.settings.Backups())
+
+	sets, err := sqlitebackup.List(cfg.Dir)
+	if err != nil {
+		return Verification{}, err
+	}
+
+	if len(sets) == 0 {
+		return Verification{}, errors.New("no backups found")
+	}
+
+	v := Verification{
+		Set:  sets[0].Name,
+		Time: time.Now(),
+	}
+
+	v.Checks, v.OK = sqlitebackup.VerifySet(ctx, cfg.Dir, sets[0])
+
+	s.mu.Lock()
+	s.verification = &v
+	s.mu.Unlock()
+
+	if !v.OK {
+		s.logger.Error(ctx, "backup verification failed", "name", v.Set, "checks", v.Checks)
+	}
+
+	return v, nil
+}
+
+func (s *Service) due() bool {
+	cfg := config(s.settings.Backups())
+	if !cfg.Enabled {
+		return false
+	}
+
+	s.mu.Lock()
+	lastRun := s.lastRun
+	s.mu.Unlock()
+
+	// Failed attempts are retried after an hour.
+	if time.Since(lastRun) < time.Hour {
+		return false
+	}
+
+	sets, err := sqlitebackup.List(cfg.Dir)
+	if err != nil || len(sets) == 0 {
+		return true
+	}
+
+	return time.Since(sets[0].Time) >= time.Duration(cfg.Interval)*time.Hour
+}
+
+func (s *Service) run() {
+	t := time.NewTicker(tickInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-t.C:
+		}
+
+		if !s.due() {
+			continue
+		}
+
+		ctx := context.Background()
+
+		if _, err := s.Run(ctx); err == nil {
+			_, _ = s.Verify(ctx)
+		}
+	}
+}
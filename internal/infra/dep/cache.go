@@ -38,6 +38,12 @@ func NewCache(deps Deps, depStorage *sqluct.Storage) *Cache {
 		panic(err)
 	}
 
+	if err := qlite.AddConsumer[uniq.Hash](deps.QueueBroker(), topic.ImageChanged, func(ctx context.Context, v uniq.Hash) error {
+		return c.ImageChanged(ctx, v)
+	}); err != nil {
+		panic(err)
+	}
+
 	return c
 }
 
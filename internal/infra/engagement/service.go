@@ -0,0 +1,237 @@
+// Package engagement scores images by visitor interest and maintains "most loved" albums.
+package engagement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/bool64/ctxd"
+	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/dep"
+	"github.com/vearutop/photo-blog/internal/infra/settings"
+	"github.com/vearutop/photo-blog/internal/infra/storage"
+	"github.com/vearutop/photo-blog/internal/infra/storage/visitor"
+	"github.com/vearutop/photo-blog/pkg/webstats"
+)
+
+// MostLovedPrefix is a name prefix of yearly albums.
+const MostLovedPrefix = "most-loved-"
+
+const defaultMostLovedSize = 30
+
+// Deps describes service dependencies.
+type Deps interface {
+	CtxdLogger() ctxd.Logger
+	Settings() settings.Values
+	VisitorStats() *visitor.StatsRepository
+	FavoriteRepository() *storage.FavoriteRepository
+	DepCache() *dep.Cache
+
+	PhotoImageFinder() uniq.Finder[photo.Image]
+	PhotoAlbumFinder() uniq.Finder[photo.Album]
+	PhotoAlbumEnsurer() uniq.Ensurer[photo.Album]
+	PhotoAlbumImageFinder() photo.AlbumImageFinder
+	PhotoAlbumImageAdder() photo.AlbumImageAdder
+	PhotoAlbumImageDeleter() photo.AlbumImageDeleter
+}
+
+// Service calculates engagement of images.
+type Service struct {
+	deps Deps
+}
+
+// NewService creates engagement service.
+func NewService(deps Deps) *Service {
+	return &Service{deps: deps}
+}
+
+// Scores returns engagement of images with views or favorites, all such images if no hashes provided.
+func (s *Service) Scores(ctx context.Context, imageHashes ...uniq.Hash) (map[uniq.Hash]webstats.Engagement, error) {
+	res, err := s.deps.VisitorStats().ImageEngagement(ctx, imageHashes...)
+	if err != nil {
+		return nil, err
+	}
+
+	favorites, err := s.deps.FavoriteRepository().CountByImage(ctx, imageHashes...)
+	if err != nil {
+		return nil, err
+	}
+
+	for h, cnt := range favorites {
+		e := res[h]
+		e.Favorites = cnt
+		res[h] = e
+	}
+
+	for h, e := range res {
+		e.CalcScore()
+		res[h] = e
+	}
+
+	return res, nil
+}
+
+// SortImages orders images by engagement score, most engaging first.
+func (s *Service) SortImages(ctx context.Context, images []photo.Image) error {
+	hashes := make([]uniq.Hash, 0, len(images))
+	for _, img := range images {
+		hashes = append(hashes, img.Hash)
+	}
+
+	scores, err := s.Scores(ctx, hashes...)
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(images, func(i, j int) bool {
+		return scores[images[i].Hash].Score > scores[images[j].Hash].Score
+	})
+
+	return nil
+}
+
+// UpdateMostLoved fills yearly albums with images of the highest engagement, by year of taking.
+func (s *Service) UpdateMostLoved(ctx context.Context) error {
+	cfg := s.deps.Settings().Visitors()
+	if !cfg.MostLovedAlbums {
+		return nil
+	}
+
+	size := cfg.MostLovedSize
+	if size <= 0 {
+		size = defaultMostLovedSize
+	}
+
+	scores, err := s.Scores(ctx)
+	if err != nil {
+		return err
+	}
+
+	hashes := make([]uniq.Hash, 0, len(scores))
+
+	for h, e := range scores {
+		if e.Score > 0 {
+			hashes = append(hashes, h)
+		}
+	}
+
+	images, err := s.deps.PhotoImageFinder().FindByHashes(ctx, hashes...)
+	if err != nil && !errors.Is(err, status.NotFound) {
+		return fmt.Errorf("find scored images: %w", err)
+	}
+
+	byYear := map[int][]photo.Image{}
+
+	for _, img := range images {
+		if img.TakenAt == nil {
+			continue
+		}
+
+		y := img.TakenAt.Year()
+		byYear[y] = append(byYear[y], img)
+	}
+
+	for y, images := range byYear {
+		sort.SliceStable(images, func(i, j int) bool {
+			si, sj := scores[images[i].Hash].Score, scores[images[j].Hash].Score
+			if si != sj {
+				return si > sj
+			}
+
+			return images[i].Hash < images[j].Hash
+		})
+
+		if len(images) > size {
+			images = images[:size]
+		}
+
+		if err := s.syncAlbum(ctx, y, images); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// syncAlbum creates a private album if it does not exist and replaces its images.
+func (s *Service) syncAlbum(ctx context.Context, year int, images []photo.Image) error {
+	name := MostLovedPrefix + strconv.Itoa(year)
+	albumHash := photo.AlbumHash(name)
+
+	_, err := s.deps.PhotoAlbumFinder().FindByHash(ctx, albumHash)
+
+	switch {
+	case errors.Is(err, status.NotFound):
+		a := photo.Album{
+			Title:     "Most loved of " + strconv.Itoa(year),
+			Name:      name,
+			UpdatedAt: time.Now(),
+		}
+		a.Hash = albumHash
+		a.Settings.MostEngagingFirst = true
+
+		if _, err := s.deps.PhotoAlbumEnsurer().Ensure(ctx, a); err != nil {
+			return fmt.Errorf("create album %s: %w", name, err)
+		}
+
+		if err := s.deps.DepCache().AlbumListChanged(ctx); err != nil {
+			return err
+		}
+
+		s.deps.CtxdLogger().Important(ctx, "most loved album created", "name", name)
+	case err != nil:
+		return fmt.Errorf("find album %s: %w", name, err)
+	}
+
+	current, err := s.deps.PhotoAlbumImageFinder().FindImages(ctx, albumHash)
+	if err != nil && !errors.Is(err, status.NotFound) {
+		return fmt.Errorf("find images of %s: %w", name, err)
+	}
+
+	keep := make(map[uniq.Hash]bool, len(images))
+	for _, img := range images {
+		keep[img.Hash] = true
+	}
+
+	var remove, add []uniq.Hash
+
+	for _, img := range current {
+		if !keep[img.Hash] {
+			remove = append(remove, img.Hash)
+		}
+
+		delete(keep, img.Hash)
+	}
+
+	for _, img := range images {
+		if keep[img.Hash] {
+			add = append(add, img.Hash)
+		}
+	}
+
+	if len(remove) == 0 && len(add) == 0 {
+		return nil
+	}
+
+	if len(remove) > 0 {
+		if err := s.deps.PhotoAlbumImageDeleter().DeleteImages(ctx, albumHash, remove...); err != nil {
+			return fmt.Errorf("remove images from %s: %w", name, err)
+		}
+	}
+
+	if len(add) > 0 {
+		if err := s.deps.PhotoAlbumImageAdder().AddImages(ctx, albumHash, add...); err != nil {
+			return fmt.Errorf("add images to %s: %w", name, err)
+		}
+	}
+
+	s.deps.CtxdLogger().Info(ctx, "most loved album updated", "name", name, "added", len(add), "removed", len(remove))
+
+	return s.deps.DepCache().AlbumChanged(ctx, name)
+}
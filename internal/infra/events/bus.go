@@ -0,0 +1,280 @@
+// Package events delivers domain events to webhooks and server-sent events subscribers.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bool64/ctxd"
+	"github.com/vearutop/photo-blog/internal/domain/event"
+	"github.com/vearutop/photo-blog/internal/domain/topic"
+	"github.com/vearutop/photo-blog/internal/infra/settings"
+	"github.com/vearutop/photo-blog/pkg/qlite"
+	"github.com/vearutop/photo-blog/pkg/webhook"
+)
+
+const (
+	replaySize         = 100
+	subscriberBuffer   = 16
+	keepAliveInterval  = 30 * time.Second
+	deliveryTimeout    = 30 * time.Second
+	defaultMaxAttempts = 8
+)
+
+// Delivery is a queued webhook call.
+type Delivery struct {
+	URL   string      `json:"url"`
+	Event event.Event `json:"event"`
+}
+
+// Bus publishes events to subscribers of the stream and enqueues webhook deliveries.
+type Bus struct {
+	logger   ctxd.Logger
+	settings settings.Values
+	broker   *qlite.Broker
+	client   *http.Client
+
+	// OnPublish is called synchronously for every published event, for example to record audit log.
+	OnPublish func(ctx context.Context, e event.Event)
+
+	mu          sync.Mutex
+	lastID      int64
+	recent      []event.Event
+	subscribers map[chan event.Event]struct{}
+}
+
+var _ event.Publisher = &Bus{}
+
+// NewBus creates event bus and starts consuming webhook deliveries.
+func NewBus(logger ctxd.Logger, s settings.Values, broker *qlite.Broker) (*Bus, error) {
+	b := &Bus{
+		logger:      logger,
+		settings:    s,
+		broker:      broker,
+		client:      &http.Client{Timeout: deliveryTimeout},
+		subscribers: map[chan event.Event]struct{}{},
+	}
+
+	if err := qlite.AddConsumer[Delivery](broker, topic.WebhookDelivery, b.deliver, func(o *qlite.ConsumerOptions) {
+		o.Concurrency = 2
+	}); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// Publish sends event to stream subscribers and to webhooks, nil bus discards events.
+func (b *Bus) Publish(ctx context.Context, e event.Event) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+
+	// IDs are time-based to stay increasing across restarts.
+	id := time.Now().UnixNano()
+	if id <= b.lastID {
+		id = b.lastID + 1
+	}
+
+	b.lastID = id
+	e.ID = strconv.FormatInt(id, 10)
+
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	b.recent = append(b.recent, e)
+	if len(b.recent) > replaySize {
+		b.recent = b.recent[len(b.recent)-replaySize:]
+	}
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- e:
+		default:
+			// Slow subscriber misses the event.
+		}
+	}
+
+	b.mu.Unlock()
+
+	b.logger.Debug(ctx, "event published", "event", e)
+
+	if b.OnPublish != nil {
+		b.OnPublish(ctx, e)
+	}
+
+	for _, ep := range b.settings.Webhooks().Endpoints {
+		if !ep.Accepts(e.Type) {
+			continue
+		}
+
+		if err := b.broker.Publish(ctx, topic.WebhookDelivery, Delivery{URL: ep.URL, Event: e}); err != nil {
+			b.logger.Error(ctx, "failed to enqueue webhook delivery", "error", err, "url", ep.URL)
+		}
+	}
+}
+
+func (b *Bus) deliver(ctx context.Context, d Delivery) error {
+	cfg := b.settings.Webhooks()
+
+	var (
+		endpoint settings.WebhookEndpoint
+		found    bool
+	)
+
+	for _, ep := range cfg.Endpoints {
+		if ep.URL == d.URL && ep.Accepts(d.Event.Type) {
+			endpoint = ep
+			found = true
+
+			break
+		}
+	}
+
+	// Endpoint was removed or disabled after event was queued.
+	if !found {
+		return nil
+	}
+
+	body, err := json.Marshal(d.Event)
+	if err != nil {
+		return err
+	}
+
+	err = webhook.Send(ctx, b.client, webhook.Delivery{
+		URL:    endpoint.URL,
+		Secret: endpoint.Secret,
+		Event:  d.Event.Type,
+		ID:     d.Event.ID,
+		Body:   body,
+	})
+	if err == nil {
+		return nil
+	}
+
+	var se webhook.StatusError
+	if errors.As(err, &se) && !se.Temporary() {
+		return fmt.Errorf("deliver %s to %s: %w", d.Event.Type, d.URL, err)
+	}
+
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	tries := qlite.Tries(ctx) + 1
+	if tries >= maxAttempts {
+		return fmt.Errorf("deliver %s to %s after %d attempts: %w", d.Event.Type, d.URL, tries, err)
+	}
+
+	b.logger.Warn(ctx, "webhook delivery failed, retrying",
+		"error", err, "url", d.URL, "event", d.Event, "attempt", tries)
+
+	// Exponential backoff: 30s, 1m, 2m, ... up to 6h.
+	delay := min(30*time.Second<<min(tries-1, 10), 6*time.Hour)
+
+	return qlite.ErrRetryAfter(time.Now().Add(delay))
+}
+
+// Subscribe returns channel of new events and events published after lastID, call cancel to unsubscribe.
+func (b *Bus) Subscribe(lastID string) (events <-chan event.Event, replay []event.Event, cancel func()) {
+	ch := make(chan event.Event, subscriberBuffer)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if lastID != "" {
+		if id, err := strconv.ParseInt(lastID, 10, 64); err == nil {
+			for _, e := range b.recent {
+				if eid, _ := strconv.ParseInt(e.ID, 10, 64); eid > id {
+					replay = append(replay, e)
+				}
+			}
+		}
+	}
+
+	b.subscribers[ch] = struct{}{}
+
+	return ch, replay, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		delete(b.subscribers, ch)
+	}
+}
+
+// ServeHTTP streams events in text/event-stream format.
+//
+// Events missed since Last-Event-ID header (or last_event_id query parameter) are replayed if still in memory.
+func (b *Bus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+
+		return
+	}
+
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("last_event_id")
+	}
+
+	events, replay, cancel := b.Subscribe(lastID)
+	defer cancel()
+
+	// Stream is long-lived, server write timeout should not apply.
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	write := func(e event.Event) error {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+
+		return err
+	}
+
+	for _, e := range replay {
+		if err := write(e); err != nil {
+			return
+		}
+	}
+
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := w.Write([]byte(": keep-alive\n\n")); err != nil {
+				return
+			}
+		case e := <-events:
+			if err := write(e); err != nil {
+				return
+			}
+		}
+
+		flusher.Flush()
+	}
+}
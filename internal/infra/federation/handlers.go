@@ -0,0 +1,293 @@
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/domain/site"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/pkg/activitypub"
+)
+
+const (
+	maxInboxBody = 1 << 20
+	outboxLimit  = 20
+)
+
+func writeJSON(w http.ResponseWriter, contentType string, v any) {
+	w.Header().Set("Content-Type", contentType)
+
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// WebFinger resolves acct:username@host to site actor.
+func (s *Service) WebFinger(w http.ResponseWriter, r *http.Request) {
+	base, ok := s.baseURL()
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	acct := "acct:" + username(s.deps.Settings().ActivityPub().Username) + "@" + hostOf(base)
+	resource := r.URL.Query().Get("resource")
+
+	if !strings.EqualFold(resource, acct) && resource != actorID(base) {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	writeJSON(w, "application/jrd+json", activitypub.WebFinger{
+		Subject: acct,
+		Aliases: []string{actorID(base)},
+		Links: []activitypub.Link{
+			{Rel: "self", Type: activitypub.ContentType, Href: actorID(base)},
+			{Rel: "http://webfinger.net/rel/profile-page", Type: "text/html", Href: base + "/"},
+		},
+	})
+}
+
+// Actor serves actor document.
+func (s *Service) Actor(w http.ResponseWriter, r *http.Request) {
+	base, ok := s.baseURL()
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	a, err := s.actor(base)
+	if err != nil {
+		s.logger.Error(r.Context(), "failed to prepare actor", "error", err)
+		http.Error(w, "failed to prepare actor", http.StatusInternalServerError)
+
+		return
+	}
+
+	writeJSON(w, activitypub.ContentType, a)
+}
+
+// Outbox serves posts about recent public albums.
+func (s *Service) Outbox(w http.ResponseWriter, r *http.Request) {
+	base, ok := s.baseURL()
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	albums, err := s.deps.PhotoAlbumFinder().FindAll(ctx)
+	if err != nil {
+		s.logger.Error(ctx, "failed to find albums", "error", err)
+		http.Error(w, "failed to find albums", http.StatusInternalServerError)
+
+		return
+	}
+
+	albums = slices.DeleteFunc(albums, func(a photo.Album) bool {
+		return !listed(a)
+	})
+
+	slices.SortFunc(albums, func(a, b photo.Album) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+
+	c := activitypub.Collection{
+		Context:    activitypub.ContextActivityStreams,
+		ID:         base + "/ap/outbox",
+		Type:       activitypub.TypeOrderedCollection,
+		TotalItems: len(albums),
+	}
+
+	for i, a := range albums {
+		if i >= outboxLimit {
+			break
+		}
+
+		c.OrderedItems = append(c.OrderedItems, create(base, s.albumNote(ctx, base, a, time.Time{}, nil)))
+	}
+
+	writeJSON(w, activitypub.ContentType, c)
+}
+
+// Followers serves followers count.
+func (s *Service) Followers(w http.ResponseWriter, r *http.Request) {
+	base, ok := s.baseURL()
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	followers, err := s.deps.SiteFollowerRepository().FindAll(r.Context())
+	if err != nil {
+		s.logger.Error(r.Context(), "failed to find followers", "error", err)
+		http.Error(w, "failed to find followers", http.StatusInternalServerError)
+
+		return
+	}
+
+	writeJSON(w, activitypub.ContentType, activitypub.Collection{
+		Context:    activitypub.ContextActivityStreams,
+		ID:         base + "/ap/followers",
+		Type:       activitypub.TypeOrderedCollection,
+		TotalItems: len(followers),
+	})
+}
+
+// Object serves a post by ID.
+func (s *Service) Object(w http.ResponseWriter, r *http.Request) {
+	base, ok := s.baseURL()
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	ctx := r.Context()
+	id := base + "/ap/objects/" + chi.URLParam(r, "id")
+
+	h, ok := parseNoteID(base, id)
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	a, err := s.deps.PhotoAlbumFinder().FindByHash(ctx, h)
+	if err != nil || !listed(a) {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	n := s.albumNote(ctx, base, a, time.Time{}, nil)
+	n.ID = id
+	n.Context = activitypub.ContextActivityStreams
+
+	writeJSON(w, activitypub.ContentType, n)
+}
+
+// Inbox handles signed activities of remote actors.
+func (s *Service) Inbox(w http.ResponseWriter, r *http.Request) {
+	base, ok := s.baseURL()
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxInboxBody))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	var activity activitypub.Object
+	if err := json.Unmarshal(body, &activity); err != nil {
+		http.Error(w, "invalid activity: "+err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	// Deleted accounts can not be verified as their keys are gone, ignoring.
+	if activity.Type == activitypub.TypeDelete && activity.Object != nil && activity.Object.ID == activity.Actor {
+		w.WriteHeader(http.StatusAccepted)
+
+		return
+	}
+
+	actor, err := activitypub.VerifyActor(ctx, r, body, s.fetchActor)
+	if err != nil {
+		s.logger.Warn(ctx, "failed to verify activity", "error", err, "actor", activity.Actor)
+		http.Error(w, "failed to verify signature", http.StatusUnauthorized)
+
+		return
+	}
+
+	if actor.ID != activity.Actor {
+		http.Error(w, "activity actor does not match signature", http.StatusForbidden)
+
+		return
+	}
+
+	if err := s.handle(ctx, base, actor, activity, r.UserAgent()); err != nil {
+		switch {
+		case errors.Is(err, status.PermissionDenied):
+			s.logger.Warn(ctx, "activity rejected", "error", err, "actor", actor.ID)
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, status.ResourceExhausted):
+			s.logger.Warn(ctx, "activity rejected", "error", err, "actor", actor.ID)
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+		default:
+			s.logger.Error(ctx, "failed to handle activity", "error", err, "activity", activity)
+			http.Error(w, "failed to handle activity", http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Service) handle(ctx context.Context, base string, actor activitypub.Actor, activity activitypub.Object, userAgent string) error {
+	if activity.Object == nil {
+		return nil
+	}
+
+	switch activity.Type {
+	case activitypub.TypeFollow:
+		if activity.Object.ID != actorID(base) {
+			return nil
+		}
+
+		f := site.Follower{Actor: actor.ID, Inbox: actor.DeliveryInbox()}
+		f.Hash = uniq.StringHash(actor.ID)
+
+		if _, err := s.deps.SiteFollowerRepository().Ensure(ctx, f); err != nil {
+			return err
+		}
+
+		s.logger.Important(ctx, "new follower", "actor", actor.ID)
+
+		// Accept is sent to personal inbox as it concerns a single actor.
+		s.enqueue(ctx, activitypub.Object{
+			Context: activitypub.ContextActivityStreams,
+			ID:      actorID(base) + "#accept-" + f.Hash.String(),
+			Type:    activitypub.TypeAccept,
+			Actor:   actorID(base),
+			Object:  &activitypub.Ref{Object: &activitypub.Object{ID: activity.ID, Type: activity.Type, Actor: activity.Actor, Object: activity.Object}},
+		}, actor.Inbox)
+	case activitypub.TypeUndo:
+		if o := activity.Object.Object; o == nil || o.Type != activitypub.TypeFollow {
+			return nil
+		}
+
+		if err := s.deps.SiteFollowerRepository().Delete(ctx, uniq.StringHash(actor.ID)); err != nil {
+			return err
+		}
+
+		s.logger.Important(ctx, "follower left", "actor", actor.ID)
+	case activitypub.TypeCreate:
+		if o := activity.Object.Object; o != nil && o.Type == activitypub.TypeNote && o.InReplyTo != "" {
+			return s.reply(ctx, base, actor, *o, userAgent)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,191 @@
+package federation
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/pkg/activitypub"
+	"github.com/vearutop/photo-blog/pkg/txt"
+)
+
+const (
+	maxAttachments = 4
+	newAlbumPeriod = 24 * time.Hour
+)
+
+func (s *Service) actor(base string) (activitypub.Actor, error) {
+	key, err := s.signingKey()
+	if err != nil {
+		return activitypub.Actor{}, err
+	}
+
+	pub, err := activitypub.EncodePublicKey(&key.PublicKey)
+	if err != nil {
+		return activitypub.Actor{}, err
+	}
+
+	cfg := s.deps.Settings().ActivityPub()
+	ap := s.deps.Settings().Appearance()
+	id := actorID(base)
+
+	icon := ap.SiteFavicon
+	if icon == "" {
+		icon = "/static/favicon.png"
+	}
+
+	if strings.HasPrefix(icon, "/") {
+		icon = base + icon
+	}
+
+	return activitypub.Actor{
+		Context:           []any{activitypub.ContextActivityStreams, activitypub.ContextSecurity},
+		ID:                id,
+		Type:              activitypub.TypeService,
+		PreferredUsername: username(cfg.Username),
+		Name:              s.deps.TxtRenderer().MustRenderLang(context.Background(), ap.SiteTitle, txt.StripTags),
+		Summary:           cfg.Summary,
+		URL:               base + "/",
+		Icon:              &activitypub.Object{Type: activitypub.TypeImage, URL: icon},
+		Inbox:             base + "/ap/inbox",
+		Outbox:            base + "/ap/outbox",
+		Followers:         base + "/ap/followers",
+		Endpoints:         &activitypub.Endpoints{SharedInbox: base + "/ap/inbox"},
+		PublicKey: &activitypub.PublicKey{
+			ID:           id + "#main-key",
+			Owner:        id,
+			PublicKeyPem: pub,
+		},
+	}, nil
+}
+
+func username(u string) string {
+	if u == "" {
+		return "blog"
+	}
+
+	return u
+}
+
+// noteID identifies a post about album, optionally with time of a batch of new photos.
+func noteID(base string, album uniq.Hash, at time.Time) string {
+	id := base + "/ap/objects/album-" + album.String()
+
+	if !at.IsZero() {
+		id += "-" + strconv.FormatInt(at.Unix(), 10)
+	}
+
+	return id
+}
+
+// parseNoteID returns album hash of a post.
+func parseNoteID(base, id string) (uniq.Hash, bool) {
+	rest, ok := strings.CutPrefix(id, base+"/ap/objects/album-")
+	if !ok {
+		return 0, false
+	}
+
+	rest, _, _ = strings.Cut(rest, "-")
+
+	var h uniq.Hash
+	if err := h.UnmarshalText([]byte(rest)); err != nil {
+		return 0, false
+	}
+
+	return h, true
+}
+
+// albumNote creates post about album, with new images if provided, or with album preview.
+func (s *Service) albumNote(ctx context.Context, base string, a photo.Album, at time.Time, images []uniq.Hash) activitypub.Object {
+	r := s.deps.TxtRenderer()
+	title := r.MustRenderLang(ctx, a.Title, txt.StripTags)
+	link := base + "/" + a.Name + "/"
+
+	published := at
+	if published.IsZero() {
+		published = a.CreatedAt
+	}
+
+	var text string
+
+	switch {
+	case len(images) == 0 || time.Since(a.CreatedAt) < newAlbumPeriod:
+		text = "New album: <b>" + html.EscapeString(title) + "</b>"
+	case len(images) == 1:
+		text = "New photo in <b>" + html.EscapeString(title) + "</b>"
+	default:
+		text = fmt.Sprintf("%d new photos in <b>%s</b>", len(images), html.EscapeString(title))
+	}
+
+	content := "<p>" + text + "</p>"
+
+	if d := r.MustRenderLang(ctx, a.Settings.Description, a.Settings.TextReplaces.Apply, txt.StripTags); d != "" && len(images) == 0 {
+		content += "<p>" + html.EscapeString(d) + "</p>"
+	}
+
+	content += `<p><a href="` + html.EscapeString(link) + `">` + html.EscapeString(link) + `</a></p>`
+
+	if len(images) == 0 {
+		images = s.previewImages(ctx, a)
+	}
+
+	n := activitypub.Object{
+		ID:           noteID(base, a.Hash, at),
+		Type:         activitypub.TypeNote,
+		AttributedTo: actorID(base),
+		Content:      content,
+		URL:          link,
+		Published:    &published,
+		To:           []string{activitypub.Public},
+		Cc:           []string{base + "/ap/followers"},
+	}
+
+	for i, h := range images {
+		if i >= maxAttachments {
+			break
+		}
+
+		n.Attachment = append(n.Attachment, activitypub.Object{
+			Type:      activitypub.TypeImage,
+			MediaType: "image/jpeg",
+			URL:       base + "/thumb/1200w/" + h.String() + ".jpg",
+			Name:      title,
+		})
+	}
+
+	return n
+}
+
+func (s *Service) previewImages(ctx context.Context, a photo.Album) []uniq.Hash {
+	images, err := s.deps.PhotoAlbumImageFinder().FindPreviewImages(ctx, a.Hash, a.CoverImage, maxAttachments)
+	if err != nil {
+		s.logger.Warn(ctx, "failed to find album preview", "error", err, "album", a.Name)
+
+		return nil
+	}
+
+	res := make([]uniq.Hash, 0, len(images))
+	for _, img := range images {
+		res = append(res, img.Hash)
+	}
+
+	return res
+}
+
+func create(base string, note activitypub.Object) activitypub.Object {
+	return activitypub.Object{
+		Context:   activitypub.ContextActivityStreams,
+		ID:        note.ID + "#create",
+		Type:      activitypub.TypeCreate,
+		Actor:     actorID(base),
+		Object:    &activitypub.Ref{Object: &note},
+		Published: note.Published,
+		To:        note.To,
+		Cc:        note.Cc,
+	}
+}
@@ -0,0 +1,200 @@
+package federation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bool64/sqluct"
+	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/domain/comment"
+	"github.com/vearutop/photo-blog/internal/domain/event"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/domain/site"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/notifier"
+	"github.com/vearutop/photo-blog/pkg/activitypub"
+	"github.com/vearutop/photo-blog/pkg/notify"
+	"github.com/vearutop/photo-blog/pkg/txt"
+)
+
+// Same defaults as for site comments.
+const (
+	defaultMaxLength     = 2000
+	defaultSpamThreshold = 0.7
+	defaultRateLimit     = 5
+)
+
+// threadOf finds comments thread for a post or a page URL.
+func threadOf(base, iri string) (comment.Thread, bool) {
+	th := comment.Thread{}
+
+	if h, ok := parseNoteID(base, iri); ok {
+		th.Type = comment.ThreadAlbum
+		th.RelatedHash = h
+	} else if p, ok := strings.CutPrefix(iri, base+"/"); ok {
+		name, rest, _ := strings.Cut(p, "/")
+
+		switch {
+		case name == "":
+			return th, false
+		case rest == "":
+			th.Type = comment.ThreadAlbum
+			th.RelatedHash = photo.AlbumHash(name)
+		case strings.HasPrefix(rest, "photo-") && strings.HasSuffix(rest, ".html"):
+			if err := th.RelatedHash.UnmarshalText([]byte(strings.TrimSuffix(strings.TrimPrefix(rest, "photo-"), ".html"))); err != nil {
+				return th, false
+			}
+
+			th.Type = comment.ThreadImage
+		default:
+			return th, false
+		}
+	} else {
+		return th, false
+	}
+
+	th.Hash = comment.ThreadHash(th.Type, th.RelatedHash, nil)
+
+	return th, true
+}
+
+// reply adds a remote reply to a post as a comment that goes through moderation.
+func (s *Service) reply(ctx context.Context, base string, actor activitypub.Actor, note activitypub.Object, userAgent string) error {
+	cfg := s.deps.Settings().Comments()
+	if !cfg.Enabled {
+		return nil
+	}
+
+	// Note ID defines message hash, so actors can only reply with notes of their own server.
+	if h := hostOf(actor.ID); h == "" || hostOf(note.ID) != h {
+		return status.Wrap(errors.New("note does not belong to actor server"), status.PermissionDenied)
+	}
+
+	thread, ok := threadOf(base, note.InReplyTo)
+	if !ok {
+		return nil
+	}
+
+	visitorHash := uniq.StringHash(actor.ID)
+
+	visitor, err := s.deps.SiteVisitorFinder().FindByHash(ctx, visitorHash)
+	if err != nil && !errors.Is(err, status.NotFound) {
+		return err
+	}
+
+	if visitor.Banned {
+		return nil
+	}
+
+	limit := cfg.RateLimit
+	if limit == 0 {
+		limit = defaultRateLimit
+	}
+
+	cnt, err := s.deps.CommentMessageRepository().CountSince(ctx, visitorHash, "", time.Now().Add(-time.Hour))
+	if err != nil {
+		return err
+	}
+
+	if cnt >= limit {
+		return status.Wrap(errors.New("too many replies, please try again later"), status.ResourceExhausted)
+	}
+
+	text, err := s.deps.TxtRenderer().Render(note.Content, txt.StripTags)
+	if err != nil {
+		return err
+	}
+
+	maxLength := cfg.MaxLength
+	if maxLength == 0 {
+		maxLength = defaultMaxLength
+	}
+
+	if utf8.RuneCountInString(text) > maxLength {
+		text = string([]rune(text)[:maxLength])
+	}
+
+	if text == "" {
+		return nil
+	}
+
+	name := "@" + actor.PreferredUsername + "@" + hostOf(actor.ID)
+
+	m := comment.Message{}
+	m.Hash = uniq.StringHash(note.ID)
+	m.ThreadHash = thread.Hash
+	m.VisitorHash = visitorHash
+	m.Text = text
+
+	score, err := s.deps.CommentSpamScorer().SpamScore(ctx, comment.SpamCandidate{Message: m, Name: name, Agent: userAgent})
+	if err != nil {
+		s.logger.Error(ctx, "failed to score message", "error", err)
+	}
+
+	threshold := cfg.SpamThreshold
+	if threshold == 0 {
+		threshold = defaultSpamThreshold
+	}
+
+	m.SpamScore = score
+
+	switch {
+	case score >= threshold:
+		m.Rejected = true
+	case cfg.AutoApprove && visitor.Approved:
+		m.Approved = true
+	}
+
+	v := site.Visitor{Name: name}
+	v.Hash = visitorHash
+
+	if _, err := s.deps.SiteVisitorEnsurer().Ensure(ctx, v, uniq.EnsureOption[site.Visitor]{
+		OnUpdate: func(st sqluct.StorageOf[site.Visitor], o *sqluct.Options) {
+			o.Columns = []string{st.Col(&st.R.Name)}
+		},
+	}); err != nil {
+		return err
+	}
+
+	if _, err := s.deps.CommentThreadEnsurer().Ensure(ctx, thread, uniq.EnsureOption[comment.Thread]{
+		Prepare: func(candidate *comment.Thread, existing *comment.Thread) (skipUpdate bool) {
+			return true
+		},
+	}); err != nil {
+		return err
+	}
+
+	exists := false
+
+	if _, err := s.deps.CommentMessageEnsurer().Ensure(ctx, m, uniq.EnsureOption[comment.Message]{
+		Prepare: func(candidate *comment.Message, existing *comment.Message) (skipUpdate bool) {
+			exists = existing != nil
+
+			return true
+		},
+	}); err != nil {
+		return err
+	}
+
+	if exists {
+		return nil
+	}
+
+	s.logger.Info(ctx, "fediverse reply received", "actor", actor.ID, "note", note.ID, "thread", thread.Hash)
+
+	if m.Approved {
+		s.deps.EventBus().Publish(ctx, event.Comment(event.CommentApproved, m.Hash))
+	} else if m.Pending() {
+		s.deps.Notifier().Notify(ctx, notify.Notification{
+			Event: notifier.MessagePending,
+			Title: "New reply from " + name,
+			Text:  text,
+			URL:   s.deps.Notifier().URL("/comments/inbox.html"),
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package federation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vearutop/photo-blog/internal/domain/comment"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+)
+
+func TestThreadOf(t *testing.T) {
+	base := "https://blog.example"
+	albumHash := photo.AlbumHash("alps")
+
+	for _, tc := range []struct {
+		iri     string
+		typ     string
+		related uniq.Hash
+	}{
+		{iri: noteID(base, albumHash, time.Time{}), typ: comment.ThreadAlbum, related: albumHash},
+		{iri: noteID(base, albumHash, time.Unix(1700000000, 0)), typ: comment.ThreadAlbum, related: albumHash},
+		{iri: base + "/alps/", typ: comment.ThreadAlbum, related: albumHash},
+		{iri: base + "/alps/photo-" + uniq.Hash(123456).String() + ".html", typ: comment.ThreadImage, related: 123456},
+		{iri: base + "/"},
+		{iri: base + "/alps/map.html"},
+		{iri: "https://other.example/alps/"},
+	} {
+		t.Run(tc.iri, func(t *testing.T) {
+			th, ok := threadOf(base, tc.iri)
+			assert.Equal(t, tc.typ != "", ok)
+			assert.Equal(t, tc.typ, th.Type)
+			assert.Equal(t, tc.related, th.RelatedHash)
+
+			if ok {
+				assert.Equal(t, comment.ThreadHash(tc.typ, tc.related, nil), th.Hash)
+			}
+		})
+	}
+}
@@ -0,0 +1,382 @@
+// Package federation makes the site followable from ActivityPub servers.
+package federation
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bool64/ctxd"
+	"github.com/vearutop/photo-blog/internal/domain/comment"
+	"github.com/vearutop/photo-blog/internal/domain/event"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/domain/site"
+	"github.com/vearutop/photo-blog/internal/domain/topic"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/events"
+	"github.com/vearutop/photo-blog/internal/infra/notifier"
+	"github.com/vearutop/photo-blog/internal/infra/settings"
+	"github.com/vearutop/photo-blog/internal/infra/storage"
+	"github.com/vearutop/photo-blog/pkg/activitypub"
+	"github.com/vearutop/photo-blog/pkg/qlite"
+	"github.com/vearutop/photo-blog/pkg/txt"
+)
+
+const (
+	keyFile = "activitypub.pem"
+
+	tickInterval        = time.Minute
+	requestTimeout      = 30 * time.Second
+	actorCacheTTL       = time.Hour
+	maxDeliveryAttempts = 8
+	defaultBatchDelay   = 10
+)
+
+// Deps describes service dependencies.
+type Deps interface {
+	CtxdLogger() ctxd.Logger
+	Settings() settings.Values
+	QueueBroker() *qlite.Broker
+	EventBus() *events.Bus
+	Notifier() *notifier.Service
+	TxtRenderer() *txt.Renderer
+	CommentSpamScorer() comment.SpamScorer
+
+	PhotoAlbumFinder() uniq.Finder[photo.Album]
+	PhotoAlbumImageFinder() photo.AlbumImageFinder
+	SiteVisitorFinder() uniq.Finder[site.Visitor]
+	SiteVisitorEnsurer() uniq.Ensurer[site.Visitor]
+	SiteFollowerRepository() *storage.FollowerRepository
+	CommentThreadEnsurer() uniq.Ensurer[comment.Thread]
+	CommentMessageEnsurer() uniq.Ensurer[comment.Message]
+	CommentMessageRepository() *storage.MessageRepository
+}
+
+// delivery is a queued activity for a remote inbox.
+type delivery struct {
+	Inbox    string          `json:"inbox"`
+	Activity json.RawMessage `json:"activity"`
+}
+
+type batch struct {
+	since  time.Time
+	images []uniq.Hash
+}
+
+type cachedActor struct {
+	actor activitypub.Actor
+	at    time.Time
+}
+
+// Service publishes new public photos to followers and handles incoming activities.
+type Service struct {
+	deps   Deps
+	logger ctxd.Logger
+	http   *http.Client
+
+	keyMu sync.Mutex
+	key   *rsa.PrivateKey
+
+	mu      sync.Mutex
+	pending map[string]*batch
+	actors  map[string]cachedActor
+
+	done chan struct{}
+}
+
+// NewService creates federation service and starts listening to site events.
+func NewService(deps Deps) (*Service, error) {
+	s := &Service{
+		deps:    deps,
+		logger:  deps.CtxdLogger(),
+		http:    activitypub.PublicClient(requestTimeout),
+		pending: map[string]*batch{},
+		actors:  map[string]cachedActor{},
+		done:    make(chan struct{}),
+	}
+
+	if err := qlite.AddConsumer[delivery](deps.QueueBroker(), topic.ActivityPubDelivery, s.deliver, func(o *qlite.ConsumerOptions) {
+		o.Concurrency = 2
+	}); err != nil {
+		return nil, err
+	}
+
+	go s.run()
+
+	return s, nil
+}
+
+// Close stops listening to site events.
+func (s *Service) Close() {
+	close(s.done)
+}
+
+// baseURL returns canonical site URL if federation is enabled.
+func (s *Service) baseURL() (string, bool) {
+	if !s.deps.Settings().ActivityPub().Enabled {
+		return "", false
+	}
+
+	base := strings.TrimSuffix(s.deps.Settings().Appearance().CanonicalBaseURL, "/")
+
+	return base, base != ""
+}
+
+func actorID(base string) string {
+	return base + "/ap/actor"
+}
+
+func (s *Service) signingKey() (*rsa.PrivateKey, error) {
+	s.keyMu.Lock()
+	defer s.keyMu.Unlock()
+
+	if s.key != nil {
+		return s.key, nil
+	}
+
+	data, err := os.ReadFile(keyFile)
+	if err == nil {
+		s.key, err = activitypub.ParsePrivateKey(data)
+
+		return s.key, err
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	key, err := activitypub.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err = activitypub.EncodePrivateKey(key); err != nil {
+		return nil, err
+	}
+
+	if err := os.WriteFile(keyFile, data, 0o600); err != nil {
+		return nil, fmt.Errorf("save actor key: %w", err)
+	}
+
+	s.key = key
+
+	return key, nil
+}
+
+func (s *Service) client(base string) (activitypub.Client, error) {
+	key, err := s.signingKey()
+	if err != nil {
+		return activitypub.Client{}, err
+	}
+
+	return activitypub.Client{HTTP: s.http, KeyID: actorID(base) + "#main-key", Key: key}, nil
+}
+
+func (s *Service) fetchActor(ctx context.Context, iri string) (activitypub.Actor, error) {
+	s.mu.Lock()
+	c, ok := s.actors[iri]
+	s.mu.Unlock()
+
+	if ok && time.Since(c.at) < actorCacheTTL {
+		return c.actor, nil
+	}
+
+	base, _ := s.baseURL()
+
+	cl, err := s.client(base)
+	if err != nil {
+		return activitypub.Actor{}, err
+	}
+
+	a, err := cl.FetchActor(ctx, iri)
+	if err != nil {
+		return a, err
+	}
+
+	s.mu.Lock()
+	s.actors[iri] = cachedActor{actor: a, at: time.Now()}
+	s.mu.Unlock()
+
+	return a, nil
+}
+
+// enqueue schedules activity delivery to inboxes.
+func (s *Service) enqueue(ctx context.Context, activity activitypub.Object, inboxes ...string) {
+	body, err := json.Marshal(activity)
+	if err != nil {
+		s.logger.Error(ctx, "failed to encode activity", "error", err)
+
+		return
+	}
+
+	for _, inbox := range inboxes {
+		if err := s.deps.QueueBroker().Publish(ctx, topic.ActivityPubDelivery, delivery{Inbox: inbox, Activity: body}); err != nil {
+			s.logger.Error(ctx, "failed to enqueue activity", "error", err, "inbox", inbox)
+		}
+	}
+}
+
+func (s *Service) deliver(ctx context.Context, d delivery) error {
+	base, ok := s.baseURL()
+	if !ok {
+		return nil
+	}
+
+	cl, err := s.client(base)
+	if err != nil {
+		return err
+	}
+
+	err = cl.Post(ctx, d.Inbox, d.Activity)
+	if err == nil {
+		return nil
+	}
+
+	var se activitypub.StatusError
+	if errors.As(err, &se) && !se.Temporary() {
+		s.logger.Warn(ctx, "activity rejected", "error", err, "inbox", d.Inbox)
+
+		return nil
+	}
+
+	tries := qlite.Tries(ctx) + 1
+	if tries >= maxDeliveryAttempts {
+		return fmt.Errorf("deliver activity to %s after %d attempts: %w", d.Inbox, tries, err)
+	}
+
+	return qlite.ErrRetryAfter(time.Now().Add(min(time.Minute<<min(tries-1, 10), 6*time.Hour)))
+}
+
+func (s *Service) run() {
+	evs, _, cancel := s.deps.EventBus().Subscribe("")
+	defer cancel()
+
+	t := time.NewTicker(tickInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case e := <-evs:
+			s.collect(e)
+		case <-t.C:
+			s.flush(context.Background())
+		}
+	}
+}
+
+// collect groups new images by album to publish them in a single post.
+func (s *Service) collect(e event.Event) {
+	if _, ok := s.baseURL(); !ok || e.AlbumName == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch e.Type {
+	case event.ImageAdded:
+		b := s.pending[e.AlbumName]
+		if b == nil {
+			b = &batch{since: e.Time}
+			s.pending[e.AlbumName] = b
+		}
+
+		b.images = append(b.images, e.ImageHash)
+	case event.AlbumDeleted:
+		delete(s.pending, e.AlbumName)
+	}
+}
+
+func (s *Service) flush(ctx context.Context) {
+	base, ok := s.baseURL()
+	if !ok {
+		return
+	}
+
+	delay := s.deps.Settings().ActivityPub().BatchDelay
+	if delay == 0 {
+		delay = defaultBatchDelay
+	}
+
+	due := map[string]*batch{}
+
+	s.mu.Lock()
+	for name, b := range s.pending {
+		if time.Since(b.since) >= time.Duration(delay)*time.Minute {
+			due[name] = b
+			delete(s.pending, name)
+		}
+	}
+	s.mu.Unlock()
+
+	for name, b := range due {
+		if err := s.publish(ctx, base, name, b); err != nil {
+			s.logger.Error(ctx, "failed to publish album update", "error", err, "album", name)
+		}
+	}
+}
+
+func (s *Service) publish(ctx context.Context, base string, albumName string, b *batch) error {
+	album, err := s.deps.PhotoAlbumFinder().FindByHash(ctx, photo.AlbumHash(albumName))
+	if err != nil {
+		return err
+	}
+
+	if !listed(album) {
+		return nil
+	}
+
+	followers, err := s.deps.SiteFollowerRepository().FindAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(followers) == 0 {
+		return nil
+	}
+
+	note := s.albumNote(ctx, base, album, time.Now(), b.images)
+	s.enqueue(ctx, create(base, note), inboxes(followers)...)
+
+	return nil
+}
+
+func inboxes(followers []site.Follower) []string {
+	seen := map[string]bool{}
+	res := make([]string, 0, len(followers))
+
+	for _, f := range followers {
+		if seen[f.Inbox] {
+			continue
+		}
+
+		seen[f.Inbox] = true
+		res = append(res, f.Inbox)
+	}
+
+	return res
+}
+
+// listed is true for albums visible on the main page.
+func listed(a photo.Album) bool {
+	return a.Public && !a.Hidden && a.Name != "" && a.Settings.Redirect == ""
+}
+
+func hostOf(iri string) string {
+	u, err := url.Parse(iri)
+	if err != nil {
+		return ""
+	}
+
+	return u.Host
+}
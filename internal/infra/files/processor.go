@@ -10,12 +10,16 @@ import (
 
 	"github.com/bool64/ctxd"
 	"github.com/bool64/stats"
+	"github.com/vearutop/photo-blog/internal/domain/event"
 	"github.com/vearutop/photo-blog/internal/domain/photo"
 	"github.com/vearutop/photo-blog/internal/domain/topic"
 	"github.com/vearutop/photo-blog/internal/domain/uniq"
 	"github.com/vearutop/photo-blog/internal/infra/dep"
+	"github.com/vearutop/photo-blog/internal/infra/events"
 	"github.com/vearutop/photo-blog/internal/infra/image"
+	"github.com/vearutop/photo-blog/internal/infra/video"
 	"github.com/vearutop/photo-blog/pkg/qlite"
+	"github.com/vearutop/photo-blog/pkg/trackfile"
 )
 
 type ProcessorDeps interface {
@@ -29,10 +33,13 @@ type ProcessorDeps interface {
 
 	PhotoImageEnsurer() uniq.Ensurer[photo.Image]
 	PhotoGpxEnsurer() uniq.Ensurer[photo.Gpx]
+	PhotoVideoEnsurer() uniq.Ensurer[photo.Video]
+	PhotoGpsEnsurer() uniq.Ensurer[photo.Gps]
 
 	PhotoThumbnailer() photo.Thumbnailer
 
 	DepCache() *dep.Cache
+	EventBus() *events.Bus
 }
 
 func NewProcessor(deps ProcessorDeps) *Processor {
@@ -93,18 +100,28 @@ func (p *Processor) AddFile(ctx context.Context, albumName string, filePath stri
 		} else if err := p.deps.PhotoAlbumImageAdder().AddImages(ctx, uniq.StringHash(albumName), img.Hash); err != nil {
 			return 0, nil, fmt.Errorf("add image to album: %w", err)
 		}
-		return img.Hash, func() {
-			if err := p.deps.QueueBroker().Publish(ctx, topic.IndexImage, image.IndexJob{Image: img}, func(msg *qlite.Message) {
-				msg.PublishOnSuccess(topic.AlbumChanged, albumName)
-			}); err != nil {
-				p.deps.CtxdLogger().Error(ctx, "failed to publish indexing flags", "error", err)
 
-				return
-			}
-		}, nil
+		p.deps.EventBus().Publish(ctx, event.Image(event.ImageAdded, albumName, img.Hash))
+
+		return img.Hash, p.indexFunc(ctx, albumName, img), nil
 	}
 
-	if strings.HasSuffix(lName, ".gpx") {
+	if mime := photo.VideoMimeType(path.Ext(lName)); mime != "" {
+		img, err := p.addVideo(ctx, filePath, mime)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		if err := p.deps.PhotoAlbumImageAdder().AddImages(ctx, uniq.StringHash(albumName), img.Hash); err != nil {
+			return 0, nil, fmt.Errorf("add video to album: %w", err)
+		}
+
+		p.deps.EventBus().Publish(ctx, event.Image(event.ImageAdded, albumName, img.Hash))
+
+		return img.Hash, p.indexFunc(ctx, albumName, img), nil
+	}
+
+	if trackfile.Supported(lName) {
 		d := photo.Gpx{}
 		if err := d.SetPath(ctx, filePath); err != nil {
 			return 0, nil, fmt.Errorf("set gpx path: %w", err)
@@ -151,6 +168,85 @@ func (p *Processor) AddFile(ctx context.Context, albumName string, filePath stri
 	return 0, nil, ErrSkip
 }
 
+func (p *Processor) indexFunc(ctx context.Context, albumName string, img photo.Image) func() {
+	return func() {
+		if err := p.deps.QueueBroker().Publish(ctx, topic.IndexImage, image.IndexJob{Image: img}, func(msg *qlite.Message) {
+			msg.PublishOnSuccess(topic.AlbumChanged, albumName)
+		}); err != nil {
+			p.deps.CtxdLogger().Error(ctx, "failed to publish indexing flags", "error", err)
+
+			return
+		}
+	}
+}
+
+// addVideo stores video clip and an image of the same hash with poster frame.
+func (p *Processor) addVideo(ctx context.Context, filePath string, mime string) (photo.Image, error) {
+	v := photo.Video{}
+	if err := v.SetPath(ctx, filePath); err != nil {
+		return photo.Image{}, fmt.Errorf("set video path: %w", err)
+	}
+
+	v.Mime = mime
+
+	m, err := video.Probe(ctx, filePath)
+	if err != nil {
+		p.deps.CtxdLogger().Warn(ctx, "failed to read video meta", "error", err, "path", filePath)
+	}
+
+	v.Duration = m.Duration.Seconds()
+	v.Width = m.Width
+	v.Height = m.Height
+
+	if v, err = p.deps.PhotoVideoEnsurer().Ensure(ctx, v); err != nil {
+		return photo.Image{}, fmt.Errorf("ensure video: %w", err)
+	}
+
+	posterPath := video.PosterPath(v.Hash)
+	if _, err := os.Stat(posterPath); err != nil {
+		if err := os.MkdirAll(path.Dir(posterPath), 0o700); err != nil {
+			return photo.Image{}, fmt.Errorf("ensure poster dir: %w", err)
+		}
+
+		if err := video.ExtractPoster(ctx, filePath, posterPath, m); err != nil {
+			return photo.Image{}, err
+		}
+	}
+
+	d := photo.Image{}
+	if err := d.SetPath(ctx, posterPath); err != nil {
+		return photo.Image{}, fmt.Errorf("set poster path: %w", err)
+	}
+
+	// Poster image shares hash with video to be added to albums and rendered as a regular image.
+	d.Hash = v.Hash
+
+	if !m.CreatedAt.IsZero() {
+		d.TakenAt = &m.CreatedAt
+		d.UTime = m.CreatedAt.Unix()
+	}
+
+	img, err := p.deps.PhotoImageEnsurer().Ensure(ctx, d)
+	if err != nil {
+		return photo.Image{}, fmt.Errorf("ensure poster image: %w", err)
+	}
+
+	if m.HasLocation {
+		g := photo.Gps{}
+		g.Hash = v.Hash
+		g.Latitude = m.Latitude
+		g.Longitude = m.Longitude
+		g.Altitude = m.Altitude
+		g.GpsTime = m.CreatedAt
+
+		if _, err := p.deps.PhotoGpsEnsurer().Ensure(ctx, g); err != nil {
+			return photo.Image{}, fmt.Errorf("ensure video gps: %w", err)
+		}
+	}
+
+	return img, nil
+}
+
 func (p *Processor) AddDirectory(ctx context.Context, albumName string, dirPath string) ([]string, error) {
 	p.deps.StatsTracker().Add(ctx, "add_dir", 1)
 	p.deps.CtxdLogger().Important(ctx, "adding directory", "path", dirPath)
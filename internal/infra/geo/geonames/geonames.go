@@ -0,0 +1,250 @@
+// Package geonames provides offline reverse geocoding with GeoNames datasets.
+//
+// See also https://download.geonames.org/export/dump/.
+package geonames
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bool64/ctxd"
+	"github.com/vearutop/photo-blog/internal/infra/settings"
+	"github.com/vearutop/photo-blog/pkg/gazetteer"
+)
+
+const (
+	baseURL = "https://download.geonames.org/export/dump/"
+
+	admin1File    = "admin1CodesASCII"
+	countriesFile = "countryInfo"
+	altNamesFile  = "alternateNamesV2"
+
+	defaultDataset     = "cities15000"
+	defaultDir         = "gazetteer"
+	defaultMaxDistance = 30
+)
+
+type Deps interface {
+	CtxdLogger() ctxd.Logger
+}
+
+// Result is a reverse geocoding result.
+type Result struct {
+	// Place has default GeoNames names to build consistent facets.
+	Place gazetteer.Location
+
+	// Label is in the first configured language.
+	Label string
+
+	// Labels are keyed by language.
+	Labels map[string]string
+}
+
+// Service lazily loads dataset and reloads it on settings change.
+type Service struct {
+	deps Deps
+	cfg  func() settings.Geocoding
+
+	mu     sync.Mutex
+	loaded settings.Geocoding
+	g      *gazetteer.Gazetteer
+}
+
+func NewService(deps Deps, cfg func() settings.Geocoding) *Service {
+	return &Service{
+		deps: deps,
+		cfg:  cfg,
+	}
+}
+
+func normalize(cfg settings.Geocoding) settings.Geocoding {
+	if cfg.Dataset == "" {
+		cfg.Dataset = defaultDataset
+	}
+
+	if cfg.Dir == "" {
+		cfg.Dir = defaultDir
+	}
+
+	if cfg.MaxDistance == 0 {
+		cfg.MaxDistance = defaultMaxDistance
+	}
+
+	return cfg
+}
+
+// Reverse finds the closest place, found is false if there are no places within max distance.
+func (s *Service) Reverse(ctx context.Context, lat, lon float64) (res Result, found bool, err error) {
+	cfg := normalize(s.cfg())
+
+	g, err := s.gazetteer(ctx, cfg)
+	if err != nil {
+		return res, false, err
+	}
+
+	res.Place, found = g.Reverse(lat, lon, cfg.MaxDistance, "")
+	if !found {
+		return res, false, nil
+	}
+
+	res.Label = res.Place.Label()
+
+	if len(cfg.Languages) > 0 {
+		res.Labels = make(map[string]string, len(cfg.Languages))
+
+		for _, lang := range cfg.Languages {
+			l, _ := g.Reverse(lat, lon, cfg.MaxDistance, lang)
+			res.Labels[lang] = l.Label()
+		}
+
+		res.Label = res.Labels[cfg.Languages[0]]
+	}
+
+	return res, true, nil
+}
+
+func (s *Service) gazetteer(ctx context.Context, cfg settings.Geocoding) (*gazetteer.Gazetteer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.g != nil && s.loaded.Dataset == cfg.Dataset && s.loaded.Dir == cfg.Dir &&
+		slices.Equal(s.loaded.Languages, cfg.Languages) {
+		return s.g, nil
+	}
+
+	start := time.Now()
+	g := gazetteer.New()
+
+	files := []struct {
+		name     string
+		optional bool
+		load     func(r io.Reader) error
+	}{
+		{name: cfg.Dataset, load: g.LoadPlaces},
+		{name: admin1File, load: g.LoadAdmin1},
+		{name: countriesFile, load: g.LoadCountries},
+		{name: altNamesFile, optional: len(cfg.Languages) == 0, load: func(r io.Reader) error {
+			return g.LoadAlternateNames(r, cfg.Languages...)
+		}},
+	}
+
+	for _, f := range files {
+		if err := s.loadFile(ctx, cfg, f.name, f.optional, f.load); err != nil {
+			return nil, fmt.Errorf("load %s: %w", f.name, err)
+		}
+	}
+
+	s.deps.CtxdLogger().Info(ctx, "gazetteer loaded",
+		"places", g.Len(), "dataset", cfg.Dataset, "elapsed", time.Since(start).String())
+
+	s.g = g
+	s.loaded = cfg
+
+	return g, nil
+}
+
+// loadFile reads name.txt or name.txt from name.zip, missing files are downloaded if allowed.
+func (s *Service) loadFile(ctx context.Context, cfg settings.Geocoding, name string, optional bool, load func(r io.Reader) error) error {
+	txtFn := path.Join(cfg.Dir, name+".txt")
+	zipFn := path.Join(cfg.Dir, name+".zip")
+
+	if f, err := os.Open(txtFn); err == nil {
+		defer f.Close()
+
+		return load(f)
+	}
+
+	if _, err := os.Stat(zipFn); err != nil {
+		if optional {
+			return nil
+		}
+
+		if !cfg.Download {
+			return fmt.Errorf("missing %s or %s, enable download or get it from %s", txtFn, zipFn, baseURL)
+		}
+
+		// Small files are only available as text.
+		fn := zipFn
+		if name == admin1File || name == countriesFile {
+			fn = txtFn
+		}
+
+		if err := s.download(ctx, path.Base(fn), fn); err != nil {
+			return err
+		}
+
+		if fn == txtFn {
+			return s.loadFile(ctx, cfg, name, optional, load)
+		}
+	}
+
+	z, err := zip.OpenReader(zipFn)
+	if err != nil {
+		return err
+	}
+	defer z.Close()
+
+	for _, zf := range z.File {
+		if zf.Name != name+".txt" {
+			continue
+		}
+
+		r, err := zf.Open()
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+
+		return load(r)
+	}
+
+	return fmt.Errorf("%s.txt not found in %s", name, zipFn)
+}
+
+func (s *Service) download(ctx context.Context, name, fn string) error {
+	s.deps.CtxdLogger().Info(ctx, "downloading gazetteer file", "name", name)
+
+	if err := os.MkdirAll(path.Dir(fn), 0o700); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+name, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download %s: unexpected status %s", name, resp.Status)
+	}
+
+	f, err := os.CreateTemp(path.Dir(fn), strings.TrimSuffix(name, path.Ext(name))+"*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		return errors.Join(err, f.Close(), os.Remove(f.Name()))
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), fn)
+}
@@ -0,0 +1,57 @@
+package tiles
+
+import (
+	"math"
+)
+
+// BBox is a geographic bounding box.
+type BBox struct {
+	MinLat, MinLon, MaxLat, MaxLon float64
+}
+
+// XY is a tile position on a zoom level.
+type XY struct {
+	X, Y uint32
+}
+
+// Cover returns tiles that cover bounding box on a zoom level.
+func Cover(b BBox, z uint8) []XY {
+	x0, y0 := lonLatToXY(b.MinLon, b.MaxLat, z)
+	x1, y1 := lonLatToXY(b.MaxLon, b.MinLat, z)
+
+	res := make([]XY, 0, int(x1-x0+1)*int(y1-y0+1))
+
+	for x := x0; x <= x1; x++ {
+		for y := y0; y <= y1; y++ {
+			res = append(res, XY{X: x, Y: y})
+		}
+	}
+
+	return res
+}
+
+func lonLatToXY(lon, lat float64, z uint8) (x, y uint32) {
+	n := float64(uint32(1) << z)
+
+	// Web Mercator is limited to these latitudes.
+	lat = math.Max(math.Min(lat, 85.0511), -85.0511)
+	lon = math.Max(math.Min(lon, 180), -180)
+
+	r := lat * math.Pi / 180
+	fx := (lon + 180) / 360 * n
+	fy := (1 - math.Log(math.Tan(r)+1/math.Cos(r))/math.Pi) / 2 * n
+
+	return clamp(fx, n), clamp(fy, n)
+}
+
+func clamp(v, n float64) uint32 {
+	if v < 0 {
+		return 0
+	}
+
+	if v >= n {
+		return uint32(n) - 1
+	}
+
+	return uint32(v)
+}
@@ -0,0 +1,17 @@
+package tiles_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vearutop/photo-blog/internal/infra/geo/tiles"
+)
+
+func TestCover(t *testing.T) {
+	assert.Equal(t, []tiles.XY{{X: 0, Y: 0}}, tiles.Cover(tiles.BBox{MinLat: -80, MinLon: -170, MaxLat: 80, MaxLon: 170}, 0))
+	assert.Len(t, tiles.Cover(tiles.BBox{MinLat: -80, MinLon: -170, MaxLat: 80, MaxLon: 170}, 2), 16)
+
+	// Berlin.
+	assert.Equal(t, []tiles.XY{{X: 550, Y: 335}},
+		tiles.Cover(tiles.BBox{MinLat: 52.50, MinLon: 13.38, MaxLat: 52.53, MaxLon: 13.40}, 10))
+}
@@ -0,0 +1,146 @@
+// Package tiles serves map tiles from remote servers or local archives.
+package tiles
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/vearutop/photo-blog/pkg/mbtiles"
+	"github.com/vearutop/photo-blog/pkg/pmtiles"
+)
+
+// ErrNotFound is returned for a missing tile.
+var ErrNotFound = errors.New("tile not found")
+
+// Tile is a map tile.
+type Tile struct {
+	Data        []byte
+	ContentType string
+}
+
+type archive interface {
+	tile(ctx context.Context, z uint8, x, y uint32) (Tile, error)
+	Close() error
+}
+
+// Local serves tiles from MBTiles or PMTiles file, archive is reopened when file name changes.
+type Local struct {
+	mu sync.Mutex
+	fn string
+	a  archive
+}
+
+// Tile returns tile from archive file.
+func (l *Local) Tile(ctx context.Context, fn string, z uint8, x, y uint32) (Tile, error) {
+	l.mu.Lock()
+	if l.fn != fn || l.a == nil {
+		if l.a != nil {
+			_ = l.a.Close()
+			l.a = nil
+		}
+
+		a, err := open(fn)
+		if err != nil {
+			l.mu.Unlock()
+
+			return Tile{}, err
+		}
+
+		l.fn = fn
+		l.a = a
+	}
+	a := l.a
+	l.mu.Unlock()
+
+	return a.tile(ctx, z, x, y)
+}
+
+// Close closes opened archive.
+func (l *Local) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.a == nil {
+		return nil
+	}
+
+	err := l.a.Close()
+	l.a = nil
+
+	return err
+}
+
+func open(fn string) (archive, error) {
+	switch strings.ToLower(path.Ext(fn)) {
+	case ".mbtiles":
+		r, err := mbtiles.Open(fn)
+		if err != nil {
+			return nil, fmt.Errorf("open mbtiles: %w", err)
+		}
+
+		return mbArchive{r: r}, nil
+	case ".pmtiles":
+		r, err := pmtiles.Open(fn)
+		if err != nil {
+			return nil, fmt.Errorf("open pmtiles: %w", err)
+		}
+
+		return pmArchive{r: r}, nil
+	default:
+		return nil, fmt.Errorf("unsupported tiles archive %s, .mbtiles or .pmtiles expected", fn)
+	}
+}
+
+type mbArchive struct {
+	r *mbtiles.Reader
+}
+
+func (a mbArchive) tile(ctx context.Context, z uint8, x, y uint32) (Tile, error) {
+	d, err := a.r.Tile(ctx, z, x, y)
+	if errors.Is(err, mbtiles.ErrNotFound) {
+		return Tile{}, ErrNotFound
+	}
+
+	ct := map[string]string{
+		"png":  "image/png",
+		"jpg":  "image/jpeg",
+		"jpeg": "image/jpeg",
+		"webp": "image/webp",
+		"pbf":  "application/x-protobuf",
+	}[a.r.Format]
+
+	return Tile{Data: d, ContentType: ct}, err
+}
+
+func (a mbArchive) Close() error {
+	return a.r.Close()
+}
+
+type pmArchive struct {
+	r *pmtiles.Reader
+}
+
+func (a pmArchive) tile(_ context.Context, z uint8, x, y uint32) (Tile, error) {
+	d, err := a.r.Tile(z, x, y)
+	if errors.Is(err, pmtiles.ErrNotFound) {
+		return Tile{}, ErrNotFound
+	}
+
+	ct := map[uint8]string{
+		pmtiles.TileTypePNG:  "image/png",
+		pmtiles.TileTypeJPEG: "image/jpeg",
+		pmtiles.TileTypeWebP: "image/webp",
+		pmtiles.TileTypeAVIF: "image/avif",
+		pmtiles.TileTypeMVT:  "application/x-protobuf",
+	}[a.r.Header.TileType]
+
+	return Tile{Data: d, ContentType: ct}, err
+}
+
+func (a pmArchive) Close() error {
+	return a.r.Close()
+}
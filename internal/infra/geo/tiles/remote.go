@@ -0,0 +1,86 @@
+package tiles
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/bool64/dev/version"
+)
+
+// DefaultURL is a template of default remote tiles.
+const DefaultURL = "https://tile.openstreetmap.org/{z}/{x}/{y}.png"
+
+// IsDefault tells if tiles template points to OpenStreetMap tile server.
+//
+// Usage policy of OpenStreetMap tile server prohibits bulk downloads.
+func IsDefault(tpl string) bool {
+	return tpl == "" || strings.Contains(tpl, "tile.openstreetmap.org")
+}
+
+// UserAgent identifies the application to tile servers.
+func UserAgent() string {
+	v := version.Info().Version
+	if v == "" {
+		v = "dev"
+	}
+
+	return "photo-blog/" + v + " (+https://github.com/vearutop/photo-blog)"
+}
+
+// URL builds remote tile URL from template.
+func URL(tpl string, s, r, z, x, y string) string {
+	if tpl == "" {
+		tpl = DefaultURL
+	}
+
+	return strings.NewReplacer(
+		"{r}", r,
+		"{z}", z,
+		"{x}", x,
+		"{y}", y,
+		"{s}", s,
+	).Replace(tpl)
+}
+
+// CacheKey returns key of a remote tile in cache.
+func CacheKey(u string) []byte {
+	return []byte(u + "-png")
+}
+
+// Fetch downloads remote tile.
+func Fetch(ctx context.Context, u string, referer string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", UserAgent())
+	req.Header.Set("Accept", "image/png,image/*;q=0.8")
+
+	if referer != "" {
+		req.Header.Set("Referer", referer)
+	}
+
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	d, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d, %s", resp.StatusCode, string(d))
+	}
+
+	return d, nil
+}
@@ -0,0 +1,19 @@
+package tiles_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vearutop/photo-blog/internal/infra/geo/tiles"
+)
+
+func TestIsDefault(t *testing.T) {
+	assert.True(t, tiles.IsDefault(""))
+	assert.True(t, tiles.IsDefault(tiles.DefaultURL))
+	assert.False(t, tiles.IsDefault("https://tiles.example.com/{z}/{x}/{y}.png"))
+}
+
+func TestUserAgent(t *testing.T) {
+	assert.Contains(t, tiles.UserAgent(), "photo-blog/")
+	assert.NotContains(t, tiles.UserAgent(), "Mozilla")
+}
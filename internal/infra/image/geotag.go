@@ -0,0 +1,147 @@
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/pkg/geotrack"
+)
+
+// DefaultGpxMaxGap limits time distance between image and track points.
+const DefaultGpxMaxGap = 10 * time.Minute
+
+// AlbumTracks loads GPX tracks of an album into a position index, nil is returned if album has no tracks.
+func AlbumTracks(ctx context.Context, finder uniq.Finder[photo.Gpx], album photo.Album) (*geotrack.Index, error) {
+	if len(album.Settings.GpxTracksHashes) == 0 {
+		return nil, nil
+	}
+
+	gpxFiles, err := finder.FindByHashes(ctx, album.Settings.GpxTracksHashes...)
+	if err != nil {
+		return nil, fmt.Errorf("find gpx tracks: %w", err)
+	}
+
+	idx := &geotrack.Index{}
+
+	for _, gpx := range gpxFiles {
+		g, err := gpx.Load()
+		if err != nil {
+			return nil, fmt.Errorf("load gpx %s: %w", gpx.Path, err)
+		}
+
+		idx.AddGPX(g)
+	}
+
+	return idx, nil
+}
+
+// DerivePosition finds image position on album tracks, camera clock offset and max gap are taken from album settings.
+func DerivePosition(idx *geotrack.Index, s photo.AlbumSettings, img photo.Image) (photo.Gps, bool) {
+	if idx == nil || img.TakenAt == nil {
+		return photo.Gps{}, false
+	}
+
+	maxGap := time.Duration(s.GpxMaxGap) * time.Second
+	if maxGap == 0 {
+		maxGap = DefaultGpxMaxGap
+	}
+
+	t := img.TakenAt.Add(time.Duration(s.GpxClockOffset) * time.Second)
+
+	p, ok := idx.Lookup(t, maxGap)
+	if !ok {
+		return photo.Gps{}, false
+	}
+
+	g := photo.Gps{}
+	g.Hash = img.Hash
+	g.Latitude = p.Lat
+	g.Longitude = p.Lon
+	g.Altitude = p.Alt
+	g.GpsTime = t
+	g.Derived = true
+
+	return g, true
+}
+
+type geoLabelResetter interface {
+	PhotoMetaFinder() uniq.Finder[photo.Meta]
+	PhotoMetaEnsurer() uniq.Ensurer[photo.Meta]
+}
+
+// ResetGeoLabel removes reverse geocoding results of an image after position change.
+func ResetGeoLabel(ctx context.Context, deps geoLabelResetter, hash uniq.Hash) error {
+	m, err := deps.PhotoMetaFinder().FindByHash(ctx, hash)
+	if err != nil {
+		if errors.Is(err, status.NotFound) {
+			return nil
+		}
+
+		return err
+	}
+
+	if m.Data.Val.GeoLabel == nil && m.Data.Val.Place == nil {
+		return nil
+	}
+
+	_, err = deps.PhotoMetaEnsurer().Ensure(ctx, m, uniq.EnsureOption[photo.Meta]{
+		Prepare: func(candidate, existing *photo.Meta) bool {
+			if existing != nil {
+				*candidate = *existing
+			}
+
+			candidate.Data.Val.GeoLabel = nil
+			candidate.Data.Val.GeoLabels = nil
+			candidate.Data.Val.Place = nil
+
+			return false
+		},
+	})
+
+	return err
+}
+
+// ensureGpxGeotag derives image position from GPX tracks of image albums if it has no GPS data.
+func (i *indexer) ensureGpxGeotag(ctx context.Context, img photo.Image) error {
+	defer i.observe(ctx, "gpx_geotag", time.Now())
+
+	if img.TakenAt == nil {
+		return nil
+	}
+
+	if _, err := i.deps.PhotoGpsFinder().FindByHash(ctx, img.Hash); err == nil {
+		return nil
+	} else if !errors.Is(err, status.NotFound) {
+		return err
+	}
+
+	albums, err := i.deps.PhotoAlbumImageFinder().FindImageAlbums(ctx, 0, img.Hash)
+	if err != nil {
+		return fmt.Errorf("find image albums: %w", err)
+	}
+
+	for _, a := range albums[img.Hash] {
+		idx, err := AlbumTracks(ctx, i.deps.PhotoGpxFinder(), a)
+		if err != nil {
+			return err
+		}
+
+		g, ok := DerivePosition(idx, a.Settings, img)
+		if !ok {
+			continue
+		}
+
+		if _, err := i.deps.PhotoGpsEnsurer().Ensure(ctx, g); err != nil {
+			return fmt.Errorf("ensure derived gps: %w", err)
+		}
+
+		return ResetGeoLabel(ctx, i.deps, img.Hash)
+	}
+
+	return nil
+}
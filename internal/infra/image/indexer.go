@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"reflect"
 	"strings"
 	"time"
 
@@ -19,9 +20,12 @@ import (
 	"github.com/swaggest/usecase/status"
 	"github.com/vearutop/image-prompt/imageprompt"
 	"github.com/vearutop/image-prompt/multi"
+	"github.com/vearutop/photo-blog/internal/domain/event"
 	"github.com/vearutop/photo-blog/internal/domain/photo"
 	"github.com/vearutop/photo-blog/internal/domain/topic"
 	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/events"
+	"github.com/vearutop/photo-blog/internal/infra/geo/geonames"
 	"github.com/vearutop/photo-blog/internal/infra/geo/ors"
 	"github.com/vearutop/photo-blog/internal/infra/image/cloudflare"
 	"github.com/vearutop/photo-blog/internal/infra/image/faces"
@@ -51,13 +55,18 @@ type indexerDeps interface {
 	PhotoMetaEnsurer() uniq.Ensurer[photo.Meta]
 	PhotoMetaFinder() uniq.Finder[photo.Meta]
 
+	PhotoAlbumImageFinder() photo.AlbumImageFinder
+	PhotoGpxFinder() uniq.Finder[photo.Gpx]
+
 	CloudflareImageClassifier() *cloudflare.ImageClassifier
 	CloudflareImageDescriber() *cloudflare.ImageDescriber
 	FacesRecognizer() *faces.Recognizer
 	OpenRouteService() *ors.Client
+	GeoNames() *geonames.Service
 	ImagePrompter() *multi.ImagePrompter
 
 	Settings() settings.Values
+	EventBus() *events.Bus
 }
 
 const (
@@ -100,6 +109,11 @@ func (i *indexer) index(ctx context.Context, job IndexJob) error {
 	return i.Index(ctx, job.Image, job.Flags)
 }
 
+// observe tracks duration of an indexing step.
+func (i *indexer) observe(ctx context.Context, step string, start time.Time) {
+	i.deps.StatsTracker().Add(ctx, "index_step_seconds", time.Since(start).Seconds(), "step", step)
+}
+
 func (i *indexer) closeFile(ctx context.Context, f *os.File) {
 	if f == nil {
 		return
@@ -139,10 +153,14 @@ func ensureImageDimensions(ctx context.Context, img *photo.Image, flags photo.In
 		img.Height = int64(c.Width)
 	}
 
+	img.Width, img.Height = img.Settings.Edits.Size(img.Width, img.Height)
+
 	return true, nil
 }
 
 func (i *indexer) Index(ctx context.Context, img photo.Image, flags photo.IndexingFlags) (err error) {
+	defer i.observe(ctx, "total", time.Now())
+
 	ctx, done := telemetry.AddSpan(ctx, attribute.String("path", img.Path))
 	defer done(&err)
 
@@ -153,6 +171,7 @@ func (i *indexer) Index(ctx context.Context, img photo.Image, flags photo.Indexi
 	}
 
 	ctx = ctxd.AddFields(ctx, "img", img)
+	before := img
 
 	if len(img.Settings.HTTPSources) > 1 {
 		dup := map[string]bool{}
@@ -216,6 +235,21 @@ func (i *indexer) Index(ctx context.Context, img photo.Image, flags photo.Indexi
 
 	s := i.deps.Settings().Indexing()
 
+	if s.GpxGeotag {
+		if err := i.ensureGpxGeotag(ctx, img); err != nil {
+			i.deps.CtxdLogger().Error(ctx, "failed to geotag from gpx", "error", err)
+		}
+	}
+
+	// Derived hashes depend on thumbnails and have to be recalculated.
+	if flags.RebuildThumbnails {
+		img.BlurHash = ""
+
+		if s.Phash {
+			img.PHash = 0
+		}
+	}
+
 	i.ensureThumbs(ctx, img, flags)
 	i.ensureBlurHash(ctx, &img)
 
@@ -247,10 +281,17 @@ func (i *indexer) Index(ctx context.Context, img photo.Image, flags photo.Indexi
 		go i.ensureLLMDescription(ctx, img)
 	}
 
+	// Reindexing without rebuild of an already indexed and unchanged image is not worth an event.
+	if !before.Ready() || flags != (photo.IndexingFlags{}) || !reflect.DeepEqual(before, img) {
+		i.deps.EventBus().Publish(ctx, event.Image(event.ImageIndexed, "", img.Hash))
+	}
+
 	return nil
 }
 
 func (i *indexer) ensureGeoLabel(ctx context.Context, hash uniq.Hash) {
+	defer i.observe(ctx, "geo_label", time.Now())
+
 	g, err := i.deps.PhotoGpsFinder().FindByHash(ctx, hash)
 	if err != nil {
 		if !errors.Is(err, status.NotFound) {
@@ -267,23 +308,57 @@ func (i *indexer) ensureGeoLabel(ctx context.Context, hash uniq.Hash) {
 		return
 	}
 
-	if m.Data.Val.GeoLabel != nil {
-		return
-	}
+	var apply func(d *photo.MetaData)
 
-	label, err := i.deps.OpenRouteService().ReverseGeocode(ctx, g.Latitude, g.Longitude)
-	if err != nil {
-		i.deps.CtxdLogger().Error(ctx, "failed to reverse geocode", "error", err, "gps", g)
+	if i.deps.Settings().Geocoding().Provider == settings.GeocoderGazetteer {
+		if m.Data.Val.Place != nil {
+			return
+		}
 
-		return
+		res, found, err := i.deps.GeoNames().Reverse(ctx, g.Latitude, g.Longitude)
+		if err != nil {
+			i.deps.CtxdLogger().Error(ctx, "failed to reverse geocode with gazetteer", "error", err, "gps", g)
+
+			return
+		}
+
+		if !found {
+			return
+		}
+
+		apply = func(d *photo.MetaData) {
+			d.Place = &res.Place
+			d.GeoLabels = res.Labels
+
+			if d.GeoLabel == nil {
+				d.GeoLabel = &res.Label
+			}
+		}
+	} else {
+		if m.Data.Val.GeoLabel != nil {
+			return
+		}
+
+		label, err := i.deps.OpenRouteService().ReverseGeocode(ctx, g.Latitude, g.Longitude)
+		if err != nil {
+			i.deps.CtxdLogger().Error(ctx, "failed to reverse geocode", "error", err, "gps", g)
+
+			return
+		}
+
+		apply = func(d *photo.MetaData) {
+			d.GeoLabel = &label
+		}
 	}
 
+	m.Hash = hash
+
 	if _, err := i.deps.PhotoMetaEnsurer().Ensure(ctx, m, uniq.EnsureOption[photo.Meta]{
 		Prepare: func(candidate, existing *photo.Meta) bool {
 			if existing != nil {
 				*candidate = *existing
 			}
-			candidate.Data.Val.GeoLabel = &label
+			apply(&candidate.Data.Val)
 
 			return false
 		},
@@ -293,6 +368,8 @@ func (i *indexer) ensureGeoLabel(ctx context.Context, hash uniq.Hash) {
 }
 
 func (i *indexer) ensureCFClassification(ctx context.Context, img photo.Image) {
+	defer i.observe(ctx, "cf_classification", time.Now())
+
 	ctx = ctxd.AddFields(ctx, "action", "cf_classify")
 
 	m, err := i.deps.PhotoMetaFinder().FindByHash(ctx, img.Hash)
@@ -331,6 +408,8 @@ func (i *indexer) ensureCFClassification(ctx context.Context, img photo.Image) {
 
 // Generate a detailed caption for this image, up to 100 words. Don't name the places, items or people unless you're sure.
 func (i *indexer) ensureLLMDescription(ctx context.Context, img photo.Image) {
+	defer i.observe(ctx, "llm_description", time.Now())
+
 	ctx = ctxd.AddFields(ctx, "action", "llm_describe")
 
 	m, err := i.deps.PhotoMetaFinder().FindByHash(ctx, img.Hash)
@@ -393,6 +472,8 @@ func (i *indexer) ensureLLMDescription(ctx context.Context, img photo.Image) {
 }
 
 func (i *indexer) ensureCFDescription(ctx context.Context, img photo.Image) {
+	defer i.observe(ctx, "cf_description", time.Now())
+
 	ctx = ctxd.AddFields(ctx, "action", "cf_describe")
 
 	m, err := i.deps.PhotoMetaFinder().FindByHash(ctx, img.Hash)
@@ -430,6 +511,8 @@ func (i *indexer) ensureCFDescription(ctx context.Context, img photo.Image) {
 }
 
 func (i *indexer) ensureFacesRecognized(ctx context.Context, img photo.Image) {
+	defer i.observe(ctx, "faces", time.Now())
+
 	ctx = ctxd.AddFields(ctx, "action", "faces")
 
 	m, err := i.deps.PhotoMetaFinder().FindByHash(ctx, img.Hash)
@@ -504,6 +587,8 @@ func (i *indexer) ensureFacesRecognized(ctx context.Context, img photo.Image) {
 }
 
 func (i *indexer) ensurePHash(ctx context.Context, img *photo.Image) {
+	defer i.observe(ctx, "phash", time.Now())
+
 	if img.PHash != 0 {
 		return
 	}
@@ -538,6 +623,8 @@ func (i *indexer) ensurePHash(ctx context.Context, img *photo.Image) {
 }
 
 func (i *indexer) ensureSharpness(ctx context.Context, img photo.Image) error {
+	defer i.observe(ctx, "sharpness", time.Now())
+
 	if img.Sharpness != nil {
 		return nil
 	}
@@ -565,6 +652,8 @@ func (i *indexer) ensureSharpness(ctx context.Context, img photo.Image) error {
 }
 
 func (i *indexer) ensureBlurHash(ctx context.Context, img *photo.Image) {
+	defer i.observe(ctx, "blurhash", time.Now())
+
 	if img.BlurHash != "" {
 		return
 	}
@@ -599,6 +688,8 @@ func (i *indexer) ensureBlurHash(ctx context.Context, img *photo.Image) {
 }
 
 func (i *indexer) ensureThumbs(ctx context.Context, img photo.Image, flags photo.IndexingFlags) {
+	defer i.observe(ctx, "thumbs", time.Now())
+
 	s := i.deps.Settings().Indexing()
 
 	if flags.RebuildThumbnails {
@@ -645,6 +736,8 @@ func readMeta(ctx context.Context, img *photo.Image) (m Meta, err error) {
 }
 
 func (i *indexer) ensureIsHDR(ctx context.Context, img *photo.Image, flags photo.IndexingFlags) (err error) {
+	defer i.observe(ctx, "hdr", time.Now())
+
 	i.deps.CtxdLogger().Info(ctx, "checking image hdr", "img", img)
 
 	if flags.RebuildExif || flags.RebuildThumbnails {
@@ -683,6 +776,8 @@ func (i *indexer) ensureIsHDR(ctx context.Context, img *photo.Image, flags photo
 }
 
 func (i *indexer) ensureExif(ctx context.Context, img *photo.Image, flags photo.IndexingFlags) error {
+	defer i.observe(ctx, "exif", time.Now())
+
 	exifExists, err := i.deps.PhotoExifFinder().Exists(ctx, img.Hash)
 	if err != nil {
 		return ctxd.WrapError(ctx, err, "check existing exif")
@@ -14,6 +14,8 @@ import (
 	"github.com/vearutop/image-prompt/multi"
 	"github.com/vearutop/photo-blog/internal/domain/photo"
 	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/events"
+	"github.com/vearutop/photo-blog/internal/infra/geo/geonames"
 	"github.com/vearutop/photo-blog/internal/infra/geo/ors"
 	"github.com/vearutop/photo-blog/internal/infra/image/cloudflare"
 	faceinfra "github.com/vearutop/photo-blog/internal/infra/image/faces"
@@ -74,11 +76,15 @@ func (t testIndexerDeps) PhotoGpsEnsurer() uniq.Ensurer[photo.Gps] { return noop
 func (t testIndexerDeps) PhotoGpsFinder() uniq.Finder[photo.Gps] { return noopFinder[photo.Gps]{} }
 func (t testIndexerDeps) PhotoMetaEnsurer() uniq.Ensurer[photo.Meta] { return noopEnsurer[photo.Meta]{} }
 func (t testIndexerDeps) PhotoMetaFinder() uniq.Finder[photo.Meta] { return noopFinder[photo.Meta]{} }
+func (t testIndexerDeps) PhotoAlbumImageFinder() photo.AlbumImageFinder { return nil }
+func (t testIndexerDeps) PhotoGpxFinder() uniq.Finder[photo.Gpx] { return noopFinder[photo.Gpx]{} }
 func (t testIndexerDeps) CloudflareImageClassifier() *cloudflare.ImageClassifier { return nil }
 func (t testIndexerDeps) CloudflareImageDescriber() *cloudflare.ImageDescriber { return nil }
 func (t testIndexerDeps) FacesRecognizer() *faceinfra.Recognizer { return nil }
 func (t testIndexerDeps) OpenRouteService() *ors.Client { return nil }
+func (t testIndexerDeps) GeoNames() *geonames.Service { return nil }
 func (t testIndexerDeps) ImagePrompter() *multi.ImagePrompter { return nil }
+func (t testIndexerDeps) EventBus() *events.Bus { return nil }
 func (t testIndexerDeps) Settings() settings.Values { return testSettings{} }
 
 type stubImageFinder struct {
@@ -148,6 +154,14 @@ func (testSettings) CFImageDescriber() cloudflare.ImageWorkerConfig { return clo
 func (testSettings) ORSConfig() ors.Config { return ors.Config{} }
 func (testSettings) ImagePrompt() multi.Config { return multi.Config{} }
 func (testSettings) Indexing() settings.Indexing { return settings.Indexing{} }
+func (testSettings) Watermark() settings.Watermark { return settings.Watermark{} }
+func (testSettings) Geocoding() settings.Geocoding { return settings.Geocoding{} }
+func (testSettings) Comments() settings.Comments { return settings.Comments{} }
+func (testSettings) Notifications() settings.Notifications { return settings.Notifications{} }
+func (testSettings) Webhooks() settings.Webhooks { return settings.Webhooks{} }
+func (testSettings) ActivityPub() settings.ActivityPub { return settings.ActivityPub{} }
+func (testSettings) Backups() settings.Backups { return settings.Backups{} }
+func (testSettings) Bots() settings.Bots { return settings.Bots{} }
 
 func mustReadFile(path string) []byte {
 	data, err := os.ReadFile(path)
@@ -113,7 +113,7 @@ func (t *Thumbnailer) makeThumbnail(
 }
 
 func (t *Thumbnailer) resizeJPEG(ctx context.Context, i photo.Image, w, h uint, buf io.Writer) error {
-	if i.IsHDR != nil && *i.IsHDR {
+	if i.IsHDR != nil && *i.IsHDR && i.Settings.Edits.IsZero() {
 		t.deps.CtxdLogger().Debug(ctx, "resizing ultra hdr", "img", i, "w", w, "h", h)
 
 		return resizeUltraHDR(ctx, i, w, h, buf)
@@ -197,7 +197,7 @@ func (t *Thumbnailer) Thumbnail(ctx context.Context, i photo.Image, size photo.T
 	filePath := dir + i.Hash.String() + ".jpg"
 
 	// Check existing thumb file.
-	if s, err := os.Lstat(filePath); err == nil && s.Size() > 0 {
+	if s, err := os.Lstat(filePath); err == nil && s.Size() > 0 && !ShouldRebuildThumb(ctx) {
 		th.FilePath = filePath
 		i, err := loadJPEG(ctx, filePath)
 		if err != nil {
@@ -301,6 +301,12 @@ func loadImage(ctx context.Context, i photo.Image, w, h uint) (image.Image, erro
 		img = rot90(img)
 	}
 
+	if !i.Settings.Edits.IsZero() {
+		if img, err = i.Settings.Edits.Apply(img); err != nil {
+			return nil, fmt.Errorf("apply edits: %w", err)
+		}
+	}
+
 	return img, nil
 }
 
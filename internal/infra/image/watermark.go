@@ -0,0 +1,113 @@
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image/jpeg"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/bool64/brick/telemetry"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/infra/settings"
+	"github.com/vearutop/photo-blog/pkg/imgedit"
+)
+
+// watermarkDir keeps watermarked copies of thumbnails in subdirectories named by watermark version.
+const watermarkDir = "thumb-wm"
+
+var watermarkMu sync.Mutex
+
+// WatermarkThumb returns path to watermarked copy of a thumbnail, the copy is created if missing.
+func WatermarkThumb(ctx context.Context, th photo.Thumb, size photo.ThumbSize, wm settings.Watermark) (fn string, err error) {
+	ctx, finish := telemetry.AddSpan(ctx)
+	defer finish(&err)
+
+	version := wm.Version()
+	dir := path.Join(watermarkDir, version, string(size), th.Hash.String()[:1])
+	fn = path.Join(dir, th.Hash.String()+"-"+thumbVersion(th)+".jpg")
+
+	if s, err := os.Stat(fn); err == nil && s.Size() > 0 {
+		return fn, nil
+	}
+
+	if err := ensureWatermarkDir(version, dir); err != nil {
+		return "", err
+	}
+
+	// Copies of previous thumbnail content are stale after image was edited or replaced.
+	if stale, err := filepath.Glob(path.Join(dir, th.Hash.String()+"-*.jpg")); err == nil {
+		for _, s := range stale {
+			if err := os.Remove(s); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return "", fmt.Errorf("remove stale watermark: %w", err)
+			}
+		}
+	}
+
+	img, err := thumbJPEG(ctx, th)
+	if err != nil {
+		return "", fmt.Errorf("decode thumb: %w", err)
+	}
+
+	w := wm.Watermark()
+	e := imgedit.Edits{Watermark: &w}
+
+	res, err := e.Apply(img)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.CreateTemp(dir, "*.tmp")
+	if err != nil {
+		return "", err
+	}
+
+	if err := jpeg.Encode(f, res, &jpeg.Options{Quality: 85}); err != nil {
+		return "", errors.Join(err, f.Close(), os.Remove(f.Name()))
+	}
+
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	return fn, os.Rename(f.Name(), fn)
+}
+
+// thumbVersion identifies thumbnail content, it changes when thumbnail is rebuilt.
+func thumbVersion(th photo.Thumb) string {
+	mtime := th.CreatedAt
+	size := int64(len(th.Data))
+
+	if th.FilePath != "" {
+		if fi, err := os.Stat(th.FilePath); err == nil {
+			mtime = fi.ModTime()
+			size = fi.Size()
+		}
+	}
+
+	return strconv.FormatInt(mtime.UnixNano(), 36) + "-" + strconv.FormatInt(size, 36)
+}
+
+// ensureWatermarkDir creates directory and removes copies of previous watermark versions.
+func ensureWatermarkDir(version, dir string) error {
+	watermarkMu.Lock()
+	defer watermarkMu.Unlock()
+
+	if _, err := os.Stat(path.Join(watermarkDir, version)); err != nil {
+		if entries, err := os.ReadDir(watermarkDir); err == nil {
+			for _, e := range entries {
+				if e.Name() != version {
+					if err := os.RemoveAll(path.Join(watermarkDir, e.Name())); err != nil {
+						return fmt.Errorf("remove stale watermarks: %w", err)
+					}
+				}
+			}
+		}
+	}
+
+	return os.MkdirAll(dir, 0o700)
+}
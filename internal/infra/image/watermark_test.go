@@ -0,0 +1,63 @@
+package image
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/infra/settings"
+)
+
+func testThumb(t *testing.T, width int, createdAt time.Time) photo.Thumb {
+	t.Helper()
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, jpeg.Encode(buf, image.NewRGBA(image.Rect(0, 0, width, 100)), nil))
+
+	th := photo.Thumb{}
+	th.Hash = 123456
+	th.CreatedAt = createdAt
+	th.Width = uint(width)
+	th.Height = 100
+	th.Data = buf.Bytes()
+
+	return th
+}
+
+func TestWatermarkThumb_rebuildsEditedThumb(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	ctx := context.Background()
+	wm := settings.Watermark{Enabled: true, Text: "test", Opacity: 0.5, Scale: 0.2}
+
+	th := testThumb(t, 200, time.Unix(1000, 0))
+
+	fn1, err := WatermarkThumb(ctx, th, "200w", wm)
+	require.NoError(t, err)
+
+	fn, err := WatermarkThumb(ctx, th, "200w", wm)
+	require.NoError(t, err)
+	assert.Equal(t, fn1, fn, "cached copy expected for the same thumbnail")
+
+	// Thumbnail is rebuilt after image edit.
+	th = testThumb(t, 220, time.Unix(2000, 0))
+
+	fn2, err := WatermarkThumb(ctx, th, "200w", wm)
+	require.NoError(t, err)
+	assert.NotEqual(t, fn1, fn2)
+
+	_, err = os.Stat(fn1)
+	assert.True(t, os.IsNotExist(err), "stale copy should be removed")
+
+	copies, err := filepath.Glob(filepath.Join(filepath.Dir(fn2), "*.jpg"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{fn2}, copies)
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/bool64/cache/filecache"
 	"github.com/bool64/ctxd"
 	"github.com/bool64/sqluct"
+	"github.com/bool64/stats"
 	"github.com/bool64/zapctxd"
 	"github.com/swaggest/jsonform-go"
 	"github.com/swaggest/refl"
@@ -27,25 +28,38 @@ import (
 	"github.com/vearutop/gooselite/iofs"
 	"github.com/vearutop/image-prompt/multi"
 	"github.com/vearutop/netrie"
+	"github.com/vearutop/photo-blog/internal/domain/event"
 	"github.com/vearutop/photo-blog/internal/domain/photo"
 	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/archive"
 	"github.com/vearutop/photo-blog/internal/infra/auth"
+	"github.com/vearutop/photo-blog/internal/infra/backups"
 	"github.com/vearutop/photo-blog/internal/infra/dep"
+	"github.com/vearutop/photo-blog/internal/infra/engagement"
+	"github.com/vearutop/photo-blog/internal/infra/events"
+	"github.com/vearutop/photo-blog/internal/infra/federation"
 	"github.com/vearutop/photo-blog/internal/infra/files"
+	"github.com/vearutop/photo-blog/internal/infra/geo/geonames"
 	"github.com/vearutop/photo-blog/internal/infra/geo/ors"
+	"github.com/vearutop/photo-blog/internal/infra/geo/tiles"
 	"github.com/vearutop/photo-blog/internal/infra/image"
 	"github.com/vearutop/photo-blog/internal/infra/image/cloudflare"
 	"github.com/vearutop/photo-blog/internal/infra/image/faces"
 	"github.com/vearutop/photo-blog/internal/infra/image/sprite"
+	"github.com/vearutop/photo-blog/internal/infra/notifier"
 	"github.com/vearutop/photo-blog/internal/infra/queue"
 	"github.com/vearutop/photo-blog/internal/infra/schema"
 	"github.com/vearutop/photo-blog/internal/infra/service"
 	"github.com/vearutop/photo-blog/internal/infra/settings"
+	"github.com/vearutop/photo-blog/internal/infra/spam"
 	"github.com/vearutop/photo-blog/internal/infra/storage"
 	"github.com/vearutop/photo-blog/internal/infra/storage/sqlite"
 	"github.com/vearutop/photo-blog/internal/infra/storage/sqlite_stats"
 	"github.com/vearutop/photo-blog/internal/infra/storage/sqlite_thumbs"
 	"github.com/vearutop/photo-blog/internal/infra/storage/visitor"
+	"github.com/vearutop/photo-blog/internal/infra/trash"
+	"github.com/vearutop/photo-blog/pkg/notify"
+	"github.com/vearutop/photo-blog/pkg/openmetrics"
 	"github.com/vearutop/photo-blog/pkg/qlite"
 	"github.com/vearutop/photo-blog/pkg/sqlitec"
 	"github.com/vearutop/photo-blog/pkg/sqlitec/invalidation"
@@ -74,6 +88,8 @@ func NewServiceLocator(cfg service.Config, docsMode bool) (loc *service.Locator,
 		return nil, err
 	}
 
+	l.MetricsInstance = openmetrics.NewRegistry("photoblog", l.BaseLocator.StatsTracker())
+
 	l.SwaggerUIOptions = append(l.SwaggerUIOptions, func(cfg *swgui.Config) {
 		cfg.HideCurl = true
 	})
@@ -113,6 +129,7 @@ func NewServiceLocator(cfg service.Config, docsMode bool) (loc *service.Locator,
 
 	l.QueueBrokerInstance = qlite.NewBroker(queueStorage)
 	l.QueueBroker().Logger = l.CtxdLogger()
+	l.QueueBroker().Stats = l.StatsTracker()
 
 	ir := storage.NewImageRepository(l.Storage)
 	l.PhotoImageEnsurerProvider = ir
@@ -158,14 +175,44 @@ func NewServiceLocator(cfg service.Config, docsMode bool) (loc *service.Locator,
 		},
 	)
 
+	l.LocalMapTilesInstance = &tiles.Local{}
+	l.OnShutdown("local-map-tiles", func() {
+		if err := l.LocalMapTiles().Close(); err != nil {
+			l.CtxdLogger().Error(context.Background(), "failed to close local map tiles", "error", err)
+		}
+	})
+
 	if l.SettingsManagerInstance, err = settings.NewManager(storage.NewSettingsRepository(l.Storage), l.DepCache()); err != nil {
 		return nil, err
 	}
 
+	l.NotifierInstance = notifier.NewService(l.CtxdLogger(), l.Settings(), ".")
+	l.OnShutdown("notifier", l.Notifier().Close)
+
+	l.QueueBroker().OnDeadLetter = func(ctx context.Context, topic string, payload any, err error) {
+		l.Notifier().Notify(ctx, notify.Notification{
+			Event: notifier.JobFailed,
+			Title: "Job failed: " + topic,
+			Text:  fmt.Sprintf("%v\n%v", payload, err),
+		})
+	}
+
+	if l.EventBusInstance, err = events.NewBus(l.CtxdLogger(), l.Settings(), l.QueueBroker()); err != nil {
+		return nil, err
+	}
+
+	// Audit log records album content changes with their authors.
+	l.EventBusInstance.OnPublish = func(ctx context.Context, e event.Event) {
+		if err := l.AuditRepository().AddEvent(ctx, e, auth.Actor(ctx)); err != nil {
+			l.CtxdLogger().Error(ctx, "failed to record audit entry", "error", err, "event", e)
+		}
+	}
+
 	l.CloudflareImageClassifierInstance = cloudflare.NewImageClassifier(l.CtxdLogger(), l.Settings().CFImageClassifier)
 	l.CloudflareImageDescriberInstance = cloudflare.NewImageDescriber(l.CtxdLogger(), l.Settings().CFImageDescriber)
 	l.FacesRecognizerInstance = faces.NewRecognizer(l.CtxdLogger(), l.Settings().ExternalAPI().FacesRecognizer)
 	l.ORS = ors.NewORS(l, l.Settings().ORSConfig)
+	l.GeoNamesInstance = geonames.NewService(l, l.Settings().Geocoding)
 	l.ImagePrompterInstance = multi.NewImagePrompter(l.Settings().ImagePrompt)
 
 	if err = setupAccessLog(l); err != nil {
@@ -176,6 +223,9 @@ func NewServiceLocator(cfg service.Config, docsMode bool) (loc *service.Locator,
 
 	fr := storage.NewFavoriteRepository(l.Storage)
 	l.FavoriteRepositoryProvider = fr
+	l.ProofingRepositoryProvider = storage.NewProofingRepository(l.Storage)
+	l.AuditRepositoryProvider = storage.NewAuditRepository(l.Storage)
+	l.TrashRepositoryProvider = storage.NewTrashRepository(l.Storage)
 
 	statsStorage, err := setupStorage(l, "stats", sqlite_stats.Migrations)
 	if err != nil {
@@ -203,11 +253,19 @@ func NewServiceLocator(cfg service.Config, docsMode bool) (loc *service.Locator,
 		return nil, err
 	}
 
+	l.VisitorStatsInstance.Anonymous = func() bool {
+		return l.Settings().Visitors().PrivacyMode
+	}
+	l.VisitorStatsInstance.PrivateIP = auth.PrivateIP
+
 	thumbStorage, err := setupStorage(l, "thumbs", sqlite_thumbs.Migrations)
 	if err != nil {
 		return nil, err
 	}
-	l.PhotoThumbnailerProvider = storage.NewThumbRepository(thumbStorage, image.NewThumbnailer(l), l.CtxdLogger())
+	thumbRepo := storage.NewThumbRepository(thumbStorage, image.NewThumbnailer(l), l.CtxdLogger(), l.StatsTracker())
+	l.PhotoThumbnailerProvider = thumbRepo
+	l.PhotoThumbDeleterProvider = thumbRepo
+	l.ArchiveInstance = archive.NewService(l.CtxdLogger(), l.Storage, thumbStorage, l.SettingsManager(), l.DepCache())
 
 	spriteBlobStorage, err := filecache.NewStorage[string]("album-sprite-blobs", func(cfg *filecache.Config[string]) {
 		split := filecache.PrefixSplit(1)
@@ -242,18 +300,27 @@ func NewServiceLocator(cfg service.Config, docsMode bool) (loc *service.Locator,
 	gpsRepo := storage.NewGpsRepository(l.Storage)
 	l.PhotoGpsFinderProvider = gpsRepo
 	l.PhotoGpsEnsurerProvider = gpsRepo
+	l.PhotoGpsDeleterProvider = gpsRepo
 
 	gpxRepo := storage.NewGpxRepository(l.Storage)
 	l.PhotoGpxFinderProvider = gpxRepo
 	l.PhotoGpxEnsurerProvider = gpxRepo
 
+	videoRepo := storage.NewVideoRepository(l.Storage)
+	l.PhotoVideoFinderProvider = videoRepo
+	l.PhotoVideoEnsurerProvider = videoRepo
+
 	visitorRepo := storage.NewVisitorRepository(l.Storage)
 	l.SiteVisitorFinderProvider = visitorRepo
 	l.SiteVisitorEnsurerProvider = visitorRepo
+	l.SiteVisitorRepositoryProvider = visitorRepo
+	l.SiteFollowerRepositoryProvider = storage.NewFollowerRepository(l.Storage)
 
 	messageRepo := storage.NewMessageRepository(l.Storage)
 	l.CommentMessageEnsurerProvider = messageRepo
 	l.CommentMessageFinderProvider = messageRepo
+	l.CommentMessageRepositoryProvider = messageRepo
+	l.CommentSpamScorerInstance = spam.NewLocal(l.Settings())
 
 	threadRepo := storage.NewThreadRepository(l.Storage)
 	l.CommentThreadEnsurerProvider = threadRepo
@@ -264,6 +331,20 @@ func NewServiceLocator(cfg service.Config, docsMode bool) (loc *service.Locator,
 
 	l.FilesProcessorInstance = files.NewProcessor(l)
 
+	if l.FederationInstance, err = federation.NewService(l); err != nil {
+		return nil, err
+	}
+	l.OnShutdown("federation", l.Federation().Close)
+
+	l.DBBackupsInstance = backups.NewService(l.CtxdLogger(), l.Settings(), l.DBInstances())
+	l.OnShutdown("db-backups", l.DBBackups().Close)
+
+	l.BotPolicyInstance = auth.NewBotPolicy(l.CtxdLogger(), l.Settings(), l.StatsTracker())
+	l.EngagementInstance = engagement.NewService(l)
+	l.TrashInstance = trash.NewService(l)
+
+	collectMetrics(l)
+
 	if err := refl.NoEmptyFields(l); err != nil {
 		return nil, err
 	}
@@ -285,9 +366,101 @@ func NewServiceLocator(cfg service.Config, docsMode bool) (loc *service.Locator,
 		}
 	}()
 
+	go func() {
+		for {
+			// Rollups are updated before raw visits are pruned.
+			if err := l.VisitorStats().RollupPending(context.Background(), time.Now()); err != nil {
+				l.CtxdLogger().Error(context.Background(), "failed to update stats rollups", "error", err)
+			} else {
+				pruneVisitors(l)
+			}
+
+			if err := l.Engagement().UpdateMostLoved(context.Background()); err != nil {
+				l.CtxdLogger().Error(context.Background(), "failed to update most loved albums", "error", err)
+			}
+
+			purgeTrash(l)
+
+			<-time.Tick(time.Hour)
+		}
+	}()
+
 	return l, nil
 }
 
+// pruneVisitors removes visitor data older than retention period.
+func pruneVisitors(l *service.Locator) {
+	days := l.Settings().Visitors().RetentionDays
+	if days <= 0 {
+		return
+	}
+
+	ctx := context.Background()
+
+	before := time.Now().AddDate(0, 0, -days)
+
+	res, err := l.VisitorStats().Prune(ctx, before)
+	if err != nil {
+		l.CtxdLogger().Error(ctx, "failed to prune visitor data", "error", err)
+
+		return
+	}
+
+	if res["message_ip"], err = l.CommentMessageRepository().ClearIPs(ctx, before); err != nil {
+		l.CtxdLogger().Error(ctx, "failed to clear message IPs", "error", err)
+	}
+
+	l.CtxdLogger().Info(ctx, "visitor data pruned", "days", days, "removed", res)
+}
+
+func purgeTrash(l *service.Locator) {
+	ctx := context.Background()
+
+	res, err := l.Trash().Purge(ctx, time.Now())
+	if err != nil {
+		l.CtxdLogger().Error(ctx, "failed to purge trash", "error", err)
+
+		return
+	}
+
+	if res.Images > 0 {
+		l.CtxdLogger().Info(ctx, "trash purged", "result", res)
+	}
+}
+
+// collectMetrics adds gauges that are measured on exposition.
+func collectMetrics(l *service.Locator) {
+	l.Metrics().Collect(func(ctx context.Context, s stats.Setter) {
+		depth, err := l.QueueBroker().Depth(ctx)
+		if err != nil {
+			l.CtxdLogger().Error(ctx, "failed to count queue messages", "error", err)
+		}
+
+		for _, d := range depth {
+			s.Set(ctx, "queue_messages", float64(d.Pending), "topic", d.Topic, "state", "pending")
+			s.Set(ctx, "queue_messages", float64(d.Running), "topic", d.Topic, "state", "running")
+		}
+
+		for _, db := range l.DBInstances() {
+			var pages, pageSize int64
+
+			if err := db.Instance.QueryRowContext(ctx, "PRAGMA page_count").Scan(&pages); err != nil {
+				l.CtxdLogger().Error(ctx, "failed to get database size", "db", db.Name, "error", err)
+
+				continue
+			}
+
+			if err := db.Instance.QueryRowContext(ctx, "PRAGMA page_size").Scan(&pageSize); err != nil {
+				l.CtxdLogger().Error(ctx, "failed to get database size", "db", db.Name, "error", err)
+
+				continue
+			}
+
+			s.Set(ctx, "sqlite_size_bytes", float64(pages*pageSize), "db", db.Name)
+		}
+	})
+}
+
 func setupAccessLog(l *service.Locator) error {
 	f, err := os.OpenFile("access.log", os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o600)
 	if err != nil {
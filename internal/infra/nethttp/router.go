@@ -158,6 +158,8 @@ func NewRouter(deps *service.Locator) *web.Service {
 		s.Post("/album/{name}/directory", addDir)
 		s.Post("/album/add-recursive", control.AddDirectoryRecursive(deps, addDir))
 		s.Post("/album/{name}/url", control.AddRemote(deps))
+		s.Post("/album/{name}/geotag", control.GeotagAlbum(deps))
+		s.Delete("/album/{name}/geotag", control.RevertGeotagAlbum(deps))
 
 		s.Get("/albums.json", usecase.GetAlbums(deps))
 		s.Post("/index/{name}", control.IndexAlbum(deps), nethttp.SuccessStatus(http.StatusAccepted))
@@ -183,6 +185,24 @@ func NewRouter(deps *service.Locator) *web.Service {
 		s.Post("/settings/external_api.json", settings.SetExternalAPI(deps))
 		s.Post("/settings/image_prompt.json", settings.SetImagePrompt(deps))
 		s.Post("/settings/indexing.json", settings.SetIndexing(deps))
+		s.Post("/settings/watermark.json", settings.SetWatermark(deps))
+		s.Post("/settings/geocoding.json", settings.SetGeocoding(deps))
+		s.Post("/settings/comments.json", settings.SetComments(deps))
+		s.Post("/settings/notifications.json", settings.SetNotifications(deps))
+		s.Post("/settings/notifications/test", settings.TestNotification(deps))
+		s.Post("/settings/webhooks.json", settings.SetWebhooks(deps))
+		s.Post("/settings/activitypub.json", settings.SetActivityPub(deps))
+		s.Post("/settings/backups.json", settings.SetBackups(deps))
+		s.Post("/settings/bots.json", settings.SetBots(deps))
+		s.Method(http.MethodGet, "/events", deps.EventBus())
+		s.Method(http.MethodGet, "/metrics", deps.Metrics())
+		s.Get("/backup/export.zip", control.ExportSite(deps))
+		s.Post("/backup/import", control.ImportSite(deps))
+		s.Get("/backups.html", control.ShowBackups(deps))
+		s.Post("/backups/run", control.RunBackup(deps))
+		s.Post("/backups/verify", control.VerifyBackup(deps))
+		s.Get("/trash.html", control.ShowTrash(deps))
+		s.Post("/trash/purge", control.PurgeTrash(deps))
 
 		s.Get("/album/{hash}.json", control.Get(deps, func() uniq.Finder[photo.Album] { return deps.PhotoAlbumFinder() }))
 		s.Get("/image/{hash}.json", control.Get(deps, func() uniq.Finder[photo.Image] { return deps.PhotoImageFinder() }))
@@ -193,25 +213,39 @@ func NewRouter(deps *service.Locator) *web.Service {
 		s.Put("/image", control.Update(deps, func() uniq.Ensurer[photo.Image] { return deps.PhotoImageEnsurer() }))
 		s.Put("/exif", control.Update(deps, func() uniq.Ensurer[photo.Exif] { return deps.PhotoExifEnsurer() }))
 		s.Put("/gps", control.Update(deps, func() uniq.Ensurer[photo.Gps] { return deps.PhotoGpsEnsurer() }))
+		s.Put("/image/{hash}/edits", control.SetImageEdits(deps))
+		s.Delete("/image/{hash}/edits", control.ResetImageEdits(deps))
 
 		s.Put("/album-image-time", control.SetAlbumImageTime(deps))
 
 		s.Delete("/album/{name}", control.DeleteAlbum(deps))
+		s.Get("/album/{name}/audit.json", control.GetAlbumAudit(deps))
+		s.Post("/album/{name}/restore", control.RestoreAlbum(deps))
 
 		s.Post("/message/approve", control.ApproveMessage(deps))
+		s.Get("/comments/inbox.html", control.ShowCommentsInbox(deps))
+		s.Post("/comments/moderate", control.ModerateMessages(deps))
 
 		s.Get("/image-info/{hash}.json", usecase.GetImageInfo(deps))
 
+		s.Delete("/proofing/{name}/submit", usecase.ReopenProofing(deps))
+		s.Get("/proofing/{name}/selected.{format}", usecase.ExportProofing(deps))
+
 		s.Get("/login", control.Login())
 		s.Get("/settings/version.html", control.Version())
 		s.Get("/settings/self-update", control.SelfUpdate())
 
 		// Stats.
+		s.Get("/stats/dashboard.html", stats.ShowDashboard(deps))
 		s.Get("/stats/daily.html", stats.ShowDailyTotal(deps))
 		s.Get("/stats/top-pages.html", stats.TopPages(deps))
 		s.Get("/stats/top-images.html", stats.TopImages(deps))
 		s.Get("/stats/refers.html", stats.ShowRefers(deps))
 		s.Get("/stats/visitor/{hash}.html", stats.ShowVisitor(deps))
+		s.Get("/stats/visitor/{hash}.json", stats.ExportVisitorData(deps))
+		s.Delete("/stats/visitor/{hash}", stats.EraseVisitorData(deps))
+		s.Get("/stats/bots.json", stats.ShowBotTraffic(deps))
+		s.Get("/stats/favorites.html", stats.ShowFavorites(deps))
 	})
 
 	maybeAuth := auth.MaybeAuth(deps.Settings())
@@ -227,6 +261,8 @@ func NewRouter(deps *service.Locator) *web.Service {
 		}
 
 		s.Delete("/album/{name}/{hash}", control.RemoveFromAlbum(deps))
+		s.Post("/album/{name}/{hash}/restore", control.RestoreToAlbum(deps))
+		s.Get("/album/{name}/trash.json", control.GetAlbumTrash(deps))
 	})
 
 	s.Get("/album-contents/{name}.json", usecase.GetAlbumContents(deps))
@@ -238,6 +274,7 @@ func NewRouter(deps *service.Locator) *web.Service {
 		s.Wrap(maybeAuth)
 
 		s.Wrap(auth.VisitorMiddleware(deps.AccessLog(), deps.Settings(), deps.VisitorStats(), deps.ASNBot))
+		s.Wrap(deps.BotPolicy().Middleware)
 
 		s.Wrap(func(handler http.Handler) http.Handler {
 			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -293,13 +330,23 @@ func NewRouter(deps *service.Locator) *web.Service {
 		s.Get("/help/{file}", help.ServeFile(deps))
 
 		s.Get("/", usecase.ShowMain(deps))
+		s.Get("/feed.atom", usecase.ServeFeed(deps, usecase.FeedAtom))
+		s.Get("/feed.json", usecase.ServeFeed(deps, usecase.FeedJSON))
+		s.Get("/{name}/feed.atom", usecase.ServeAlbumFeed(deps, usecase.FeedAtom))
+		s.Get("/{name}/feed.json", usecase.ServeAlbumFeed(deps, usecase.FeedJSON))
 		showAlbum := usecase.ShowAlbum(deps)
 		s.Get("/{name}/", showAlbum)
 		s.Get("/{name}/photo-{hash}.html", usecase.ShowAlbumAtImage(showAlbum))
 
 		s.Get("/search/", usecase.SearchImages(deps))
+		s.Get("/search/places.json", usecase.Places(deps))
+
+		s.Get("/map/", usecase.ShowMap(deps))
+		s.Get("/map/photos.json", usecase.MapPhotos(deps))
 
 		s.Get("/poi/photos-{name}.gpx", usecase.DownloadImagesPoiGpx(deps))
+		s.Get("/geo/{name}.geojson", usecase.ExportGeo(deps, usecase.GeoJSON))
+		s.Get("/geo/{name}.kml", usecase.ExportGeo(deps, usecase.KML))
 		s.Get("/album/{name}.zip", usecase.DownloadAlbum(deps))
 		s.Get("/{name}/pano-{hash}.html", usecase.ShowPano(deps))
 
@@ -307,11 +354,16 @@ func NewRouter(deps *service.Locator) *web.Service {
 
 		s.Get("/image/{hash}.jpg", usecase.ShowImage(deps, false))
 		s.Get("/image/{hash}.avif", usecase.ShowImage(deps, true))
+		s.Get("/video/{hash}", usecase.ShowVideo(deps))
 		s.Get("/thumb/{size}/{hash}.jpg", usecase.ShowThumb(deps))
 		s.Get("/thumb-sprite/{key}.jpg", usecase.ShowAlbumSprite(deps))
 		s.Get("/track/{hash}.gpx", usecase.DownloadGpx(deps))
+		s.Get("/track/{hash}/profile.json", usecase.GetTrackProfile(deps))
 
 		s.Post("/message", usecase.AddMessage(deps))
+		s.Put("/message/{hash}", usecase.EditMessage(deps))
+		s.Delete("/message/{hash}", usecase.DeleteMessage(deps))
+		s.Get("/comments.json", usecase.GetMessages(deps))
 
 		s.Get("/site/{file}", usecase.ServeSiteFile(deps))
 		s.Get("/stats", usecase.CollectStats(deps))
@@ -319,15 +371,30 @@ func NewRouter(deps *service.Locator) *web.Service {
 		s.Post("/favorite", usecase.AddFavorite(deps))
 		s.Delete("/favorite", usecase.DeleteFavorite(deps))
 		s.Get("/favorite", usecase.GetFavorite(deps))
+		s.Put("/favorite/note", usecase.SetFavoriteNote(deps))
+		s.Get("/favorite/lists", usecase.GetFavoriteLists(deps))
+		s.Post("/favorite/list", usecase.SaveFavoriteList(deps))
+		s.Delete("/favorite/list", usecase.DeleteFavoriteList(deps))
+		s.Get("/favorite/list/{hash}.csv", usecase.ExportFavoriteList(deps))
+
+		s.Get("/proofing/{name}.json", usecase.GetProofing(deps))
+		s.Put("/proofing/{name}/mark", usecase.MarkProofingImage(deps))
+		s.Post("/proofing/{name}/import-favorites", usecase.ImportProofingFavorites(deps))
+		s.Post("/proofing/{name}/submit", usecase.SubmitProofing(deps))
 	})
 
 	s.Get("/sitemap.xml", usecase.ServeSitemap(deps))
 
+	// ActivityPub federation.
+	s.Method(http.MethodGet, "/.well-known/webfinger", http.HandlerFunc(deps.Federation().WebFinger))
+	s.Method(http.MethodGet, "/ap/actor", http.HandlerFunc(deps.Federation().Actor))
+	s.Method(http.MethodGet, "/ap/outbox", http.HandlerFunc(deps.Federation().Outbox))
+	s.Method(http.MethodGet, "/ap/followers", http.HandlerFunc(deps.Federation().Followers))
+	s.Method(http.MethodGet, "/ap/objects/{id}", http.HandlerFunc(deps.Federation().Object))
+	s.Method(http.MethodPost, "/ap/inbox", http.HandlerFunc(deps.Federation().Inbox))
+
 	s.Get("/favicon.ico", usecase.ServeFavicon(deps))
-	s.Method(http.MethodGet, "/robots.txt", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-		_, _ = w.Write([]byte("User-agent: *\nAllow: /"))
-	}))
+	s.Get("/robots.txt", usecase.ServeRobots(deps))
 
 	// Redirecting `/my-album` to `/my-album/`.
 	s.Method(http.MethodGet, "/{name}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
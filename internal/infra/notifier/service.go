@@ -0,0 +1,254 @@
+// Package notifier sends admin notifications about site events.
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bool64/ctxd"
+	"github.com/vearutop/photo-blog/internal/infra/settings"
+	"github.com/vearutop/photo-blog/pkg/diskspace"
+	"github.com/vearutop/photo-blog/pkg/notify"
+)
+
+// Events.
+const (
+	MessagePending = "message_pending"
+	CollabUpload   = "collab_upload"
+	JobFailed      = "job_failed"
+	DiskSpaceLow   = "disk_space_low"
+	ProofingSubmit = "proofing_submit"
+)
+
+const (
+	tickInterval      = time.Minute
+	diskCheckInterval = 10 * time.Minute
+	sendTimeout       = 30 * time.Second
+	queueSize         = 100
+)
+
+// Service filters events by settings, collects digests and delivers notifications to configured channels.
+type Service struct {
+	logger      ctxd.Logger
+	settings    settings.Values
+	storagePath string
+
+	digest notify.Digest
+	queue  chan notify.Notification
+
+	mu        sync.Mutex
+	diskLow   bool
+	diskCheck time.Time
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewService creates notifier and starts background delivery of digests.
+func NewService(logger ctxd.Logger, s settings.Values, storagePath string) *Service {
+	n := &Service{
+		logger:      logger,
+		settings:    s,
+		storagePath: storagePath,
+		queue:       make(chan notify.Notification, queueSize),
+		done:        make(chan struct{}),
+	}
+
+	go n.run()
+
+	return n
+}
+
+// Close stops background delivery and sends queued notifications and pending digest.
+func (s *Service) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+
+		for {
+			select {
+			case n := <-s.queue:
+				_ = s.send(context.Background(), []notify.Notification{n})
+			default:
+				if pending := s.digest.Due(0); len(pending) > 0 {
+					_ = s.send(context.Background(), pending)
+				}
+
+				return
+			}
+		}
+	})
+}
+
+// URL returns absolute address for a site path.
+func (s *Service) URL(path string) string {
+	base := s.settings.Notifications().BaseURL
+	if base == "" {
+		base = s.settings.Appearance().CanonicalBaseURL
+	}
+
+	return strings.TrimRight(base, "/") + path
+}
+
+func (s *Service) enabled(cfg settings.Notifications, event string) bool {
+	if !cfg.Email.Enabled() && !cfg.Webhook.Enabled() {
+		return false
+	}
+
+	switch event {
+	case MessagePending:
+		return cfg.MessagePending
+	case CollabUpload:
+		return cfg.CollabUpload
+	case JobFailed:
+		return cfg.JobFailed
+	case DiskSpaceLow:
+		return cfg.DiskSpaceLow
+	case ProofingSubmit:
+		return cfg.ProofingSubmit
+	}
+
+	return false
+}
+
+// Notify delivers or queues a notification if the event is enabled in settings.
+func (s *Service) Notify(ctx context.Context, n notify.Notification) {
+	cfg := s.settings.Notifications()
+
+	if !s.enabled(cfg, n.Event) {
+		return
+	}
+
+	if n.Time.IsZero() {
+		n.Time = time.Now()
+	}
+
+	if cfg.Digest > 0 {
+		s.digest.Add(n)
+
+		return
+	}
+
+	select {
+	case s.queue <- n:
+	default:
+		s.logger.Error(ctx, "notification queue is full, dropping notification", "event", n.Event, "title", n.Title)
+	}
+}
+
+// Test sends a notification to all configured channels regardless of enabled events.
+func (s *Service) Test(ctx context.Context) error {
+	cfg := s.settings.Notifications()
+
+	if !cfg.Email.Enabled() && !cfg.Webhook.Enabled() {
+		return errors.New("no notification channels configured")
+	}
+
+	return s.send(ctx, []notify.Notification{{
+		Event: "test",
+		Title: "Test notification",
+		Text:  "Notifications are configured.",
+		URL:   s.URL("/edit/settings.html"),
+		Time:  time.Now(),
+	}})
+}
+
+func (s *Service) send(ctx context.Context, notifications []notify.Notification) error {
+	cfg := s.settings.Notifications()
+
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	var (
+		channels []notify.Channel
+		errs     []error
+	)
+
+	if cfg.Email.Enabled() {
+		channels = append(channels, cfg.Email)
+	}
+
+	if cfg.Webhook.Enabled() {
+		channels = append(channels, cfg.Webhook)
+	}
+
+	for _, c := range channels {
+		if err := c.Send(ctx, notifications); err != nil {
+			s.logger.Error(ctx, "failed to send notifications",
+				"error", err, "channel", fmt.Sprintf("%T", c), "count", len(notifications))
+
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *Service) run() {
+	t := time.NewTicker(tickInterval)
+	defer t.Stop()
+
+	for {
+		ctx := context.Background()
+
+		select {
+		case <-s.done:
+			return
+		case n := <-s.queue:
+			_ = s.send(ctx, []notify.Notification{n})
+
+			continue
+		case <-t.C:
+		}
+
+		cfg := s.settings.Notifications()
+
+		if pending := s.digest.Due(time.Duration(cfg.Digest) * time.Minute); len(pending) > 0 {
+			_ = s.send(ctx, pending)
+		}
+
+		if cfg.DiskSpaceLow {
+			s.checkDiskSpace(ctx, cfg)
+		}
+	}
+}
+
+func (s *Service) checkDiskSpace(ctx context.Context, cfg settings.Notifications) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(s.diskCheck) < diskCheckInterval {
+		return
+	}
+
+	s.diskCheck = time.Now()
+
+	minFree := cfg.MinFreeSpace
+	if minFree == 0 {
+		minFree = 1024
+	}
+
+	free, err := diskspace.Free(s.storagePath)
+	if err != nil {
+		s.logger.Error(ctx, "failed to check disk space", "error", err)
+
+		return
+	}
+
+	freeMB := free / (1 << 20)
+	low := freeMB < uint64(minFree)
+
+	// Notify once when free space goes below the limit.
+	if low && !s.diskLow {
+		s.Notify(ctx, notify.Notification{
+			Event: DiskSpaceLow,
+			Title: "Low disk space",
+			Text:  fmt.Sprintf("%d MB left in %s, limit is %d MB.", freeMB, s.storagePath, minFree),
+		})
+	}
+
+	s.diskLow = low
+}
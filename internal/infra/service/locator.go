@@ -5,19 +5,32 @@ import (
 	"github.com/bool64/cache"
 	"github.com/bool64/ctxd"
 	"github.com/bool64/sqluct"
+	"github.com/bool64/stats"
 	"github.com/swaggest/jsonform-go"
 	"github.com/vearutop/dbcon/dbcon"
 	"github.com/vearutop/image-prompt/multi"
 	"github.com/vearutop/netrie"
+	"github.com/vearutop/photo-blog/internal/domain/comment"
+	"github.com/vearutop/photo-blog/internal/infra/archive"
+	"github.com/vearutop/photo-blog/internal/infra/auth"
+	"github.com/vearutop/photo-blog/internal/infra/backups"
 	"github.com/vearutop/photo-blog/internal/infra/dep"
+	"github.com/vearutop/photo-blog/internal/infra/engagement"
+	"github.com/vearutop/photo-blog/internal/infra/events"
+	"github.com/vearutop/photo-blog/internal/infra/federation"
 	"github.com/vearutop/photo-blog/internal/infra/files"
+	"github.com/vearutop/photo-blog/internal/infra/geo/geonames"
 	"github.com/vearutop/photo-blog/internal/infra/geo/ors"
+	"github.com/vearutop/photo-blog/internal/infra/geo/tiles"
 	"github.com/vearutop/photo-blog/internal/infra/image/cloudflare"
 	"github.com/vearutop/photo-blog/internal/infra/image/faces"
 	"github.com/vearutop/photo-blog/internal/infra/image/sprite"
+	"github.com/vearutop/photo-blog/internal/infra/notifier"
 	"github.com/vearutop/photo-blog/internal/infra/settings"
 	"github.com/vearutop/photo-blog/internal/infra/storage"
 	"github.com/vearutop/photo-blog/internal/infra/storage/visitor"
+	"github.com/vearutop/photo-blog/internal/infra/trash"
+	"github.com/vearutop/photo-blog/pkg/openmetrics"
 	"github.com/vearutop/photo-blog/pkg/qlite"
 	"github.com/vearutop/photo-blog/pkg/sqlitec/invalidation"
 )
@@ -30,6 +43,7 @@ type Locator struct {
 	AccessLogger                   ctxd.Logger
 	VisitorStatsInstance           *visitor.StatsRepository
 	MapTilesCacheInstance          *cache.FailoverOf[[]byte]
+	LocalMapTilesInstance          *tiles.Local
 	PersistentCacheStorageInstance *sqluct.Storage
 
 	DepCacheInstance       *dep.Cache
@@ -57,12 +71,14 @@ type Locator struct {
 	PhotoImageFinderProvider
 
 	PhotoThumbnailerProvider
+	PhotoThumbDeleterProvider
 
 	PhotoExifEnsurerProvider
 	PhotoExifFinderProvider
 
 	PhotoGpsEnsurerProvider
 	PhotoGpsFinderProvider
+	PhotoGpsDeleterProvider
 
 	PhotoMetaEnsurerProvider
 	PhotoMetaFinderProvider
@@ -70,18 +86,27 @@ type Locator struct {
 	PhotoGpxEnsurerProvider
 	PhotoGpxFinderProvider
 
+	PhotoVideoEnsurerProvider
+	PhotoVideoFinderProvider
+
 	TxtRendererProvider
 
 	SiteVisitorEnsurerProvider
 	SiteVisitorFinderProvider
+	SiteVisitorRepositoryProvider
+	SiteFollowerRepositoryProvider
 
 	CommentMessageEnsurerProvider
 	CommentMessageFinderProvider
+	CommentMessageRepositoryProvider
 
 	CommentThreadEnsurerProvider
 	CommentThreadFinderProvider
 
 	FavoriteRepositoryProvider
+	ProofingRepositoryProvider
+	AuditRepositoryProvider
+	TrashRepositoryProvider
 
 	CloudflareImageClassifierInstance *cloudflare.ImageClassifier
 	CloudflareImageDescriberInstance  *cloudflare.ImageDescriber
@@ -89,6 +114,18 @@ type Locator struct {
 	FacesRecognizerInstance *faces.Recognizer
 	AlbumSpritesInstance    *sprite.Service
 	ORS                     *ors.Client
+	GeoNamesInstance        *geonames.Service
+
+	CommentSpamScorerInstance comment.SpamScorer
+	NotifierInstance          *notifier.Service
+	EventBusInstance          *events.Bus
+	FederationInstance        *federation.Service
+	ArchiveInstance           *archive.Service
+	DBBackupsInstance         *backups.Service
+	MetricsInstance           *openmetrics.Registry
+	BotPolicyInstance         *auth.BotPolicy
+	EngagementInstance        *engagement.Service
+	TrashInstance             *trash.Service
 
 	ImagePrompterInstance *multi.ImagePrompter
 
@@ -159,6 +196,57 @@ func (l *Locator) OpenRouteService() *ors.Client {
 	return l.ORS
 }
 
+func (l *Locator) GeoNames() *geonames.Service {
+	return l.GeoNamesInstance
+}
+
+func (l *Locator) CommentSpamScorer() comment.SpamScorer {
+	return l.CommentSpamScorerInstance
+}
+
+func (l *Locator) Notifier() *notifier.Service {
+	return l.NotifierInstance
+}
+
+func (l *Locator) EventBus() *events.Bus {
+	return l.EventBusInstance
+}
+
+func (l *Locator) Federation() *federation.Service {
+	return l.FederationInstance
+}
+
+func (l *Locator) Archive() *archive.Service {
+	return l.ArchiveInstance
+}
+
+func (l *Locator) DBBackups() *backups.Service {
+	return l.DBBackupsInstance
+}
+
+func (l *Locator) BotPolicy() *auth.BotPolicy {
+	return l.BotPolicyInstance
+}
+
+// Engagement scores images by visitor interest.
+func (l *Locator) Engagement() *engagement.Service {
+	return l.EngagementInstance
+}
+
+func (l *Locator) Trash() *trash.Service {
+	return l.TrashInstance
+}
+
+// Metrics exposes collected metrics.
+func (l *Locator) Metrics() *openmetrics.Registry {
+	return l.MetricsInstance
+}
+
+// StatsTracker collects metrics for exposition and passes them to base tracker.
+func (l *Locator) StatsTracker() stats.Tracker {
+	return l.MetricsInstance
+}
+
 func (l *Locator) VisitorStats() *visitor.StatsRepository {
 	return l.VisitorStatsInstance
 }
@@ -183,6 +271,10 @@ func (l *Locator) MapTilesCache() *cache.FailoverOf[[]byte] {
 	return l.MapTilesCacheInstance
 }
 
+func (l *Locator) LocalMapTiles() *tiles.Local {
+	return l.LocalMapTilesInstance
+}
+
 func (l *Locator) PersistentCacheStorage() *sqluct.Storage {
 	return l.PersistentCacheStorageInstance
 }
@@ -54,6 +54,10 @@ type PhotoThumbnailerProvider interface {
 	PhotoThumbnailer() photo.Thumbnailer
 }
 
+type PhotoThumbDeleterProvider interface {
+	PhotoThumbDeleter() uniq.Deleter[photo.Thumb]
+}
+
 type AlbumSpritesProvider interface {
 	AlbumSprites() *sprite.Service
 }
@@ -74,6 +78,10 @@ type PhotoGpsFinderProvider interface {
 	PhotoGpsFinder() uniq.Finder[photo.Gps]
 }
 
+type PhotoGpsDeleterProvider interface {
+	PhotoGpsDeleter() uniq.Deleter[photo.Gps]
+}
+
 type PhotoMetaEnsurerProvider interface {
 	PhotoMetaEnsurer() uniq.Ensurer[photo.Meta]
 }
@@ -90,6 +98,14 @@ type PhotoGpxFinderProvider interface {
 	PhotoGpxFinder() uniq.Finder[photo.Gpx]
 }
 
+type PhotoVideoEnsurerProvider interface {
+	PhotoVideoEnsurer() uniq.Ensurer[photo.Video]
+}
+
+type PhotoVideoFinderProvider interface {
+	PhotoVideoFinder() uniq.Finder[photo.Video]
+}
+
 type TxtRendererProvider interface {
 	TxtRenderer() *txt.Renderer
 }
@@ -102,6 +118,10 @@ type SiteVisitorFinderProvider interface {
 	SiteVisitorFinder() uniq.Finder[site.Visitor]
 }
 
+type SiteVisitorRepositoryProvider interface {
+	SiteVisitorRepository() *storage.VisitorRepository
+}
+
 type CommentMessageEnsurerProvider interface {
 	CommentMessageEnsurer() uniq.Ensurer[comment.Message]
 }
@@ -110,6 +130,10 @@ type CommentMessageFinderProvider interface {
 	CommentMessageFinder() uniq.Finder[comment.Message]
 }
 
+type CommentMessageRepositoryProvider interface {
+	CommentMessageRepository() *storage.MessageRepository
+}
+
 type CommentThreadEnsurerProvider interface {
 	CommentThreadEnsurer() uniq.Ensurer[comment.Thread]
 }
@@ -118,6 +142,22 @@ type CommentThreadFinderProvider interface {
 	CommentThreadFinder() uniq.Finder[comment.Thread]
 }
 
+type SiteFollowerRepositoryProvider interface {
+	SiteFollowerRepository() *storage.FollowerRepository
+}
+
 type FavoriteRepositoryProvider interface {
 	FavoriteRepository() *storage.FavoriteRepository
 }
+
+type ProofingRepositoryProvider interface {
+	ProofingRepository() *storage.ProofingRepository
+}
+
+type AuditRepositoryProvider interface {
+	AuditRepository() *storage.AuditRepository
+}
+
+type TrashRepositoryProvider interface {
+	TrashRepository() *storage.TrashRepository
+}
@@ -0,0 +1,32 @@
+package settings
+
+import (
+	"context"
+)
+
+type ActivityPub struct {
+	Enabled    bool   `json:"enabled" inlineTitle:"Enable ActivityPub federation." noTitle:"true" description:"Requires canonical base URL in Appearance settings, the site can be followed as @username@host."`
+	Username   string `json:"username" title:"Username" pattern:"^[a-zA-Z0-9_]*$" default:"blog"`
+	Summary    string `json:"summary,omitempty" title:"Summary" formType:"textarea" description:"Profile description, can contain HTML."`
+	BatchDelay int    `json:"batch_delay" title:"Batch delay, minutes" description:"New photos of an album are published in a single post after this delay." minimum:"0" default:"10"`
+}
+
+func (m *Manager) SetActivityPub(ctx context.Context, value ActivityPub) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.set(ctx, "activitypub", value); err != nil {
+		return err
+	}
+
+	m.activityPub = value
+
+	return nil
+}
+
+func (m *Manager) ActivityPub() ActivityPub {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.activityPub
+}
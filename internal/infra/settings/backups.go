@@ -0,0 +1,33 @@
+package settings
+
+import (
+	"context"
+)
+
+type Backups struct {
+	Enabled  bool     `json:"enabled" inlineTitle:"Enable scheduled backups of databases." noTitle:"true" description:"Consistent copies of all SQLite databases are made while the service is running."`
+	Dir      string   `json:"dir" title:"Directory" description:"Absolute or relative to storage path, should be on a different disk for better safety." default:"backups"`
+	Interval int      `json:"interval" title:"Interval, hours" minimum:"1" default:"24"`
+	Keep     int      `json:"keep" title:"Backups to keep" description:"Older backups are removed." minimum:"1" default:"7"`
+	Skip     []string `json:"skip,omitempty" title:"Skip databases" description:"Names of databases to skip, for example map-tiles or thumbs, as they can be large and rebuilt."`
+}
+
+func (m *Manager) SetBackups(ctx context.Context, value Backups) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.set(ctx, "backups", value); err != nil {
+		return err
+	}
+
+	m.backups = value
+
+	return nil
+}
+
+func (m *Manager) Backups() Backups {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.backups
+}
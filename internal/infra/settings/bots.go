@@ -0,0 +1,42 @@
+package settings
+
+import (
+	"context"
+
+	"github.com/vearutop/photo-blog/pkg/botpolicy"
+)
+
+type Bots struct {
+	BlockAICrawlers bool             `json:"block_ai_crawlers" inlineTitle:"Block AI crawlers." noTitle:"true" description:"Known crawlers that collect content for AI models are disallowed in robots.txt and denied access."`
+	Disallow        []string         `json:"disallow,omitempty" title:"Disallow paths" description:"Path prefixes disallowed for crawlers in robots.txt." example:"/search/"`
+	Rules           []botpolicy.Rule `json:"rules,omitempty" title:"Rules" description:"First matching rule is applied to a bot, rule for * applies to bots without other rules."`
+}
+
+// Policy returns bot policy.
+func (b Bots) Policy() botpolicy.Policy {
+	return botpolicy.Policy{
+		Rules:    b.Rules,
+		BlockAI:  b.BlockAICrawlers,
+		Disallow: b.Disallow,
+	}
+}
+
+func (m *Manager) SetBots(ctx context.Context, value Bots) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.set(ctx, "bots", value); err != nil {
+		return err
+	}
+
+	m.bots = value
+
+	return nil
+}
+
+func (m *Manager) Bots() Bots {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.bots
+}
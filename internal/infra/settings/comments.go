@@ -0,0 +1,34 @@
+package settings
+
+import (
+	"context"
+)
+
+type Comments struct {
+	Enabled       bool     `json:"enabled" inlineTitle:"Enable comments on album and image pages." noTitle:"true" description:"Requires visitor tagging in Visitors settings." default:"true"`
+	AutoApprove   bool     `json:"auto_approve" inlineTitle:"Publish messages of approved visitors without moderation." noTitle:"true"`
+	RateLimit     int      `json:"rate_limit" title:"Rate limit" description:"Max messages per visitor or IP address in an hour." minimum:"0" default:"5"`
+	MaxLength     int      `json:"max_length" title:"Max length" description:"Max message length in characters." minimum:"0" default:"2000"`
+	SpamThreshold float64  `json:"spam_threshold" title:"Spam threshold" description:"Messages with higher spam score (0 to 1) are rejected without moderation." minimum:"0" maximum:"1" default:"0.7"`
+	BlockedWords  []string `json:"blocked_words,omitempty" title:"Blocked words" description:"Case-insensitive words that mark a message as spam."`
+}
+
+func (m *Manager) SetComments(ctx context.Context, value Comments) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.set(ctx, "comments", value); err != nil {
+		return err
+	}
+
+	m.comments = value
+
+	return nil
+}
+
+func (m *Manager) Comments() Comments {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.comments
+}
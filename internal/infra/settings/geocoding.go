@@ -0,0 +1,40 @@
+package settings
+
+import (
+	"context"
+)
+
+// Reverse geocoding providers.
+const (
+	GeocoderORS       = "ors"
+	GeocoderGazetteer = "gazetteer"
+)
+
+type Geocoding struct {
+	Provider    string   `json:"provider" title:"Provider" description:"OpenRouteService needs API key in External API settings, gazetteer works offline with GeoNames dataset." enum:"[\"ors\",\"gazetteer\"]" default:"ors"`
+	Dataset     string   `json:"dataset" title:"Dataset" description:"GeoNames cities dataset, smaller population threshold gives more precise labels and uses more memory." enum:"[\"cities500\",\"cities1000\",\"cities5000\",\"cities15000\"]" default:"cities15000"`
+	Dir         string   `json:"dir" title:"Directory" description:"Directory with GeoNames files, relative to data directory." default:"gazetteer"`
+	Download    bool     `json:"download" inlineTitle:"Download missing dataset files from geonames.org." noTitle:"true"`
+	Languages   []string `json:"languages" title:"Languages" description:"ISO 639-1 codes of label languages, first one is used by default, empty for GeoNames default names." example:"[\"en\",\"de\"]"`
+	MaxDistance float64  `json:"max_distance" title:"Max distance, km" description:"Photos farther from any known place are not labeled." minimum:"0" default:"30"`
+}
+
+func (m *Manager) SetGeocoding(ctx context.Context, value Geocoding) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.set(ctx, "geocoding", value); err != nil {
+		return err
+	}
+
+	m.geocoding = value
+
+	return nil
+}
+
+func (m *Manager) Geocoding() Geocoding {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.geocoding
+}
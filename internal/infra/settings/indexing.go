@@ -9,6 +9,7 @@ type Indexing struct {
 	CFClassification     bool `json:"cf_classification" inlineTitle:"ResNet50 labels." noTitle:"true" title:"ResNet50" description:"Image labels."`
 	CFDescription        bool `json:"cf_description" inlineTitle:"Legacy CF image description." noTitle:"true" title:"CF Description"`
 	GeoLabel             bool `json:"geo_label" inlineTitle:"Reverse geo tag." noTitle:"true"`
+	GpxGeotag            bool `json:"gpx_geotag" inlineTitle:"Geotag images without GPS from album GPX tracks." noTitle:"true"`
 	LLMDescription       bool `json:"llm_description" inlineTitle:"Prompt LLM for image description." noTitle:"true"`
 	Phash                bool `json:"phash" inlineTitle:"Calculate perception hash." noTitle:"true"`
 	SharpnessV0          bool `json:"sharpness_v0" inlineTitle:"Calculate sharpness (legacy)." noTitle:"true"`
@@ -29,16 +29,24 @@ type Manager struct {
 	r  Repository
 	dc *dep.Cache
 
-	mu          sync.Mutex
-	security    Security
-	appearance  Appearance
-	maps        Maps
-	visitors    Visitors
-	storage     Storage
-	privacy     Privacy
-	externalAPI ExternalAPI
-	imagePrompt multi.Config
-	indexing    Indexing
+	mu            sync.Mutex
+	security      Security
+	appearance    Appearance
+	maps          Maps
+	visitors      Visitors
+	storage       Storage
+	privacy       Privacy
+	externalAPI   ExternalAPI
+	imagePrompt   multi.Config
+	indexing      Indexing
+	watermark     Watermark
+	geocoding     Geocoding
+	comments      Comments
+	notifications Notifications
+	webhooks      Webhooks
+	activityPub   ActivityPub
+	backups       Backups
+	bots          Bots
 }
 
 type Values interface {
@@ -56,13 +64,36 @@ type Values interface {
 
 	ImagePrompt() multi.Config
 	Indexing() Indexing
+	Watermark() Watermark
+	Geocoding() Geocoding
+	Comments() Comments
+	Notifications() Notifications
+	Webhooks() Webhooks
+	ActivityPub() ActivityPub
+	Backups() Backups
+	Bots() Bots
 }
 
 func NewManager(r Repository, dc *dep.Cache) (*Manager, error) {
 	m := Manager{r: r, dc: dc}
-	ctx := context.Background()
 
-	return &m, errs(
+	return &m, m.load(context.Background())
+}
+
+// Reload reads all settings from repository, for example after restoring a backup.
+func (m *Manager) Reload(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.load(ctx); err != nil {
+		return err
+	}
+
+	return m.dc.ServiceSettingsChanged(ctx)
+}
+
+func (m *Manager) load(ctx context.Context) error {
+	return errs(
 		m.get(ctx, "security", &m.security),
 		m.get(ctx, "appearance", &m.appearance),
 		m.appearance.change(),
@@ -73,6 +104,14 @@ func NewManager(r Repository, dc *dep.Cache) (*Manager, error) {
 		m.get(ctx, "external_api", &m.externalAPI),
 		m.get(ctx, "image_prompt", &m.imagePrompt),
 		m.get(ctx, "indexing", &m.indexing),
+		m.get(ctx, "watermark", &m.watermark),
+		m.get(ctx, "geocoding", &m.geocoding),
+		m.get(ctx, "comments", &m.comments),
+		m.get(ctx, "notifications", &m.notifications),
+		m.get(ctx, "webhooks", &m.webhooks),
+		m.get(ctx, "activitypub", &m.activityPub),
+		m.get(ctx, "backups", &m.backups),
+		m.get(ctx, "bots", &m.bots),
 	)
 }
 
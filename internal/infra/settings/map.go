@@ -6,6 +6,7 @@ type Maps struct {
 	Tiles       string `json:"tiles" title:"Tiles" description:"URL to custom map tiles." example:"https://retina-tiles.p.rapidapi.com/local/osm{r}/v1/{z}/{x}/{y}.png?rapidapi-key=YOUR-RAPIDAPI-KEY"`
 	Attribution string `json:"attribution" title:"Attribution" description:"Map tiles attribution."`
 	Cache       bool   `json:"cache" inlineTitle:"Cache tiles." noTitle:"true" title:"Cache" description:"Enable local cache of map tiles."`
+	LocalTiles  string `json:"local_tiles" title:"Local tiles" description:"Path to MBTiles or PMTiles archive, relative to data directory, overrides remote tiles." example:"tiles/europe.mbtiles"`
 }
 
 func (m *Manager) SetMaps(ctx context.Context, value Maps) error {
@@ -0,0 +1,42 @@
+package settings
+
+import (
+	"context"
+
+	"github.com/vearutop/photo-blog/pkg/notify"
+)
+
+type Notifications struct {
+	MessagePending bool `json:"message_pending" inlineTitle:"New message waiting for moderation." noTitle:"true"`
+	CollabUpload   bool `json:"collab_upload" inlineTitle:"File uploaded with collaborator key." noTitle:"true"`
+	JobFailed      bool `json:"job_failed" inlineTitle:"Background job failed." noTitle:"true"`
+	DiskSpaceLow   bool `json:"disk_space_low" inlineTitle:"Low disk space." noTitle:"true"`
+	ProofingSubmit bool `json:"proofing_submit" inlineTitle:"Client submitted proofing selection." noTitle:"true"`
+
+	MinFreeSpace int `json:"min_free_space" title:"Min free space, MB" description:"Low disk space is reported when storage has less free space." minimum:"0" default:"1024"`
+	Digest       int `json:"digest" title:"Digest interval, minutes" description:"Notifications are collected and sent together, 0 to send immediately." minimum:"0"`
+
+	BaseURL string         `json:"base_url,omitempty" title:"Base URL" description:"Site address for links in notifications, canonical base URL from Appearance is used by default." example:"https://photos.example.com"`
+	Email   notify.SMTP    `json:"email" title:"Email"`
+	Webhook notify.Webhook `json:"webhook" title:"Webhook"`
+}
+
+func (m *Manager) SetNotifications(ctx context.Context, value Notifications) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.set(ctx, "notifications", value); err != nil {
+		return err
+	}
+
+	m.notifications = value
+
+	return nil
+}
+
+func (m *Manager) Notifications() Notifications {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.notifications
+}
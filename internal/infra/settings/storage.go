@@ -3,7 +3,9 @@ package settings
 import "context"
 
 type Storage struct {
-	WebDAV bool `json:"web_dav" inlineTitle:"Enable WebDAV access to storage." noTitle:"true" title:"Enable WebDAV" description:"Served at http(s)://[this-site-address]/webdav/ URL with admin password."`
+	WebDAV           bool `json:"web_dav" inlineTitle:"Enable WebDAV access to storage." noTitle:"true" title:"Enable WebDAV" description:"Served at http(s)://[this-site-address]/webdav/ URL with admin password."`
+	TrashDays        int  `json:"trash_days,omitempty" title:"Trash retention, days" description:"Deleted albums and removed images can be restored during this period, 0 to keep forever." minimum:"0" default:"30"`
+	TrashDeleteFiles bool `json:"trash_delete_files,omitempty" inlineTitle:"Delete files of purged images." noTitle:"true" title:"Delete purged files" description:"Originals and thumbnails are deleted from disk when purged image is not in any album."`
 }
 
 func (m *Manager) SetStorage(ctx context.Context, value Storage) error {
@@ -5,6 +5,11 @@ import "context"
 type Visitors struct {
 	Tag             bool     `json:"tag" inlineTitle:"Tag unique visitors with cookies." noTitle:"true"`
 	AccessLog       bool     `json:"access_log" inlineTitle:"Enable access log." noTitle:"true"`
+	PrivacyMode     bool     `json:"privacy_mode" inlineTitle:"Privacy mode: store daily salted hashes of truncated IP addresses and country-level location only." noTitle:"true"`
+	Cookieless      bool     `json:"cookieless" inlineTitle:"Count visitors without cookies, using a daily rotating hash of IP address and browser." noTitle:"true"`
+	RetentionDays   int      `json:"retention_days,omitempty" title:"Retention, days" description:"Visitor data and IP addresses of comments older than this are removed, also limits cookie lifetime, 0 to keep forever."`
+	MostLovedAlbums bool     `json:"most_loved_albums" inlineTitle:"Maintain private \"most loved\" album for each year, images are selected by visitor engagement." noTitle:"true"`
+	MostLovedSize   int      `json:"most_loved_size,omitempty" title:"Most loved album size" description:"Number of images in a most loved album." minimum:"0" default:"30"`
 	IgnoreReferrers []string `json:"ignore_referrers,omitempty" title:"Ignore referrers" description:"List of referrer URL prefixes to ignore."`
 	TrustedProxies  []string `json:"trusted_proxies,omitempty" title:"Trusted proxies" description:"List of IP addresses of trusted proxies."`
 	CityDB          string   `json:"city_db" title:"City location DB" description:"Local path to DB, download and decompress from https://github.com/vearutop/ipinfo/releases/download/index/city-loc-lite.bin.zst."`
@@ -0,0 +1,73 @@
+package settings
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path"
+	"strconv"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/vearutop/photo-blog/pkg/imgedit"
+)
+
+type Watermark struct {
+	Enabled  bool    `json:"enabled" inlineTitle:"Enable watermark." noTitle:"true" description:"Watermark is applied to thumbnails served to guests, admin views stay clean."`
+	Text     string  `json:"text" title:"Text" description:"Watermark text, used if image is not set."`
+	Image    string  `json:"image" title:"Image" description:"Name of PNG or JPEG file uploaded to /site/, e.g. logo.png."`
+	Position string  `json:"position" title:"Position" enum:"[\"bottom-right\",\"bottom-left\",\"top-right\",\"top-left\",\"center\"]" default:"bottom-right"`
+	Opacity  float64 `json:"opacity" title:"Opacity" minimum:"0" maximum:"1" default:"0.5"`
+	Scale    float64 `json:"scale" title:"Scale" description:"Width of watermark as a fraction of image width." minimum:"0" maximum:"1" default:"0.2"`
+	MinWidth uint    `json:"min_width" title:"Minimal width, px" description:"Thumbnails of this width or larger are watermarked. Reduced images shown instead of hidden originals are always watermarked." default:"1200"`
+}
+
+// Watermark returns drawing parameters.
+func (w Watermark) Watermark() imgedit.Watermark {
+	res := imgedit.Watermark{
+		Text:     w.Text,
+		Position: w.Position,
+		Opacity:  w.Opacity,
+		Scale:    w.Scale,
+	}
+
+	if w.Image != "" {
+		res.Image = path.Join("site", path.Clean("/"+w.Image))
+	}
+
+	return res
+}
+
+// Version identifies watermark appearance, it changes with settings or watermark image file.
+func (w Watermark) Version() string {
+	j, _ := json.Marshal(w.Watermark())
+	h := xxhash.New()
+	_, _ = h.Write(j)
+
+	if w.Image != "" {
+		if fi, err := os.Stat(w.Watermark().Image); err == nil {
+			_, _ = h.WriteString(fi.ModTime().String() + strconv.Itoa(int(fi.Size())))
+		}
+	}
+
+	return strconv.FormatUint(h.Sum64(), 36)
+}
+
+func (m *Manager) SetWatermark(ctx context.Context, value Watermark) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.set(ctx, "watermark", value); err != nil {
+		return err
+	}
+
+	m.watermark = value
+
+	return nil
+}
+
+func (m *Manager) Watermark() Watermark {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.watermark
+}
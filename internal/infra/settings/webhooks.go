@@ -0,0 +1,56 @@
+package settings
+
+import (
+	"context"
+)
+
+type Webhooks struct {
+	Endpoints   []WebhookEndpoint `json:"endpoints,omitempty" title:"Endpoints" description:"Receive POST requests with JSON event in body."`
+	MaxAttempts int               `json:"max_attempts" title:"Max attempts" description:"Failed deliveries are retried with growing delay." minimum:"1" default:"8"`
+}
+
+type WebhookEndpoint struct {
+	URL      string   `json:"url" title:"URL" required:"true"`
+	Secret   string   `json:"secret,omitempty" title:"Secret" description:"Used to sign requests, X-Photo-Blog-Signature header has sha256=<hex> of HMAC-SHA256 of X-Photo-Blog-Timestamp, dot and body."`
+	Events   []string `json:"events,omitempty" title:"Events" description:"Event types to deliver, all events if empty: album.created, album.updated, album.deleted, image.added, image.removed, image.indexed, comment.approved."`
+	Disabled bool     `json:"disabled,omitempty" inlineTitle:"Disabled." noTitle:"true"`
+}
+
+// Accepts is true if endpoint is enabled and subscribed to event type.
+func (e WebhookEndpoint) Accepts(eventType string) bool {
+	if e.Disabled || e.URL == "" {
+		return false
+	}
+
+	if len(e.Events) == 0 {
+		return true
+	}
+
+	for _, t := range e.Events {
+		if t == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *Manager) SetWebhooks(ctx context.Context, value Webhooks) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.set(ctx, "webhooks", value); err != nil {
+		return err
+	}
+
+	m.webhooks = value
+
+	return nil
+}
+
+func (m *Manager) Webhooks() Webhooks {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.webhooks
+}
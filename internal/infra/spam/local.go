@@ -0,0 +1,41 @@
+// Package spam implements comment spam scorers.
+package spam
+
+import (
+	"context"
+
+	"github.com/vearutop/photo-blog/internal/domain/comment"
+	"github.com/vearutop/photo-blog/internal/infra/settings"
+	"github.com/vearutop/photo-blog/pkg/spamscore"
+	"github.com/vearutop/photo-blog/pkg/webstats"
+)
+
+// Local scores messages with content heuristics, it works offline.
+type Local struct {
+	settings settings.Values
+}
+
+// NewLocal creates local spam scorer that takes blocked words from comments settings.
+func NewLocal(s settings.Values) *Local {
+	return &Local{settings: s}
+}
+
+// SpamScore implements comment.SpamScorer.
+func (l *Local) SpamScore(_ context.Context, c comment.SpamCandidate) (float64, error) {
+	h := spamscore.Heuristic{
+		BlockedWords: l.settings.Comments().BlockedWords,
+	}
+
+	score := h.Score(c.Message.Text, c.Name).Score
+
+	// Browsers leave comments, scripts are suspicious.
+	if webstats.IsBot(c.Agent) {
+		score += 0.5
+	}
+
+	if score > 1 {
+		score = 1
+	}
+
+	return score, nil
+}
@@ -156,6 +156,8 @@ func (r *AlbumRepository) FindOrphanImages(ctx context.Context) ([]photo.Image,
 			r.Fmt("%s ON %s = %s", r.ai.R, &r.ai.R.ImageHash, &r.i.R.Hash),
 		).
 		Where(r.Fmt("%s IS NULL", &r.ai.R.AlbumHash)).
+		// Images in trash can still be restored to their albums.
+		Where(r.Fmt("%s NOT IN (SELECT image_hash FROM "+TrashAlbumImageTable+")", &r.i.R.Hash)).
 		GroupBy(r.Ref(&r.i.R.Hash)).
 		OrderByClause(r.Fmt("COALESCE(%s, %s), %s", &r.i.R.TakenAt, &r.i.R.CreatedAt, &r.i.R.Path))
 
@@ -255,6 +257,23 @@ func (r *AlbumRepository) FindPreviewImages(ctx context.Context, albumHash uniq.
 	return hashed.AugmentResErr(r.i.List(ctx, q))
 }
 
+// FindRecentImages finds latest created images of albums.
+func (r *AlbumRepository) FindRecentImages(ctx context.Context, limit uint64, albumHashes ...uniq.Hash) ([]photo.Image, error) {
+	if len(albumHashes) == 0 {
+		return nil, nil
+	}
+
+	q := r.i.SelectStmt().
+		InnerJoin(r.Fmt("%s ON %s = %s", r.ai.R, &r.ai.R.ImageHash, &r.i.R.Hash)).
+		Where(r.Eq(&r.ai.R.AlbumHash, albumHashes)).
+		Where(r.Fmt("%s != ''", &r.i.R.BlurHash)).
+		GroupBy(r.Ref(&r.i.R.Hash)).
+		OrderByClause(r.Fmt("%s DESC", &r.i.R.CreatedAt)).
+		Limit(limit)
+
+	return hashed.AugmentResErr(r.i.List(ctx, q))
+}
+
 func (r *AlbumRepository) FindByName(ctx context.Context, names ...string) (photo.Album, error) {
 	q := r.SelectStmt().
 		Where(r.Eq(&r.R.Name, names)).
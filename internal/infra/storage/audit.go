@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/bool64/ctxd"
+	"github.com/bool64/sqluct"
+	"github.com/vearutop/photo-blog/internal/domain/event"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/storage/hashed"
+)
+
+const (
+	// AuditLogTable is the name of the table.
+	AuditLogTable = "audit_log"
+)
+
+func NewAuditRepository(storage *sqluct.Storage) *AuditRepository {
+	return &AuditRepository{
+		al: sqluct.Table[photo.AuditEntry](storage, AuditLogTable),
+	}
+}
+
+// AuditRepository saves changes of album content.
+type AuditRepository struct {
+	al sqluct.StorageOf[photo.AuditEntry]
+}
+
+// Add stores audit entry.
+func (r *AuditRepository) Add(ctx context.Context, e photo.AuditEntry) error {
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+
+	if _, err := r.al.InsertRow(ctx, e); err != nil {
+		return ctxd.WrapError(ctx, hashed.AugmentErr(err), "store audit entry", "entry", e)
+	}
+
+	return nil
+}
+
+var auditActions = map[string]photo.AuditAction{
+	event.AlbumCreated:  photo.AuditCreate,
+	event.AlbumUpdated:  photo.AuditUpdate,
+	event.AlbumDeleted:  photo.AuditDelete,
+	event.AlbumRestored: photo.AuditRestore,
+	event.ImageAdded:    photo.AuditAdd,
+	event.ImageRemoved:  photo.AuditRemove,
+	event.ImageRestored: photo.AuditRestore,
+}
+
+// AddEvent stores audit entry for an event that changes album content, other events are ignored.
+func (r *AuditRepository) AddEvent(ctx context.Context, e event.Event, actor string) error {
+	action, ok := auditActions[e.Type]
+	if !ok || e.AlbumHash == 0 {
+		return nil
+	}
+
+	return r.Add(ctx, photo.AuditEntry{
+		CreatedAt: e.Time,
+		AlbumHash: e.AlbumHash,
+		AlbumName: e.AlbumName,
+		ImageHash: e.ImageHash,
+		Action:    action,
+		Actor:     actor,
+	})
+}
+
+// Find returns latest audit entries, of all albums if album hash is zero.
+func (r *AuditRepository) Find(ctx context.Context, albumHash uniq.Hash, limit uint64) ([]photo.AuditEntry, error) {
+	q := r.al.SelectStmt().
+		OrderByClause(r.al.Fmt("%s DESC", &r.al.R.ID)).
+		Limit(limit)
+
+	if albumHash != 0 {
+		q = q.Where(r.al.Eq(&r.al.R.AlbumHash, albumHash))
+	}
+
+	return hashed.AugmentResErr(r.al.List(ctx, q))
+}
+
+func (r *AuditRepository) AuditRepository() *AuditRepository {
+	return r
+}
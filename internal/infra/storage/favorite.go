@@ -2,10 +2,14 @@ package storage
 
 import (
 	"context"
+	"crypto/rand"
+	"math"
+	"math/big"
 	"time"
 
 	"github.com/bool64/ctxd"
 	"github.com/bool64/sqluct"
+	"github.com/swaggest/usecase/status"
 	"github.com/vearutop/photo-blog/internal/domain/photo"
 	"github.com/vearutop/photo-blog/internal/domain/uniq"
 	"github.com/vearutop/photo-blog/internal/infra/storage/hashed"
@@ -14,15 +18,37 @@ import (
 const (
 	// FavoriteImageTable is the name of the table.
 	FavoriteImageTable = "favorite_image"
+
+	// FavoriteListTable is the name of the table.
+	FavoriteListTable = "favorite_list"
 )
 
 // FavoriteImage describes database mapping.
 type FavoriteImage struct {
 	VisitorHash uniq.Hash `db:"visitor_hash"`
+	ListHash    uniq.Hash `db:"list_hash"`
 	ImageHash   uniq.Hash `db:"image_hash"`
+	Note        string    `db:"note"`
 	CreatedAt   time.Time `db:"created_at"`
 }
 
+// FavoriteList is a named selection of favorite images, hash is random and serves as a read-only share token.
+//
+// Images that are not in a named list belong to a default list with zero hash.
+type FavoriteList struct {
+	uniq.Head
+	VisitorHash uniq.Hash `db:"visitor_hash" json:"-"`
+	Name        string    `db:"name" json:"name" title:"Name"`
+}
+
+// FavoriteListCount is a number of images in a visitor list.
+type FavoriteListCount struct {
+	VisitorHash uniq.Hash `db:"visitor_hash"`
+	ListHash    uniq.Hash `db:"list_hash"`
+	Count       int       `db:"cnt"`
+	LastAdded   string    `db:"last_added"`
+}
+
 func NewFavoriteRepository(storage *sqluct.Storage) *FavoriteRepository {
 	fr := &FavoriteRepository{
 		st: storage,
@@ -37,6 +63,10 @@ func NewFavoriteRepository(storage *sqluct.Storage) *FavoriteRepository {
 	fr.i = sqluct.Table[photo.Image](storage, ImageTable)
 	fr.r.AddTableAlias(fr.i.R, ImageTable)
 
+	fr.lists = hashed.Repo[FavoriteList, *FavoriteList]{
+		StorageOf: sqluct.Table[FavoriteList](storage, FavoriteListTable),
+	}
+
 	return fr
 }
 
@@ -49,24 +79,26 @@ type FavoriteRepository struct {
 	fi sqluct.StorageOf[FavoriteImage]
 	ai sqluct.StorageOf[AlbumImage]
 	i  sqluct.StorageOf[photo.Image]
+
+	lists hashed.Repo[FavoriteList, *FavoriteList]
 }
 
-func (r *FavoriteRepository) FindImages(ctx context.Context, visitorHash uniq.Hash) ([]photo.Image, error) {
+func (r *FavoriteRepository) FindImages(ctx context.Context, visitorHash, listHash uniq.Hash) ([]photo.Image, error) {
 	q := r.i.SelectStmt().
 		InnerJoin(
-			r.r.Fmt("%s ON %s = %s AND %s = ?", r.fi.R, &r.fi.R.ImageHash, &r.i.R.Hash, &r.fi.R.VisitorHash),
-			visitorHash,
+			r.r.Fmt("%s ON %s = %s AND %s = ? AND %s = ?", r.fi.R, &r.fi.R.ImageHash, &r.i.R.Hash, &r.fi.R.VisitorHash, &r.fi.R.ListHash),
+			visitorHash, listHash,
 		).
 		OrderByClause(r.r.Fmt("%s DESC", &r.fi.R.CreatedAt))
 
 	return hashed.AugmentResErr(r.i.List(ctx, q))
 }
 
-func (r *FavoriteRepository) FindAlbumImages(ctx context.Context, visitorHash, albumHash uniq.Hash) ([]photo.Image, error) {
+func (r *FavoriteRepository) FindAlbumImages(ctx context.Context, visitorHash, listHash, albumHash uniq.Hash) ([]photo.Image, error) {
 	q := r.i.SelectStmt().
 		InnerJoin(
-			r.r.Fmt("%s ON %s = %s AND %s = ?", r.fi.R, &r.fi.R.ImageHash, &r.i.R.Hash, &r.fi.R.VisitorHash),
-			visitorHash,
+			r.r.Fmt("%s ON %s = %s AND %s = ? AND %s = ?", r.fi.R, &r.fi.R.ImageHash, &r.i.R.Hash, &r.fi.R.VisitorHash, &r.fi.R.ListHash),
+			visitorHash, listHash,
 		).
 		InnerJoin(
 			r.r.Fmt("%s ON %s = %s AND %s = ?", r.ai.R, &r.ai.R.ImageHash, &r.i.R.Hash, &r.ai.R.AlbumHash),
@@ -77,8 +109,10 @@ func (r *FavoriteRepository) FindAlbumImages(ctx context.Context, visitorHash, a
 	return hashed.AugmentResErr(r.i.List(ctx, q))
 }
 
-func (r *FavoriteRepository) FindImageHashes(ctx context.Context, visitorHash uniq.Hash, albumHash uniq.Hash) ([]uniq.Hash, error) {
-	q := r.fi.SelectStmt().Where(r.r.Fmt("%s = ?", &r.fi.R.VisitorHash), visitorHash)
+func (r *FavoriteRepository) FindImageHashes(ctx context.Context, visitorHash, listHash, albumHash uniq.Hash) ([]uniq.Hash, error) {
+	q := r.fi.SelectStmt().
+		Where(r.r.Fmt("%s = ?", &r.fi.R.VisitorHash), visitorHash).
+		Where(r.r.Fmt("%s = ?", &r.fi.R.ListHash), listHash)
 
 	if albumHash != 0 {
 		q = q.InnerJoin(
@@ -92,7 +126,7 @@ func (r *FavoriteRepository) FindImageHashes(ctx context.Context, visitorHash un
 		return nil, err
 	}
 
-	res := make([]uniq.Hash, len(rows))
+	res := make([]uniq.Hash, 0, len(rows))
 	for _, row := range rows {
 		res = append(res, row.ImageHash)
 	}
@@ -100,20 +134,186 @@ func (r *FavoriteRepository) FindImageHashes(ctx context.Context, visitorHash un
 	return res, nil
 }
 
-func (r *FavoriteRepository) DeleteImages(ctx context.Context, visitorHash uniq.Hash, imageHashes ...uniq.Hash) error {
+// CountByImage returns numbers of visitors that added images to favorites, all favorite images if no hashes provided.
+func (r *FavoriteRepository) CountByImage(ctx context.Context, imageHashes ...uniq.Hash) (map[uniq.Hash]int, error) {
+	var rows []struct {
+		ImageHash uniq.Hash `db:"image_hash"`
+		Count     int       `db:"cnt"`
+	}
+
+	q := r.st.QueryBuilder().
+		Select(r.r.Col(&r.fi.R.ImageHash), r.r.Fmt("COUNT(DISTINCT %s) AS cnt", &r.fi.R.VisitorHash)).
+		From(FavoriteImageTable).
+		GroupBy(r.r.Col(&r.fi.R.ImageHash))
+
+	if len(imageHashes) > 0 {
+		q = q.Where(r.r.Eq(&r.fi.R.ImageHash, imageHashes))
+	}
+
+	if err := r.st.Select(ctx, q, &rows); err != nil {
+		return nil, ctxd.WrapError(ctx, hashed.AugmentErr(err), "count favorites")
+	}
+
+	res := make(map[uniq.Hash]int, len(rows))
+	for _, row := range rows {
+		res[row.ImageHash] = row.Count
+	}
+
+	return res, nil
+}
+
+// FindNotes returns notes of images in a list, images without notes are omitted.
+func (r *FavoriteRepository) FindNotes(ctx context.Context, visitorHash, listHash uniq.Hash) (map[uniq.Hash]string, error) {
+	q := r.fi.SelectStmt().
+		Where(r.r.Eq(&r.fi.R.VisitorHash, visitorHash)).
+		Where(r.r.Eq(&r.fi.R.ListHash, listHash)).
+		Where(r.r.Fmt("%s != ''", &r.fi.R.Note))
+
+	rows, err := hashed.AugmentResErr(r.fi.List(ctx, q))
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[uniq.Hash]string, len(rows))
+	for _, row := range rows {
+		res[row.ImageHash] = row.Note
+	}
+
+	return res, nil
+}
+
+// SetNote updates a note of an image in a list.
+func (r *FavoriteRepository) SetNote(ctx context.Context, visitorHash, listHash, imageHash uniq.Hash, note string) error {
+	res, err := r.fi.UpdateStmt(nil).
+		Set(r.r.Col(&r.fi.R.Note), note).
+		Where(r.r.Eq(&r.fi.R.VisitorHash, visitorHash)).
+		Where(r.r.Eq(&r.fi.R.ListHash, listHash)).
+		Where(r.r.Eq(&r.fi.R.ImageHash, imageHash)).
+		ExecContext(ctx)
+	if err != nil {
+		return hashed.AugmentErr(err)
+	}
+
+	if aff, err := res.RowsAffected(); err == nil && aff == 0 {
+		return status.NotFound
+	}
+
+	return nil
+}
+
+// CountByList returns numbers of images in all favorite lists, including default ones.
+func (r *FavoriteRepository) CountByList(ctx context.Context) ([]FavoriteListCount, error) {
+	var res []FavoriteListCount
+
+	q := r.st.QueryBuilder().
+		Select(
+			r.r.Col(&r.fi.R.VisitorHash),
+			r.r.Col(&r.fi.R.ListHash),
+			"COUNT(1) AS cnt",
+			r.r.Fmt("MAX(%s) AS last_added", &r.fi.R.CreatedAt),
+		).
+		From(FavoriteImageTable).
+		GroupBy(r.r.Col(&r.fi.R.VisitorHash), r.r.Col(&r.fi.R.ListHash)).
+		OrderBy("last_added DESC")
+
+	if err := r.st.Select(ctx, q, &res); err != nil {
+		return nil, ctxd.WrapError(ctx, hashed.AugmentErr(err), "count favorite lists")
+	}
+
+	return res, nil
+}
+
+// CreateList adds a new named list for a visitor.
+//
+// List hash grants read access, so it is unpredictable.
+func (r *FavoriteRepository) CreateList(ctx context.Context, visitorHash uniq.Hash, name string) (FavoriteList, error) {
+	l := FavoriteList{}
+
+	n, err := rand.Int(rand.Reader, big.NewInt(math.MaxInt64))
+	if err != nil {
+		return l, err
+	}
+
+	l.Hash = uniq.Hash(n.Int64())
+	l.CreatedAt = time.Now()
+	l.VisitorHash = visitorHash
+	l.Name = name
+
+	if err := r.lists.Add(ctx, l); err != nil {
+		return l, err
+	}
+
+	return l, nil
+}
+
+// RenameList updates name of a list.
+func (r *FavoriteRepository) RenameList(ctx context.Context, l FavoriteList) error {
+	return hashed.AugmentReturnErr(r.lists.UpdateStmt(nil).
+		Set(r.lists.Col(&r.lists.R.Name), l.Name).
+		Where(r.lists.Eq(&r.lists.R.Hash, l.Hash)).
+		ExecContext(ctx))
+}
+
+// FindList returns a named list by its hash.
+func (r *FavoriteRepository) FindList(ctx context.Context, listHash uniq.Hash) (FavoriteList, error) {
+	return r.lists.FindByHash(ctx, listHash)
+}
+
+// FindLists returns named lists of a visitor.
+func (r *FavoriteRepository) FindLists(ctx context.Context, visitorHash uniq.Hash) ([]FavoriteList, error) {
+	q := r.lists.SelectStmt().
+		Where(r.lists.Eq(&r.lists.R.VisitorHash, visitorHash)).
+		OrderByClause(r.lists.Fmt("%s ASC", &r.lists.R.CreatedAt))
+
+	return hashed.AugmentResErr(r.lists.List(ctx, q))
+}
+
+// FindAllLists returns all named lists.
+func (r *FavoriteRepository) FindAllLists(ctx context.Context) ([]FavoriteList, error) {
+	return r.lists.FindAll(ctx)
+}
+
+// DeleteList removes a named list with its images.
+func (r *FavoriteRepository) DeleteList(ctx context.Context, l FavoriteList) error {
+	if err := hashed.AugmentReturnErr(r.fi.DeleteStmt().
+		Where(r.r.Eq(&r.fi.R.VisitorHash, l.VisitorHash)).
+		Where(r.r.Eq(&r.fi.R.ListHash, l.Hash)).
+		ExecContext(ctx)); err != nil {
+		return err
+	}
+
+	return r.lists.Delete(ctx, l.Hash)
+}
+
+func (r *FavoriteRepository) DeleteImages(ctx context.Context, visitorHash, listHash uniq.Hash, imageHashes ...uniq.Hash) error {
 	return hashed.AugmentReturnErr(r.fi.DeleteStmt().
 		Where(r.r.Eq(&r.fi.R.VisitorHash, visitorHash)).
+		Where(r.r.Eq(&r.fi.R.ListHash, listHash)).
 		Where(r.r.Eq(&r.fi.R.ImageHash, imageHashes)).
 		ExecContext(ctx))
 }
 
-func (r *FavoriteRepository) AddImages(ctx context.Context, visitorHash uniq.Hash, imageHashes ...uniq.Hash) error {
+// DeleteVisitor removes all favorite images and lists of a visitor.
+func (r *FavoriteRepository) DeleteVisitor(ctx context.Context, visitorHash uniq.Hash) error {
+	if err := hashed.AugmentReturnErr(r.fi.DeleteStmt().
+		Where(r.r.Eq(&r.fi.R.VisitorHash, visitorHash)).
+		ExecContext(ctx)); err != nil {
+		return err
+	}
+
+	return hashed.AugmentReturnErr(r.lists.DeleteStmt().
+		Where(r.lists.Eq(&r.lists.R.VisitorHash, visitorHash)).
+		ExecContext(ctx))
+}
+
+func (r *FavoriteRepository) AddImages(ctx context.Context, visitorHash, listHash uniq.Hash, imageHashes ...uniq.Hash) error {
 	rows := make([]FavoriteImage, 0, len(imageHashes))
 
 	for _, imageHash := range imageHashes {
 		fi := FavoriteImage{}
 		fi.ImageHash = imageHash
 		fi.VisitorHash = visitorHash
+		fi.ListHash = listHash
 		fi.CreatedAt = time.Now()
 
 		rows = append(rows, fi)
@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"github.com/bool64/sqluct"
+	"github.com/vearutop/photo-blog/internal/domain/site"
+	"github.com/vearutop/photo-blog/internal/infra/storage/hashed"
+)
+
+const (
+	// FollowerTable is the name of the table.
+	FollowerTable = "follower"
+)
+
+func NewFollowerRepository(storage *sqluct.Storage) *FollowerRepository {
+	return &FollowerRepository{
+		Repo: hashed.Repo[site.Follower, *site.Follower]{
+			StorageOf: sqluct.Table[site.Follower](storage, FollowerTable),
+		},
+	}
+}
+
+// FollowerRepository saves ActivityPub followers to database.
+type FollowerRepository struct {
+	hashed.Repo[site.Follower, *site.Follower]
+}
+
+func (r *FollowerRepository) SiteFollowerRepository() *FollowerRepository {
+	return r
+}
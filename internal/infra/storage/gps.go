@@ -32,3 +32,7 @@ func (ir *GpsRepository) PhotoGpsEnsurer() uniq.Ensurer[photo.Gps] {
 func (ir *GpsRepository) PhotoGpsFinder() uniq.Finder[photo.Gps] {
 	return ir
 }
+
+func (ir *GpsRepository) PhotoGpsDeleter() uniq.Deleter[photo.Gps] {
+	return ir
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/Masterminds/squirrel"
 	"github.com/bool64/sqluct"
 	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
 	"github.com/vearutop/photo-blog/internal/infra/storage/hashed"
 )
 
@@ -17,6 +18,7 @@ type ImageSelector struct {
 	i  sqluct.StorageOf[photo.Image]
 	m  sqluct.StorageOf[photo.Meta]
 	e  sqluct.StorageOf[photo.Exif]
+	g  sqluct.StorageOf[photo.Gps]
 
 	ref *sqluct.Referencer
 }
@@ -29,6 +31,7 @@ func NewImageSelector(storage *sqluct.Storage) *ImageSelector {
 		i:  sqluct.Table[photo.Image](storage, ImageTable),
 		m:  sqluct.Table[photo.Meta](storage, MetaTable),
 		e:  sqluct.Table[photo.Exif](storage, ExifTable),
+		g:  sqluct.Table[photo.Gps](storage, GpsTable),
 	}
 
 	ref := storage.MakeReferencer()
@@ -38,6 +41,7 @@ func NewImageSelector(storage *sqluct.Storage) *ImageSelector {
 	ref.AddTableAlias(f.m.R, MetaTable)
 	ref.AddTableAlias(f.i.R, ImageTable)
 	ref.AddTableAlias(f.e.R, ExifTable)
+	ref.AddTableAlias(f.g.R, GpsTable)
 
 	f.ref = ref
 
@@ -52,6 +56,7 @@ type ImageQuery struct {
 	albumImagesJoined bool
 	metaJoined        bool
 	exifJoined        bool
+	gpsJoined         bool
 	withSingleAlbum   bool
 }
 
@@ -119,6 +124,21 @@ func (is *ImageQuery) joinMeta() *ImageQuery {
 	return is
 }
 
+// joinGps skips images without position.
+func (is *ImageQuery) joinGps() *ImageQuery {
+	if is.gpsJoined {
+		return is
+	}
+
+	is.gpsJoined = true
+	ref := is.f.ref
+	ir := is.f.i.R
+	gr := is.f.g.R
+	is.q = is.q.Join(ref.Fmt("%s ON %s = %s", gr, &gr.Hash, &ir.Hash))
+
+	return is
+}
+
 func (is *ImageQuery) OnlyPublic() *ImageQuery {
 	is.joinAlbums()
 
@@ -211,3 +231,106 @@ func (is *ImageQuery) Find(ctx context.Context) ([]photo.Image, error) {
 
 	return hashed.AugmentResErr(is.f.i.List(ctx, is.q))
 }
+
+// PlaceFacet is a number of images in a place.
+type PlaceFacet struct {
+	CountryCode string `db:"country_code" json:"country_code"`
+	Country     string `db:"country" json:"country"`
+	Region      string `db:"region" json:"region,omitempty"`
+	City        string `db:"city" json:"city,omitempty"`
+	Count       int    `db:"cnt" json:"count"`
+}
+
+func (is *ImageQuery) placeField(field string) string {
+	return is.f.ref.Fmt("json_extract(%s, ", &is.f.m.R.Data) + "'$.place." + field + "')"
+}
+
+// ByPlace filters images by reverse geocoded place, empty values are ignored.
+func (is *ImageQuery) ByPlace(countryCode, region, city string) *ImageQuery {
+	is.joinMeta()
+
+	for _, f := range []struct{ name, val string }{
+		{name: "country_code", val: countryCode},
+		{name: "region", val: region},
+		{name: "city", val: city},
+	} {
+		if f.val != "" {
+			is.q = is.q.Where(is.placeField(f.name)+" = ?", f.val)
+		}
+	}
+
+	return is
+}
+
+// PlaceFacets counts selected images by place.
+func (is *ImageQuery) PlaceFacets(ctx context.Context) ([]PlaceFacet, error) {
+	is.joinMeta()
+
+	// Subquery keeps images unique when joined with albums.
+	sub := is.q.Column(is.f.ref.Fmt("json_extract(%s, '$.place') AS place", &is.f.m.R.Data))
+
+	q := squirrel.Select(
+		"COALESCE(json_extract(place, '$.country_code'), '') AS country_code",
+		"COALESCE(json_extract(place, '$.country'), '') AS country",
+		"COALESCE(json_extract(place, '$.region'), '') AS region",
+		"COALESCE(json_extract(place, '$.city'), '') AS city",
+		"COUNT(*) AS cnt",
+	).
+		FromSelect(sub, "i").
+		Where("place IS NOT NULL").
+		GroupBy("country_code", "country", "region", "city").
+		OrderBy("cnt DESC", "country", "region", "city")
+
+	var res []PlaceFacet
+
+	return res, is.f.st.Select(ctx, q, &res)
+}
+
+// GeoPoint is a position of an image.
+type GeoPoint struct {
+	Hash   uniq.Hash `db:"hash" json:"hash"`
+	Width  int64     `db:"width" json:"width"`
+	Height int64     `db:"height" json:"height"`
+	Lat    float64   `db:"lat" json:"lat"`
+	Lon    float64   `db:"lon" json:"lon"`
+}
+
+// InBBox filters images by position within bounding box.
+func (is *ImageQuery) InBBox(minLat, minLon, maxLat, maxLon float64) *ImageQuery {
+	is.joinGps()
+
+	ref := is.f.ref
+	gr := is.f.g.R
+
+	is.q = is.q.Where(ref.Fmt("%s BETWEEN ? AND ?", &gr.Latitude), minLat, maxLat)
+
+	// Bounding box crosses antimeridian.
+	if minLon > maxLon {
+		is.q = is.q.Where(ref.Fmt("(%s >= ? OR %s <= ?)", &gr.Longitude, &gr.Longitude), minLon, maxLon)
+	} else {
+		is.q = is.q.Where(ref.Fmt("%s BETWEEN ? AND ?", &gr.Longitude), minLon, maxLon)
+	}
+
+	return is
+}
+
+// GeoPoints finds positions of selected images, latest images go first.
+func (is *ImageQuery) GeoPoints(ctx context.Context) ([]GeoPoint, error) {
+	is.joinGps()
+
+	ref := is.f.ref
+	ir := is.f.i.R
+	gr := is.f.g.R
+
+	// Subquery keeps images unique when joined with albums.
+	sub := is.q.Column(ref.Fmt("%s AS lat, %s AS lon, COALESCE(%s, %s) AS sort_time",
+		&gr.Latitude, &gr.Longitude, &ir.TakenAt, &ir.CreatedAt))
+
+	q := squirrel.Select("hash", "width", "height", "lat", "lon").
+		FromSelect(sub, "i").
+		OrderBy("sort_time DESC")
+
+	var res []GeoPoint
+
+	return res, is.f.st.Select(ctx, q, &res)
+}
@@ -1,6 +1,10 @@
 package storage
 
 import (
+	"context"
+	"time"
+
+	"github.com/Masterminds/squirrel"
 	"github.com/bool64/sqluct"
 	"github.com/vearutop/photo-blog/internal/domain/comment"
 	"github.com/vearutop/photo-blog/internal/domain/uniq"
@@ -14,6 +18,7 @@ const (
 
 func NewMessageRepository(storage *sqluct.Storage) *MessageRepository {
 	return &MessageRepository{
+		st: storage,
 		Repo: hashed.Repo[comment.Message, *comment.Message]{
 			StorageOf: sqluct.Table[comment.Message](storage, MessageTable),
 		},
@@ -22,6 +27,7 @@ func NewMessageRepository(storage *sqluct.Storage) *MessageRepository {
 
 // MessageRepository saves images to database.
 type MessageRepository struct {
+	st *sqluct.Storage
 	hashed.Repo[comment.Message, *comment.Message]
 }
 
@@ -32,3 +38,179 @@ func (ir *MessageRepository) CommentMessageEnsurer() uniq.Ensurer[comment.Messag
 func (ir *MessageRepository) CommentMessageFinder() uniq.Finder[comment.Message] {
 	return ir
 }
+
+func (ir *MessageRepository) CommentMessageRepository() *MessageRepository {
+	return ir
+}
+
+// FindThread returns approved messages of a thread together with not rejected messages of a visitor.
+func (ir *MessageRepository) FindThread(ctx context.Context, threadHash uniq.Hash, visitorHash uniq.Hash) ([]comment.Message, error) {
+	q := ir.SelectStmt().
+		Where(ir.Eq(&ir.R.ThreadHash, threadHash)).
+		OrderByClause(ir.Fmt("%s", &ir.R.CreatedAt))
+
+	if visitorHash != 0 {
+		q = q.Where(squirrel.Or{
+			ir.Eq(&ir.R.Approved, true),
+			squirrel.And{
+				ir.Eq(&ir.R.VisitorHash, visitorHash),
+				ir.Eq(&ir.R.Rejected, false),
+			},
+		})
+	} else {
+		q = q.Where(ir.Eq(&ir.R.Approved, true))
+	}
+
+	return hashed.AugmentResErr(ir.List(ctx, q))
+}
+
+// FindPending returns messages waiting for moderation, newest first.
+func (ir *MessageRepository) FindPending(ctx context.Context, limit uint64) ([]comment.Message, error) {
+	q := ir.SelectStmt().
+		Where(ir.Eq(&ir.R.Approved, false)).
+		Where(ir.Eq(&ir.R.Rejected, false)).
+		OrderByClause(ir.Fmt("%s DESC", &ir.R.CreatedAt)).
+		Limit(limit)
+
+	return hashed.AugmentResErr(ir.List(ctx, q))
+}
+
+// CountSince counts messages left by visitor or from IP address after a moment.
+func (ir *MessageRepository) CountSince(ctx context.Context, visitorHash uniq.Hash, ip string, since time.Time) (int, error) {
+	or := squirrel.Or{ir.Eq(&ir.R.VisitorHash, visitorHash)}
+	if ip != "" {
+		or = append(or, ir.Eq(&ir.R.IP, ip))
+	}
+
+	q := ir.SelectStmt(func(o *sqluct.Options) {
+		o.Columns = []string{"COUNT(1)"}
+	}).
+		Where(or).
+		Where(ir.Fmt("%s > ?", &ir.R.CreatedAt), since)
+
+	var cnt int
+
+	return cnt, hashed.AugmentErr(ir.st.Select(ctx, q, &cnt))
+}
+
+// SetStatus updates moderation status of messages.
+func (ir *MessageRepository) SetStatus(ctx context.Context, approved, rejected bool, hashes ...uniq.Hash) error {
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	m := comment.Message{Approved: approved, Rejected: rejected}
+
+	return hashed.AugmentReturnErr(ir.UpdateStmt(m, func(o *sqluct.Options) {
+		o.Columns = []string{ir.Col(&ir.R.Approved), ir.Col(&ir.R.Rejected)}
+	}).
+		Where(ir.Eq(&ir.R.Hash, hashes)).
+		ExecContext(ctx))
+}
+
+// RejectVisitor rejects all pending messages of a visitor.
+func (ir *MessageRepository) RejectVisitor(ctx context.Context, visitorHash uniq.Hash) error {
+	m := comment.Message{Rejected: true}
+
+	return hashed.AugmentReturnErr(ir.UpdateStmt(m, func(o *sqluct.Options) {
+		o.Columns = []string{ir.Col(&ir.R.Rejected)}
+	}).
+		Where(ir.Eq(&ir.R.VisitorHash, visitorHash)).
+		Where(ir.Eq(&ir.R.Approved, false)).
+		ExecContext(ctx))
+}
+
+// DeleteWithReplies removes a message and replies to it.
+func (ir *MessageRepository) DeleteWithReplies(ctx context.Context, hash uniq.Hash) error {
+	return hashed.AugmentReturnErr(ir.DeleteStmt().
+		Where(squirrel.Or{
+			ir.Eq(&ir.R.Hash, hash),
+			ir.Eq(&ir.R.ParentHash, hash),
+		}).
+		ExecContext(ctx))
+}
+
+// DeleteOwn removes a single message, message with replies of others keeps its place in thread with text erased.
+func (ir *MessageRepository) DeleteOwn(ctx context.Context, hash uniq.Hash) error {
+	return ir.st.InTx(ctx, func(ctx context.Context) error {
+		q := ir.SelectStmt(func(o *sqluct.Options) {
+			o.Columns = []string{"COUNT(1)"}
+		}).
+			Where(ir.Eq(&ir.R.ParentHash, hash))
+
+		var replies int
+		if err := ir.st.Select(ctx, q, &replies); err != nil {
+			return hashed.AugmentErr(err)
+		}
+
+		if replies == 0 {
+			return hashed.AugmentReturnErr(ir.st.Exec(ctx, ir.DeleteStmt().Where(ir.Eq(&ir.R.Hash, hash))))
+		}
+
+		m := comment.Message{Text: comment.DeletedText}
+
+		return hashed.AugmentReturnErr(ir.st.Exec(ctx, ir.UpdateStmt(m, func(o *sqluct.Options) {
+			o.Columns = []string{ir.Col(&ir.R.Text), ir.Col(&ir.R.IP)}
+		}).
+			Where(ir.Eq(&ir.R.Hash, hash))))
+	})
+}
+
+// FindByVisitor returns all messages of a visitor, oldest first.
+func (ir *MessageRepository) FindByVisitor(ctx context.Context, visitorHash uniq.Hash) ([]comment.Message, error) {
+	q := ir.SelectStmt().
+		Where(ir.Eq(&ir.R.VisitorHash, visitorHash)).
+		OrderByClause(ir.Fmt("%s", &ir.R.CreatedAt))
+
+	return hashed.AugmentResErr(ir.List(ctx, q))
+}
+
+// DeleteByVisitor removes all messages of a visitor, replies of other visitors are kept.
+//
+// Like in DeleteOwn, messages with replies keep their place in thread with text and author erased.
+func (ir *MessageRepository) DeleteByVisitor(ctx context.Context, visitorHash uniq.Hash) error {
+	return ir.st.InTx(ctx, func(ctx context.Context) error {
+		// Removing a reply can leave its parent without replies, so deletion is repeated.
+		for {
+			res, err := ir.st.Exec(ctx, ir.DeleteStmt().
+				Where(ir.Eq(&ir.R.VisitorHash, visitorHash)).
+				Where(ir.Fmt("%s NOT IN (SELECT %s FROM %s)", &ir.R.Hash, &ir.R.ParentHash, MessageTable)))
+			if err != nil {
+				return hashed.AugmentErr(err)
+			}
+
+			n, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+
+			if n == 0 {
+				break
+			}
+		}
+
+		m := comment.Message{Text: comment.DeletedText}
+
+		return hashed.AugmentReturnErr(ir.st.Exec(ctx, ir.UpdateStmt(m, func(o *sqluct.Options) {
+			o.Columns = []string{ir.Col(&ir.R.Text), ir.Col(&ir.R.IP), ir.Col(&ir.R.VisitorHash)}
+		}).
+			Where(ir.Eq(&ir.R.VisitorHash, visitorHash))))
+	})
+}
+
+// ClearIPs erases IP addresses of messages created before a moment.
+func (ir *MessageRepository) ClearIPs(ctx context.Context, before time.Time) (int64, error) {
+	m := comment.Message{}
+
+	res, err := ir.UpdateStmt(m, func(o *sqluct.Options) {
+		o.Columns = []string{ir.Col(&ir.R.IP)}
+	}).
+		Where(ir.Fmt("%s < ?", &ir.R.CreatedAt), before).
+		Where(ir.Fmt("%s != ''", &ir.R.IP)).
+		ExecContext(ctx)
+	if err != nil {
+		return 0, hashed.AugmentErr(err)
+	}
+
+	return res.RowsAffected()
+}
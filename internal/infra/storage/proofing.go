@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/bool64/ctxd"
+	"github.com/bool64/sqluct"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/storage/hashed"
+)
+
+const (
+	// ProofingMarkTable is the name of the table.
+	ProofingMarkTable = "proofing_mark"
+
+	// ProofingSubmissionTable is the name of the table.
+	ProofingSubmissionTable = "proofing_submission"
+)
+
+func NewProofingRepository(storage *sqluct.Storage) *ProofingRepository {
+	return &ProofingRepository{
+		st:    storage,
+		marks: sqluct.Table[photo.ProofingMark](storage, ProofingMarkTable),
+		subs:  sqluct.Table[photo.ProofingSubmission](storage, ProofingSubmissionTable),
+	}
+}
+
+// ProofingRepository saves client selections of album images.
+type ProofingRepository struct {
+	st *sqluct.Storage
+
+	marks sqluct.StorageOf[photo.ProofingMark]
+	subs  sqluct.StorageOf[photo.ProofingSubmission]
+}
+
+// FindMarks returns client decisions about album images.
+func (r *ProofingRepository) FindMarks(ctx context.Context, albumHash uniq.Hash) ([]photo.ProofingMark, error) {
+	q := r.marks.SelectStmt().
+		Where(r.marks.Eq(&r.marks.R.AlbumHash, albumHash)).
+		OrderByClause(r.marks.Fmt("%s ASC", &r.marks.R.UpdatedAt))
+
+	return hashed.AugmentResErr(r.marks.List(ctx, q))
+}
+
+// SetMarks stores client decisions, marks without state and note are removed.
+func (r *ProofingRepository) SetMarks(ctx context.Context, marks ...photo.ProofingMark) error {
+	for _, m := range marks {
+		if m.State == "" && m.Note == "" {
+			if err := hashed.AugmentReturnErr(r.marks.DeleteStmt().
+				Where(r.marks.Eq(&r.marks.R.AlbumHash, m.AlbumHash)).
+				Where(r.marks.Eq(&r.marks.R.ImageHash, m.ImageHash)).
+				ExecContext(ctx)); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		m.UpdatedAt = time.Now()
+
+		if _, err := r.st.InsertStmt(ProofingMarkTable, m).
+			Suffix("ON CONFLICT (album_hash, image_hash) DO UPDATE SET state = excluded.state, note = excluded.note, updated_at = excluded.updated_at").
+			ExecContext(ctx); err != nil {
+			return ctxd.WrapError(ctx, hashed.AugmentErr(err), "store proofing mark", "mark", m)
+		}
+	}
+
+	return nil
+}
+
+// FindSubmission returns final selection of an album or status.NotFound.
+func (r *ProofingRepository) FindSubmission(ctx context.Context, albumHash uniq.Hash) (photo.ProofingSubmission, error) {
+	return hashed.AugmentResErr(r.subs.Get(ctx, r.subs.SelectStmt().Where(r.subs.Eq(&r.subs.R.AlbumHash, albumHash))))
+}
+
+// Submit stores final selection of an album.
+func (r *ProofingRepository) Submit(ctx context.Context, s photo.ProofingSubmission) error {
+	if _, err := r.subs.InsertRow(ctx, s); err != nil {
+		return ctxd.WrapError(ctx, hashed.AugmentErr(err), "store proofing submission", "submission", s)
+	}
+
+	return nil
+}
+
+// Reopen removes final selection of an album, so that client can change it.
+func (r *ProofingRepository) Reopen(ctx context.Context, albumHash uniq.Hash) error {
+	return hashed.AugmentReturnErr(r.subs.DeleteStmt().
+		Where(r.subs.Eq(&r.subs.R.AlbumHash, albumHash)).
+		ExecContext(ctx))
+}
+
+// DeleteAlbum removes all proofing data of an album.
+func (r *ProofingRepository) DeleteAlbum(ctx context.Context, albumHash uniq.Hash) error {
+	if err := r.Reopen(ctx, albumHash); err != nil {
+		return err
+	}
+
+	return hashed.AugmentReturnErr(r.marks.DeleteStmt().
+		Where(r.marks.Eq(&r.marks.R.AlbumHash, albumHash)).
+		ExecContext(ctx))
+}
+
+func (r *ProofingRepository) ProofingRepository() *ProofingRepository {
+	return r
+}
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE video
+(
+    `hash`       INTEGER     NOT NULL DEFAULT 0,
+    `created_at` DATETIME    NOT NULL DEFAULT current_timestamp,
+    `size`       INTEGER     NOT NULL DEFAULT 0,
+    `path`       TEXT        NOT NULL DEFAULT '',
+    `duration`   REAL        NOT NULL DEFAULT 0,
+    `width`      INTEGER     NOT NULL DEFAULT 0,
+    `height`     INTEGER     NOT NULL DEFAULT 0,
+    `mime`       VARCHAR(50) NOT NULL DEFAULT '',
+    PRIMARY KEY (`hash`)
+);
+-- +goose StatementEnd
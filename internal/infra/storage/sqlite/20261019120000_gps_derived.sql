@@ -0,0 +1,5 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE gps
+    ADD COLUMN `derived` INTEGER NOT NULL DEFAULT 0;
+-- +goose StatementEnd
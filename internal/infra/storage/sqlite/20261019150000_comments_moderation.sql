@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE message
+    ADD COLUMN `parent_hash` INTEGER NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE message
+    ADD COLUMN `rejected` INTEGER NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE message
+    ADD COLUMN `spam_score` REAL NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE message
+    ADD COLUMN `ip` VARCHAR(64) NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE message
+    ADD COLUMN `edited_at` DATETIME DEFAULT NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+create index message_pending_idx on message (approved, rejected, created_at);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+create index message_of_visitor_idx on message (visitor_hash, created_at);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE visitor
+    ADD COLUMN `banned` INTEGER NOT NULL DEFAULT 0;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE follower
+(
+    `hash`       INTEGER  NOT NULL DEFAULT 0, -- hash of actor
+    `created_at` DATETIME NOT NULL DEFAULT current_timestamp,
+    `actor`      TEXT     NOT NULL DEFAULT '',
+    `inbox`      TEXT     NOT NULL DEFAULT '',
+    PRIMARY KEY (`hash`)
+);
+-- +goose StatementEnd
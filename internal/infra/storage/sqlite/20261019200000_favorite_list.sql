@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE favorite_list
+(
+    `hash`         INTEGER  NOT NULL DEFAULT 0, -- random, used in share link
+    `created_at`   DATETIME NOT NULL DEFAULT current_timestamp,
+    `visitor_hash` INTEGER  NOT NULL DEFAULT 0,
+    `name`         TEXT     NOT NULL DEFAULT '',
+    PRIMARY KEY (`hash`)
+);
+
+CREATE INDEX favorite_list_visitor ON favorite_list (`visitor_hash`);
+
+-- Favorite images of a visitor that do not belong to a named list have list_hash 0.
+CREATE TABLE favorite_image_new
+(
+    `visitor_hash` INTEGER  NOT NULL,
+    `list_hash`    INTEGER  NOT NULL DEFAULT 0,
+    `image_hash`   INTEGER  NOT NULL,
+    `note`         TEXT     NOT NULL DEFAULT '',
+    `created_at`   DATETIME NOT NULL DEFAULT current_timestamp,
+    PRIMARY KEY (`visitor_hash`, `list_hash`, `image_hash`)
+);
+
+INSERT INTO favorite_image_new (`visitor_hash`, `image_hash`, `created_at`)
+SELECT `visitor_hash`, `image_hash`, `created_at`
+FROM favorite_image;
+
+DROP TABLE favorite_image;
+
+ALTER TABLE favorite_image_new RENAME TO favorite_image;
+-- +goose StatementEnd
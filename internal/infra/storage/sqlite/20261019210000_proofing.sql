@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE proofing_mark
+(
+    `album_hash` INTEGER  NOT NULL,
+    `image_hash` INTEGER  NOT NULL,
+    `state`      TEXT     NOT NULL DEFAULT '',
+    `note`       TEXT     NOT NULL DEFAULT '',
+    `updated_at` DATETIME NOT NULL DEFAULT current_timestamp,
+    PRIMARY KEY (`album_hash`, `image_hash`)
+);
+
+CREATE TABLE proofing_submission
+(
+    `album_hash`   INTEGER  NOT NULL,
+    `submitted_at` DATETIME NOT NULL DEFAULT current_timestamp,
+    `name`         TEXT     NOT NULL DEFAULT '',
+    `comment`      TEXT     NOT NULL DEFAULT '',
+    `selected`     INTEGER  NOT NULL DEFAULT 0,
+    PRIMARY KEY (`album_hash`)
+);
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE audit_log
+(
+    `id`         INTEGER PRIMARY KEY AUTOINCREMENT,
+    `created_at` DATETIME NOT NULL DEFAULT current_timestamp,
+    `album_hash` INTEGER  NOT NULL DEFAULT 0,
+    `album_name` TEXT     NOT NULL DEFAULT '',
+    `image_hash` INTEGER  NOT NULL DEFAULT 0,
+    `action`     TEXT     NOT NULL DEFAULT '',
+    `actor`      TEXT     NOT NULL DEFAULT ''
+);
+
+CREATE INDEX audit_log_album ON audit_log (`album_hash`, `id`);
+
+-- Images removed from albums, kept to restore membership during grace period.
+CREATE TABLE trash_album_image
+(
+    `album_hash` INTEGER  NOT NULL,
+    `image_hash` INTEGER  NOT NULL,
+    `utime`      INTEGER,
+    `removed_at` DATETIME NOT NULL DEFAULT current_timestamp,
+    `actor`      TEXT     NOT NULL DEFAULT '',
+    PRIMARY KEY (`album_hash`, `image_hash`)
+);
+-- +goose StatementEnd
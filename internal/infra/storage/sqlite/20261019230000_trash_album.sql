@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Deleted albums, images of deleted album are kept in trash_album_image.
+CREATE TABLE trash_album
+(
+    `hash`        INTEGER      NOT NULL PRIMARY KEY,
+    `created_at`  DATETIME     NOT NULL DEFAULT current_timestamp,
+    `updated_at`  DATETIME     NOT NULL DEFAULT '1970-01-01 00:00:00',
+    `title`       VARCHAR(255) NOT NULL,
+    `name`        VARCHAR(255) NOT NULL,
+    `public`      INTEGER      NOT NULL DEFAULT 0,
+    `hidden`      INTEGER      NOT NULL DEFAULT 0,
+    `cover_image` INTEGER      NOT NULL DEFAULT 0,
+    `settings`    TEXT                  DEFAULT NULL,
+    `removed_at`  DATETIME     NOT NULL DEFAULT current_timestamp,
+    `actor`       TEXT         NOT NULL DEFAULT ''
+);
+
+CREATE INDEX trash_album_image_removed ON trash_album_image (`removed_at`);
+-- +goose StatementEnd
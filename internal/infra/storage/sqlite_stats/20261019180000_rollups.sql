@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE period_stats
+(
+    `period` CHAR(1) not null, -- d, w or m
+    `start`  integer not null, -- period start date as truncated unix timestamp
+    `uniq`   integer not null default 0,
+    `views`  integer not null default 0,
+    PRIMARY KEY (`period`, `start`)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE daily_image_stats
+(
+    `hash`    integer not null, -- image hash
+    `date`    integer not null, -- view date as truncated unix timestamp
+    `views`   integer not null default 0,
+    `view_ms` integer not null default 0,
+    `zooms`   integer not null default 0,
+    PRIMARY KEY (`hash`, `date`)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE daily_breakdown
+(
+    `date`     integer      not null, -- visit date as truncated unix timestamp
+    `dim`      VARCHAR(16)  not null, -- country, device, screen or referrer
+    `value`    VARCHAR(255) not null,
+    `visitors` integer      not null default 0,
+    `views`    integer      not null default 0,
+    PRIMARY KEY (`date`, `dim`, `value`)
+);
+-- +goose StatementEnd
+
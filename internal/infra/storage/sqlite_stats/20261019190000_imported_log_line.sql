@@ -0,0 +1,7 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE imported_log_line
+(
+    `hash` integer not null primary key -- hash of access log line replayed by log2stats
+) WITHOUT ROWID;
+-- +goose StatementEnd
@@ -7,6 +7,7 @@ import (
 	"github.com/Masterminds/squirrel"
 	"github.com/bool64/ctxd"
 	"github.com/bool64/sqluct"
+	"github.com/bool64/stats"
 	"github.com/swaggest/usecase/status"
 	"github.com/vearutop/photo-blog/internal/domain/photo"
 	"github.com/vearutop/photo-blog/internal/domain/uniq"
@@ -19,10 +20,11 @@ const (
 	ThumbTable = "thumb"
 )
 
-func NewThumbRepository(storage *sqluct.Storage, upstream photo.Thumbnailer, logger ctxd.Logger) *ThumbRepository {
+func NewThumbRepository(storage *sqluct.Storage, upstream photo.Thumbnailer, logger ctxd.Logger, tracker stats.Tracker) *ThumbRepository {
 	return &ThumbRepository{
 		upstream: upstream,
 		logger:   logger,
+		tracker:  tracker,
 		Repo: hashed.Repo[photo.Thumb, *photo.Thumb]{
 			StorageOf: sqluct.Table[photo.Thumb](storage, ThumbTable),
 		},
@@ -33,6 +35,7 @@ func NewThumbRepository(storage *sqluct.Storage, upstream photo.Thumbnailer, log
 type ThumbRepository struct {
 	upstream photo.Thumbnailer
 	logger   ctxd.Logger
+	tracker  stats.Tracker
 	hashed.Repo[photo.Thumb, *photo.Thumb]
 }
 
@@ -52,6 +55,7 @@ func (tr *ThumbRepository) Thumbnail(ctx context.Context, img photo.Image, size
 		found = true
 		if !shouldRebuild {
 			tr.logger.Debug(ctx, "thumb: found", "imageHash", img.Hash, "size", size)
+			tr.tracker.Add(ctx, "thumb_requests", 1, "size", string(size), "result", "hit")
 
 			return th, nil
 		}
@@ -64,6 +68,7 @@ func (tr *ThumbRepository) Thumbnail(ctx context.Context, img photo.Image, size
 	}
 
 	tr.logger.Info(ctx, "thumb: build", "imageHash", img.Hash, "size", size)
+	tr.tracker.Add(ctx, "thumb_requests", 1, "size", string(size), "result", "miss")
 
 	th, err = tr.upstream.Thumbnail(ctx, img, size)
 	if err != nil {
@@ -140,3 +145,7 @@ func (tr *ThumbRepository) Find(ctx context.Context, imageHash uniq.Hash, width,
 func (tr *ThumbRepository) PhotoThumbnailer() photo.Thumbnailer {
 	return tr
 }
+
+func (tr *ThumbRepository) PhotoThumbDeleter() uniq.Deleter[photo.Thumb] {
+	return tr
+}
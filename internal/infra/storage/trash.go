@@ -0,0 +1,334 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/bool64/ctxd"
+	"github.com/bool64/sqluct"
+	"github.com/vearutop/photo-blog/internal/domain/comment"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/storage/hashed"
+)
+
+const (
+	// TrashAlbumImageTable is the name of the table.
+	TrashAlbumImageTable = "trash_album_image"
+
+	// TrashAlbumTable is the name of the table.
+	TrashAlbumTable = "trash_album"
+)
+
+// TrashAlbum is a deleted album.
+type TrashAlbum struct {
+	photo.Album
+	RemovedAt time.Time `db:"removed_at" json:"removed_at"`
+	Actor     string    `db:"actor" json:"actor"`
+}
+
+// TrashAlbumImage is an image removed from album.
+type TrashAlbumImage struct {
+	AlbumHash uniq.Hash `db:"album_hash" json:"album_hash"`
+	ImageHash uniq.Hash `db:"image_hash" json:"image_hash"`
+	UTime     *int64    `db:"utime" json:"utime,omitempty" description:"Timestamp of image in album."`
+	RemovedAt time.Time `db:"removed_at" json:"removed_at"`
+	Actor     string    `db:"actor" json:"actor"`
+}
+
+func NewTrashRepository(storage *sqluct.Storage) *TrashRepository {
+	return &TrashRepository{
+		st:  storage,
+		al:  sqluct.Table[photo.Album](storage, AlbumTable),
+		ai:  sqluct.Table[AlbumImage](storage, AlbumImageTable),
+		ta:  sqluct.Table[TrashAlbum](storage, TrashAlbumTable),
+		tai: sqluct.Table[TrashAlbumImage](storage, TrashAlbumImageTable),
+	}
+}
+
+// TrashRepository keeps removed content to restore it during grace period.
+type TrashRepository struct {
+	st *sqluct.Storage
+
+	al  sqluct.StorageOf[photo.Album]
+	ai  sqluct.StorageOf[AlbumImage]
+	ta  sqluct.StorageOf[TrashAlbum]
+	tai sqluct.StorageOf[TrashAlbumImage]
+}
+
+// RemoveImages moves images from album to trash.
+func (r *TrashRepository) RemoveImages(ctx context.Context, actor string, albumHash uniq.Hash, imageHashes ...uniq.Hash) error {
+	if len(imageHashes) == 0 {
+		return nil
+	}
+
+	return r.st.InTx(ctx, func(ctx context.Context) error {
+		return r.moveImages(ctx, actor, albumHash, r.ai.Eq(&r.ai.R.ImageHash, imageHashes))
+	})
+}
+
+// moveImages moves album images matching condition to trash, must be called in transaction.
+func (r *TrashRepository) moveImages(ctx context.Context, actor string, albumHash uniq.Hash, cond squirrel.Sqlizer) error {
+	rows, err := r.ai.List(ctx, r.ai.SelectStmt().
+		Where(r.ai.Eq(&r.ai.R.AlbumHash, albumHash)).
+		Where(cond))
+	if err != nil {
+		return hashed.AugmentErr(err)
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	trash := make([]TrashAlbumImage, 0, len(rows))
+	for _, row := range rows {
+		trash = append(trash, TrashAlbumImage{
+			AlbumHash: row.AlbumHash,
+			ImageHash: row.ImageHash,
+			UTime:     row.UTime,
+			RemovedAt: time.Now(),
+			Actor:     actor,
+		})
+	}
+
+	if _, err := r.st.Exec(ctx, r.st.InsertStmt(TrashAlbumImageTable, trash).Options("OR REPLACE")); err != nil {
+		return ctxd.WrapError(ctx, hashed.AugmentErr(err), "store trash images", "rows", trash)
+	}
+
+	return hashed.AugmentReturnErr(r.st.Exec(ctx, r.ai.DeleteStmt().
+		Where(r.ai.Eq(&r.ai.R.AlbumHash, albumHash)).
+		Where(cond)))
+}
+
+// RestoreImages moves images from trash back to album with original timestamps, restored hashes are returned.
+func (r *TrashRepository) RestoreImages(ctx context.Context, albumHash uniq.Hash, imageHashes ...uniq.Hash) ([]uniq.Hash, error) {
+	if len(imageHashes) == 0 {
+		return nil, nil
+	}
+
+	var restored []uniq.Hash
+
+	err := r.st.InTx(ctx, func(ctx context.Context) (err error) {
+		restored, err = r.restoreImages(ctx, albumHash, r.tai.Eq(&r.tai.R.ImageHash, imageHashes))
+
+		return err
+	})
+
+	return restored, err
+}
+
+// restoreImages moves trash images matching condition back to album, must be called in transaction.
+func (r *TrashRepository) restoreImages(ctx context.Context, albumHash uniq.Hash, cond squirrel.Sqlizer) ([]uniq.Hash, error) {
+	trash, err := r.tai.List(ctx, r.tai.SelectStmt().
+		Where(r.tai.Eq(&r.tai.R.AlbumHash, albumHash)).
+		Where(cond))
+	if err != nil {
+		return nil, hashed.AugmentErr(err)
+	}
+
+	if len(trash) == 0 {
+		return nil, nil
+	}
+
+	rows := make([]AlbumImage, 0, len(trash))
+	restored := make([]uniq.Hash, 0, len(trash))
+
+	for _, t := range trash {
+		rows = append(rows, AlbumImage{AlbumHash: t.AlbumHash, ImageHash: t.ImageHash, UTime: t.UTime})
+		restored = append(restored, t.ImageHash)
+	}
+
+	if _, err := r.st.Exec(ctx, r.st.InsertStmt(AlbumImageTable, rows).Options("OR IGNORE")); err != nil {
+		return nil, ctxd.WrapError(ctx, hashed.AugmentErr(err), "restore album images", "rows", rows)
+	}
+
+	return restored, hashed.AugmentReturnErr(r.st.Exec(ctx, r.tai.DeleteStmt().
+		Where(r.tai.Eq(&r.tai.R.AlbumHash, albumHash)).
+		Where(cond)))
+}
+
+// RemoveAlbum moves album with all its images to trash.
+func (r *TrashRepository) RemoveAlbum(ctx context.Context, actor string, albumHash uniq.Hash) error {
+	return r.st.InTx(ctx, func(ctx context.Context) error {
+		album, err := r.al.Get(ctx, r.al.SelectStmt().Where(r.al.Eq(&r.al.R.Hash, albumHash)))
+		if err != nil {
+			return hashed.AugmentErr(err)
+		}
+
+		ta := TrashAlbum{Album: album, RemovedAt: time.Now(), Actor: actor}
+
+		if _, err := r.st.Exec(ctx, r.st.InsertStmt(TrashAlbumTable, ta).Options("OR REPLACE")); err != nil {
+			return ctxd.WrapError(ctx, hashed.AugmentErr(err), "store trash album", "album", album.Name)
+		}
+
+		if err := r.moveImages(ctx, actor, albumHash, squirrel.Expr("1=1")); err != nil {
+			return err
+		}
+
+		return hashed.AugmentReturnErr(r.st.Exec(ctx, r.al.DeleteStmt().
+			Where(r.al.Eq(&r.al.R.Hash, albumHash))))
+	})
+}
+
+// RestoreAlbum moves album with its images from trash back, status.AlreadyExists is returned
+// if album with the same name was created again.
+func (r *TrashRepository) RestoreAlbum(ctx context.Context, albumHash uniq.Hash) (photo.Album, []uniq.Hash, error) {
+	var (
+		album    photo.Album
+		restored []uniq.Hash
+	)
+
+	err := r.st.InTx(ctx, func(ctx context.Context) error {
+		ta, err := r.ta.Get(ctx, r.ta.SelectStmt().Where(r.ta.Eq(&r.ta.R.Hash, albumHash)))
+		if err != nil {
+			return hashed.AugmentErr(err)
+		}
+
+		album = ta.Album
+
+		if _, err := r.st.Exec(ctx, r.st.InsertStmt(AlbumTable, album)); err != nil {
+			return ctxd.WrapError(ctx, hashed.AugmentErr(err), "restore album", "album", album.Name)
+		}
+
+		if restored, err = r.restoreImages(ctx, albumHash, squirrel.Expr("1=1")); err != nil {
+			return err
+		}
+
+		return hashed.AugmentReturnErr(r.st.Exec(ctx, r.ta.DeleteStmt().
+			Where(r.ta.Eq(&r.ta.R.Hash, albumHash))))
+	})
+
+	return album, restored, err
+}
+
+// FindAlbums returns deleted albums.
+func (r *TrashRepository) FindAlbums(ctx context.Context) ([]TrashAlbum, error) {
+	return hashed.AugmentResErr(r.ta.List(ctx, r.ta.SelectStmt().
+		OrderByClause(r.ta.Fmt("%s DESC", &r.ta.R.RemovedAt))))
+}
+
+// FindImages returns images removed from album, from all albums if album hash is zero.
+func (r *TrashRepository) FindImages(ctx context.Context, albumHash uniq.Hash) ([]TrashAlbumImage, error) {
+	q := r.tai.SelectStmt().OrderByClause(r.tai.Fmt("%s DESC", &r.tai.R.RemovedAt))
+
+	if albumHash != 0 {
+		q = q.Where(r.tai.Eq(&r.tai.R.AlbumHash, albumHash))
+	}
+
+	return hashed.AugmentResErr(r.tai.List(ctx, q))
+}
+
+// Purge permanently forgets albums and images removed before time, hashes of purged images are returned.
+func (r *TrashRepository) Purge(ctx context.Context, before time.Time) ([]uniq.Hash, error) {
+	var purged []uniq.Hash
+
+	err := r.st.InTx(ctx, func(ctx context.Context) error {
+		q := r.tai.SelectStmt().Where(r.tai.Fmt("%s < ?", &r.tai.R.RemovedAt), before)
+
+		rows, err := r.tai.List(ctx, q)
+		if err != nil {
+			return hashed.AugmentErr(err)
+		}
+
+		seen := make(map[uniq.Hash]bool, len(rows))
+		for _, row := range rows {
+			if !seen[row.ImageHash] {
+				seen[row.ImageHash] = true
+				purged = append(purged, row.ImageHash)
+			}
+		}
+
+		if _, err := r.st.Exec(ctx, r.tai.DeleteStmt().
+			Where(r.tai.Fmt("%s < ?", &r.tai.R.RemovedAt), before)); err != nil {
+			return hashed.AugmentErr(err)
+		}
+
+		return hashed.AugmentReturnErr(r.st.Exec(ctx, r.ta.DeleteStmt().
+			Where(r.ta.Fmt("%s < ?", &r.ta.R.RemovedAt), before)))
+	})
+
+	return purged, err
+}
+
+// FindUnreferenced returns hashes of images that are neither in albums nor in trash.
+func (r *TrashRepository) FindUnreferenced(ctx context.Context, imageHashes ...uniq.Hash) ([]uniq.Hash, error) {
+	if len(imageHashes) == 0 {
+		return nil, nil
+	}
+
+	ref := make(map[uniq.Hash]bool)
+
+	rows, err := r.ai.List(ctx, r.ai.SelectStmt().Where(r.ai.Eq(&r.ai.R.ImageHash, imageHashes)))
+	if err != nil {
+		return nil, hashed.AugmentErr(err)
+	}
+
+	for _, row := range rows {
+		ref[row.ImageHash] = true
+	}
+
+	trash, err := r.tai.List(ctx, r.tai.SelectStmt().Where(r.tai.Eq(&r.tai.R.ImageHash, imageHashes)))
+	if err != nil {
+		return nil, hashed.AugmentErr(err)
+	}
+
+	for _, row := range trash {
+		ref[row.ImageHash] = true
+	}
+
+	var res []uniq.Hash
+
+	for _, h := range imageHashes {
+		if !ref[h] {
+			res = append(res, h)
+		}
+	}
+
+	return res, nil
+}
+
+// DeleteImageRecords removes images with their EXIF, GPS, meta data with faces, video details,
+// favorites, proofing marks and comments.
+func (r *TrashRepository) DeleteImageRecords(ctx context.Context, imageHashes ...uniq.Hash) error {
+	if len(imageHashes) == 0 {
+		return nil
+	}
+
+	imageThreads := squirrel.Eq{"type": comment.ThreadImage, "related_hash": imageHashes}
+
+	threads, args, err := squirrel.Select("hash").From(ThreadTable).Where(imageThreads).ToSql()
+	if err != nil {
+		return err
+	}
+
+	byImageHash := squirrel.Eq{"image_hash": imageHashes}
+	byHash := squirrel.Eq{"hash": imageHashes}
+
+	return r.st.InTx(ctx, func(ctx context.Context) error {
+		for _, d := range []struct {
+			table string
+			where squirrel.Sqlizer
+		}{
+			{table: MessageTable, where: squirrel.Expr("thread_hash IN ("+threads+")", args...)},
+			{table: ThreadTable, where: imageThreads},
+			{table: FavoriteImageTable, where: byImageHash},
+			{table: ProofingMarkTable, where: byImageHash},
+			{table: VideoTable, where: byHash},
+			{table: ImageTable, where: byHash},
+			{table: ExifTable, where: byHash},
+			{table: GpsTable, where: byHash},
+			{table: MetaTable, where: byHash},
+		} {
+			if _, err := r.st.Exec(ctx, r.st.DeleteStmt(d.table).Where(d.where)); err != nil {
+				return ctxd.WrapError(ctx, hashed.AugmentErr(err), "delete image records", "table", d.table)
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *TrashRepository) TrashRepository() *TrashRepository {
+	return r
+}
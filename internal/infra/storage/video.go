@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"github.com/bool64/sqluct"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/storage/hashed"
+)
+
+const (
+	// VideoTable is the name of the table.
+	VideoTable = "video"
+)
+
+func NewVideoRepository(storage *sqluct.Storage) *VideoRepository {
+	return &VideoRepository{
+		Repo: hashed.Repo[photo.Video, *photo.Video]{
+			StorageOf: sqluct.Table[photo.Video](storage, VideoTable),
+		},
+	}
+}
+
+// VideoRepository saves video clips to database.
+type VideoRepository struct {
+	hashed.Repo[photo.Video, *photo.Video]
+}
+
+func (ir *VideoRepository) PhotoVideoEnsurer() uniq.Ensurer[photo.Video] {
+	return ir
+}
+
+func (ir *VideoRepository) PhotoVideoFinder() uniq.Finder[photo.Video] {
+	return ir
+}
+
+func (ir *VideoRepository) PhotoVideoUpdater() uniq.Updater[photo.Video] {
+	return ir
+}
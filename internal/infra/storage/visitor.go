@@ -32,3 +32,7 @@ func (ir *VisitorRepository) SiteVisitorEnsurer() uniq.Ensurer[site.Visitor] {
 func (ir *VisitorRepository) SiteVisitorFinder() uniq.Finder[site.Visitor] {
 	return ir
 }
+
+func (ir *VisitorRepository) SiteVisitorRepository() *VisitorRepository {
+	return ir
+}
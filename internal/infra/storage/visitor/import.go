@@ -0,0 +1,38 @@
+package visitor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+)
+
+const importedLineTable = "imported_log_line"
+
+// IsImported checks if access log line with hash was imported before.
+func (s *StatsRepository) IsImported(ctx context.Context, hash uint64) (bool, error) {
+	var cnt int
+
+	if err := s.st.Select(ctx, s.st.QueryBuilder().Select("COUNT(1)").From(importedLineTable).
+		Where(squirrel.Eq{"hash": int64(hash)}), &cnt); err != nil {
+		return false, fmt.Errorf("check imported line: %w", err)
+	}
+
+	return cnt > 0, nil
+}
+
+// MarkImported records access log line hash, false is returned if it was imported before.
+func (s *StatsRepository) MarkImported(ctx context.Context, hash uint64) (bool, error) {
+	res, err := s.st.Exec(ctx, s.st.QueryBuilder().Insert(importedLineTable).
+		Columns("hash").Values(int64(hash)).Suffix("ON CONFLICT DO NOTHING"))
+	if err != nil {
+		return false, fmt.Errorf("mark imported line: %w", err)
+	}
+
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("mark imported line: %w", err)
+	}
+
+	return aff > 0, nil
+}
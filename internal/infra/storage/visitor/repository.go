@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"net"
 	"net/http"
 	"strconv"
@@ -17,6 +18,7 @@ import (
 	"github.com/vearutop/netrie"
 	"github.com/vearutop/photo-blog/internal/domain/photo"
 	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/pkg/webstats"
 )
 
 type StatsRepository struct {
@@ -31,6 +33,9 @@ type StatsRepository struct {
 	pv  *PageVisitor
 	v   *Visitor
 	rf  *Refer
+	pst *PeriodStats
+	dis *DailyImageStats
+	bd  *Breakdown
 
 	visitorRepository *visitorRepository
 
@@ -40,12 +45,20 @@ type StatsRepository struct {
 	collectPageSuffix      string
 	collectDailyPageSuffix string
 
+	collectDailyImageSuffix string
+
 	mu             sync.Mutex
 	recentVisitors map[uniq.Hash]Visitor
 	recentNames    map[uniq.Hash]bool
 	isAdmin        map[uniq.Hash]bool
 
 	cityLoc netrie.IPLookuper
+
+	// Anonymous enables privacy mode when returns true, IP addresses are truncated and location is country-level.
+	Anonymous func() bool
+
+	// PrivateIP is an optional replacement of truncated IP address in privacy mode.
+	PrivateIP func(ip string) string
 }
 
 func NewStats(st *sqluct.Storage, l ctxd.Logger, cityLoc netrie.IPLookuper) (*StatsRepository, error) {
@@ -64,6 +77,9 @@ func NewStats(st *sqluct.Storage, l ctxd.Logger, cityLoc netrie.IPLookuper) (*St
 	s.dps = &DailyPageStats{}
 	s.pv = &PageVisitor{}
 	s.rf = &Refer{}
+	s.pst = &PeriodStats{}
+	s.dis = &DailyImageStats{}
+	s.bd = &Breakdown{}
 
 	s.visitorRepository = newVisitorRepository(st)
 	s.v = s.visitorRepository.R
@@ -76,6 +92,9 @@ func NewStats(st *sqluct.Storage, l ctxd.Logger, cityLoc netrie.IPLookuper) (*St
 	s.ref.AddTableAlias(s.pv, "page_visitors")
 	s.ref.AddTableAlias(s.v, "")
 	s.ref.AddTableAlias(s.rf, "")
+	s.ref.AddTableAlias(s.pst, "")
+	s.ref.AddTableAlias(s.dis, "")
+	s.ref.AddTableAlias(s.bd, "")
 
 	s.collectImageSuffix = s.ref.Fmt(
 		"ON CONFLICT(%s) "+
@@ -127,6 +146,20 @@ func NewStats(st *sqluct.Storage, l ctxd.Logger, cityLoc netrie.IPLookuper) (*St
 		)...,
 	)
 
+	s.collectDailyImageSuffix = s.ref.Fmt(
+		"ON CONFLICT(%s, %s) "+
+			"DO UPDATE SET "+
+			"%s = %s + excluded.%s, "+
+			"%s = %s + excluded.%s, "+
+			"%s = %s + excluded.%s",
+		sqluct.NoTableAll(
+			&s.dis.Hash, &s.dis.Date,
+			&s.dis.Views, &s.dis.Views, &s.dis.Views,
+			&s.dis.ViewMs, &s.dis.ViewMs, &s.dis.ViewMs,
+			&s.dis.Zooms, &s.dis.Zooms, &s.dis.Zooms,
+		)...,
+	)
+
 	if err := s.populateAdmins(); err != nil {
 		return nil, err
 	}
@@ -208,7 +241,9 @@ func (s *StatsRepository) CollectImage(ctx context.Context, visitor, image uniq.
 		s.l.Error(ctx, "failed to collect image stats", "error", err)
 	}
 
-	_, err = s.st.InsertStmt(imageVisitorsTable, imageVisitor{
+	s.collectDailyImage(ctx, image, viewTimeMs, zoomedIn, time.Now())
+
+	res, err := s.st.InsertStmt(imageVisitorsTable, imageVisitor{
 		Visitor: visitor,
 		Image:   image,
 	}, func(o *sqluct.Options) {
@@ -216,37 +251,24 @@ func (s *StatsRepository) CollectImage(ctx context.Context, visitor, image uniq.
 	}).ExecContext(ctx)
 	if err != nil {
 		s.l.Error(ctx, "failed to collect image visitor", "error", err)
+
+		return
 	}
 
-	s.updateImageUniq(ctx, image)
+	// Unique counters are incremented for new visitors instead of recounting, so that they survive data retention.
+	if n, err := res.RowsAffected(); err == nil && n > 0 {
+		s.incUniq(ctx, imageStatsTable, squirrel.Eq{s.ref.Ref(&s.is.Hash): image})
+	}
 }
 
-func (s *StatsRepository) updateImageUniq(ctx context.Context, hashes ...uniq.Hash) {
-	// INSERT INTO image_stats (hash, uniq)
-	// SELECT image, count(distinct visitor) as uniq
-	// FROM image_visitors
-	// WHERE image in (-2775344103693285314)
-	// GROUP BY image
-	// ON CONFLICT DO UPDATE SET uniq = excluded.uniq;
-
-	sel := s.st.QueryBuilder().
-		Select(s.ref.Ref(&s.iv.Image), s.ref.Fmt("count(distinct %s) as uniq", &s.iv.Visitor)).
-		From(imageVisitorsTable).
-		// LeftJoin(s.ref.Fmt(visitorTable+" ON %s = %s", &s.iv.Visitor, &s.v.Hash)).
-		Where(squirrel.Eq{
-			s.ref.Ref(&s.iv.Image): hashes,
-		}).
-		// Where(s.ref.Fmt("%s = 0 AND %s = 0", &s.v.IsAdmin, &s.v.IsBot)).
-		GroupBy(s.ref.Ref(&s.iv.Image))
-
-	q := s.st.QueryBuilder().Insert(imageStatsTable).
-		Columns(s.ref.Ref(&s.is.Hash), s.ref.Ref(&s.is.Uniq)).
-		Select(sel).
-		Suffix(s.ref.Fmt("ON CONFLICT DO UPDATE SET %s = excluded.%s", sqluct.NoTableAll(&s.is.Uniq, &s.is.Uniq)...))
-
-	_, err := q.ExecContext(ctx)
+// incUniq increments unique visitors counter of a stats row.
+func (s *StatsRepository) incUniq(ctx context.Context, table string, where squirrel.Eq) {
+	_, err := s.st.QueryBuilder().Update(table).
+		Set("uniq", squirrel.Expr("uniq + 1")).
+		Where(where).
+		ExecContext(ctx)
 	if err != nil {
-		s.l.Error(ctx, "failed to update image uniq", "error", err)
+		s.l.Error(ctx, "failed to update uniq", "error", err, "table", table)
 	}
 }
 
@@ -332,7 +354,7 @@ func (s *StatsRepository) CollectAlbum(ctx context.Context, visitor uniq.Hash, a
 		s.l.Error(ctx, "failed to collect daily page stats", "error", err)
 	}
 
-	_, err = s.st.InsertStmt(pageVisitorsTable, PageVisitor{
+	res, err := s.st.InsertStmt(pageVisitorsTable, PageVisitor{
 		Visitor: visitor,
 		Page:    album,
 		Date:    d,
@@ -341,52 +363,31 @@ func (s *StatsRepository) CollectAlbum(ctx context.Context, visitor uniq.Hash, a
 	}).ExecContext(ctx)
 	if err != nil {
 		s.l.Error(ctx, "failed to collect page visitor", "error", err)
+
+		return
 	}
 
-	s.updatePageUniq(ctx, album)
-	s.updateDailyPageUniq(ctx, d, album)
-}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return
+	}
 
-func (s *StatsRepository) updatePageUniq(ctx context.Context, hashes ...uniq.Hash) {
-	sel := s.st.QueryBuilder().
-		Select(s.ref.Ref(&s.pv.Page), s.ref.Fmt("count(distinct %s) as uniq", &s.pv.Visitor)).
-		From(pageVisitorsTable).
-		// LeftJoin(s.ref.Fmt(visitorTable+" ON %s = %s", &s.pv.Visitor, &s.v.Hash)).
-		Where(squirrel.Eq{s.ref.Ref(&s.pv.Page): hashes}).
-		// Where(s.ref.Fmt("%s = 0 AND %s = 0", &s.v.IsAdmin, &s.v.IsBot)).
-		GroupBy(s.ref.Ref(&s.pv.Page))
+	s.incUniq(ctx, dailyPageStatsTable, squirrel.Eq{s.ref.Ref(&s.dps.Hash): album, s.ref.Ref(&s.dps.Date): d})
 
-	q := s.st.QueryBuilder().Insert(pageStatsTable).
-		Columns(s.ref.Refs(sqluct.NoTableAll(&s.ps.Hash, &s.ps.Uniq)...)...).
-		Select(sel).
-		Suffix(s.ref.Fmt("ON CONFLICT DO UPDATE SET %s = excluded.%s",
-			sqluct.NoTableAll(&s.ps.Uniq, &s.ps.Uniq)...),
-		)
+	// Visitor is counted once per page over all days.
+	var cnt int
 
-	_, err := q.ExecContext(ctx)
-	if err != nil {
-		s.l.Error(ctx, "failed to update page uniq", "error", err)
-	}
-}
+	q := s.st.QueryBuilder().Select("COUNT(1)").From(pageVisitorsTable).
+		Where(squirrel.Eq{s.ref.Ref(&s.pv.Page): album, s.ref.Ref(&s.pv.Visitor): visitor}).
+		Where(squirrel.NotEq{s.ref.Ref(&s.pv.Date): d})
 
-func (s *StatsRepository) updateDailyPageUniq(ctx context.Context, d int64, hashes ...uniq.Hash) {
-	sel := s.st.QueryBuilder().
-		Select(s.ref.Ref(&s.pv.Page), s.ref.Ref(&s.pv.Date), s.ref.Fmt("count(distinct %s) as uniq", &s.pv.Visitor)).
-		From(pageVisitorsTable).
-		// LeftJoin(s.ref.Fmt(visitorTable+" ON %s = %s", &s.pv.Visitor, &s.v.Hash)).
-		Where(squirrel.Eq{s.ref.Ref(&s.pv.Page): hashes}).
-		Where(squirrel.Eq{s.ref.Ref(&s.pv.Date): d}).
-		// Where(s.ref.Fmt("%s = 0 AND %s = 0", &s.v.IsAdmin, &s.v.IsBot)).
-		GroupBy(s.ref.Ref(&s.pv.Page))
+	if err := s.st.Select(ctx, q, &cnt); err != nil {
+		s.l.Error(ctx, "failed to count page visits", "error", err)
 
-	q := s.st.QueryBuilder().Insert(dailyPageStatsTable).
-		Columns(s.ref.Refs(sqluct.NoTableAll(&s.dps.Hash, &s.dps.Date, &s.dps.Uniq)...)...).
-		Select(sel).
-		Suffix(s.ref.Fmt("ON CONFLICT DO UPDATE SET %s = excluded.%s", sqluct.NoTableAll(&s.dps.Uniq, &s.dps.Uniq)...))
+		return
+	}
 
-	_, err := q.ExecContext(ctx)
-	if err != nil {
-		s.l.Error(ctx, "failed to update daily page uniq", "error", err)
+	if cnt == 0 {
+		s.incUniq(ctx, pageStatsTable, squirrel.Eq{s.ref.Ref(&s.ps.Hash): album})
 	}
 }
 
@@ -464,6 +465,19 @@ func (s *StatsRepository) CollectVisitor(h uniq.Hash, isBot, isAdmin bool, ip st
 		}
 	}
 
+	if s.Anonymous != nil && s.Anonymous() {
+		if s.PrivateIP != nil {
+			v.IP = s.PrivateIP(ip)
+		} else {
+			v.IP = webstats.AnonymizeIP(ip)
+		}
+
+		v.IPAddr = ""
+		v.City = ""
+		v.Latitude = 0
+		v.Longitude = 0
+	}
+
 	v.Hash = h
 	v.CreatedAt = ts
 
@@ -639,8 +653,8 @@ type DPSVisitors struct {
 func (s *StatsRepository) DailyTotal(ctx context.Context, minDate, maxDate time.Time) ([]DPSVisitors, error) {
 	q := s.st.SelectStmt(dailyPageStatsTable, nil).
 		Columns(s.ref.Cols(s.dps)...).
-		Columns(s.ref.Fmt("GROUP_CONCAT(%s) AS visitors", &s.pv.Visitor)).
-		InnerJoin(s.ref.Fmt("%s ON %s = %s AND %s = %s", s.pv,
+		Columns(s.ref.Fmt("COALESCE(GROUP_CONCAT(%s), '') AS visitors", &s.pv.Visitor)).
+		LeftJoin(s.ref.Fmt("%s ON %s = %s AND %s = %s", s.pv,
 			&s.dps.Hash, &s.pv.Page,
 			&s.dps.Date, &s.pv.Date)).
 		Where(squirrel.GtOrEq{s.ref.Ref(&s.dps.Date): dateTs(minDate)}).
@@ -738,3 +752,145 @@ func (s *StatsRepository) AlbumViews(ctx context.Context, hash uniq.Hash) (PageS
 
 	return res, nil
 }
+
+// PruneResult contains number of removed rows per table.
+type PruneResult map[string]int64
+
+// Prune removes visitors not seen since a moment and their activity records collected before it.
+//
+// Aggregated stats are kept intact.
+func (s *StatsRepository) Prune(ctx context.Context, before time.Time) (PruneResult, error) {
+	res := PruneResult{}
+
+	exec := func(table string, q squirrel.Sqlizer) error {
+		r, err := s.st.Exec(ctx, q)
+		if err != nil {
+			return fmt.Errorf("prune %s: %w", table, err)
+		}
+
+		res[table], _ = r.RowsAffected()
+
+		return nil
+	}
+
+	if err := exec(visitorTable, s.st.DeleteStmt(visitorTable).
+		Where(squirrel.Lt{s.ref.Ref(&s.v.LastSeen): before}).
+		Where(squirrel.Eq{s.ref.Ref(&s.v.IsAdmin): 0})); err != nil {
+		return res, err
+	}
+
+	if err := exec(pageVisitorsTable, s.st.DeleteStmt(pageVisitorsTable).
+		Where(squirrel.Lt{s.ref.Ref(&s.pv.Date): dateTs(before)})); err != nil {
+		return res, err
+	}
+
+	if err := exec(refersTable, s.st.DeleteStmt(refersTable).
+		Where(squirrel.Lt{s.ref.Ref(&s.rf.TS): before.Unix()})); err != nil {
+		return res, err
+	}
+
+	// Image visits have no time, so they are removed together with visitors.
+	if err := exec(imageVisitorsTable, s.st.DeleteStmt(imageVisitorsTable).
+		Where(s.ref.Fmt("%s NOT IN (SELECT %s FROM "+visitorTable+")", &s.iv.Visitor, &s.v.Hash))); err != nil {
+		return res, err
+	}
+
+	s.mu.Lock()
+	s.recentVisitors = make(map[uniq.Hash]Visitor)
+	s.mu.Unlock()
+
+	return res, nil
+}
+
+// VisitorData contains all collected records of a single visitor.
+type VisitorData struct {
+	Visitor *Visitor      `json:"visitor,omitempty"`
+	Pages   []PageVisitor `json:"pages"`
+	Images  []uniq.Hash   `json:"images"`
+	Refers  []Refer       `json:"refers"`
+}
+
+// VisitorData returns collected records of a visitor.
+func (s *StatsRepository) VisitorData(ctx context.Context, hash uniq.Hash) (VisitorData, error) {
+	res := VisitorData{}
+
+	v, err := s.VisitorInfo(ctx, hash)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return res, err
+	}
+
+	if err == nil {
+		res.Visitor = &v
+	}
+
+	if res.Pages, err = s.PageVisits(ctx, hash); err != nil {
+		return res, err
+	}
+
+	q := s.st.QueryBuilder().Select(s.ref.Ref(&s.iv.Image)).From(imageVisitorsTable).
+		Where(squirrel.Eq{s.ref.Ref(&s.iv.Visitor): hash})
+
+	if err := s.st.Select(ctx, q, &res.Images); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return res, err
+	}
+
+	q = s.st.SelectStmt(refersTable, res.Refers).
+		Where(squirrel.Eq{s.ref.Ref(&s.rf.Visitor): hash}).
+		OrderByClause(s.ref.Fmt("%s DESC", &s.rf.TS))
+
+	if err := s.st.Select(ctx, q, &res.Refers); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return res, err
+	}
+
+	return res, nil
+}
+
+// EraseVisitor removes all collected records of a visitor.
+//
+// Aggregated stats are kept intact.
+func (s *StatsRepository) EraseVisitor(ctx context.Context, hash uniq.Hash) error {
+	for table, col := range map[string]*uniq.Hash{
+		visitorTable:       &s.v.Hash,
+		pageVisitorsTable:  &s.pv.Visitor,
+		imageVisitorsTable: &s.iv.Visitor,
+		refersTable:        &s.rf.Visitor,
+	} {
+		if _, err := s.st.Exec(ctx, s.st.DeleteStmt(table).Where(squirrel.Eq{s.ref.Ref(col): hash})); err != nil {
+			return fmt.Errorf("erase visitor from %s: %w", table, err)
+		}
+	}
+
+	s.mu.Lock()
+	delete(s.recentVisitors, hash)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// ImageEngagement returns view counters of images, all images with stats are returned if no hashes provided.
+func (s *StatsRepository) ImageEngagement(ctx context.Context, imageHashes ...uniq.Hash) (map[uniq.Hash]webstats.Engagement, error) {
+	var rows []imageStats
+
+	q := s.st.SelectStmt(imageStatsTable, rows).Where(squirrel.NotEq{s.ref.Ref(&s.is.Hash): 0})
+	if len(imageHashes) > 0 {
+		q = q.Where(s.ref.Eq(&s.is.Hash, imageHashes))
+	}
+
+	if err := s.st.Select(ctx, q, &rows); err != nil {
+		return nil, fmt.Errorf("find image stats: %w", err)
+	}
+
+	res := make(map[uniq.Hash]webstats.Engagement, len(rows))
+
+	for _, r := range rows {
+		res[r.Hash] = webstats.Engagement{
+			Uniq:       r.Uniq,
+			Views:      r.Views,
+			ViewMs:     r.ViewMs,
+			Zooms:      r.Zooms,
+			ExposureMs: r.ThumbMs + r.ThumbPrtMs,
+		}
+	}
+
+	return res, nil
+}
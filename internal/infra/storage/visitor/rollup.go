@@ -0,0 +1,370 @@
+package visitor
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/bool64/sqluct"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/pkg/webstats"
+)
+
+const (
+	periodStatsTable     = "period_stats"
+	dailyImageStatsTable = "daily_image_stats"
+	dailyBreakdownTable  = "daily_breakdown"
+)
+
+// Breakdown dimensions.
+const (
+	DimCountry  = "country"
+	DimDevice   = "device"
+	DimScreen   = "screen"
+	DimReferrer = "referrer"
+)
+
+// PeriodStats is a rollup of unique visitors and page views for a day, week or month.
+type PeriodStats struct {
+	Period webstats.Period `db:"period" description:"Period: d, w or m"`
+	Start  int64           `db:"start" description:"Period start date as truncated unix timestamp"`
+	Uniq   int             `db:"uniq" description:"Unique visitors count."`
+	Views  int             `db:"views" description:"Page views count."`
+}
+
+// DailyImageStats is a daily rollup of focused image views.
+type DailyImageStats struct {
+	Hash   uniq.Hash `db:"hash" description:"Image hash, 0 for totals"`
+	Date   int64     `db:"date" description:"Date as truncated unix timestamp"`
+	Views  int       `db:"views" description:"Focused views count."`
+	ViewMs int       `db:"view_ms" description:"Focused view time in ms."`
+	Zooms  int       `db:"zooms" description:"Zoom in count."`
+}
+
+// Breakdown is a daily count of visitors by a dimension value.
+type Breakdown struct {
+	Date     int64  `db:"date" description:"Date as truncated unix timestamp"`
+	Dim      string `db:"dim" description:"Dimension"`
+	Value    string `db:"value" description:"Dimension value"`
+	Visitors int    `db:"visitors" description:"Unique visitors count."`
+	Views    int    `db:"views" description:"Page visits count."`
+}
+
+func (s *StatsRepository) collectDailyImage(ctx context.Context, image uniq.Hash, viewTimeMs int, zoomedIn bool, ts time.Time) {
+	dis := DailyImageStats{
+		Hash:   image,
+		Date:   dateTs(ts),
+		Views:  1,
+		ViewMs: viewTimeMs,
+	}
+
+	if zoomedIn {
+		dis.Zooms = 1
+	}
+
+	_, err := s.st.InsertStmt(dailyImageStatsTable, dis).Suffix(s.collectDailyImageSuffix).ExecContext(ctx)
+	if err != nil {
+		s.l.Error(ctx, "failed to collect daily image stats", "error", err)
+	}
+}
+
+// RollupPending updates rollups for days since the last rollup (or the first visit) until now.
+func (s *StatsRepository) RollupPending(ctx context.Context, now time.Time) error {
+	var last int64
+
+	q := s.st.QueryBuilder().Select(s.ref.Fmt("COALESCE(MAX(%s), 0)", &s.pst.Start)).From(periodStatsTable).
+		Where(squirrel.Eq{s.ref.Ref(&s.pst.Period): webstats.Day})
+	if err := s.st.Select(ctx, q, &last); err != nil {
+		return fmt.Errorf("find last rollup: %w", err)
+	}
+
+	if last == 0 {
+		q = s.st.QueryBuilder().Select(s.ref.Fmt("COALESCE(MIN(%s), 0)", &s.pv.Date)).From(pageVisitorsTable)
+		if err := s.st.Select(ctx, q, &last); err != nil {
+			return fmt.Errorf("find first visit: %w", err)
+		}
+	}
+
+	from := now
+	if last != 0 {
+		from = time.Unix(last, 0)
+	}
+
+	for _, day := range webstats.Day.Starts(from, now) {
+		if err := s.Rollup(ctx, day); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Rollup updates period totals and breakdowns for a day.
+func (s *StatsRepository) Rollup(ctx context.Context, day time.Time) error {
+	for _, p := range []webstats.Period{webstats.Day, webstats.Week, webstats.Month} {
+		if err := s.rollupPeriod(ctx, p, day); err != nil {
+			return err
+		}
+	}
+
+	return s.rollupBreakdowns(ctx, day)
+}
+
+func (s *StatsRepository) rollupPeriod(ctx context.Context, p webstats.Period, day time.Time) error {
+	start, end := p.Start(day), p.Next(day)
+
+	ps := PeriodStats{
+		Period: p,
+		Start:  start.Unix(),
+	}
+
+	q := s.st.QueryBuilder().Select(s.ref.Fmt("COUNT(DISTINCT %s)", &s.pv.Visitor)).From(pageVisitorsTable).
+		Where(squirrel.GtOrEq{s.ref.Ref(&s.pv.Date): start.Unix()}).
+		Where(squirrel.Lt{s.ref.Ref(&s.pv.Date): end.Unix()})
+	if err := s.st.Select(ctx, q, &ps.Uniq); err != nil {
+		return fmt.Errorf("count %s uniq: %w", p, err)
+	}
+
+	q = s.st.QueryBuilder().Select(s.ref.Fmt("COALESCE(SUM(%s), 0)", &s.dps.Views)).From(dailyPageStatsTable).
+		Where(squirrel.GtOrEq{s.ref.Ref(&s.dps.Date): start.Unix()}).
+		Where(squirrel.Lt{s.ref.Ref(&s.dps.Date): end.Unix()})
+	if err := s.st.Select(ctx, q, &ps.Views); err != nil {
+		return fmt.Errorf("count %s views: %w", p, err)
+	}
+
+	// Raw visits may be already removed by retention, so counters never decrease.
+	_, err := s.st.InsertStmt(periodStatsTable, ps).Suffix(s.ref.Fmt(
+		"ON CONFLICT(%s, %s) DO UPDATE SET %s = MAX(%s, excluded.%s), %s = MAX(%s, excluded.%s)",
+		sqluct.NoTableAll(
+			&s.pst.Period, &s.pst.Start,
+			&s.pst.Uniq, &s.pst.Uniq, &s.pst.Uniq,
+			&s.pst.Views, &s.pst.Views, &s.pst.Views,
+		)...,
+	)).ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("store %s rollup: %w", p, err)
+	}
+
+	return nil
+}
+
+type visitorPages struct {
+	Visitor   uniq.Hash `db:"visitor"`
+	Pages     int       `db:"pages"`
+	Country   string    `db:"country"`
+	UserAgent string    `db:"user_agent"`
+	Width     int       `db:"scr_w"`
+}
+
+func (s *StatsRepository) rollupBreakdowns(ctx context.Context, day time.Time) error {
+	start, end := webstats.Day.Start(day), webstats.Day.Next(day)
+
+	var visitors []visitorPages
+
+	q := s.st.QueryBuilder().
+		Select(
+			s.ref.Ref(&s.pv.Visitor),
+			"COUNT(1) AS pages",
+			s.ref.Fmt("COALESCE(%s, '') AS country", &s.v.Country),
+			s.ref.Fmt("COALESCE(%s, '') AS user_agent", &s.v.UserAgent),
+			s.ref.Fmt("COALESCE(%s, 0) AS scr_w", &s.v.ScreenWidth),
+		).
+		From(pageVisitorsTable).
+		LeftJoin(s.ref.Fmt(visitorTable+" ON %s = %s", &s.v.Hash, &s.pv.Visitor)).
+		Where(squirrel.Eq{s.ref.Ref(&s.pv.Date): start.Unix()}).
+		GroupBy(s.ref.Ref(&s.pv.Visitor))
+	if err := s.st.Select(ctx, q, &visitors); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("find daily visitors: %w", err)
+	}
+
+	var refers []Refer
+
+	rq := s.st.SelectStmt(refersTable, refers).
+		Where(squirrel.GtOrEq{s.ref.Ref(&s.rf.TS): start.Unix()}).
+		Where(squirrel.Lt{s.ref.Ref(&s.rf.TS): end.Unix()})
+	if err := s.st.Select(ctx, rq, &refers); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("find daily refers: %w", err)
+	}
+
+	// Raw data is removed by retention, existing rollup is kept then.
+	if len(visitors) == 0 && len(refers) == 0 {
+		return nil
+	}
+
+	type key struct{ dim, value string }
+
+	rows := map[key]*Breakdown{}
+	seen := map[key]map[uniq.Hash]bool{}
+
+	add := func(dim, value string, v uniq.Hash, views int) {
+		if value == "" {
+			value = "unknown"
+		}
+
+		k := key{dim: dim, value: value}
+
+		b := rows[k]
+		if b == nil {
+			b = &Breakdown{Date: start.Unix(), Dim: dim, Value: value}
+			rows[k] = b
+			seen[k] = map[uniq.Hash]bool{}
+		}
+
+		b.Views += views
+
+		if !seen[k][v] {
+			seen[k][v] = true
+			b.Visitors++
+		}
+	}
+
+	for _, v := range visitors {
+		add(DimCountry, v.Country, v.Visitor, v.Pages)
+		add(DimDevice, webstats.DeviceClass(v.UserAgent), v.Visitor, v.Pages)
+		add(DimScreen, webstats.ScreenClass(v.Width), v.Visitor, v.Pages)
+	}
+
+	for _, r := range refers {
+		if d := webstats.RefererDomain(r.Referer); d != "" {
+			add(DimReferrer, d, r.Visitor, 1)
+		}
+	}
+
+	res := make([]Breakdown, 0, len(rows))
+	for _, b := range rows {
+		res = append(res, *b)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Dim != res[j].Dim {
+			return res[i].Dim < res[j].Dim
+		}
+
+		return res[i].Value < res[j].Value
+	})
+
+	if _, err := s.st.Exec(ctx, s.st.DeleteStmt(dailyBreakdownTable).
+		Where(squirrel.Eq{s.ref.Ref(&s.bd.Date): start.Unix()})); err != nil {
+		return fmt.Errorf("clear daily breakdown: %w", err)
+	}
+
+	if _, err := s.st.InsertStmt(dailyBreakdownTable, res).ExecContext(ctx); err != nil {
+		return fmt.Errorf("store daily breakdown: %w", err)
+	}
+
+	return nil
+}
+
+// Periods returns rollups of a period type that start within [from, to].
+func (s *StatsRepository) Periods(ctx context.Context, p webstats.Period, from, to time.Time) ([]PeriodStats, error) {
+	var res []PeriodStats
+
+	q := s.st.SelectStmt(periodStatsTable, res).
+		Where(squirrel.Eq{s.ref.Ref(&s.pst.Period): p}).
+		Where(squirrel.GtOrEq{s.ref.Ref(&s.pst.Start): p.Start(from).Unix()}).
+		Where(squirrel.LtOrEq{s.ref.Ref(&s.pst.Start): to.Unix()}).
+		OrderByClause(s.ref.Ref(&s.pst.Start))
+
+	if err := s.st.Select(ctx, q, &res); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// DailyPages returns daily page stats within [from, to].
+func (s *StatsRepository) DailyPages(ctx context.Context, from, to time.Time) ([]DailyPageStats, error) {
+	var res []DailyPageStats
+
+	q := s.st.SelectStmt(dailyPageStatsTable, res).
+		Where(squirrel.GtOrEq{s.ref.Ref(&s.dps.Date): dateTs(from)}).
+		Where(squirrel.LtOrEq{s.ref.Ref(&s.dps.Date): dateTs(to)}).
+		OrderByClause(s.ref.Ref(&s.dps.Date))
+
+	if err := s.st.Select(ctx, q, &res); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// DailyImages returns daily totals of focused image views within [from, to].
+func (s *StatsRepository) DailyImages(ctx context.Context, from, to time.Time) ([]DailyImageStats, error) {
+	var res []DailyImageStats
+
+	q := s.imageRange(from, to, false).OrderByClause(s.ref.Ref(&s.dis.Date))
+
+	if err := s.st.Select(ctx, q, &res); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// TopImagesInRange returns most viewed images within [from, to].
+func (s *StatsRepository) TopImagesInRange(ctx context.Context, from, to time.Time, limit uint64) ([]DailyImageStats, error) {
+	var res []DailyImageStats
+
+	q := s.imageRange(from, to, true).
+		OrderByClause("views DESC").
+		Limit(limit)
+
+	if err := s.st.Select(ctx, q, &res); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// imageRange sums daily image stats by image or by date.
+func (s *StatsRepository) imageRange(from, to time.Time, byImage bool) squirrel.SelectBuilder {
+	hash, date, groupBy := "0 AS hash", s.ref.Ref(&s.dis.Date), s.ref.Ref(&s.dis.Date)
+	if byImage {
+		hash, date, groupBy = s.ref.Ref(&s.dis.Hash), "0 AS date", s.ref.Ref(&s.dis.Hash)
+	}
+
+	return s.st.QueryBuilder().
+		Select(
+			hash, date,
+			s.ref.Fmt("SUM(%s) AS views", &s.dis.Views),
+			s.ref.Fmt("SUM(%s) AS view_ms", &s.dis.ViewMs),
+			s.ref.Fmt("SUM(%s) AS zooms", &s.dis.Zooms),
+		).
+		From(dailyImageStatsTable).
+		Where(squirrel.GtOrEq{s.ref.Ref(&s.dis.Date): dateTs(from)}).
+		Where(squirrel.LtOrEq{s.ref.Ref(&s.dis.Date): dateTs(to)}).
+		GroupBy(groupBy)
+}
+
+// Breakdowns returns visitors by dimension value within [from, to], most frequent first.
+//
+// Visitors are summed over days, so a visitor returning on another day is counted again.
+func (s *StatsRepository) Breakdowns(ctx context.Context, dim string, from, to time.Time, limit uint64) ([]Breakdown, error) {
+	var res []Breakdown
+
+	q := s.st.QueryBuilder().
+		Select(
+			"0 AS date",
+			s.ref.Ref(&s.bd.Dim),
+			s.ref.Ref(&s.bd.Value),
+			s.ref.Fmt("SUM(%s) AS visitors", &s.bd.Visitors),
+			s.ref.Fmt("SUM(%s) AS views", &s.bd.Views),
+		).
+		From(dailyBreakdownTable).
+		Where(squirrel.Eq{s.ref.Ref(&s.bd.Dim): dim}).
+		Where(squirrel.GtOrEq{s.ref.Ref(&s.bd.Date): dateTs(from)}).
+		Where(squirrel.LtOrEq{s.ref.Ref(&s.bd.Date): dateTs(to)}).
+		GroupBy(s.ref.Ref(&s.bd.Value)).
+		OrderByClause("visitors DESC").
+		Limit(limit)
+
+	if err := s.st.Select(ctx, q, &res); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	return res, nil
+}
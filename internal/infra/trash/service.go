@@ -0,0 +1,118 @@
+// Package trash purges removed album content after retention period.
+package trash
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/bool64/ctxd"
+	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/settings"
+	"github.com/vearutop/photo-blog/internal/infra/storage"
+)
+
+// Deps describes service dependencies.
+type Deps interface {
+	CtxdLogger() ctxd.Logger
+	Settings() settings.Values
+	TrashRepository() *storage.TrashRepository
+
+	PhotoImageFinder() uniq.Finder[photo.Image]
+	PhotoVideoFinder() uniq.Finder[photo.Video]
+	PhotoThumbDeleter() uniq.Deleter[photo.Thumb]
+}
+
+// PurgeResult describes purged content.
+type PurgeResult struct {
+	Images       int `json:"images" description:"Number of images removed from trash."`
+	DeletedFiles int `json:"deleted_files" description:"Number of images deleted from disk."`
+}
+
+// Service purges trash.
+type Service struct {
+	deps Deps
+}
+
+// NewService creates trash service.
+func NewService(deps Deps) *Service {
+	return &Service{deps: deps}
+}
+
+// Purge permanently removes content that was in trash longer than retention period.
+func (s *Service) Purge(ctx context.Context, now time.Time) (PurgeResult, error) {
+	res := PurgeResult{}
+	cfg := s.deps.Settings().Storage()
+
+	if cfg.TrashDays <= 0 {
+		return res, nil
+	}
+
+	purged, err := s.deps.TrashRepository().Purge(ctx, now.AddDate(0, 0, -cfg.TrashDays))
+	if err != nil {
+		return res, err
+	}
+
+	res.Images = len(purged)
+
+	if !cfg.TrashDeleteFiles || len(purged) == 0 {
+		return res, nil
+	}
+
+	unreferenced, err := s.deps.TrashRepository().FindUnreferenced(ctx, purged...)
+	if err != nil {
+		return res, err
+	}
+
+	if len(unreferenced) == 0 {
+		return res, nil
+	}
+
+	images, err := s.deps.PhotoImageFinder().FindByHashes(ctx, unreferenced...)
+	if err != nil {
+		return res, err
+	}
+
+	var deleted []uniq.Hash
+
+	for _, img := range images {
+		if err := s.deleteFiles(ctx, img); err != nil {
+			s.deps.CtxdLogger().Error(ctx, "failed to delete image files", "error", err, "path", img.Path)
+
+			continue
+		}
+
+		deleted = append(deleted, img.Hash)
+	}
+
+	res.DeletedFiles = len(deleted)
+
+	return res, s.deps.TrashRepository().DeleteImageRecords(ctx, deleted...)
+}
+
+func (s *Service) deleteFiles(ctx context.Context, img photo.Image) error {
+	if err := s.deps.PhotoThumbDeleter().Delete(ctx, img.Hash); err != nil {
+		return err
+	}
+
+	files := []string{img.Path}
+
+	// Image of a video is its poster, video file is removed together with it.
+	v, err := s.deps.PhotoVideoFinder().FindByHash(ctx, img.Hash)
+	if err == nil {
+		files = append(files, v.Path)
+	} else if !errors.Is(err, status.NotFound) {
+		return err
+	}
+
+	for _, f := range files {
+		if err := os.Remove(f); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 	"path"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/bool64/ctxd"
 	"github.com/swaggest/rest/web"
@@ -21,6 +22,8 @@ import (
 	"github.com/vearutop/photo-blog/internal/domain/uniq"
 	"github.com/vearutop/photo-blog/internal/infra/auth"
 	"github.com/vearutop/photo-blog/internal/infra/files"
+	"github.com/vearutop/photo-blog/internal/infra/notifier"
+	"github.com/vearutop/photo-blog/pkg/notify"
 	"golang.org/x/exp/slog"
 )
 
@@ -69,7 +72,12 @@ func MountTus(s *web.Service, deps TusHandlerDeps) error {
 			}
 
 			album, err := deps.PhotoAlbumFinder().FindByHash(r.Context(), photo.AlbumHash(albumName))
-			if err != nil || album.Settings.CollabKey != collabKey {
+			if err != nil {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			if _, ok := album.Settings.Collaborator(collabKey, time.Now()); !ok {
 				w.WriteHeader(http.StatusForbidden)
 				return
 			}
@@ -119,6 +127,8 @@ func (up *uploadProcessor) processUpload(deps TusHandlerDeps, event tusd.HookEve
 		return
 	}
 
+	ctx = uploadAuthor(ctx, deps, albumName, event.HTTPRequest.Header)
+
 	albumPath := AlbumPath(albumName)
 	if err := os.MkdirAll(albumPath, 0o700); err != nil {
 		deps.CtxdLogger().Error(ctx, "failed to create album directory", "error", err)
@@ -214,6 +224,15 @@ func (up *uploadProcessor) processUpload(deps TusHandlerDeps, event tusd.HookEve
 				"filePath", filePath)
 		}
 	} else {
+		if c := auth.Collaborator(ctx); c != "" {
+			deps.Notifier().Notify(ctx, notify.Notification{
+				Event: notifier.CollabUpload,
+				Title: "File uploaded to " + albumName + " by " + c,
+				Text:  md["filename"],
+				URL:   deps.Notifier().URL("/" + albumName + "/"),
+			})
+		}
+
 		if len(tw.thumbsLeft) > 0 {
 			up.mu.Lock()
 			defer up.mu.Unlock()
@@ -225,6 +244,26 @@ func (up *uploadProcessor) processUpload(deps TusHandlerDeps, event tusd.HookEve
 	}
 }
 
+// uploadAuthor adds author of an upload to context, hook context does not keep request values.
+func uploadAuthor(ctx context.Context, deps TusHandlerDeps, albumName string, hd http.Header) context.Context {
+	collabKey := hd.Get("X-Collab-Key")
+	if collabKey == "" {
+		// Uploads without collaborator key are only accepted from admin.
+		return auth.SetAdmin(ctx)
+	}
+
+	album, err := deps.PhotoAlbumFinder().FindByHash(ctx, photo.AlbumHash(albumName))
+	if err != nil {
+		return ctx
+	}
+
+	if c, ok := album.Settings.Collaborator(collabKey, time.Now()); ok {
+		return auth.SetCollaborator(ctx, c.Name)
+	}
+
+	return ctx
+}
+
 func AlbumPath(albumName string) string {
 	return path.Join("album", albumName)
 }
@@ -254,6 +293,7 @@ type TusHandlerDeps interface {
 	CtxdLogger() ctxd.Logger
 	FilesProcessor() *files.Processor
 	PhotoAlbumFinder() uniq.Finder[photo.Album]
+	Notifier() *notifier.Service
 }
 
 func TusUploadsButton() template.HTML {
@@ -286,7 +326,7 @@ func TusAlbumHTMLButton(albumName string) template.HTML {
         const uppy = new Uppy.Uppy({ debug: true, autoProceed: false, limit: 1 })
             .use(Dashboard, { 
 				trigger: '#uppyModalOpener', 
-				note: 'JPG, GPX are supported', 
+				note: 'JPG, GPX, MP4, MOV are supported', 
 				proudlyDisplayPoweredByUppy: false,
 			})
             .use(Tus, { 
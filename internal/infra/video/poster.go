@@ -0,0 +1,127 @@
+// Package video provides helpers to index video clips.
+package video
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/bool64/ctxd"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/pkg/mp4meta"
+)
+
+// PosterPath returns path to poster frame of a video, posters are kept with other derivatives of originals.
+func PosterPath(h uniq.Hash) string {
+	return "thumb/poster/" + h.String()[:1] + "/" + h.String() + ".jpg"
+}
+
+// Probe reads video metadata.
+//
+// Container is parsed natively for MP4/MOV files, ffprobe is used as a fallback if it is available in PATH.
+func Probe(ctx context.Context, fn string) (mp4meta.Meta, error) {
+	m, err := mp4meta.ReadFile(fn)
+	if err == nil && m.Width != 0 {
+		return m, nil
+	}
+
+	ffprobe, lErr := exec.LookPath("ffprobe")
+	if lErr != nil {
+		if err != nil {
+			return m, ctxd.WrapError(ctx, err, "read video meta", "path", fn)
+		}
+
+		return m, nil
+	}
+
+	out, pErr := exec.CommandContext(ctx, ffprobe, "-v", "error", "-select_streams", "v:0",
+		"-show_entries", "stream=width,height:format=duration", "-of", "json", fn).Output()
+	if pErr != nil {
+		return m, ctxd.WrapError(ctx, pErr, "ffprobe", "path", fn)
+	}
+
+	var res struct {
+		Streams []struct {
+			Width  int64 `json:"width"`
+			Height int64 `json:"height"`
+		} `json:"streams"`
+		Format struct {
+			Duration string `json:"duration"`
+		} `json:"format"`
+	}
+
+	if err := json.Unmarshal(out, &res); err != nil {
+		return m, ctxd.WrapError(ctx, err, "decode ffprobe output", "path", fn)
+	}
+
+	if len(res.Streams) > 0 {
+		m.Width = res.Streams[0].Width
+		m.Height = res.Streams[0].Height
+	}
+
+	if d, err := strconv.ParseFloat(res.Format.Duration, 64); err == nil {
+		m.Duration = time.Duration(d * float64(time.Second))
+	}
+
+	return m, nil
+}
+
+// ExtractPoster saves a frame of video into JPEG file.
+//
+// If ffmpeg is not available in PATH, a blank placeholder of matching aspect ratio is saved instead.
+func ExtractPoster(ctx context.Context, fn, posterFn string, m mp4meta.Meta) error {
+	ffmpeg, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return placeholder(posterFn, m)
+	}
+
+	// Skip the very first frame, it is often black.
+	at := m.Duration / 10
+	if at > time.Second {
+		at = time.Second
+	}
+
+	stderr := bytes.NewBuffer(nil)
+	cmd := exec.CommandContext(ctx, ffmpeg, "-y", "-v", "error",
+		"-ss", strconv.FormatFloat(at.Seconds(), 'f', 3, 64),
+		"-i", fn, "-frames:v", "1", "-q:v", "2", posterFn)
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		return ctxd.WrapError(ctx, err, "extract poster frame", "path", fn, "stderr", stderr.String())
+	}
+
+	return nil
+}
+
+func placeholder(posterFn string, m mp4meta.Meta) error {
+	w, h := 1280, 720
+	if m.Width > 0 && m.Height > 0 {
+		h = int(int64(w) * m.Height / m.Width)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: color.RGBA{R: 0x20, G: 0x20, B: 0x20, A: 0xff}}, image.Point{}, draw.Src)
+
+	f, err := os.Create(posterFn)
+	if err != nil {
+		return fmt.Errorf("create poster: %w", err)
+	}
+
+	if err := jpeg.Encode(f, img, &jpeg.Options{Quality: 85}); err != nil {
+		_ = f.Close()
+
+		return fmt.Errorf("encode poster: %w", err)
+	}
+
+	return f.Close()
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 
 	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
 	"github.com/vearutop/photo-blog/internal/domain/uniq"
 	"github.com/vearutop/photo-blog/internal/infra/auth"
 	"github.com/vearutop/photo-blog/internal/infra/storage"
@@ -17,6 +18,7 @@ type FavoriteDeps interface {
 func AddFavorite(deps FavoriteDeps) usecase.Interactor {
 	type AddFavorite struct {
 		ImageHash uniq.Hash `query:"image_hash"`
+		ListHash  uniq.Hash `query:"list_hash" description:"Named list, default list is used if empty."`
 	}
 
 	u := usecase.NewInteractor(func(ctx context.Context, input AddFavorite, output *struct{}) error {
@@ -26,8 +28,30 @@ func AddFavorite(deps FavoriteDeps) usecase.Interactor {
 			return errors.New("missing visitor hash")
 		}
 
-		return deps.FavoriteRepository().AddImages(ctx, visitorHash, input.ImageHash)
+		if err := checkFavoriteList(ctx, deps, visitorHash, input.ListHash); err != nil {
+			return err
+		}
+
+		return deps.FavoriteRepository().AddImages(ctx, visitorHash, input.ListHash, input.ImageHash)
 	})
 
 	return u
 }
+
+// checkFavoriteList fails if named list does not belong to visitor.
+func checkFavoriteList(ctx context.Context, deps FavoriteDeps, visitorHash, listHash uniq.Hash) error {
+	if listHash == 0 {
+		return nil
+	}
+
+	l, err := deps.FavoriteRepository().FindList(ctx, listHash)
+	if err != nil {
+		return err
+	}
+
+	if l.VisitorHash != visitorHash {
+		return status.PermissionDenied
+	}
+
+	return nil
+}
@@ -1,45 +1,141 @@
 package usecase
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/bool64/ctxd"
 	"github.com/bool64/sqluct"
+	"github.com/swaggest/rest/request"
 	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
 	"github.com/vearutop/photo-blog/internal/domain/comment"
+	"github.com/vearutop/photo-blog/internal/domain/event"
 	"github.com/vearutop/photo-blog/internal/domain/site"
 	"github.com/vearutop/photo-blog/internal/domain/uniq"
 	"github.com/vearutop/photo-blog/internal/infra/auth"
+	"github.com/vearutop/photo-blog/internal/infra/events"
+	"github.com/vearutop/photo-blog/internal/infra/notifier"
 	"github.com/vearutop/photo-blog/internal/infra/service"
+	"github.com/vearutop/photo-blog/internal/infra/settings"
+	"github.com/vearutop/photo-blog/pkg/notify"
+)
+
+// Defaults of comments settings.
+const (
+	defaultCommentsRateLimit     = 5
+	defaultCommentsMaxLength     = 2000
+	defaultCommentsSpamThreshold = 0.7
 )
 
 type addMessageDeps interface {
+	CtxdLogger() ctxd.Logger
+	Settings() settings.Values
+	CommentSpamScorer() comment.SpamScorer
+	Notifier() *notifier.Service
+	EventBus() *events.Bus
+
 	service.SiteVisitorFinderProvider
 	service.SiteVisitorEnsurerProvider
 	service.CommentThreadEnsurerProvider
 	service.CommentMessageEnsurerProvider
+	service.CommentMessageFinderProvider
+	service.CommentMessageRepositoryProvider
 }
 
 func AddMessage(deps addMessageDeps) usecase.Interactor {
 	type addMessageRequest struct {
+		request.EmbeddedSetter
+
 		Name        string     `json:"name"`
 		Type        string     `json:"type" enum:"image,album"`
 		RelatedHash uniq.Hash  `json:"related_hash"`
 		RelatedAt   *time.Time `json:"related_at"`
+		ParentHash  uniq.Hash  `json:"parent_hash" description:"Message to reply to."`
 		Text        string     `json:"text"`
 	}
 
 	u := usecase.NewInteractor(func(ctx context.Context, input addMessageRequest, output *comment.Message) (err error) {
+		cfg := deps.Settings().Comments()
+		if !cfg.Enabled {
+			return status.Wrap(errors.New("comments are disabled"), status.FailedPrecondition)
+		}
+
+		visitorHash := auth.VisitorFromContext(ctx)
+		if visitorHash == 0 {
+			return status.Wrap(errors.New("missing visitor hash"), status.PermissionDenied)
+		}
+
+		visitor, err := deps.SiteVisitorFinder().FindByHash(ctx, visitorHash)
+		if err != nil && !errors.Is(err, status.NotFound) {
+			return err
+		}
+
+		if visitor.Banned {
+			return status.PermissionDenied
+		}
+
+		text, err := messageText(input.Text, cfg)
+		if err != nil {
+			return err
+		}
+
+		ip := auth.ClientIP(input.Request(), deps.Settings().Visitors().TrustedProxies)
+		if deps.Settings().Visitors().PrivacyMode {
+			// Salted network part of address is enough to limit message rate.
+			ip = auth.PrivateIP(ip)
+		}
+
+		limit := cfg.RateLimit
+		if limit == 0 {
+			limit = defaultCommentsRateLimit
+		}
+
+		cnt, err := deps.CommentMessageRepository().CountSince(ctx, visitorHash, ip, time.Now().Add(-time.Hour))
+		if err != nil {
+			return err
+		}
+
+		if cnt >= limit {
+			return status.Wrap(errors.New("too many messages, please try again later"), status.ResourceExhausted)
+		}
+
 		thread := comment.Thread{
 			Type:        input.Type,
 			RelatedHash: input.RelatedHash,
 			RelatedAt:   input.RelatedAt,
 		}
-		th := input.Type + input.RelatedHash.String()
-		if input.RelatedAt != nil {
-			th += input.RelatedAt.String()
+		thread.Hash = comment.ThreadHash(input.Type, input.RelatedHash, input.RelatedAt)
+
+		message := comment.Message{}
+		message.ThreadHash = thread.Hash
+		message.VisitorHash = visitorHash
+		message.IP = ip
+		message.Text = text
+
+		if input.ParentHash != 0 {
+			parent, err := deps.CommentMessageFinder().FindByHash(ctx, input.ParentHash)
+			if err != nil {
+				return fmt.Errorf("find parent message: %w", err)
+			}
+
+			if parent.ThreadHash != thread.Hash || parent.Rejected {
+				return status.Wrap(errors.New("invalid parent message"), status.InvalidArgument)
+			}
+
+			// Replies are kept one level deep.
+			message.ParentHash = parent.Hash
+			if parent.ParentHash != 0 {
+				message.ParentHash = parent.ParentHash
+			}
 		}
-		thread.Hash = uniq.StringHash(th)
+
+		moderateMessage(ctx, deps, cfg, visitor, input.Name, input.Request().UserAgent(), &message)
 
 		if _, err := deps.CommentThreadEnsurer().Ensure(ctx, thread, uniq.EnsureOption[comment.Thread]{
 			Prepare: func(candidate *comment.Thread, existing *comment.Thread) (skipUpdate bool) {
@@ -49,12 +145,11 @@ func AddMessage(deps addMessageDeps) usecase.Interactor {
 			return err
 		}
 
-		visitor := auth.VisitorFromContext(ctx)
-		message := comment.Message{}
-		message.Hash = uniq.StringHash(thread.Hash.String() + visitor.String() + input.Text)
-		message.ThreadHash = thread.Hash
-		message.VisitorHash = visitor
-		message.Text = input.Text
+		h := thread.Hash.String() + visitorHash.String() + text
+		if message.ParentHash != 0 {
+			h += message.ParentHash.String()
+		}
+		message.Hash = uniq.StringHash(h)
 
 		*output, err = deps.CommentMessageEnsurer().Ensure(ctx, message, uniq.EnsureOption[comment.Message]{
 			Prepare: func(candidate *comment.Message, existing *comment.Message) (skipUpdate bool) {
@@ -65,9 +160,27 @@ func AddMessage(deps addMessageDeps) usecase.Interactor {
 			return err
 		}
 
-		if input.Name != "" {
+		if output.Approved {
+			deps.EventBus().Publish(ctx, event.Comment(event.CommentApproved, output.Hash))
+		}
+
+		if output.Pending() {
+			name := input.Name
+			if name == "" {
+				name = visitor.Name
+			}
+
+			deps.Notifier().Notify(ctx, notify.Notification{
+				Event: notifier.MessagePending,
+				Title: "New message from " + cmp.Or(name, "guest"),
+				Text:  text,
+				URL:   deps.Notifier().URL("/comments/inbox.html"),
+			})
+		}
+
+		if input.Name != "" && input.Name != visitor.Name {
 			v := site.Visitor{Name: input.Name}
-			v.Hash = visitor
+			v.Hash = visitorHash
 
 			_, err = deps.SiteVisitorEnsurer().Ensure(ctx, v, uniq.EnsureOption[site.Visitor]{
 				OnUpdate: func(st sqluct.StorageOf[site.Visitor], o *sqluct.Options) {
@@ -79,5 +192,61 @@ func AddMessage(deps addMessageDeps) usecase.Interactor {
 		return err
 	})
 
+	u.SetTags("Comments")
+	u.SetExpectedErrors(status.InvalidArgument, status.PermissionDenied, status.FailedPrecondition, status.ResourceExhausted)
+
 	return u
 }
+
+func messageText(text string, cfg settings.Comments) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", status.Wrap(errors.New("empty message"), status.InvalidArgument)
+	}
+
+	maxLength := cfg.MaxLength
+	if maxLength == 0 {
+		maxLength = defaultCommentsMaxLength
+	}
+
+	if utf8.RuneCountInString(text) > maxLength {
+		return "", status.Wrap(fmt.Errorf("message is longer than %d characters", maxLength), status.InvalidArgument)
+	}
+
+	return text, nil
+}
+
+// moderateMessage sets spam score and initial moderation status.
+func moderateMessage(
+	ctx context.Context,
+	deps addMessageDeps,
+	cfg settings.Comments,
+	visitor site.Visitor,
+	name, userAgent string,
+	message *comment.Message,
+) {
+	score, err := deps.CommentSpamScorer().SpamScore(ctx, comment.SpamCandidate{
+		Message: *message,
+		Name:    name,
+		Agent:   userAgent,
+	})
+	if err != nil {
+		deps.CtxdLogger().Error(ctx, "failed to score message", "error", err)
+	}
+
+	threshold := cfg.SpamThreshold
+	if threshold == 0 {
+		threshold = defaultCommentsSpamThreshold
+	}
+
+	message.SpamScore = score
+	message.Approved = false
+	message.Rejected = false
+
+	switch {
+	case score >= threshold:
+		message.Rejected = true
+	case cfg.AutoApprove && visitor.Approved:
+		message.Approved = true
+	}
+}
@@ -24,6 +24,11 @@ func CollectStats(deps collectStatsDeps) usecase.Interactor {
 			return nil
 		}
 
+		// Visitor recognized by middleware takes precedence, it is the only source in cookieless mode.
+		if v := auth.VisitorFromContext(ctx); v != 0 {
+			input.Visitor = v
+		}
+
 		deps.VisitorStats().CollectRequest(ctx, input, time.Now())
 
 		return nil
@@ -13,6 +13,7 @@ import (
 	"github.com/vearutop/photo-blog/internal/domain/photo"
 	"github.com/vearutop/photo-blog/internal/domain/uniq"
 	"github.com/vearutop/photo-blog/internal/infra/dep"
+	"github.com/vearutop/photo-blog/pkg/trackfile"
 )
 
 type addDirectoryDeps interface {
@@ -87,7 +88,7 @@ func AddDirectory(deps addDirectoryDeps, indexer usecase.IOInteractorOf[indexAlb
 				}
 			}
 
-			if strings.HasSuffix(lName, ".gpx") {
+			if trackfile.Supported(lName) {
 				d := photo.Gpx{}
 				if err := d.SetPath(ctx, path.Join(in.Path, name)); err != nil {
 					errs = append(errs, name+": "+err.Error())
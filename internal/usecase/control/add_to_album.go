@@ -16,9 +16,11 @@ import (
 	"github.com/bool64/stats"
 	"github.com/swaggest/usecase"
 	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/domain/event"
 	"github.com/vearutop/photo-blog/internal/domain/photo"
 	"github.com/vearutop/photo-blog/internal/domain/uniq"
 	"github.com/vearutop/photo-blog/internal/infra/dep"
+	"github.com/vearutop/photo-blog/internal/infra/events"
 	"github.com/vearutop/photo-blog/internal/infra/files"
 	"github.com/vearutop/photo-blog/internal/infra/image"
 	"github.com/vearutop/photo-blog/internal/infra/upload"
@@ -39,6 +41,7 @@ type addToAlbumDeps interface {
 	FilesProcessor() *files.Processor
 
 	DepCache() *dep.Cache
+	EventBus() *events.Bus
 }
 type addToAlbumInput struct {
 	DstAlbumName        string    `path:"name" description:"Name of destination album to add photo."`
@@ -68,7 +71,15 @@ func AddToAlbum(deps addToAlbumDeps) usecase.Interactor {
 					imgHashes = append(imgHashes, img.Hash)
 				}
 
-				return deps.PhotoAlbumImageAdder().AddImages(ctx, dstAlbum.Hash, imgHashes...)
+				if err := deps.PhotoAlbumImageAdder().AddImages(ctx, dstAlbum.Hash, imgHashes...); err != nil {
+					return err
+				}
+
+				for _, h := range imgHashes {
+					deps.EventBus().Publish(ctx, event.Image(event.ImageAdded, dstAlbum.Name, h))
+				}
+
+				return nil
 			}
 		}
 
@@ -79,6 +90,9 @@ func AddToAlbum(deps addToAlbumDeps) usecase.Interactor {
 			}
 
 			err = deps.PhotoAlbumImageAdder().AddImages(ctx, dstAlbum.Hash, img.Hash)
+			if err == nil {
+				deps.EventBus().Publish(ctx, event.Image(event.ImageAdded, dstAlbum.Name, img.Hash))
+			}
 		}
 
 		if in.SrcImageURL != "" {
@@ -0,0 +1,37 @@
+package control
+
+import (
+	"context"
+
+	"github.com/swaggest/usecase"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/infra/storage"
+)
+
+type albumAuditDeps interface {
+	AuditRepository() *storage.AuditRepository
+}
+
+// GetAlbumAudit creates use case interactor to show who changed album content.
+func GetAlbumAudit(deps albumAuditDeps) usecase.Interactor {
+	type getAlbumAudit struct {
+		AlbumName string `path:"name"`
+		Limit     uint64 `query:"limit" default:"100" maximum:"10000"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, in getAlbumAudit, out *[]photo.AuditEntry) error {
+		if in.Limit == 0 {
+			in.Limit = 100
+		}
+
+		var err error
+
+		*out, err = deps.AuditRepository().Find(ctx, photo.AlbumHash(in.AlbumName), in.Limit)
+
+		return err
+	})
+
+	u.SetTags("Album")
+
+	return u
+}
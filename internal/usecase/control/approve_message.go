@@ -6,8 +6,10 @@ import (
 	"github.com/bool64/sqluct"
 	"github.com/swaggest/usecase"
 	"github.com/vearutop/photo-blog/internal/domain/comment"
+	"github.com/vearutop/photo-blog/internal/domain/event"
 	"github.com/vearutop/photo-blog/internal/domain/site"
 	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/events"
 	"github.com/vearutop/photo-blog/internal/infra/service"
 )
 
@@ -15,6 +17,8 @@ type approveMessageDeps interface {
 	service.SiteVisitorEnsurerProvider
 	service.CommentMessageFinderProvider
 	service.CommentMessageEnsurerProvider
+
+	EventBus() *events.Bus
 }
 
 func ApproveMessage(deps approveMessageDeps) usecase.Interactor {
@@ -49,8 +53,13 @@ func ApproveMessage(deps approveMessageDeps) usecase.Interactor {
 				o.Columns = []string{st.Col(&st.R.Approved)}
 			},
 		})
+		if err != nil {
+			return err
+		}
+
+		deps.EventBus().Publish(ctx, event.Comment(event.CommentApproved, message.Hash))
 
-		return err
+		return nil
 	})
 
 	return u
@@ -0,0 +1,108 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/bool64/ctxd"
+	"github.com/swaggest/rest/request"
+	"github.com/swaggest/rest/response"
+	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/infra/archive"
+	"github.com/vearutop/photo-blog/pkg/backup"
+)
+
+type backupDeps interface {
+	CtxdLogger() ctxd.Logger
+	Archive() *archive.Service
+}
+
+// ExportSite downloads a portable backup archive.
+func ExportSite(deps backupDeps) usecase.Interactor {
+	u := usecase.NewInteractor(func(ctx context.Context, in archive.ExportOptions, out *response.EmbeddedSetter) error {
+		rw := out.ResponseWriter()
+
+		rw.Header().Set("Content-Type", "application/zip")
+		rw.Header().Set("Content-Disposition",
+			`attachment; filename="photo-blog-`+time.Now().Format("2006-01-02")+`.zip"`)
+
+		if err := deps.Archive().Export(ctx, rw, in); err != nil {
+			// Headers are already sent, so error can only be logged.
+			deps.CtxdLogger().Error(ctx, "failed to export site", "error", err)
+		}
+
+		return nil
+	})
+
+	u.SetTags("Backup")
+
+	return u
+}
+
+// ImportSite restores a backup archive uploaded in request body or available on server.
+func ImportSite(deps backupDeps) usecase.Interactor {
+	type importSiteInput struct {
+		request.EmbeddedSetter
+
+		Path      string   `query:"path" description:"Path to archive on server, request body is used if empty."`
+		Remap     []string `query:"remap" description:"Path prefix replacements in old=new format, applied to original files."`
+		Originals bool     `query:"originals" description:"Write original files from archive, existing files are kept."`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, in importSiteInput, out *archive.ImportResult) error {
+		paths, err := backup.ParsePathMap(in.Remap...)
+		if err != nil {
+			return status.Wrap(err, status.InvalidArgument)
+		}
+
+		p := in.Path
+
+		if p == "" {
+			f, err := os.CreateTemp("temp", "import-*.zip")
+			if err != nil {
+				return err
+			}
+
+			defer func() {
+				if err := os.Remove(f.Name()); err != nil {
+					deps.CtxdLogger().Warn(ctx, "failed to remove uploaded archive", "error", err)
+				}
+			}()
+
+			_, err = io.Copy(f, in.Request().Body)
+			if err := errors.Join(err, f.Close()); err != nil {
+				return fmt.Errorf("receive archive: %w", err)
+			}
+
+			p = f.Name()
+		}
+
+		f, err := os.Open(p)
+		if err != nil {
+			return status.Wrap(err, status.InvalidArgument)
+		}
+		defer f.Close()
+
+		st, err := f.Stat()
+		if err != nil {
+			return err
+		}
+
+		*out, err = deps.Archive().Import(ctx, f, st.Size(), archive.ImportOptions{
+			Paths:     paths,
+			Originals: in.Originals,
+		})
+
+		return err
+	})
+
+	u.SetTags("Backup")
+	u.SetExpectedErrors(status.InvalidArgument)
+
+	return u
+}
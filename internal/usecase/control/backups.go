@@ -0,0 +1,89 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/infra/backups"
+	"github.com/vearutop/photo-blog/pkg/sqlitebackup"
+	"github.com/vearutop/photo-blog/pkg/web"
+	"github.com/vearutop/photo-blog/resources/static"
+)
+
+type backupsDeps interface {
+	DBBackups() *backups.Service
+}
+
+// ShowBackups renders status of database backups.
+func ShowBackups(deps backupsDeps) usecase.Interactor {
+	type backupSet struct {
+		sqlitebackup.Set
+
+		SizeMB string
+	}
+
+	type backupsPage struct {
+		Title  string
+		Status backups.Status
+		Sets   []backupSet
+	}
+
+	tmpl := static.MustParseTemplate("backups.html")
+
+	u := usecase.NewInteractor(func(ctx context.Context, in struct{}, out *web.Page) error {
+		st, err := deps.DBBackups().Status()
+		if err != nil {
+			return err
+		}
+
+		d := backupsPage{Title: "Backups", Status: st}
+
+		for _, s := range st.Sets {
+			d.Sets = append(d.Sets, backupSet{Set: s, SizeMB: fmt.Sprintf("%.1f", float64(s.Size)/(1<<20))})
+		}
+
+		return out.Render(tmpl, d)
+	})
+
+	u.SetTags("Backup")
+	u.SetExpectedErrors(status.Unknown)
+
+	return u
+}
+
+// RunBackup makes a backup of all databases.
+func RunBackup(deps backupsDeps) usecase.Interactor {
+	u := usecase.NewInteractor(func(ctx context.Context, in struct{}, out *sqlitebackup.Set) (err error) {
+		*out, err = deps.DBBackups().Run(ctx)
+		if errors.Is(err, backups.ErrRunning) {
+			return status.Wrap(err, status.FailedPrecondition)
+		}
+
+		return err
+	})
+
+	u.SetTags("Backup")
+	u.SetExpectedErrors(status.FailedPrecondition)
+
+	return u
+}
+
+// VerifyBackup checks integrity of the latest backup.
+func VerifyBackup(deps backupsDeps) usecase.Interactor {
+	u := usecase.NewInteractor(func(ctx context.Context, in struct{}, out *backups.Verification) (err error) {
+		*out, err = deps.DBBackups().Verify(ctx)
+		if err != nil {
+			return status.Wrap(err, status.FailedPrecondition)
+		}
+
+		return nil
+	})
+
+	u.SetTags("Backup")
+	u.SetExpectedErrors(status.FailedPrecondition)
+
+	return u
+}
@@ -0,0 +1,214 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bool64/ctxd"
+	"github.com/bool64/sqluct"
+	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/domain/comment"
+	"github.com/vearutop/photo-blog/internal/domain/event"
+	"github.com/vearutop/photo-blog/internal/domain/site"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/events"
+	"github.com/vearutop/photo-blog/internal/infra/service"
+	"github.com/vearutop/photo-blog/pkg/web"
+	"github.com/vearutop/photo-blog/resources/static"
+)
+
+const inboxLimit = 500
+
+type commentsInboxDeps interface {
+	CtxdLogger() ctxd.Logger
+
+	service.PhotoAlbumFinderProvider
+	service.PhotoAlbumImageFinderProvider
+	service.SiteVisitorFinderProvider
+	service.CommentThreadFinderProvider
+	service.CommentMessageFinderProvider
+	service.CommentMessageRepositoryProvider
+}
+
+type inboxMessage struct {
+	comment.Message
+
+	VisitorName string
+	ParentText  string
+	Subject     string
+	SubjectURL  string
+	Thumb       string
+}
+
+// ShowCommentsInbox renders messages waiting for moderation.
+func ShowCommentsInbox(deps commentsInboxDeps) usecase.Interactor {
+	type inboxPage struct {
+		Title    string
+		Messages []inboxMessage
+	}
+
+	tmpl := static.MustParseTemplate("comments_inbox.html")
+
+	u := usecase.NewInteractor(func(ctx context.Context, in struct{}, out *web.Page) error {
+		pending, err := deps.CommentMessageRepository().FindPending(ctx, inboxLimit)
+		if err != nil {
+			return err
+		}
+
+		d := inboxPage{Title: "Comments Inbox"}
+
+		for _, m := range pending {
+			im := inboxMessage{Message: m}
+
+			if v, err := deps.SiteVisitorFinder().FindByHash(ctx, m.VisitorHash); err == nil {
+				im.VisitorName = v.Name
+			}
+
+			if m.ParentHash != 0 {
+				if p, err := deps.CommentMessageFinder().FindByHash(ctx, m.ParentHash); err == nil {
+					im.ParentText = p.Text
+				}
+			}
+
+			if err := messageSubject(ctx, deps, &im); err != nil {
+				deps.CtxdLogger().Warn(ctx, "failed to find message subject",
+					"error", err, "thread", m.ThreadHash)
+			}
+
+			d.Messages = append(d.Messages, im)
+		}
+
+		return out.Render(tmpl, d)
+	})
+
+	u.SetTags("Comments")
+	u.SetExpectedErrors(status.Unknown)
+
+	return u
+}
+
+func messageSubject(ctx context.Context, deps commentsInboxDeps, im *inboxMessage) error {
+	th, err := deps.CommentThreadFinder().FindByHash(ctx, im.ThreadHash)
+	if err != nil {
+		return err
+	}
+
+	switch th.Type {
+	case comment.ThreadAlbum:
+		a, err := deps.PhotoAlbumFinder().FindByHash(ctx, th.RelatedHash)
+		if err != nil {
+			return err
+		}
+
+		im.Subject = a.Title
+		im.SubjectURL = "/" + a.Name + "/"
+	case comment.ThreadImage:
+		im.Subject = "Photo " + th.RelatedHash.String()
+		im.Thumb = "/thumb/300w/" + th.RelatedHash.String() + ".jpg"
+
+		albums, err := deps.PhotoAlbumImageFinder().FindImageAlbums(ctx, 0, th.RelatedHash)
+		if err != nil {
+			return err
+		}
+
+		if aa := albums[th.RelatedHash]; len(aa) > 0 {
+			im.Subject = aa[0].Title
+			im.SubjectURL = "/" + aa[0].Name + "/photo-" + th.RelatedHash.String() + ".html"
+		}
+	default:
+		im.Subject = th.Type
+	}
+
+	return nil
+}
+
+// Moderation actions.
+const (
+	moderationApprove = "approve"
+	moderationReject  = "reject"
+	moderationBan     = "ban"
+)
+
+type moderateMessagesDeps interface {
+	CtxdLogger() ctxd.Logger
+	EventBus() *events.Bus
+
+	service.SiteVisitorEnsurerProvider
+	service.CommentMessageFinderProvider
+	service.CommentMessageRepositoryProvider
+}
+
+// ModerateMessages applies moderation action to a batch of messages.
+func ModerateMessages(deps moderateMessagesDeps) usecase.Interactor {
+	type moderateMessagesInput struct {
+		Action string      `json:"action" enum:"approve,reject,ban" required:"true" description:"Ban rejects messages and all pending messages of their authors, further messages of banned visitors are refused."`
+		Hashes []uniq.Hash `json:"hashes" required:"true" minItems:"1"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, in moderateMessagesInput, out *struct{}) error {
+		repo := deps.CommentMessageRepository()
+
+		switch in.Action {
+		case moderationApprove:
+			if err := repo.SetStatus(ctx, true, false, in.Hashes...); err != nil {
+				return err
+			}
+
+			for _, h := range in.Hashes {
+				deps.EventBus().Publish(ctx, event.Comment(event.CommentApproved, h))
+			}
+
+			return nil
+		case moderationReject:
+			return repo.SetStatus(ctx, false, true, in.Hashes...)
+		case moderationBan:
+		default:
+			return status.Wrap(fmt.Errorf("unknown action: %s", in.Action), status.InvalidArgument)
+		}
+
+		messages, err := deps.CommentMessageFinder().FindByHashes(ctx, in.Hashes...)
+		if err != nil {
+			return err
+		}
+
+		if len(messages) == 0 {
+			return status.Wrap(errors.New("messages not found"), status.NotFound)
+		}
+
+		banned := map[uniq.Hash]bool{}
+
+		for _, m := range messages {
+			if m.VisitorHash == 0 || banned[m.VisitorHash] {
+				continue
+			}
+
+			banned[m.VisitorHash] = true
+
+			v := site.Visitor{Banned: true}
+			v.Hash = m.VisitorHash
+
+			if _, err := deps.SiteVisitorEnsurer().Ensure(ctx, v, uniq.EnsureOption[site.Visitor]{
+				OnUpdate: func(st sqluct.StorageOf[site.Visitor], o *sqluct.Options) {
+					o.Columns = []string{st.Col(&st.R.Banned), st.Col(&st.R.Approved)}
+				},
+			}); err != nil {
+				return fmt.Errorf("ban visitor %s: %w", m.VisitorHash, err)
+			}
+
+			if err := repo.RejectVisitor(ctx, m.VisitorHash); err != nil {
+				return fmt.Errorf("reject messages of %s: %w", m.VisitorHash, err)
+			}
+
+			deps.CtxdLogger().Important(ctx, "visitor banned", "visitor", m.VisitorHash)
+		}
+
+		return repo.SetStatus(ctx, false, true, in.Hashes...)
+	})
+
+	u.SetTags("Comments")
+	u.SetExpectedErrors(status.InvalidArgument, status.NotFound)
+
+	return u
+}
@@ -0,0 +1,239 @@
+package control_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/bool64/brick/database"
+	"github.com/bool64/ctxd"
+	"github.com/bool64/sqluct"
+	"github.com/bool64/stats"
+	"github.com/stretchr/testify/require"
+	"github.com/swaggest/usecase"
+	"github.com/vearutop/photo-blog/internal/domain/event"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/auth"
+	"github.com/vearutop/photo-blog/internal/infra/dep"
+	"github.com/vearutop/photo-blog/internal/infra/events"
+	"github.com/vearutop/photo-blog/internal/infra/settings"
+	"github.com/vearutop/photo-blog/internal/infra/storage"
+	"github.com/vearutop/photo-blog/internal/infra/storage/sqlite"
+	"github.com/vearutop/photo-blog/internal/infra/storage/sqlite_thumbs"
+	"github.com/vearutop/photo-blog/internal/infra/trash"
+	"github.com/vearutop/photo-blog/pkg/qlite"
+	"github.com/vearutop/photo-blog/pkg/sqlitec/invalidation"
+	_ "modernc.org/sqlite"
+)
+
+// testStorage returns database with applied migrations.
+func testStorage(t *testing.T, name string, migrations fs.FS) *sqluct.Storage {
+	t.Helper()
+
+	cfg := database.Config{
+		DriverName:      "sqlite",
+		DSN:             filepath.Join(t.TempDir(), name+".sqlite") + "?_time_format=sqlite",
+		ApplyMigrations: true,
+		MaxOpen:         1,
+		MaxIdle:         1,
+	}
+
+	st, err := database.SetupStorageDSN(cfg, ctxd.NoOpLogger{}, stats.NoOp{}, migrations)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, st.DB().DB.Close())
+	})
+
+	return st
+}
+
+// testDeps provides repositories and services of test storage to use cases.
+type testDeps struct {
+	*storage.AlbumRepository
+
+	st       *sqluct.Storage
+	images   *storage.ImageRepository
+	videos   *storage.VideoRepository
+	thumbs   *storage.ThumbRepository
+	trash    *storage.TrashRepository
+	audit    *storage.AuditRepository
+	broker   *qlite.Broker
+	depCache *dep.Cache
+	settings *settings.Manager
+	bus      *events.Bus
+	purger   *trash.Service
+}
+
+func newTestDeps(t *testing.T) *testDeps {
+	t.Helper()
+
+	st := testStorage(t, "db", sqlite.Migrations)
+
+	d := &testDeps{
+		st:     st,
+		images: storage.NewImageRepository(st),
+		videos: storage.NewVideoRepository(st),
+		thumbs: storage.NewThumbRepository(testStorage(t, "thumbs", sqlite_thumbs.Migrations), nil, ctxd.NoOpLogger{}, stats.NoOp{}),
+		trash:  storage.NewTrashRepository(st),
+		audit:  storage.NewAuditRepository(st),
+		broker: qlite.NewBroker(testStorage(t, "queue", qlite.Migrations)),
+	}
+
+	t.Cleanup(d.broker.Close)
+
+	d.AlbumRepository = storage.NewAlbumRepository(st, d.images, storage.NewMetaRepository(st))
+	d.depCache = dep.NewCache(d, testStorage(t, "cache", invalidation.Migrations))
+
+	var err error
+
+	d.settings, err = settings.NewManager(storage.NewSettingsRepository(st), d.depCache)
+	require.NoError(t, err)
+
+	d.bus, err = events.NewBus(ctxd.NoOpLogger{}, d.settings, d.broker)
+	require.NoError(t, err)
+
+	d.purger = trash.NewService(d)
+
+	d.bus.OnPublish = func(ctx context.Context, e event.Event) {
+		require.NoError(t, d.audit.AddEvent(ctx, e, auth.Actor(ctx)))
+	}
+
+	return d
+}
+
+func (d *testDeps) CtxdLogger() ctxd.Logger {
+	return ctxd.NoOpLogger{}
+}
+
+func (d *testDeps) StatsTracker() stats.Tracker {
+	return stats.NoOp{}
+}
+
+func (d *testDeps) QueueBroker() *qlite.Broker {
+	return d.broker
+}
+
+func (d *testDeps) Settings() settings.Values {
+	return d.settings
+}
+
+func (d *testDeps) PhotoImageFinder() uniq.Finder[photo.Image] {
+	return d.images.PhotoImageFinder()
+}
+
+func (d *testDeps) PhotoVideoFinder() uniq.Finder[photo.Video] {
+	return d.videos.PhotoVideoFinder()
+}
+
+func (d *testDeps) PhotoThumbDeleter() uniq.Deleter[photo.Thumb] {
+	return d.thumbs.PhotoThumbDeleter()
+}
+
+func (d *testDeps) Trash() *trash.Service {
+	return d.purger
+}
+
+func (d *testDeps) TrashRepository() *storage.TrashRepository {
+	return d.trash
+}
+
+func (d *testDeps) AuditRepository() *storage.AuditRepository {
+	return d.audit
+}
+
+func (d *testDeps) DepCache() *dep.Cache {
+	return d.depCache
+}
+
+func (d *testDeps) EventBus() *events.Bus {
+	return d.bus
+}
+
+// addImages stores images with files named by their file names.
+func addImages(t *testing.T, d *testDeps, names ...string) []uniq.Hash {
+	t.Helper()
+
+	dir := t.TempDir()
+	hashes := make([]uniq.Hash, 0, len(names))
+
+	for _, n := range names {
+		img := photo.Image{}
+		img.Hash = uniq.StringHash(n)
+		img.Path = filepath.Join(dir, n)
+
+		require.NoError(t, os.WriteFile(img.Path, []byte(n), 0o600))
+		require.NoError(t, d.images.Add(context.Background(), img))
+
+		hashes = append(hashes, img.Hash)
+	}
+
+	return hashes
+}
+
+// addAlbum stores album with images.
+func addAlbum(t *testing.T, d *testDeps, album photo.Album, images ...uniq.Hash) {
+	t.Helper()
+
+	album.Hash = photo.AlbumHash(album.Name)
+
+	require.NoError(t, d.AlbumRepository.Add(context.Background(), album))
+	require.NoError(t, d.AlbumRepository.AddImages(context.Background(), album.Hash, images...))
+}
+
+// albumImages returns hashes of album images.
+func albumImages(t *testing.T, d *testDeps, albumName string) []uniq.Hash {
+	t.Helper()
+
+	images, err := d.AlbumRepository.FindImages(context.Background(), photo.AlbumHash(albumName))
+	require.NoError(t, err)
+
+	hashes := make([]uniq.Hash, 0, len(images))
+	for _, img := range images {
+		hashes = append(hashes, img.Hash)
+	}
+
+	return hashes
+}
+
+// interact invokes use case with input made from JSON encoding of in.
+//
+// Use case input types are often unexported, so fields are filled by their JSON names
+// or by Go names for fields without JSON tags. Output is returned as JSON, raw responses
+// are written to the returned recorder.
+func interact(ctx context.Context, u usecase.Interactor, in any) (string, *httptest.ResponseRecorder, error) {
+	input := reflect.New(reflect.TypeOf(u.(usecase.HasInputPort).InputPort()))
+
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if err := json.Unmarshal(b, input.Interface()); err != nil {
+			return "", nil, err
+		}
+	}
+
+	output := reflect.New(reflect.TypeOf(u.(usecase.HasOutputPort).OutputPort()).Elem()).Interface()
+	rec := httptest.NewRecorder()
+
+	if s, ok := output.(interface{ SetResponseWriter(rw http.ResponseWriter) }); ok {
+		s.SetResponseWriter(rec)
+	}
+
+	if err := u.Interact(ctx, input.Elem().Interface(), output); err != nil {
+		return "", rec, err
+	}
+
+	b, err := json.Marshal(output)
+
+	return string(b), rec, err
+}
@@ -8,9 +8,11 @@ import (
 	"github.com/bool64/stats"
 	"github.com/swaggest/usecase"
 	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/domain/event"
 	"github.com/vearutop/photo-blog/internal/domain/photo"
 	"github.com/vearutop/photo-blog/internal/domain/uniq"
 	"github.com/vearutop/photo-blog/internal/infra/dep"
+	"github.com/vearutop/photo-blog/internal/infra/events"
 )
 
 type createAlbumDeps interface {
@@ -18,6 +20,7 @@ type createAlbumDeps interface {
 	CtxdLogger() ctxd.Logger
 	PhotoAlbumEnsurer() uniq.Ensurer[photo.Album] // See storage.AlbumRepository.
 	DepCache() *dep.Cache
+	EventBus() *events.Bus
 }
 
 // CreateAlbum creates use case interactor to add directory of photos.
@@ -33,6 +36,7 @@ func CreateAlbum(deps createAlbumDeps) usecase.Interactor {
 
 		if err == nil {
 			err = deps.DepCache().AlbumListChanged(ctx)
+			deps.EventBus().Publish(ctx, event.Album(event.AlbumCreated, out.Name))
 		}
 
 		return err
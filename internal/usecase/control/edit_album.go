@@ -58,6 +58,8 @@ function formDone(x, ctx) { $(ctx.result).html('Done.').show() }
 <hr />
 <button style="margin: 2em" class="btn btn-danger" onclick="deleteAlbum('` + a.Name + `')">Delete this album</button>
 <button style="margin: 2em" class="btn" onclick="reindexAlbum('` + a.Name + `')">Reindex this album</button>
+<button style="margin: 2em" class="btn" onclick="geotagAlbum('` + a.Name + `', 'POST')">Geotag from GPX tracks</button>
+<button style="margin: 2em" class="btn" onclick="geotagAlbum('` + a.Name + `', 'DELETE')">Revert GPX geotags</button>
 `),
 		}
 
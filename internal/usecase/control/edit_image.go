@@ -15,6 +15,7 @@ import (
 	"github.com/swaggest/usecase/status"
 	"github.com/vearutop/photo-blog/internal/domain/photo"
 	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/pkg/imgedit"
 )
 
 type editImagePageDeps interface {
@@ -58,6 +59,11 @@ func EditImage(deps editImagePageDeps) usecase.Interactor {
 			gps.GpsTime = time.Now()
 		}
 
+		edits := imgedit.Edits{}
+		if img.Settings.Edits != nil {
+			edits = *img.Settings.Edits
+		}
+
 		return deps.SchemaRepository().Render(out.Writer,
 			jsonform.Page{
 				Title: "Edit Photo Details",
@@ -65,9 +71,14 @@ func EditImage(deps editImagePageDeps) usecase.Interactor {
 <div style="margin:2em" class="pure-u-2-5">
     <h1>Manage photo</h1>
     <img alt="" style="width:100%" src="/thumb/600w/` + img.Hash.String() + `.jpg" />
+    <p>Edits are applied to thumbnails, original file stays untouched.</p>
+    <button class="pure-button" onclick="resetEdits()">Reset edits</button>
 </div>` +
 					`<script>
 function formSaved(x, ctx) { $(ctx.result).html('Saved.').show() } 
+function resetEdits() {
+    fetch('/image/` + img.Hash.String() + `/edits', {method: 'DELETE'}).then(function () { location.reload() })
+}
 </script>`),
 			},
 			jsonform.Form{
@@ -88,6 +99,15 @@ function formSaved(x, ctx) { $(ctx.result).html('Saved.').show() }
 				SubmitText:    "Save",
 				OnSuccess:     `formSaved`,
 			},
+			jsonform.Form{
+				Title:         "Edits",
+				SubmitURL:     "/image/" + img.Hash.String() + "/edits",
+				SubmitMethod:  http.MethodPut,
+				SuccessStatus: http.StatusNoContent,
+				Value:         edits,
+				SubmitText:    "Apply",
+				OnSuccess:     `formSaved`,
+			},
 			jsonform.Form{
 				Title:         "GPS",
 				SubmitURL:     "/gps",
@@ -0,0 +1,182 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bool64/ctxd"
+	"github.com/bool64/stats"
+	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/domain/topic"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/dep"
+	"github.com/vearutop/photo-blog/internal/infra/image"
+	"github.com/vearutop/photo-blog/pkg/qlite"
+)
+
+type geotagAlbumDeps interface {
+	StatsTracker() stats.Tracker
+	CtxdLogger() ctxd.Logger
+
+	PhotoAlbumFinder() uniq.Finder[photo.Album]
+	PhotoAlbumImageFinder() photo.AlbumImageFinder
+	PhotoGpxFinder() uniq.Finder[photo.Gpx]
+
+	PhotoGpsFinder() uniq.Finder[photo.Gps]
+	PhotoGpsEnsurer() uniq.Ensurer[photo.Gps]
+	PhotoGpsDeleter() uniq.Deleter[photo.Gps]
+
+	PhotoMetaFinder() uniq.Finder[photo.Meta]
+	PhotoMetaEnsurer() uniq.Ensurer[photo.Meta]
+
+	QueueBroker() *qlite.Broker
+	DepCache() *dep.Cache
+}
+
+type geotagAlbumInput struct {
+	Name string `path:"name"`
+}
+
+type geotagAlbumOutput struct {
+	Tagged   int `json:"tagged" description:"Number of images with derived positions."`
+	Skipped  int `json:"skipped" description:"Number of images with GPS from EXIF."`
+	NotFound int `json:"not_found" description:"Number of images without time or matching track points."`
+}
+
+// GeotagAlbum creates use case interactor to derive image positions from album GPX tracks.
+//
+// Images with GPS from EXIF are not changed, previously derived positions are recalculated.
+func GeotagAlbum(deps geotagAlbumDeps) usecase.Interactor {
+	u := usecase.NewInteractor(func(ctx context.Context, in geotagAlbumInput, out *geotagAlbumOutput) error {
+		deps.StatsTracker().Add(ctx, "geotag_album", 1)
+		deps.CtxdLogger().Info(ctx, "geotagging album", "name", in.Name)
+
+		album, err := deps.PhotoAlbumFinder().FindByHash(ctx, photo.AlbumHash(in.Name))
+		if err != nil {
+			return err
+		}
+
+		idx, err := image.AlbumTracks(ctx, deps.PhotoGpxFinder(), album)
+		if err != nil {
+			return err
+		}
+
+		if idx == nil {
+			return status.Wrap(errors.New("album has no GPX tracks"), status.FailedPrecondition)
+		}
+
+		images, err := deps.PhotoAlbumImageFinder().FindImages(ctx, album.Hash)
+		if err != nil {
+			return err
+		}
+
+		for _, img := range images {
+			existing, err := deps.PhotoGpsFinder().FindByHash(ctx, img.Hash)
+			if err != nil && !errors.Is(err, status.NotFound) {
+				return err
+			}
+
+			if err == nil && !existing.Derived {
+				out.Skipped++
+
+				continue
+			}
+
+			g, ok := image.DerivePosition(idx, album.Settings, img)
+			if !ok {
+				out.NotFound++
+
+				continue
+			}
+
+			if existing.Derived && existing.Latitude == g.Latitude && existing.Longitude == g.Longitude {
+				out.Tagged++
+
+				continue
+			}
+
+			if _, err := deps.PhotoGpsEnsurer().Ensure(ctx, g); err != nil {
+				return fmt.Errorf("ensure derived gps: %w", err)
+			}
+
+			if err := positionChanged(ctx, deps, img); err != nil {
+				return err
+			}
+
+			out.Tagged++
+		}
+
+		return deps.DepCache().AlbumChanged(ctx, album.Name)
+	})
+
+	u.SetTags("Album")
+	u.SetExpectedErrors(status.Unknown, status.NotFound, status.FailedPrecondition)
+
+	return u
+}
+
+// RevertGeotagAlbum creates use case interactor to remove derived image positions of an album.
+func RevertGeotagAlbum(deps geotagAlbumDeps) usecase.Interactor {
+	type revertOutput struct {
+		Reverted int `json:"reverted"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, in geotagAlbumInput, out *revertOutput) error {
+		deps.StatsTracker().Add(ctx, "revert_geotag_album", 1)
+		deps.CtxdLogger().Info(ctx, "reverting album geotags", "name", in.Name)
+
+		album, err := deps.PhotoAlbumFinder().FindByHash(ctx, photo.AlbumHash(in.Name))
+		if err != nil {
+			return err
+		}
+
+		images, err := deps.PhotoAlbumImageFinder().FindImages(ctx, album.Hash)
+		if err != nil {
+			return err
+		}
+
+		for _, img := range images {
+			g, err := deps.PhotoGpsFinder().FindByHash(ctx, img.Hash)
+			if err != nil {
+				if errors.Is(err, status.NotFound) {
+					continue
+				}
+
+				return err
+			}
+
+			if !g.Derived {
+				continue
+			}
+
+			if err := deps.PhotoGpsDeleter().Delete(ctx, img.Hash); err != nil {
+				return fmt.Errorf("delete derived gps: %w", err)
+			}
+
+			if err := image.ResetGeoLabel(ctx, deps, img.Hash); err != nil {
+				return err
+			}
+
+			out.Reverted++
+		}
+
+		return deps.DepCache().AlbumChanged(ctx, album.Name)
+	})
+
+	u.SetTags("Album")
+	u.SetExpectedErrors(status.Unknown, status.NotFound)
+
+	return u
+}
+
+// positionChanged drops stale geo labels and queues indexing to label the new position.
+func positionChanged(ctx context.Context, deps geotagAlbumDeps, img photo.Image) error {
+	if err := image.ResetGeoLabel(ctx, deps, img.Hash); err != nil {
+		return err
+	}
+
+	return deps.QueueBroker().Publish(ctx, topic.IndexImage, image.IndexJob{Image: img})
+}
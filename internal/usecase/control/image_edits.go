@@ -0,0 +1,103 @@
+package control
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bool64/ctxd"
+	"github.com/bool64/stats"
+	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/domain/topic"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/dep"
+	"github.com/vearutop/photo-blog/internal/infra/image"
+	"github.com/vearutop/photo-blog/pkg/imgedit"
+	"github.com/vearutop/photo-blog/pkg/qlite"
+)
+
+type imageEditsDeps interface {
+	StatsTracker() stats.Tracker
+	CtxdLogger() ctxd.Logger
+
+	PhotoImageFinder() uniq.Finder[photo.Image]
+	PhotoImageUpdater() uniq.Updater[photo.Image]
+
+	QueueBroker() *qlite.Broker
+	DepCache() *dep.Cache
+}
+
+type imageEditsInput struct {
+	Hash uniq.Hash `path:"hash"`
+}
+
+// SetImageEdits creates use case interactor to update non-destructive edits of an image.
+func SetImageEdits(deps imageEditsDeps) usecase.Interactor {
+	type setImageEditsInput struct {
+		imageEditsInput
+		imgedit.Edits
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, in setImageEditsInput, out *struct{}) error {
+		deps.StatsTracker().Add(ctx, "set_image_edits", 1)
+		deps.CtxdLogger().Info(ctx, "setting image edits", "hash", in.Hash, "edits", in.Edits)
+
+		e := in.Edits
+		if e.IsZero() {
+			return applyImageEdits(ctx, deps, in.Hash, nil)
+		}
+
+		return applyImageEdits(ctx, deps, in.Hash, &e)
+	})
+
+	u.SetTags("Image")
+	u.SetExpectedErrors(status.Unknown, status.NotFound)
+
+	return u
+}
+
+// ResetImageEdits creates use case interactor to drop all edits of an image.
+func ResetImageEdits(deps imageEditsDeps) usecase.Interactor {
+	u := usecase.NewInteractor(func(ctx context.Context, in imageEditsInput, out *struct{}) error {
+		deps.StatsTracker().Add(ctx, "reset_image_edits", 1)
+		deps.CtxdLogger().Info(ctx, "resetting image edits", "hash", in.Hash)
+
+		return applyImageEdits(ctx, deps, in.Hash, nil)
+	})
+
+	u.SetTags("Image")
+	u.SetExpectedErrors(status.Unknown, status.NotFound)
+
+	return u
+}
+
+func applyImageEdits(ctx context.Context, deps imageEditsDeps, hash uniq.Hash, edits *imgedit.Edits) error {
+	img, err := deps.PhotoImageFinder().FindByHash(ctx, hash)
+	if err != nil {
+		return err
+	}
+
+	img.Settings.Edits = edits
+	img.Settings.UpdatedAt = time.Now()
+
+	if err := deps.PhotoImageUpdater().Update(ctx, img); err != nil {
+		return fmt.Errorf("update image: %w", err)
+	}
+
+	if err := deps.DepCache().ImageChanged(ctx, img.Hash); err != nil {
+		return err
+	}
+
+	// Dimensions and all derivatives are rebuilt from the original file.
+	return deps.QueueBroker().Publish(ctx, topic.IndexImage, image.IndexJob{
+		Image: img,
+		Flags: photo.IndexingFlags{
+			RebuildImageSize:  true,
+			RebuildThumbnails: true,
+		},
+	}, func(msg *qlite.Message) {
+		msg.PublishOnSuccess(topic.ImageChanged, img.Hash)
+	})
+}
@@ -23,6 +23,8 @@ type indexAlbumDeps interface {
 	PhotoAlbumUpdater() uniq.Updater[photo.Album]
 	PhotoAlbumImageFinder() photo.AlbumImageFinder
 	PhotoImageFinder() uniq.Finder[photo.Image]
+	PhotoGpxFinder() uniq.Finder[photo.Gpx]
+	PhotoGpxEnsurer() uniq.Ensurer[photo.Gpx]
 
 	QueueBroker() *qlite.Broker
 }
@@ -31,6 +33,8 @@ type indexAlbumInput struct {
 	Name      string    `path:"name" description:"Album name, use '-' for all images and albums."`
 	ImageHash uniq.Hash `query:"image_hash" description:"Only one image to index."`
 	photo.IndexingFlags
+
+	RebuildTracks bool `formData:"rebuild_tracks" description:"Recalculate statistics of all tracks, tracks without statistics are always indexed."`
 }
 
 // IndexAlbum creates use case interactor to index album.
@@ -39,7 +43,10 @@ func IndexAlbum(deps indexAlbumDeps) usecase.IOInteractorOf[indexAlbumInput, str
 		deps.StatsTracker().Add(ctx, "index_album", 1)
 		deps.CtxdLogger().Info(ctx, "indexing album", "name", in.Name)
 
-		var images []photo.Image
+		var (
+			images []photo.Image
+			tracks []photo.Gpx
+		)
 
 		if in.Name != "-" {
 			album, err := deps.PhotoAlbumFinder().FindByHash(ctx, photo.AlbumHash(in.Name))
@@ -51,6 +58,11 @@ func IndexAlbum(deps indexAlbumDeps) usecase.IOInteractorOf[indexAlbumInput, str
 			if err != nil {
 				return err
 			}
+
+			tracks, err = deps.PhotoGpxFinder().FindByHashes(ctx, album.Settings.GpxTracksHashes...)
+			if err != nil {
+				return err
+			}
 		} else {
 			albums, err := deps.PhotoAlbumFinder().FindAll(ctx)
 			if err != nil {
@@ -71,6 +83,15 @@ func IndexAlbum(deps indexAlbumDeps) usecase.IOInteractorOf[indexAlbumInput, str
 			if err != nil {
 				return err
 			}
+
+			tracks, err = deps.PhotoGpxFinder().FindAll(ctx)
+			if err != nil {
+				return err
+			}
+		}
+
+		if in.ImageHash == 0 {
+			indexTracks(ctx, deps, tracks, in.RebuildTracks)
 		}
 
 		deps.CtxdLogger().Info(ctx, "indexing album", "num_images", len(images))
@@ -100,6 +121,25 @@ func IndexAlbum(deps indexAlbumDeps) usecase.IOInteractorOf[indexAlbumInput, str
 	return u
 }
 
+// indexTracks calculates track statistics, failures are logged to keep indexing images.
+func indexTracks(ctx context.Context, deps indexAlbumDeps, tracks []photo.Gpx, rebuild bool) {
+	for _, t := range tracks {
+		if t.Settings.Val.HasStats() && !rebuild {
+			continue
+		}
+
+		if err := t.Index(); err != nil {
+			deps.CtxdLogger().Error(ctx, "failed to index track", "path", t.Path, "error", err)
+
+			continue
+		}
+
+		if _, err := deps.PhotoGpxEnsurer().Ensure(ctx, t); err != nil {
+			deps.CtxdLogger().Error(ctx, "failed to store track", "path", t.Path, "error", err)
+		}
+	}
+}
+
 // detachedContext exposes parent values, but suppresses parent cancellation.
 type detachedContext struct {
 	parent context.Context //nolint:containedctx // This wrapping is here on purpose.
@@ -2,15 +2,19 @@ package control
 
 import (
 	"context"
+	"time"
 
 	"github.com/bool64/ctxd"
 	"github.com/bool64/stats"
 	"github.com/swaggest/usecase"
 	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/domain/event"
 	"github.com/vearutop/photo-blog/internal/domain/photo"
 	"github.com/vearutop/photo-blog/internal/domain/uniq"
 	"github.com/vearutop/photo-blog/internal/infra/auth"
 	"github.com/vearutop/photo-blog/internal/infra/dep"
+	"github.com/vearutop/photo-blog/internal/infra/events"
+	"github.com/vearutop/photo-blog/internal/infra/storage"
 )
 
 type removeFromAlbumDeps interface {
@@ -18,10 +22,10 @@ type removeFromAlbumDeps interface {
 	CtxdLogger() ctxd.Logger
 
 	PhotoAlbumFinder() uniq.Finder[photo.Album]
-	PhotoAlbumImageFinder() photo.AlbumImageFinder
-	PhotoAlbumImageDeleter() photo.AlbumImageDeleter
+	TrashRepository() *storage.TrashRepository
 
 	DepCache() *dep.Cache
+	EventBus() *events.Bus
 }
 
 type removeFromAlbumInput struct {
@@ -43,27 +47,36 @@ func RemoveFromAlbum(deps removeFromAlbumDeps) usecase.Interactor {
 			return err
 		}
 
-		if !auth.IsAdmin(ctx) {
-
-			if in.CollabKey == "" {
-				return status.PermissionDenied
-			}
-
-			if album.Settings.CollabKey != in.CollabKey {
-				return status.PermissionDenied
-			}
+		if ctx, err = collabCanDelete(ctx, album, in.CollabKey); err != nil {
+			return err
 		}
 
-		err = deps.PhotoAlbumImageDeleter().DeleteImages(ctx, albumHash, in.ImageHash)
+		err = deps.TrashRepository().RemoveImages(ctx, auth.Actor(ctx), albumHash, in.ImageHash)
 		if err != nil {
 			return err
 		}
 
+		deps.EventBus().Publish(ctx, event.Image(event.ImageRemoved, in.AlbumName, in.ImageHash))
+
 		return deps.DepCache().AlbumChanged(ctx, in.AlbumName)
 	})
 
 	u.SetTags("Album")
-	u.SetExpectedErrors(status.Unknown)
+	u.SetExpectedErrors(status.Unknown, status.PermissionDenied)
 
 	return u
 }
+
+// collabCanDelete checks if current user can remove album content and adds collaborator to context.
+func collabCanDelete(ctx context.Context, album photo.Album, collabKey string) (context.Context, error) {
+	if auth.IsAdmin(ctx) {
+		return ctx, nil
+	}
+
+	c, ok := album.Settings.Collaborator(collabKey, time.Now())
+	if !ok || !c.CanDelete() {
+		return ctx, status.PermissionDenied
+	}
+
+	return auth.SetCollaborator(ctx, c.Name), nil
+}
@@ -0,0 +1,104 @@
+package control_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/auth"
+	"github.com/vearutop/photo-blog/internal/infra/storage"
+	"github.com/vearutop/photo-blog/internal/usecase/control"
+)
+
+func TestRemoveFromAlbum_collaborators(t *testing.T) {
+	deps := newTestDeps(t)
+
+	expired := time.Now().Add(-time.Hour)
+	album := photo.Album{Name: "party", Title: "Party"}
+	album.Settings.Collaborators = []photo.Collaborator{
+		{Name: "ann", Key: "ann-key-1", Permission: photo.CollabAdd},
+		{Name: "bob", Key: "bob-key-1", Permission: photo.CollabAddDelete},
+		{Name: "eve", Key: "eve-key-1", Permission: photo.CollabAddDelete, ExpiresAt: &expired},
+	}
+
+	images := addImages(t, deps, "a.jpg", "b.jpg", "c.jpg")
+	addAlbum(t, deps, album, images...)
+
+	ctx := context.Background()
+	admin := auth.SetAdmin(ctx)
+
+	remove := func(ctx context.Context, h uniq.Hash, collabKey string) error {
+		_, _, err := interact(ctx, control.RemoveFromAlbum(deps),
+			map[string]any{"AlbumName": "party", "ImageHash": h, "CollabKey": collabKey})
+
+		return err
+	}
+
+	restore := func(ctx context.Context, h uniq.Hash, collabKey string) error {
+		_, _, err := interact(ctx, control.RestoreToAlbum(deps),
+			map[string]any{"AlbumName": "party", "ImageHash": h, "CollabKey": collabKey})
+
+		return err
+	}
+
+	// Only admin and collaborators with delete permission can remove images.
+	assert.ErrorIs(t, remove(ctx, images[0], ""), status.PermissionDenied)
+	assert.ErrorIs(t, remove(ctx, images[0], "ann-key-1"), status.PermissionDenied)
+	assert.ErrorIs(t, remove(ctx, images[0], "eve-key-1"), status.PermissionDenied, "expired key")
+	assert.ErrorIs(t, remove(ctx, images[0], "wrong-key"), status.PermissionDenied)
+	assert.ElementsMatch(t, images, albumImages(t, deps, "party"))
+
+	require.NoError(t, remove(ctx, images[0], "bob-key-1"))
+	require.NoError(t, remove(admin, images[1], ""))
+	assert.Equal(t, []uniq.Hash{images[2]}, albumImages(t, deps, "party"))
+
+	// Removed images are kept in trash with their authors.
+	res, _, err := interact(ctx, control.GetAlbumTrash(deps), map[string]any{"AlbumName": "party", "CollabKey": "bob-key-1"})
+	require.NoError(t, err)
+
+	var trash []storage.TrashAlbumImage
+	require.NoError(t, json.Unmarshal([]byte(res), &trash))
+	require.Len(t, trash, 2)
+
+	actors := map[uniq.Hash]string{}
+	for _, ti := range trash {
+		actors[ti.ImageHash] = ti.Actor
+	}
+
+	assert.Equal(t, map[uniq.Hash]string{images[0]: "collaborator:bob", images[1]: "admin"}, actors)
+
+	_, _, err = interact(ctx, control.GetAlbumTrash(deps), map[string]any{"AlbumName": "party", "CollabKey": "ann-key-1"})
+	assert.ErrorIs(t, err, status.PermissionDenied)
+
+	// Removed images can be restored.
+	assert.ErrorIs(t, restore(ctx, images[0], "ann-key-1"), status.PermissionDenied)
+	require.NoError(t, restore(ctx, images[0], "bob-key-1"))
+	assert.ErrorIs(t, restore(ctx, images[0], "bob-key-1"), status.NotFound, "already restored")
+	assert.ElementsMatch(t, []uniq.Hash{images[0], images[2]}, albumImages(t, deps, "party"))
+
+	// Audit log has all changes, latest first.
+	res, _, err = interact(admin, control.GetAlbumAudit(deps), map[string]any{"AlbumName": "party"})
+	require.NoError(t, err)
+
+	var audit []photo.AuditEntry
+	require.NoError(t, json.Unmarshal([]byte(res), &audit))
+	require.Len(t, audit, 3)
+
+	for i, exp := range []photo.AuditEntry{
+		{ImageHash: images[0], Action: photo.AuditRestore, Actor: "collaborator:bob"},
+		{ImageHash: images[1], Action: photo.AuditRemove, Actor: "admin"},
+		{ImageHash: images[0], Action: photo.AuditRemove, Actor: "collaborator:bob"},
+	} {
+		assert.Equal(t, album.Name, audit[i].AlbumName)
+		assert.Equal(t, photo.AlbumHash(album.Name), audit[i].AlbumHash)
+		assert.Equal(t, exp.ImageHash, audit[i].ImageHash)
+		assert.Equal(t, exp.Action, audit[i].Action)
+		assert.Equal(t, exp.Actor, audit[i].Actor)
+	}
+}
@@ -0,0 +1,96 @@
+package control
+
+import (
+	"context"
+
+	"github.com/bool64/ctxd"
+	"github.com/bool64/stats"
+	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/domain/event"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/dep"
+	"github.com/vearutop/photo-blog/internal/infra/events"
+	"github.com/vearutop/photo-blog/internal/infra/storage"
+)
+
+type restoreToAlbumDeps interface {
+	StatsTracker() stats.Tracker
+	CtxdLogger() ctxd.Logger
+
+	PhotoAlbumFinder() uniq.Finder[photo.Album]
+	TrashRepository() *storage.TrashRepository
+
+	DepCache() *dep.Cache
+	EventBus() *events.Bus
+}
+
+// RestoreToAlbum creates use case interactor to return removed photo to album.
+func RestoreToAlbum(deps restoreToAlbumDeps) usecase.Interactor {
+	u := usecase.NewInteractor(func(ctx context.Context, in removeFromAlbumInput, out *struct{}) error {
+		deps.StatsTracker().Add(ctx, "restore_to_album", 1)
+		deps.CtxdLogger().Info(ctx, "restoring to album", "name", in.AlbumName, "hash", in.ImageHash)
+
+		albumHash := photo.AlbumHash(in.AlbumName)
+
+		album, err := deps.PhotoAlbumFinder().FindByHash(ctx, albumHash)
+		if err != nil {
+			return err
+		}
+
+		if ctx, err = collabCanDelete(ctx, album, in.CollabKey); err != nil {
+			return err
+		}
+
+		restored, err := deps.TrashRepository().RestoreImages(ctx, albumHash, in.ImageHash)
+		if err != nil {
+			return err
+		}
+
+		if len(restored) == 0 {
+			return status.NotFound
+		}
+
+		for _, h := range restored {
+			deps.EventBus().Publish(ctx, event.Image(event.ImageRestored, in.AlbumName, h))
+		}
+
+		return deps.DepCache().AlbumChanged(ctx, in.AlbumName)
+	})
+
+	u.SetTags("Album")
+	u.SetExpectedErrors(status.NotFound, status.PermissionDenied)
+
+	return u
+}
+
+// GetAlbumTrash creates use case interactor to list removed photos of album.
+func GetAlbumTrash(deps restoreToAlbumDeps) usecase.Interactor {
+	type getAlbumTrash struct {
+		AlbumName string `path:"name"`
+		CollabKey string `query:"collabKey" description:"Collaborator key to allow admin access."`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, in getAlbumTrash, out *[]storage.TrashAlbumImage) error {
+		albumHash := photo.AlbumHash(in.AlbumName)
+
+		album, err := deps.PhotoAlbumFinder().FindByHash(ctx, albumHash)
+		if err != nil {
+			return err
+		}
+
+		if _, err = collabCanDelete(ctx, album, in.CollabKey); err != nil {
+			return err
+		}
+
+		*out, err = deps.TrashRepository().FindImages(ctx, albumHash)
+
+		return err
+	})
+
+	u.SetTags("Album")
+	u.SetExpectedErrors(status.NotFound, status.PermissionDenied)
+
+	return u
+}
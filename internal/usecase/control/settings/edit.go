@@ -11,6 +11,7 @@ import (
 	"github.com/swaggest/usecase"
 	"github.com/swaggest/usecase/status"
 	"github.com/vearutop/image-prompt/multi"
+	"github.com/vearutop/photo-blog/internal/infra/notifier"
 	"github.com/vearutop/photo-blog/internal/infra/settings"
 	"github.com/vearutop/photo-blog/internal/infra/upload"
 )
@@ -59,7 +60,9 @@ func Edit(deps editSettingsDeps) usecase.Interactor {
 			form("Appearance", "/settings/appearance.json", deps.Settings().Appearance()),
 			form("Maps", "/settings/maps.json", deps.Settings().Maps()),
 			form("Visitors", "/settings/visitors.json", deps.Settings().Visitors()),
-			form("Storage", "/settings/storage.json", deps.Settings().Storage()),
+			form("Storage", "/settings/storage.json", deps.Settings().Storage(), func(f *jsonform.Form) {
+				f.Description = `Deleted albums and removed images can be restored from <a href="/trash.html">trash</a>.`
+			}),
 			form("Privacy", "/settings/privacy.json", deps.Settings().Privacy(), func(f *jsonform.Form) {
 				f.Description = "These settings do not affect how pages look for admin user, only for guests."
 			}),
@@ -76,6 +79,31 @@ func Edit(deps editSettingsDeps) usecase.Interactor {
 			form("External API Integration", "/settings/external_api.json", deps.Settings().ExternalAPI()),
 			form("Image Descriptions (LLM)", "/settings/image_prompt.json", deps.Settings().ImagePrompt()),
 			form("Indexing", "/settings/indexing.json", deps.Settings().Indexing()),
+			form("Watermark", "/settings/watermark.json", deps.Settings().Watermark(), func(f *jsonform.Form) {
+				f.Description = "Upload watermark image to site files first, then refer to it by name."
+			}),
+			form("Geocoding", "/settings/geocoding.json", deps.Settings().Geocoding(), func(f *jsonform.Form) {
+				f.Description = "Reverse geocoding labels photos with places, enable it in Indexing settings."
+			}),
+			form("Comments", "/settings/comments.json", deps.Settings().Comments(), func(f *jsonform.Form) {
+				f.Description = `Pending messages are listed in <a href="/comments/inbox.html">moderation inbox</a>.`
+			}),
+			form("Notifications", "/settings/notifications.json", deps.Settings().Notifications(), func(f *jsonform.Form) {
+				f.Description = `Email and webhook notifications for selected events, ` +
+					`<a href="#" onclick="fetch('/settings/notifications/test', {method: 'POST'}).then(function (r) { alert(r.ok ? 'Sent.' : 'Failed.') }); return false">send test notification</a> after saving.`
+			}),
+			form("Webhooks", "/settings/webhooks.json", deps.Settings().Webhooks(), func(f *jsonform.Form) {
+				f.Description = `Content changes are delivered to webhooks and streamed to <a href="/events">/events</a> for admin.`
+			}),
+			form("ActivityPub", "/settings/activitypub.json", deps.Settings().ActivityPub(), func(f *jsonform.Form) {
+				f.Description = "Fediverse users can follow the site, replies to posts arrive in comments moderation inbox."
+			}),
+			form("Backups", "/settings/backups.json", deps.Settings().Backups(), func(f *jsonform.Form) {
+				f.Description = `Status and verification of backups are available in <a href="/backups.html">backups</a>.`
+			}),
+			form("Bots", "/settings/bots.json", deps.Settings().Bots(), func(f *jsonform.Form) {
+				f.Description = `Rules control robots.txt and access of crawlers, traffic is reported in <a href="/stats/bots.json">bot stats</a>.`
+			}),
 		)
 	})
 
@@ -117,6 +145,90 @@ func SetIndexing(deps setSettingsDeps) usecase.Interactor {
 	return u
 }
 
+func SetWatermark(deps setSettingsDeps) usecase.Interactor {
+	u := usecase.NewInteractor(func(ctx context.Context, input settings.Watermark, output *struct{}) error {
+		return deps.SettingsManager().SetWatermark(ctx, input)
+	})
+
+	return u
+}
+
+func SetGeocoding(deps setSettingsDeps) usecase.Interactor {
+	u := usecase.NewInteractor(func(ctx context.Context, input settings.Geocoding, output *struct{}) error {
+		return deps.SettingsManager().SetGeocoding(ctx, input)
+	})
+
+	return u
+}
+
+func SetComments(deps setSettingsDeps) usecase.Interactor {
+	u := usecase.NewInteractor(func(ctx context.Context, input settings.Comments, output *struct{}) error {
+		return deps.SettingsManager().SetComments(ctx, input)
+	})
+
+	return u
+}
+
+func SetNotifications(deps setSettingsDeps) usecase.Interactor {
+	u := usecase.NewInteractor(func(ctx context.Context, input settings.Notifications, output *struct{}) error {
+		return deps.SettingsManager().SetNotifications(ctx, input)
+	})
+
+	return u
+}
+
+func SetWebhooks(deps setSettingsDeps) usecase.Interactor {
+	u := usecase.NewInteractor(func(ctx context.Context, input settings.Webhooks, output *struct{}) error {
+		return deps.SettingsManager().SetWebhooks(ctx, input)
+	})
+
+	return u
+}
+
+func SetActivityPub(deps setSettingsDeps) usecase.Interactor {
+	u := usecase.NewInteractor(func(ctx context.Context, input settings.ActivityPub, output *struct{}) error {
+		return deps.SettingsManager().SetActivityPub(ctx, input)
+	})
+
+	return u
+}
+
+func SetBackups(deps setSettingsDeps) usecase.Interactor {
+	u := usecase.NewInteractor(func(ctx context.Context, input settings.Backups, output *struct{}) error {
+		return deps.SettingsManager().SetBackups(ctx, input)
+	})
+
+	return u
+}
+
+func SetBots(deps setSettingsDeps) usecase.Interactor {
+	u := usecase.NewInteractor(func(ctx context.Context, input settings.Bots, output *struct{}) error {
+		return deps.SettingsManager().SetBots(ctx, input)
+	})
+
+	return u
+}
+
+type testNotificationDeps interface {
+	Notifier() *notifier.Service
+}
+
+// TestNotification sends a test message to configured notification channels.
+func TestNotification(deps testNotificationDeps) usecase.Interactor {
+	u := usecase.NewInteractor(func(ctx context.Context, input struct{}, output *struct{}) error {
+		if err := deps.Notifier().Test(ctx); err != nil {
+			return status.Wrap(err, status.FailedPrecondition)
+		}
+
+		return nil
+	})
+
+	u.SetTags("Control Panel")
+	u.SetExpectedErrors(status.FailedPrecondition)
+
+	return u
+}
+
 func SetMaps(deps setSettingsDeps) usecase.Interactor {
 	u := usecase.NewInteractor(func(ctx context.Context, input settings.Maps, output *struct{}) error {
 		return deps.SettingsManager().SetMaps(ctx, input)
@@ -0,0 +1,169 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/domain/event"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/dep"
+	"github.com/vearutop/photo-blog/internal/infra/events"
+	"github.com/vearutop/photo-blog/internal/infra/settings"
+	"github.com/vearutop/photo-blog/internal/infra/storage"
+	"github.com/vearutop/photo-blog/internal/infra/trash"
+	"github.com/vearutop/photo-blog/pkg/web"
+	"github.com/vearutop/photo-blog/resources/static"
+)
+
+type trashDeps interface {
+	Settings() settings.Values
+	PhotoAlbumFinder() uniq.Finder[photo.Album]
+	TrashRepository() *storage.TrashRepository
+	Trash() *trash.Service
+
+	DepCache() *dep.Cache
+	EventBus() *events.Bus
+}
+
+// ShowTrash renders deleted albums and removed images.
+func ShowTrash(deps trashDeps) usecase.Interactor {
+	type trashAlbum struct {
+		storage.TrashAlbum
+
+		Images  int
+		PurgeAt time.Time
+	}
+
+	type trashImage struct {
+		storage.TrashAlbumImage
+
+		AlbumName  string
+		AlbumTitle string
+		PurgeAt    time.Time
+	}
+
+	type trashPage struct {
+		Title     string
+		TrashDays int
+		Albums    []trashAlbum
+		Images    []trashImage
+	}
+
+	tmpl := static.MustParseTemplate("trash.html")
+
+	u := usecase.NewInteractor(func(ctx context.Context, in struct{}, out *web.Page) error {
+		albums, err := deps.TrashRepository().FindAlbums(ctx)
+		if err != nil {
+			return err
+		}
+
+		images, err := deps.TrashRepository().FindImages(ctx, 0)
+		if err != nil {
+			return err
+		}
+
+		d := trashPage{Title: "Trash", TrashDays: deps.Settings().Storage().TrashDays}
+
+		purgeAt := func(removedAt time.Time) time.Time {
+			if d.TrashDays <= 0 {
+				return time.Time{}
+			}
+
+			return removedAt.AddDate(0, 0, d.TrashDays)
+		}
+
+		deleted := make(map[uniq.Hash]int, len(albums))
+		for _, img := range images {
+			deleted[img.AlbumHash]++
+		}
+
+		for _, a := range albums {
+			d.Albums = append(d.Albums, trashAlbum{TrashAlbum: a, Images: deleted[a.Hash], PurgeAt: purgeAt(a.RemovedAt)})
+		}
+
+		var albumHashes []uniq.Hash
+
+		for h := range deleted {
+			albumHashes = append(albumHashes, h)
+		}
+
+		existing, err := deps.PhotoAlbumFinder().FindByHashes(ctx, albumHashes...)
+		if err != nil {
+			return err
+		}
+
+		byHash := make(map[uniq.Hash]photo.Album, len(existing))
+		for _, a := range existing {
+			byHash[a.Hash] = a
+		}
+
+		for _, img := range images {
+			a, ok := byHash[img.AlbumHash]
+			if !ok {
+				// Images of deleted album are restored with it.
+				continue
+			}
+
+			d.Images = append(d.Images, trashImage{
+				TrashAlbumImage: img,
+				AlbumName:       a.Name,
+				AlbumTitle:      a.Title,
+				PurgeAt:         purgeAt(img.RemovedAt),
+			})
+		}
+
+		return out.Render(tmpl, d)
+	})
+
+	u.SetTags("Album")
+	u.SetExpectedErrors(status.Unknown)
+
+	return u
+}
+
+// RestoreAlbum creates use case interactor to return deleted album with its images from trash.
+func RestoreAlbum(deps trashDeps) usecase.Interactor {
+	type restoreAlbumInput struct {
+		Name string `path:"name" description:"Name of deleted album."`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, in restoreAlbumInput, out *struct{}) error {
+		album, _, err := deps.TrashRepository().RestoreAlbum(ctx, photo.AlbumHash(in.Name))
+		if err != nil {
+			if errors.Is(err, status.AlreadyExists) {
+				return status.Wrap(errors.New("album with the same name exists"), status.AlreadyExists)
+			}
+
+			return err
+		}
+
+		deps.EventBus().Publish(ctx, event.Album(event.AlbumRestored, album.Name))
+
+		return errors.Join(
+			deps.DepCache().AlbumListChanged(ctx),
+			deps.DepCache().AlbumChanged(ctx, album.Name),
+		)
+	})
+
+	u.SetTags("Album")
+	u.SetExpectedErrors(status.NotFound, status.AlreadyExists)
+
+	return u
+}
+
+// PurgeTrash creates use case interactor to permanently remove expired trash content.
+func PurgeTrash(deps trashDeps) usecase.Interactor {
+	u := usecase.NewInteractor(func(ctx context.Context, in struct{}, out *trash.PurgeResult) (err error) {
+		*out, err = deps.Trash().Purge(ctx, time.Now())
+
+		return err
+	})
+
+	u.SetTags("Album")
+
+	return u
+}
@@ -0,0 +1,169 @@
+package control_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/infra/auth"
+	"github.com/vearutop/photo-blog/internal/infra/settings"
+	"github.com/vearutop/photo-blog/internal/usecase/control"
+)
+
+func TestDeleteAlbum_restore(t *testing.T) {
+	deps := newTestDeps(t)
+	ctx := auth.SetAdmin(context.Background())
+
+	images := addImages(t, deps, "a.jpg", "b.jpg")
+	addAlbum(t, deps, photo.Album{Name: "trip", Title: "Trip"}, images...)
+
+	// Image removed before album deletion goes to trash too.
+	_, _, err := interact(ctx, control.RemoveFromAlbum(deps), map[string]any{"AlbumName": "trip", "ImageHash": images[0]})
+	require.NoError(t, err)
+
+	_, _, err = interact(ctx, control.DeleteAlbum(deps), map[string]any{"Name": "trip"})
+	require.NoError(t, err)
+
+	_, err = deps.FindByHash(ctx, photo.AlbumHash("trip"))
+	require.ErrorIs(t, err, status.NotFound)
+
+	albums, err := deps.TrashRepository().FindAlbums(ctx)
+	require.NoError(t, err)
+	require.Len(t, albums, 1)
+	assert.Equal(t, "trip", albums[0].Name)
+	assert.Equal(t, "admin", albums[0].Actor)
+
+	// Album with the same name blocks restore.
+	addAlbum(t, deps, photo.Album{Name: "trip"})
+
+	_, _, err = interact(ctx, control.RestoreAlbum(deps), map[string]any{"Name": "trip"})
+	require.ErrorIs(t, err, status.AlreadyExists)
+
+	require.NoError(t, deps.Delete(ctx, photo.AlbumHash("trip")))
+
+	_, _, err = interact(ctx, control.RestoreAlbum(deps), map[string]any{"Name": "trip"})
+	require.NoError(t, err)
+
+	album, err := deps.FindByHash(ctx, photo.AlbumHash("trip"))
+	require.NoError(t, err)
+	assert.Equal(t, "Trip", album.Title)
+
+	// Album is restored with all its removed images.
+	assert.ElementsMatch(t, images, albumImages(t, deps, "trip"))
+
+	removed, err := deps.TrashRepository().FindImages(ctx, album.Hash)
+	require.NoError(t, err)
+	assert.Empty(t, removed)
+
+	_, _, err = interact(ctx, control.RestoreAlbum(deps), map[string]any{"Name": "trip"})
+	require.ErrorIs(t, err, status.NotFound)
+
+	res, _, err := interact(ctx, control.GetAlbumAudit(deps), map[string]any{"AlbumName": "trip"})
+	require.NoError(t, err)
+
+	var audit []photo.AuditEntry
+	require.NoError(t, json.Unmarshal([]byte(res), &audit))
+	require.Len(t, audit, 3)
+	assert.Equal(t, photo.AuditRestore, audit[0].Action)
+	assert.Equal(t, photo.AuditDelete, audit[1].Action)
+	assert.Equal(t, photo.AuditRemove, audit[2].Action)
+}
+
+func TestPurgeTrash(t *testing.T) {
+	deps := newTestDeps(t)
+	ctx := auth.SetAdmin(context.Background())
+
+	require.NoError(t, deps.settings.SetStorage(ctx, settings.Storage{TrashDays: 30, TrashDeleteFiles: true}))
+
+	images := addImages(t, deps, "a.jpg", "b.jpg", "c.jpg")
+	a, b, clip := images[0], images[1], images[2]
+
+	// Poster image of a video shares hash with video.
+	img, err := deps.images.FindByHash(ctx, clip)
+	require.NoError(t, err)
+
+	v := photo.Video{}
+	v.Hash = clip
+	v.Path = strings.TrimSuffix(img.Path, ".jpg") + ".mp4"
+	require.NoError(t, os.WriteFile(v.Path, []byte("video"), 0o600))
+	require.NoError(t, deps.videos.Add(ctx, v))
+
+	addAlbum(t, deps, photo.Album{Name: "trip"}, images...)
+	addAlbum(t, deps, photo.Album{Name: "best"}, b)
+
+	// Related records of images.
+	for _, r := range []struct {
+		query string
+		args  []any
+	}{
+		{"INSERT INTO favorite_image (visitor_hash, image_hash) VALUES (1, ?), (1, ?)", []any{a, b}},
+		{"INSERT INTO proofing_mark (album_hash, image_hash, state) VALUES (1, ?, 'selected'), (1, ?, 'selected')", []any{a, b}},
+		{"INSERT INTO thread (hash, type, related_hash) VALUES (10, 'image', ?), (20, 'album', ?)", []any{a, a}},
+		{"INSERT INTO message (hash, thread_hash, text) VALUES (11, 10, 'Nice'), (21, 20, 'Great')", nil},
+	} {
+		_, err := deps.st.DB().ExecContext(ctx, r.query, r.args...)
+		require.NoError(t, err, r.query)
+	}
+
+	_, _, err = interact(ctx, control.DeleteAlbum(deps), map[string]any{"Name": "trip"})
+	require.NoError(t, err)
+
+	// Trash is kept during retention period.
+	res, _, err := interact(ctx, control.PurgeTrash(deps), nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"images":0,"deleted_files":0}`, res)
+
+	pr, err := deps.Trash().Purge(ctx, time.Now().AddDate(0, 0, 31))
+	require.NoError(t, err)
+	assert.Equal(t, 3, pr.Images)
+	assert.Equal(t, 2, pr.DeletedFiles, "image of other album is kept")
+
+	albums, err := deps.TrashRepository().FindAlbums(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, albums)
+
+	for _, p := range []string{img.Path, v.Path} {
+		_, err := os.Stat(p)
+		assert.ErrorIs(t, err, os.ErrNotExist, p)
+	}
+
+	imgB, err := deps.images.FindByHash(ctx, b)
+	require.NoError(t, err)
+
+	_, err = os.Stat(imgB.Path)
+	require.NoError(t, err)
+
+	_, err = deps.images.FindByHash(ctx, a)
+	require.ErrorIs(t, err, status.NotFound)
+
+	_, err = deps.videos.FindByHash(ctx, clip)
+	require.ErrorIs(t, err, status.NotFound)
+
+	// Records of purged images are removed, records of kept image and album thread stay.
+	for _, r := range []struct {
+		query string
+		arg   any
+		count int
+	}{
+		{"SELECT count(*) FROM favorite_image WHERE image_hash = ?", a, 0},
+		{"SELECT count(*) FROM favorite_image WHERE image_hash = ?", b, 1},
+		{"SELECT count(*) FROM proofing_mark WHERE image_hash = ?", a, 0},
+		{"SELECT count(*) FROM proofing_mark WHERE image_hash = ?", b, 1},
+		{"SELECT count(*) FROM thread WHERE hash = ?", 10, 0},
+		{"SELECT count(*) FROM message WHERE thread_hash = ?", 10, 0},
+		{"SELECT count(*) FROM thread WHERE hash = ?", 20, 1},
+		{"SELECT count(*) FROM message WHERE thread_hash = ?", 20, 1},
+	} {
+		var cnt int
+
+		require.NoError(t, deps.st.DB().QueryRowContext(ctx, r.query, r.arg).Scan(&cnt), r.query)
+		assert.Equal(t, r.count, cnt, r.query, r.arg)
+	}
+}
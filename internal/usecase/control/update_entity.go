@@ -8,15 +8,18 @@ import (
 	"github.com/bool64/stats"
 	"github.com/swaggest/usecase"
 	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/domain/event"
 	"github.com/vearutop/photo-blog/internal/domain/photo"
 	"github.com/vearutop/photo-blog/internal/domain/uniq"
 	"github.com/vearutop/photo-blog/internal/infra/dep"
+	"github.com/vearutop/photo-blog/internal/infra/events"
 )
 
 type updateEntityDeps interface {
 	StatsTracker() stats.Tracker
 	CtxdLogger() ctxd.Logger
 	DepCache() *dep.Cache
+	EventBus() *events.Bus
 }
 
 // Update creates use case interactor to update entity data.
@@ -33,6 +36,7 @@ func Update[V any](deps updateEntityDeps, ensurer func() uniq.Ensurer[V]) usecas
 		if err == nil {
 			if a, ok := any(in).(photo.Album); ok {
 				err = deps.DepCache().AlbumChanged(ctx, a.Name)
+				deps.EventBus().Publish(ctx, event.Album(event.AlbumUpdated, a.Name))
 			} else if img, ok := any(in).(photo.Image); ok {
 				err = deps.DepCache().ImageChanged(ctx, img.Hash)
 			}
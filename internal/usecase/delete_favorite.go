@@ -12,6 +12,7 @@ import (
 func DeleteFavorite(deps FavoriteDeps) usecase.Interactor {
 	type deleteFavorite struct {
 		ImageHash uniq.Hash `query:"image_hash"`
+		ListHash  uniq.Hash `query:"list_hash" description:"Named list, default list is used if empty."`
 	}
 
 	u := usecase.NewInteractor(func(ctx context.Context, input deleteFavorite, output *struct{}) error {
@@ -21,7 +22,11 @@ func DeleteFavorite(deps FavoriteDeps) usecase.Interactor {
 			return errors.New("missing visitor hash")
 		}
 
-		return deps.FavoriteRepository().DeleteImages(ctx, visitorHash, input.ImageHash)
+		if err := checkFavoriteList(ctx, deps, visitorHash, input.ListHash); err != nil {
+			return err
+		}
+
+		return deps.FavoriteRepository().DeleteImages(ctx, visitorHash, input.ListHash, input.ImageHash)
 	})
 
 	return u
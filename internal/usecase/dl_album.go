@@ -33,8 +33,9 @@ type dlAlbumDeps interface {
 }
 
 type dlAlbumInput struct {
-	Name     string `path:"name"`
-	Favorite bool   `query:"favorite"`
+	Name     string    `path:"name"`
+	Favorite bool      `query:"favorite"`
+	ListHash uniq.Hash `query:"list_hash" description:"Named list of favorites, default list is used if empty."`
 }
 
 func DownloadAlbum(deps dlAlbumDeps) usecase.Interactor {
@@ -60,7 +61,18 @@ func DownloadAlbum(deps dlAlbumDeps) usecase.Interactor {
 				return status.PermissionDenied
 			}
 
-			images, err = deps.FavoriteRepository().FindAlbumImages(ctx, visitorHash, album.Hash)
+			if in.ListHash != 0 {
+				l, err := deps.FavoriteRepository().FindList(ctx, in.ListHash)
+				if err != nil {
+					return err
+				}
+
+				if l.VisitorHash != visitorHash {
+					return status.PermissionDenied
+				}
+			}
+
+			images, err = deps.FavoriteRepository().FindAlbumImages(ctx, visitorHash, in.ListHash, album.Hash)
 		} else {
 			images, err = deps.PhotoAlbumImageFinder().FindImages(ctx, album.Hash)
 		}
@@ -1,11 +1,15 @@
 package usecase
 
 import (
+	"bytes"
 	"context"
 	"net/http"
+	"path"
+	"strings"
 
 	"github.com/swaggest/rest/response"
 	"github.com/swaggest/usecase"
+	gpxgo "github.com/tkrajina/gpxgo/gpx"
 	"github.com/vearutop/photo-blog/internal/domain/photo"
 	"github.com/vearutop/photo-blog/internal/domain/uniq"
 )
@@ -26,7 +30,24 @@ func DownloadGpx(deps dlGpxDeps) usecase.Interactor {
 		rw.Header().Set("Cache-Control", "max-age=31536000")
 		rw.Header().Set("Content-Type", "application/gpx+xml")
 
-		http.ServeFile(rw, in.Request(), gpx.Path)
+		if strings.EqualFold(path.Ext(gpx.Path), ".gpx") {
+			http.ServeFile(rw, in.Request(), gpx.Path)
+
+			return nil
+		}
+
+		// Other track formats are converted to GPX.
+		g, err := gpx.Load()
+		if err != nil {
+			return err
+		}
+
+		x, err := g.ToXml(gpxgo.ToXmlParams{Version: "1.1"})
+		if err != nil {
+			return err
+		}
+
+		http.ServeContent(rw, in.Request(), in.Hash.String()+".gpx", gpx.CreatedAt, bytes.NewReader(x))
 
 		return nil
 	})
@@ -0,0 +1,226 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"path"
+	"strings"
+
+	"github.com/swaggest/rest/response"
+	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/auth"
+	"github.com/vearutop/photo-blog/internal/infra/storage"
+)
+
+type favoriteList struct {
+	storage.FavoriteList
+	Link string `json:"link" description:"Read-only link to share."`
+}
+
+func makeFavoriteList(l storage.FavoriteList) favoriteList {
+	return favoriteList{
+		FavoriteList: l,
+		Link:         "/" + photo.FavoriteListPrefix + l.Hash.String() + "/",
+	}
+}
+
+// GetFavoriteLists returns named favorite lists of current visitor.
+func GetFavoriteLists(deps FavoriteDeps) usecase.Interactor {
+	u := usecase.NewInteractor(func(ctx context.Context, _ struct{}, out *[]favoriteList) error {
+		visitorHash := auth.VisitorFromContext(ctx)
+		if visitorHash == 0 {
+			return errors.New("missing visitor hash")
+		}
+
+		lists, err := deps.FavoriteRepository().FindLists(ctx, visitorHash)
+		if err != nil {
+			return err
+		}
+
+		*out = make([]favoriteList, 0, len(lists))
+		for _, l := range lists {
+			*out = append(*out, makeFavoriteList(l))
+		}
+
+		return nil
+	})
+
+	u.SetTags("Favorite")
+
+	return u
+}
+
+// SaveFavoriteList creates a named favorite list or renames existing one.
+func SaveFavoriteList(deps FavoriteDeps) usecase.Interactor {
+	type saveList struct {
+		ListHash uniq.Hash `json:"list_hash,omitempty" description:"Existing list to rename, new list is created if empty."`
+		Name     string    `json:"name" required:"true" minLength:"1" maxLength:"200"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, in saveList, out *favoriteList) error {
+		visitorHash := auth.VisitorFromContext(ctx)
+		if visitorHash == 0 {
+			return errors.New("missing visitor hash")
+		}
+
+		name := strings.TrimSpace(in.Name)
+
+		if in.ListHash == 0 {
+			l, err := deps.FavoriteRepository().CreateList(ctx, visitorHash, name)
+			if err != nil {
+				return err
+			}
+
+			*out = makeFavoriteList(l)
+
+			return nil
+		}
+
+		l, err := deps.FavoriteRepository().FindList(ctx, in.ListHash)
+		if err != nil {
+			return err
+		}
+
+		if l.VisitorHash != visitorHash {
+			return status.PermissionDenied
+		}
+
+		l.Name = name
+
+		if err := deps.FavoriteRepository().RenameList(ctx, l); err != nil {
+			return err
+		}
+
+		*out = makeFavoriteList(l)
+
+		return nil
+	})
+
+	u.SetTags("Favorite")
+	u.SetExpectedErrors(status.PermissionDenied, status.NotFound)
+
+	return u
+}
+
+// DeleteFavoriteList removes a named favorite list with its images.
+func DeleteFavoriteList(deps FavoriteDeps) usecase.Interactor {
+	type deleteList struct {
+		ListHash uniq.Hash `query:"list_hash" required:"true"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, in deleteList, out *struct{}) error {
+		visitorHash := auth.VisitorFromContext(ctx)
+		if visitorHash == 0 {
+			return errors.New("missing visitor hash")
+		}
+
+		l, err := deps.FavoriteRepository().FindList(ctx, in.ListHash)
+		if err != nil {
+			return err
+		}
+
+		if l.VisitorHash != visitorHash {
+			return status.PermissionDenied
+		}
+
+		return deps.FavoriteRepository().DeleteList(ctx, l)
+	})
+
+	u.SetTags("Favorite")
+	u.SetExpectedErrors(status.PermissionDenied, status.NotFound)
+
+	return u
+}
+
+// SetFavoriteNote updates a note of a favorite image.
+func SetFavoriteNote(deps FavoriteDeps) usecase.Interactor {
+	type setNote struct {
+		ImageHash uniq.Hash `json:"image_hash" required:"true"`
+		ListHash  uniq.Hash `json:"list_hash,omitempty" description:"Named list, default list is used if empty."`
+		Note      string    `json:"note" maxLength:"2000"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, in setNote, out *struct{}) error {
+		visitorHash := auth.VisitorFromContext(ctx)
+		if visitorHash == 0 {
+			return errors.New("missing visitor hash")
+		}
+
+		if err := checkFavoriteList(ctx, deps, visitorHash, in.ListHash); err != nil {
+			return err
+		}
+
+		return deps.FavoriteRepository().SetNote(ctx, visitorHash, in.ListHash, in.ImageHash, strings.TrimSpace(in.Note))
+	})
+
+	u.SetTags("Favorite")
+	u.SetExpectedErrors(status.PermissionDenied, status.NotFound)
+
+	return u
+}
+
+// ExportFavoriteList serves CSV with file names and notes of a named list, anyone with list hash can read it.
+func ExportFavoriteList(deps FavoriteDeps) usecase.Interactor {
+	type exportList struct {
+		ListHash uniq.Hash `path:"hash"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, in exportList, out *response.EmbeddedSetter) error {
+		l, err := deps.FavoriteRepository().FindList(ctx, in.ListHash)
+		if err != nil {
+			return err
+		}
+
+		images, err := deps.FavoriteRepository().FindImages(ctx, l.VisitorHash, l.Hash)
+		if err != nil {
+			return err
+		}
+
+		notes, err := deps.FavoriteRepository().FindNotes(ctx, l.VisitorHash, l.Hash)
+		if err != nil {
+			return err
+		}
+
+		rw := out.ResponseWriter()
+		rw.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		rw.Header().Set("Content-Disposition", `attachment; filename="`+photo.FavoriteListPrefix+l.Hash.String()+`.csv"`)
+
+		w := csv.NewWriter(rw)
+
+		if err := w.Write([]string{"file", "hash", "note"}); err != nil {
+			return err
+		}
+
+		for _, img := range images {
+			if err := w.Write([]string{
+				csvCell(path.Base(strings.TrimSuffix(img.Path, "."+img.Hash.String()+".jpg"))),
+				img.Hash.String(),
+				csvCell(notes[img.Hash]),
+			}); err != nil {
+				return err
+			}
+		}
+
+		w.Flush()
+
+		return w.Error()
+	})
+
+	u.SetTags("Favorite")
+	u.SetExpectedErrors(status.NotFound)
+
+	return u
+}
+
+// csvCell prevents spreadsheet applications from evaluating text as formula.
+func csvCell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+
+	return s
+}
@@ -0,0 +1,118 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/auth"
+	"github.com/vearutop/photo-blog/internal/infra/storage"
+	"github.com/vearutop/photo-blog/internal/usecase"
+)
+
+func TestFavoriteLists(t *testing.T) {
+	st := testStorage(t)
+	deps := storage.NewFavoriteRepository(st)
+	images := addImages(t, st, "a.jpg", "b.jpg", "=HYPERLINK(1).jpg")
+
+	alice := auth.ContextWithVisitor(context.Background(), 1)
+	bob := auth.ContextWithVisitor(context.Background(), 2)
+
+	type list struct {
+		Hash uniq.Hash `json:"hash"`
+		Name string    `json:"name"`
+		Link string    `json:"link"`
+	}
+
+	res, _, err := interact(alice, usecase.SaveFavoriteList(deps), map[string]any{"name": " Wedding "})
+	require.NoError(t, err)
+
+	var wedding list
+	require.NoError(t, json.Unmarshal([]byte(res), &wedding))
+	assert.NotZero(t, wedding.Hash)
+	assert.Equal(t, "Wedding", wedding.Name)
+	assert.Equal(t, "/favorite-list-"+wedding.Hash.String()+"/", wedding.Link)
+
+	res, _, err = interact(alice, usecase.SaveFavoriteList(deps), map[string]any{"name": "Prints"})
+	require.NoError(t, err)
+
+	var prints list
+	require.NoError(t, json.Unmarshal([]byte(res), &prints))
+	assert.NotEqual(t, wedding.Hash, prints.Hash)
+
+	// Images go to a named list or to the default one.
+	for _, h := range images {
+		_, _, err = interact(alice, usecase.AddFavorite(deps), map[string]any{"ImageHash": h, "ListHash": wedding.Hash})
+		require.NoError(t, err)
+	}
+
+	_, _, err = interact(alice, usecase.AddFavorite(deps), map[string]any{"ImageHash": images[0]})
+	require.NoError(t, err)
+
+	res, _, err = interact(alice, usecase.GetFavorite(deps), map[string]any{"ListHash": wedding.Hash})
+	require.NoError(t, err)
+	assert.Len(t, unmarshalHashes(t, res), 3)
+
+	res, _, err = interact(alice, usecase.GetFavorite(deps), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []uniq.Hash{images[0]}, unmarshalHashes(t, res))
+
+	res, _, err = interact(alice, usecase.GetFavoriteLists(deps), nil)
+	require.NoError(t, err)
+
+	var lists []list
+	require.NoError(t, json.Unmarshal([]byte(res), &lists))
+	require.Len(t, lists, 2)
+	assert.Equal(t, "Wedding", lists[0].Name)
+	assert.Equal(t, "Prints", lists[1].Name)
+
+	_, _, err = interact(alice, usecase.SetFavoriteNote(deps),
+		map[string]any{"image_hash": images[1], "list_hash": wedding.Hash, "note": "Crop square"})
+	require.NoError(t, err)
+
+	// Lists of other visitors are read-only.
+	res, _, err = interact(bob, usecase.GetFavoriteLists(deps), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "[]", res)
+
+	_, _, err = interact(bob, usecase.AddFavorite(deps), map[string]any{"ImageHash": images[0], "ListHash": prints.Hash})
+	assert.ErrorIs(t, err, status.PermissionDenied)
+
+	_, _, err = interact(bob, usecase.SaveFavoriteList(deps), map[string]any{"list_hash": wedding.Hash, "name": "Mine"})
+	assert.ErrorIs(t, err, status.PermissionDenied)
+
+	_, _, err = interact(bob, usecase.DeleteFavoriteList(deps), map[string]any{"ListHash": wedding.Hash})
+	assert.ErrorIs(t, err, status.PermissionDenied)
+
+	// Anyone with the list hash can export it.
+	_, rec, err := interact(bob, usecase.ExportFavoriteList(deps), map[string]any{"ListHash": wedding.Hash})
+	require.NoError(t, err)
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "file,hash,note\n")
+	assert.Contains(t, rec.Body.String(), "b.jpg,"+images[1].String()+",Crop square\n")
+	assert.Contains(t, rec.Body.String(), "'=HYPERLINK(1).jpg,"+images[2].String()+",\n", "formula must be escaped")
+
+	_, _, err = interact(alice, usecase.DeleteFavoriteList(deps), map[string]any{"ListHash": wedding.Hash})
+	require.NoError(t, err)
+
+	_, _, err = interact(alice, usecase.ExportFavoriteList(deps), map[string]any{"ListHash": wedding.Hash})
+	assert.ErrorIs(t, err, status.NotFound)
+
+	// Default list is intact.
+	res, _, err = interact(alice, usecase.GetFavorite(deps), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []uniq.Hash{images[0]}, unmarshalHashes(t, res))
+}
+
+func unmarshalHashes(t *testing.T, res string) []uniq.Hash {
+	t.Helper()
+
+	var hashes []uniq.Hash
+	require.NoError(t, json.Unmarshal([]byte(res), &hashes))
+
+	return hashes
+}
@@ -0,0 +1,350 @@
+package usecase
+
+import (
+	"cmp"
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bool64/ctxd"
+	"github.com/swaggest/rest/request"
+	"github.com/swaggest/rest/response"
+	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/settings"
+	"github.com/vearutop/photo-blog/pkg/feed"
+	"github.com/vearutop/photo-blog/pkg/txt"
+)
+
+// Feed formats.
+const (
+	FeedAtom = "atom"
+	FeedJSON = "json"
+)
+
+const (
+	feedAlbumsLimit = 20
+	feedImagesLimit = 50
+)
+
+type feedDeps interface {
+	CtxdLogger() ctxd.Logger
+	Settings() settings.Values
+	TxtRenderer() *txt.Renderer
+	PhotoAlbumFinder() uniq.Finder[photo.Album]
+	PhotoAlbumImageFinder() photo.AlbumImageFinder
+}
+
+// ServeFeed creates use case interactor to serve feed of recently updated public albums and new images.
+func ServeFeed(deps feedDeps, format string) usecase.Interactor {
+	type feedInput struct {
+		request.EmbeddedSetter
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, in feedInput, out *response.EmbeddedSetter) error {
+		albums, err := deps.PhotoAlbumFinder().FindAll(ctx)
+		if err != nil {
+			return err
+		}
+
+		albums = slices.DeleteFunc(albums, func(a photo.Album) bool {
+			return !inFeed(a)
+		})
+
+		slices.SortFunc(albums, func(a, b photo.Album) int {
+			return b.UpdatedAt.Compare(a.UpdatedAt)
+		})
+
+		fb := newFeedBuilder(ctx, deps, in.Request())
+		f := fb.feed("/", format)
+
+		if len(albums) > 0 {
+			f.Updated = albums[0].UpdatedAt
+		}
+
+		if fb.notModified(out.ResponseWriter(), f.Updated) {
+			return nil
+		}
+
+		albumHashes := make([]uniq.Hash, 0, len(albums))
+		for _, a := range albums {
+			albumHashes = append(albumHashes, a.Hash)
+		}
+
+		for i, a := range albums {
+			if i >= feedAlbumsLimit {
+				break
+			}
+
+			f.Items = append(f.Items, fb.albumItem(a))
+		}
+
+		images, err := deps.PhotoAlbumImageFinder().FindRecentImages(ctx, feedImagesLimit, albumHashes...)
+		if err != nil {
+			return err
+		}
+
+		if err := fb.imageItems(&f, images, albums); err != nil {
+			return err
+		}
+
+		return fb.write(out.ResponseWriter(), f, format)
+	})
+
+	u.SetTitle("Site " + format + " feed")
+	u.SetName("ServeFeed[" + format + "]")
+	u.SetTags("SEO")
+	u.SetExpectedErrors(status.Unknown)
+
+	return u
+}
+
+// ServeAlbumFeed creates use case interactor to serve feed of new album images.
+func ServeAlbumFeed(deps feedDeps, format string) usecase.Interactor {
+	type albumFeedInput struct {
+		request.EmbeddedSetter
+		Name string `path:"name"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, in albumFeedInput, out *response.EmbeddedSetter) error {
+		album, err := deps.PhotoAlbumFinder().FindByHash(ctx, photo.AlbumHash(in.Name))
+		if err != nil {
+			return err
+		}
+
+		if !inFeed(album) {
+			return status.NotFound
+		}
+
+		fb := newFeedBuilder(ctx, deps, in.Request())
+		f := fb.feed("/"+album.Name+"/", format)
+		f.Title = fb.text(album.Title)
+		f.Subtitle = fb.plain(album.Settings.Description, album.Settings.TextReplaces)
+		f.Updated = album.UpdatedAt
+
+		if fb.notModified(out.ResponseWriter(), f.Updated) {
+			return nil
+		}
+
+		images, err := deps.PhotoAlbumImageFinder().FindRecentImages(ctx, feedImagesLimit, album.Hash)
+		if err != nil {
+			return err
+		}
+
+		if err := fb.imageItems(&f, images, []photo.Album{album}); err != nil {
+			return err
+		}
+
+		return fb.write(out.ResponseWriter(), f, format)
+	})
+
+	u.SetTitle("Album " + format + " feed")
+	u.SetName("ServeAlbumFeed[" + format + "]")
+	u.SetTags("SEO")
+	u.SetExpectedErrors(status.Unknown, status.NotFound)
+
+	return u
+}
+
+// inFeed is true for albums listed on the main page.
+func inFeed(a photo.Album) bool {
+	return a.Public && !a.Hidden && a.Name != "" && a.Settings.Redirect == ""
+}
+
+type feedBuilder struct {
+	ctx     context.Context
+	deps    feedDeps
+	r       *http.Request
+	baseURL string
+}
+
+func newFeedBuilder(ctx context.Context, deps feedDeps, r *http.Request) *feedBuilder {
+	baseURL := deps.Settings().Appearance().CanonicalBaseURL
+	if baseURL == "" {
+		baseURL = "https://" + r.Host
+	}
+
+	return &feedBuilder{
+		ctx:     ctx,
+		deps:    deps,
+		r:       r,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+func (fb *feedBuilder) feed(path string, format string) feed.Feed {
+	return feed.Feed{
+		ID:       fb.baseURL + path,
+		Title:    fb.text(fb.deps.Settings().Appearance().SiteTitle),
+		Language: txt.Language(fb.ctx),
+		Link:     fb.baseURL + path,
+		FeedURL:  fb.baseURL + path + "feed." + format,
+		Icon:     fb.baseURL + "/favicon.ico",
+	}
+}
+
+// text renders a title in current language as plain text.
+func (fb *feedBuilder) text(s string) string {
+	return fb.deps.TxtRenderer().MustRenderLang(fb.ctx, s, txt.StripTags)
+}
+
+func (fb *feedBuilder) replaces(albumReplaces txt.Replaces) txt.Replaces {
+	return append(append(txt.Replaces(nil), fb.deps.Settings().Appearance().TextReplaces...), albumReplaces...)
+}
+
+// plain renders a description in current language as plain text.
+func (fb *feedBuilder) plain(s string, albumReplaces txt.Replaces) string {
+	return fb.deps.TxtRenderer().MustRenderLang(fb.ctx, s, fb.replaces(albumReplaces).Apply, txt.StripTags)
+}
+
+// html renders a description in current language.
+func (fb *feedBuilder) html(s string, albumReplaces txt.Replaces) string {
+	return fb.deps.TxtRenderer().MustRenderLang(fb.ctx, s, fb.replaces(albumReplaces).Apply)
+}
+
+func (fb *feedBuilder) thumb(hash uniq.Hash) string {
+	return fb.baseURL + "/thumb/1200w/" + hash.String() + ".jpg"
+}
+
+func (fb *feedBuilder) albumItem(a photo.Album) feed.Item {
+	it := feed.Item{
+		ID:      fb.baseURL + "/" + a.Name + "/",
+		Title:   fb.text(a.Title),
+		Link:    fb.baseURL + "/" + a.Name + "/",
+		Summary: fb.plain(a.Settings.Description, a.Settings.TextReplaces),
+		Content: fb.html(a.Settings.Description, a.Settings.TextReplaces),
+		Updated: a.UpdatedAt,
+	}
+
+	if a.CreatedAt.IsZero() {
+		it.Published = a.UpdatedAt
+	} else {
+		it.Published = a.CreatedAt
+	}
+
+	cover := a.CoverImage
+	if cover == 0 {
+		if preview, err := fb.deps.PhotoAlbumImageFinder().FindPreviewImages(fb.ctx, a.Hash, 0, 1); err != nil {
+			fb.deps.CtxdLogger().Warn(fb.ctx, "failed to find album preview", "error", err, "album", a.Name)
+		} else if len(preview) > 0 {
+			cover = preview[0].Hash
+		}
+	}
+
+	if cover != 0 {
+		it.Image = fb.thumb(cover)
+	}
+
+	return it
+}
+
+// imageItems adds images to feed, each image is linked to the first of given albums it belongs to.
+func (fb *feedBuilder) imageItems(f *feed.Feed, images []photo.Image, albums []photo.Album) error {
+	if len(images) == 0 {
+		return nil
+	}
+
+	hashes := make([]uniq.Hash, 0, len(images))
+	for _, img := range images {
+		hashes = append(hashes, img.Hash)
+	}
+
+	imageAlbums, err := fb.deps.PhotoAlbumImageFinder().FindImageAlbums(fb.ctx, 0, hashes...)
+	if err != nil {
+		return err
+	}
+
+	allowed := make(map[uniq.Hash]bool, len(albums))
+	for _, a := range albums {
+		allowed[a.Hash] = true
+	}
+
+	for _, img := range images {
+		var album photo.Album
+
+		for _, a := range imageAlbums[img.Hash] {
+			if allowed[a.Hash] {
+				album = a
+
+				break
+			}
+		}
+
+		if album.Name == "" {
+			continue
+		}
+
+		link := fb.baseURL + "/" + album.Name + "/photo-" + img.Hash.String() + ".html"
+		title := fb.text(album.Title)
+
+		if img.TakenAt != nil {
+			title += ", " + img.TakenAt.Format("2 Jan 2006")
+		}
+
+		f.Items = append(f.Items, feed.Item{
+			ID:        link,
+			Title:     title,
+			Link:      link,
+			Summary:   fb.plain(img.Settings.Description, album.Settings.TextReplaces),
+			Content:   fb.html(img.Settings.Description, album.Settings.TextReplaces),
+			Image:     fb.thumb(img.Hash),
+			Published: img.CreatedAt,
+			Updated:   img.CreatedAt,
+		})
+	}
+
+	slices.SortStableFunc(f.Items, func(a, b feed.Item) int {
+		return cmp.Compare(b.Updated.Unix(), a.Updated.Unix())
+	})
+
+	return nil
+}
+
+// notModified writes Last-Modified header and responds with 304 if feed was not updated since If-Modified-Since.
+func (fb *feedBuilder) notModified(rw http.ResponseWriter, updated time.Time) bool {
+	if updated.IsZero() {
+		return false
+	}
+
+	updated = updated.UTC().Truncate(time.Second)
+
+	rw.Header().Set("Last-Modified", updated.Format(http.TimeFormat))
+	rw.Header().Set("Cache-Control", "public, max-age=600")
+
+	if ims, err := http.ParseTime(fb.r.Header.Get("If-Modified-Since")); err == nil && !updated.After(ims) {
+		rw.WriteHeader(http.StatusNotModified)
+
+		return true
+	}
+
+	return false
+}
+
+func (fb *feedBuilder) write(rw http.ResponseWriter, f feed.Feed, format string) error {
+	var (
+		body []byte
+		err  error
+	)
+
+	if format == FeedJSON {
+		rw.Header().Set("Content-Type", feed.JSONContentType)
+		body, err = f.JSON()
+	} else {
+		rw.Header().Set("Content-Type", feed.AtomContentType)
+		body, err = f.Atom()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if _, err = rw.Write(body); err != nil {
+		fb.deps.CtxdLogger().Error(fb.ctx, "write feed", "error", err)
+	}
+
+	return nil
+}
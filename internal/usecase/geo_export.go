@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/swaggest/rest/request"
+	"github.com/swaggest/rest/response"
+	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/auth"
+	"github.com/vearutop/photo-blog/internal/infra/settings"
+	"github.com/vearutop/photo-blog/pkg/geoexport"
+)
+
+// Geo export formats.
+const (
+	GeoJSON = "geojson"
+	KML     = "kml"
+)
+
+// allPublicAlbums is an album name to export all public albums.
+const allPublicAlbums = "-"
+
+type geoExportInput struct {
+	request.EmbeddedSetter
+	Name string `path:"name" description:"Album name, use '-' for all public albums."`
+}
+
+// baseURL returns canonical base URL without trailing slash, request host is used if it is not configured.
+func baseURL(a settings.Appearance, r *http.Request) string {
+	if a.CanonicalBaseURL != "" {
+		return strings.TrimSuffix(a.CanonicalBaseURL, "/")
+	}
+
+	return "https://" + r.Host
+}
+
+// ExportGeo creates use case interactor to export photo positions and tracks of an album as GeoJSON or KML.
+func ExportGeo(deps getAlbumImagesDeps, format string) usecase.Interactor {
+	u := usecase.NewInteractor(func(ctx context.Context, in geoExportInput, out *response.EmbeddedSetter) error {
+		deps.StatsTracker().Add(ctx, "export_geo", 1)
+		deps.CtxdLogger().Info(ctx, "exporting geo data", "name", in.Name, "format", format)
+
+		var albumNames []string
+
+		col := geoexport.Collection{}
+
+		if in.Name == allPublicAlbums {
+			albums, err := deps.PhotoAlbumFinder().FindAll(ctx)
+			if err != nil {
+				return fmt.Errorf("find albums: %w", err)
+			}
+
+			for _, a := range albums {
+				if a.Public && a.Name != "" {
+					albumNames = append(albumNames, a.Name)
+				}
+			}
+
+			col.Name = deps.Settings().Appearance().SiteTitle
+		} else {
+			albumNames = append(albumNames, in.Name)
+		}
+
+		base := baseURL(deps.Settings().Appearance(), in.Request())
+		hideGeo := !auth.IsAdmin(ctx) && deps.Settings().Privacy().HideGeoPosition
+		seen := map[string]bool{}
+		seenTracks := map[uniq.Hash]bool{}
+
+		for _, name := range albumNames {
+			cont, err := getAlbumContents(ctx, deps, imagesFilter{albumName: name}, false)
+			if err != nil {
+				return fmt.Errorf("get album contents: %w", err)
+			}
+
+			if col.Name == "" {
+				col.Name = cont.Album.Title
+			}
+
+			for _, img := range cont.Images {
+				if img.Gps == nil || seen[img.Hash] {
+					continue
+				}
+
+				seen[img.Hash] = true
+
+				col.Places = append(col.Places, geoexport.Place{
+					Position: geoexport.Position{
+						Lat: img.Gps.Latitude,
+						Lon: img.Gps.Longitude,
+						Alt: img.Gps.Altitude,
+					},
+					Name:        img.Name,
+					Description: img.Description,
+					URL:         base + "/" + cont.Album.Name + "/photo-" + img.Hash + ".html",
+					Thumb:       base + "/thumb/300w/" + img.Hash + ".jpg",
+					Time:        img.Gps.GpsTime,
+					Properties: map[string]any{
+						"hash":  img.Hash,
+						"album": cont.Album.Name,
+					},
+				})
+			}
+
+			// Tracks reveal positions as much as images do.
+			if hideGeo {
+				continue
+			}
+
+			tracks, err := deps.PhotoGpxFinder().FindByHashes(ctx, cont.Album.Settings.GpxTracksHashes...)
+			if err != nil {
+				return fmt.Errorf("find gpx tracks: %w", err)
+			}
+
+			for _, tr := range tracks {
+				if seenTracks[tr.Hash] {
+					continue
+				}
+
+				seenTracks[tr.Hash] = true
+
+				g, err := tr.Load()
+				if err != nil {
+					return fmt.Errorf("load gpx: %w", err)
+				}
+
+				t := geoexport.Track{Name: tr.Settings.Val.Name}
+				if t.Name == "" {
+					t.Name = path.Base(tr.Path)
+				}
+
+				for _, gt := range g.Tracks {
+					for _, gs := range gt.Segments {
+						seg := make([]geoexport.Position, 0, len(gs.Points))
+
+						for _, p := range gs.Points {
+							seg = append(seg, geoexport.Position{Lat: p.Latitude, Lon: p.Longitude, Alt: p.Elevation.Value()})
+						}
+
+						t.Segments = append(t.Segments, seg)
+					}
+				}
+
+				col.Tracks = append(col.Tracks, t)
+			}
+		}
+
+		var (
+			data        []byte
+			err         error
+			contentType string
+		)
+
+		switch format {
+		case GeoJSON:
+			data, err = col.GeoJSON()
+			contentType = "application/geo+json"
+		case KML:
+			data, err = col.KML()
+			contentType = "application/vnd.google-earth.kml+xml"
+		default:
+			return status.Wrap(fmt.Errorf("unknown format: %s", format), status.InvalidArgument)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		fn := in.Name
+		if fn == allPublicAlbums {
+			fn = "photos"
+		}
+
+		rw := out.ResponseWriter()
+		rw.Header().Set("Content-Type", contentType)
+		rw.Header().Set("Content-Disposition", `attachment; filename="`+fn+"."+format+`"`)
+
+		http.ServeContent(rw, in.Request(), fn+"."+format, time.Now(), bytes.NewReader(data))
+
+		return nil
+	})
+
+	u.SetTags("Album")
+	u.SetExpectedErrors(status.Unknown, status.InvalidArgument, status.NotFound)
+
+	return u
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/vearutop/photo-blog/internal/domain/uniq"
 	"github.com/vearutop/photo-blog/internal/infra/auth"
 	"github.com/vearutop/photo-blog/internal/infra/dep"
+	"github.com/vearutop/photo-blog/internal/infra/engagement"
 	"github.com/vearutop/photo-blog/internal/infra/image/sprite"
 	"github.com/vearutop/photo-blog/internal/infra/service"
 	"github.com/vearutop/photo-blog/internal/infra/settings"
@@ -39,11 +40,13 @@ type getAlbumImagesDeps interface {
 	PhotoMetaFinder() uniq.Finder[photo.Meta]
 	Settings() settings.Values
 	PhotoGpxFinder() uniq.Finder[photo.Gpx]
+	PhotoVideoFinder() uniq.Finder[photo.Video]
 	VisitorStats() *visitor.StatsRepository
 	FavoriteRepository() *storage.FavoriteRepository
 	DepCache() *dep.Cache
 	ImageSelector() *storage.ImageSelector
 	PersistentCacheStorage() *sqluct.Storage
+	Engagement() *engagement.Service
 
 	service.TxtRendererProvider
 }
@@ -68,6 +71,8 @@ type Image struct {
 	Size            int64           `json:"size,omitempty"`
 	UTime           int64           `json:"utime"`
 	Meta            *photo.MetaData `json:"meta,omitempty"`
+	Video           *photo.Video    `json:"video,omitempty"`
+	Edited          bool            `json:"edited,omitempty"`
 }
 
 type track struct {
@@ -94,6 +99,12 @@ func GetAlbumContents(deps getAlbumImagesDeps) usecase.IOInteractorOf[getAlbumIn
 
 		*out, err = getAlbumContents(ctx, deps, imagesFilter{albumName: in.Name}, false)
 
+		if !auth.IsAdmin(ctx) {
+			out.Album.Settings.CollabKey = ""
+			out.Album.Settings.Collaborators = nil
+			out.Album.Settings.ProofingKey = ""
+		}
+
 		return err
 	})
 
@@ -152,6 +163,28 @@ func buildAlbumContents(ctx context.Context, deps getAlbumImagesDeps, filter ima
 		images, err = deps.PhotoImageFinder().FindByHashes(ctx, hashes...)
 	}
 
+	if strings.HasPrefix(name, photo.FavoriteListPrefix) {
+		var listHash uniq.Hash
+
+		if err := listHash.UnmarshalText([]byte(strings.TrimPrefix(name, photo.FavoriteListPrefix))); err != nil {
+			return out, status.Wrap(fmt.Errorf("decode list hash: %w", err), status.InvalidArgument)
+		}
+
+		l, err := deps.FavoriteRepository().FindList(ctx, listHash)
+		if err != nil {
+			return out, err
+		}
+
+		album.Title = l.Name
+		album.Name = name
+		name = "list"
+
+		images, err = deps.FavoriteRepository().FindImages(ctx, l.VisitorHash, l.Hash)
+		if err != nil {
+			return out, err
+		}
+	}
+
 	if strings.HasPrefix(name, "search:") {
 		query = strings.TrimPrefix(name, "search:")
 		name = "search"
@@ -168,7 +201,7 @@ func buildAlbumContents(ctx context.Context, deps getAlbumImagesDeps, filter ima
 
 		album.Title = "Favorite Photos"
 		album.Name = photo.Favorite
-		images, err = deps.FavoriteRepository().FindImages(ctx, visitorHash)
+		images, err = deps.FavoriteRepository().FindImages(ctx, visitorHash, 0)
 
 	case "search":
 		if !auth.IsAdmin(ctx) {
@@ -215,6 +248,12 @@ func buildAlbumContents(ctx context.Context, deps getAlbumImagesDeps, filter ima
 
 	out.Album = album
 
+	if album.Settings.MostEngagingFirst && !preview {
+		if err := deps.Engagement().SortImages(ctx, images); err != nil {
+			return out, err
+		}
+	}
+
 	if err := out.prepare(ctx, deps, images, preview); err != nil {
 		return out, err
 	}
@@ -251,9 +290,22 @@ func (out *getAlbumOutput) prepare(ctx context.Context, deps getAlbumImagesDeps,
 		gpsData  = map[uniq.Hash]photo.Gps{}
 		exifData = map[uniq.Hash]photo.Exif{}
 		metaData = map[uniq.Hash]photo.Meta{}
+		videos   = map[uniq.Hash]photo.Video{}
+		vidNames = map[uniq.Hash]string{}
 		imgAlbum map[uniq.Hash][]photo.Album
 	)
 
+	vids, err := deps.PhotoVideoFinder().FindByHashes(ctx, imageHashes...)
+	if err != nil && !errors.Is(err, status.NotFound) {
+		return err
+	}
+
+	for _, v := range vids {
+		vidNames[v.Hash] = path.Base(v.Path)
+		v.Path = ""
+		videos[v.Hash] = v
+	}
+
 	if !preview {
 		if !privacy.HideGeoPosition {
 			gpss, err := deps.PhotoGpsFinder().FindByHashes(ctx, imageHashes...)
@@ -323,10 +375,22 @@ func (out *getAlbumOutput) prepare(ctx context.Context, deps getAlbumImagesDeps,
 			continue
 		}
 
+		v, isVideo := videos[i.Hash]
+
+		// Video clips are originals, guests do not see them when originals are hidden.
+		if isVideo && privacy.HideOriginal {
+			continue
+		}
+
 		h := i.Hash.String()
 
+		name := strings.TrimSuffix(path.Base(i.Path), "."+h+".jpg")
+		if isVideo {
+			name = vidNames[i.Hash]
+		}
+
 		img := Image{
-			Name:        strings.TrimSuffix(path.Base(i.Path), "."+h+".jpg"),
+			Name:        name,
 			Hash:        h,
 			Width:       i.Width,
 			Height:      i.Height,
@@ -334,6 +398,7 @@ func (out *getAlbumOutput) prepare(ctx context.Context, deps getAlbumImagesDeps,
 			Description: deps.TxtRenderer().MustRenderLang(ctx, i.Settings.Description, textReplaces.Apply),
 			Size:        i.Size,
 			UTime:       i.UTime,
+			Edited:      !i.Settings.Edits.IsZero(),
 		}
 
 		if !preview {
@@ -365,6 +430,16 @@ func (out *getAlbumOutput) prepare(ctx context.Context, deps getAlbumImagesDeps,
 
 			if meta, ok := metaData[i.Hash]; ok {
 				img.Meta = &meta.Data.Val
+
+				if l, ok := img.Meta.GeoLabels[txt.Language(ctx)]; ok && l != "" {
+					img.Meta.GeoLabel = &l
+				}
+
+				img.Meta.GeoLabels = nil
+			}
+
+			if isVideo {
+				img.Video = &v
 			}
 
 			if albums, ok := imgAlbum[i.Hash]; ok {
@@ -401,11 +476,11 @@ func (out *getAlbumOutput) prepare(ctx context.Context, deps getAlbumImagesDeps,
 		out.Images = append(out.Images, img)
 	}
 
-	if albumSettings.NewestFirst {
+	if albumSettings.NewestFirst && !albumSettings.MostEngagingFirst {
 		reverse(out.Images)
 	}
 
-	if albumSettings.DailyRulers {
+	if albumSettings.DailyRulers && !albumSettings.MostEngagingFirst {
 		dateShift := -time.Second
 		if albumSettings.NewestFirst {
 			dateShift = time.Second
@@ -460,8 +535,6 @@ func (out *getAlbumOutput) prepare(ctx context.Context, deps getAlbumImagesDeps,
 		}
 	}
 
-	var err error
-
 	album.Title, err = deps.TxtRenderer().RenderLang(ctx, album.Title, txt.StripTags, textReplaces.Apply)
 	if err != nil {
 		return err
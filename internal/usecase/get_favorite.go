@@ -12,6 +12,7 @@ import (
 func GetFavorite(deps FavoriteDeps) usecase.Interactor {
 	type getFavorite struct {
 		AlbumHash uniq.Hash `query:"album_hash,omitempty"`
+		ListHash  uniq.Hash `query:"list_hash,omitempty" description:"Named list, default list is used if empty."`
 	}
 
 	u := usecase.NewInteractor(func(ctx context.Context, input getFavorite, output *[]uniq.Hash) error {
@@ -21,7 +22,11 @@ func GetFavorite(deps FavoriteDeps) usecase.Interactor {
 			return errors.New("missing visitor hash")
 		}
 
-		res, err := deps.FavoriteRepository().FindImageHashes(ctx, visitorHash, input.AlbumHash)
+		if err := checkFavoriteList(ctx, deps, visitorHash, input.ListHash); err != nil {
+			return err
+		}
+
+		res, err := deps.FavoriteRepository().FindImageHashes(ctx, visitorHash, input.ListHash, input.AlbumHash)
 		*output = res
 
 		return err
@@ -10,13 +10,16 @@ import (
 	"github.com/swaggest/usecase/status"
 	"github.com/vearutop/photo-blog/internal/domain/photo"
 	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/engagement"
 	"github.com/vearutop/photo-blog/internal/infra/image"
+	"github.com/vearutop/photo-blog/pkg/webstats"
 )
 
 type getImageInfoDeps interface {
 	PhotoImageFinder() uniq.Finder[photo.Image]
 	PhotoGpsFinder() uniq.Finder[photo.Gps]
 	PhotoExifFinder() uniq.Finder[photo.Exif]
+	Engagement() *engagement.Service
 }
 
 func GetImageInfo(deps getImageInfoDeps) usecase.Interactor {
@@ -29,6 +32,8 @@ func GetImageInfo(deps getImageInfoDeps) usecase.Interactor {
 		Exif        photo.Exif  `json:"exif"`
 		Gps         *photo.Gps  `json:"gps"`
 		HashDecoded string      `json:"hash_decoded"`
+
+		Engagement *webstats.Engagement `json:"engagement,omitempty"`
 	}
 
 	u := usecase.NewInteractor(func(ctx context.Context, in getImageInput, out *imageInfo) error {
@@ -40,6 +45,15 @@ func GetImageInfo(deps getImageInfoDeps) usecase.Interactor {
 		out.Image = img
 		out.HashDecoded = strconv.Itoa(int(img.Hash))
 
+		scores, err := deps.Engagement().Scores(ctx, img.Hash)
+		if err != nil {
+			return err
+		}
+
+		if e, ok := scores[img.Hash]; ok {
+			out.Engagement = &e
+		}
+
 		if in.ReadMeta {
 			f, err := os.Open(img.Path)
 			if err != nil {
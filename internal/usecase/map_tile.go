@@ -3,11 +3,9 @@ package usecase
 import (
 	"bytes"
 	"context"
-	"fmt"
-	"io"
+	"errors"
 	"net/http"
 	"strconv"
-	"strings"
 	"sync"
 	"time"
 
@@ -15,6 +13,8 @@ import (
 	"github.com/swaggest/rest/request"
 	"github.com/swaggest/rest/response"
 	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/infra/geo/tiles"
 	"github.com/vearutop/photo-blog/internal/infra/service"
 )
 
@@ -32,67 +32,67 @@ func MapTile(deps *service.Locator) usecase.Interactor {
 	return usecase.NewInteractor(func(ctx context.Context, input mapTileID, output *response.EmbeddedSetter) error {
 		rw := output.ResponseWriter()
 
-		u := deps.Settings().Maps().Tiles
-		if u == "" {
-			u = "https://tile.openstreetmap.org/{z}/{x}/{y}.png"
-		}
+		var t tiles.Tile
 
-		u = strings.ReplaceAll(u, "{r}", input.Retina)
-		u = strings.ReplaceAll(u, "{z}", input.Zoom)
-		u = strings.ReplaceAll(u, "{x}", input.X)
-		u = strings.ReplaceAll(u, "{y}", input.Y)
-		u = strings.ReplaceAll(u, "{s}", input.S)
+		if fn := deps.Settings().Maps().LocalTiles; fn != "" {
+			z, err := strconv.ParseUint(input.Zoom, 10, 8)
+			if err != nil {
+				return status.Wrap(err, status.InvalidArgument)
+			}
 
-		mu := sync.Mutex{}
+			x, err := strconv.ParseUint(input.X, 10, 32)
+			if err != nil {
+				return status.Wrap(err, status.InvalidArgument)
+			}
 
-		t, err := deps.MapTilesCache().Get(ctx, []byte(u+"-png"),
-			func(ctx context.Context) ([]byte, error) {
-				mu.Lock()
-				defer mu.Unlock()
+			y, err := strconv.ParseUint(input.Y, 10, 32)
+			if err != nil {
+				return status.Wrap(err, status.InvalidArgument)
+			}
 
-				req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
-				if err != nil {
-					return nil, err
+			t, err = deps.LocalMapTiles().Tile(ctx, fn, uint8(z), uint32(x), uint32(y))
+			if err != nil {
+				if errors.Is(err, tiles.ErrNotFound) {
+					return status.Wrap(err, status.NotFound)
 				}
 
-				req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:128.0) Gecko/20100101 Firefox/128.0")
-				req.Header.Set("Referer", input.Request().Header.Get("Referer"))
-				req.Header.Set("Accept", "mage/avif,image/jxl,image/webp,image/png,image/svg+xml,image/*;q=0.8,*/*;q=0.5")
-				req.Header.Set("Accept-Language", "en-US,en;q=0.5")
-				req.Header.Set("Accept-Encoding", "gzip, deflate, br")
+				return err
+			}
+		} else {
+			u := tiles.URL(deps.Settings().Maps().Tiles, input.S, input.Retina, input.Zoom, input.X, input.Y)
+			mu := sync.Mutex{}
 
-				resp, err := http.DefaultTransport.RoundTrip(req)
-				if err != nil {
-					return nil, err
-				}
+			d, err := deps.MapTilesCache().Get(ctx, tiles.CacheKey(u),
+				func(ctx context.Context) ([]byte, error) {
+					mu.Lock()
+					defer mu.Unlock()
 
-				defer func() {
-					_ = resp.Body.Close()
-				}()
+					d, err := tiles.Fetch(ctx, u, input.Request().Header.Get("Referer"))
+					if err != nil {
+						return nil, err
+					}
 
-				d, err := io.ReadAll(resp.Body)
-				if err != nil {
-					return nil, err
-				}
+					deps.CtxdLogger().Debug(ctx, "map tile cached", "size", len(d))
 
-				if resp.StatusCode != http.StatusOK {
-					return nil, fmt.Errorf("unexpected status code: %d, %s", resp.StatusCode, string(d))
-				}
+					return d, nil
+				},
+			)
+			if err != nil {
+				return err
+			}
 
-				deps.CtxdLogger().Debug(ctx, "map tile cached", "size", len(d))
+			t.Data = d
+		}
 
-				return d, nil
-			},
-		)
-		if err != nil {
-			return err
+		if t.ContentType == "" {
+			t.ContentType = "image/png"
 		}
 
-		rw.Header().Set("Etag", strconv.FormatUint(xxhash.Sum64(t), 36))
-		rw.Header().Set("Content-Type", "image/png")
+		rw.Header().Set("Etag", strconv.FormatUint(xxhash.Sum64(t.Data), 36))
+		rw.Header().Set("Content-Type", t.ContentType)
 		rw.Header().Set("Cache-Control", "max-age=31536000")
 
-		http.ServeContent(rw, input.Request(), "image.png", time.Time{}, bytes.NewReader(t))
+		http.ServeContent(rw, input.Request(), "image.png", time.Time{}, bytes.NewReader(t.Data))
 
 		return nil
 	})
@@ -0,0 +1,219 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bool64/ctxd"
+	"github.com/swaggest/rest/request"
+	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/domain/comment"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/auth"
+	"github.com/vearutop/photo-blog/internal/infra/service"
+	"github.com/vearutop/photo-blog/internal/infra/settings"
+)
+
+type threadMessage struct {
+	Hash      uniq.Hash       `json:"hash"`
+	Name      string          `json:"name,omitempty"`
+	Text      string          `json:"text"`
+	CreatedAt time.Time       `json:"created_at"`
+	EditedAt  *time.Time      `json:"edited_at,omitempty"`
+	Pending   bool            `json:"pending,omitempty" description:"Message is waiting for moderation, only visible to its author."`
+	Own       bool            `json:"own,omitempty" description:"Message can be edited or deleted by current visitor."`
+	Replies   []threadMessage `json:"replies,omitempty"`
+}
+
+type getMessagesDeps interface {
+	Settings() settings.Values
+
+	service.SiteVisitorFinderProvider
+	service.CommentMessageRepositoryProvider
+}
+
+// GetMessages lists approved messages of a thread with replies.
+func GetMessages(deps getMessagesDeps) usecase.Interactor {
+	type getMessagesInput struct {
+		Type        string     `query:"type" enum:"image,album" required:"true"`
+		RelatedHash uniq.Hash  `query:"related_hash" required:"true"`
+		RelatedAt   *time.Time `query:"related_at"`
+	}
+
+	type getMessagesOutput struct {
+		Messages []threadMessage `json:"messages"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, in getMessagesInput, out *getMessagesOutput) error {
+		out.Messages = []threadMessage{}
+
+		if !deps.Settings().Comments().Enabled {
+			return nil
+		}
+
+		visitorHash := auth.VisitorFromContext(ctx)
+		isAdmin := auth.IsAdmin(ctx)
+
+		messages, err := deps.CommentMessageRepository().FindThread(ctx,
+			comment.ThreadHash(in.Type, in.RelatedHash, in.RelatedAt), visitorHash)
+		if err != nil {
+			return err
+		}
+
+		var visitorHashes []uniq.Hash
+		for _, m := range messages {
+			visitorHashes = append(visitorHashes, m.VisitorHash)
+		}
+
+		names := map[uniq.Hash]string{}
+
+		visitors, err := deps.SiteVisitorFinder().FindByHashes(ctx, visitorHashes...)
+		if err != nil {
+			return err
+		}
+
+		for _, v := range visitors {
+			names[v.Hash] = v.Name
+		}
+
+		replies := map[uniq.Hash][]threadMessage{}
+
+		for _, m := range messages {
+			tm := threadMessage{
+				Hash:      m.Hash,
+				Name:      names[m.VisitorHash],
+				Text:      m.Text,
+				CreatedAt: m.CreatedAt,
+				EditedAt:  m.EditedAt,
+				Pending:   m.Pending(),
+				Own:       isAdmin || (visitorHash != 0 && m.VisitorHash == visitorHash),
+			}
+
+			if m.ParentHash != 0 {
+				replies[m.ParentHash] = append(replies[m.ParentHash], tm)
+			} else {
+				out.Messages = append(out.Messages, tm)
+			}
+		}
+
+		for i, m := range out.Messages {
+			out.Messages[i].Replies = replies[m.Hash]
+		}
+
+		return nil
+	})
+
+	u.SetTags("Comments")
+
+	return u
+}
+
+type messageInPath struct {
+	Hash uniq.Hash `path:"hash"`
+	request.EmbeddedSetter
+}
+
+// ownMessage finds a message that can be changed by current visitor.
+func ownMessage(ctx context.Context, deps service.CommentMessageFinderProvider, hash uniq.Hash) (comment.Message, error) {
+	m, err := deps.CommentMessageFinder().FindByHash(ctx, hash)
+	if err != nil {
+		return m, err
+	}
+
+	if auth.IsAdmin(ctx) {
+		return m, nil
+	}
+
+	if v := auth.VisitorFromContext(ctx); v == 0 || v != m.VisitorHash {
+		return m, status.PermissionDenied
+	}
+
+	return m, nil
+}
+
+// EditMessage updates text of own message, edited message of a visitor goes back to moderation.
+func EditMessage(deps addMessageDeps) usecase.Interactor {
+	type editMessageInput struct {
+		messageInPath
+		Text string `json:"text"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, in editMessageInput, out *comment.Message) error {
+		cfg := deps.Settings().Comments()
+
+		m, err := ownMessage(ctx, deps, in.Hash)
+		if err != nil {
+			return err
+		}
+
+		if m.Rejected {
+			return status.Wrap(errors.New("message was rejected"), status.FailedPrecondition)
+		}
+
+		if m.Text, err = messageText(in.Text, cfg); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		m.EditedAt = &now
+
+		if !auth.IsAdmin(ctx) {
+			visitor, err := deps.SiteVisitorFinder().FindByHash(ctx, m.VisitorHash)
+			if err != nil && !errors.Is(err, status.NotFound) {
+				return err
+			}
+
+			if visitor.Banned {
+				return status.PermissionDenied
+			}
+
+			moderateMessage(ctx, deps, cfg, visitor, visitor.Name, in.Request().UserAgent(), &m)
+		}
+
+		if err := deps.CommentMessageRepository().Update(ctx, m); err != nil {
+			return err
+		}
+
+		*out = m
+
+		return nil
+	})
+
+	u.SetTags("Comments")
+	u.SetExpectedErrors(status.NotFound, status.PermissionDenied, status.InvalidArgument, status.FailedPrecondition)
+
+	return u
+}
+
+type deleteMessageDeps interface {
+	CtxdLogger() ctxd.Logger
+
+	service.CommentMessageFinderProvider
+	service.CommentMessageRepositoryProvider
+}
+
+// DeleteMessage removes a message, admin removes it together with replies,
+// visitor removes own message keeping replies of others.
+func DeleteMessage(deps deleteMessageDeps) usecase.Interactor {
+	u := usecase.NewInteractor(func(ctx context.Context, in messageInPath, out *struct{}) error {
+		m, err := ownMessage(ctx, deps, in.Hash)
+		if err != nil {
+			return err
+		}
+
+		deps.CtxdLogger().Info(ctx, "deleting message", "hash", m.Hash, "visitor", m.VisitorHash)
+
+		if auth.IsAdmin(ctx) {
+			return deps.CommentMessageRepository().DeleteWithReplies(ctx, m.Hash)
+		}
+
+		return deps.CommentMessageRepository().DeleteOwn(ctx, m.Hash)
+	})
+
+	u.SetTags("Comments")
+	u.SetExpectedErrors(status.NotFound, status.PermissionDenied)
+
+	return u
+}
@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/infra/auth"
+	"github.com/vearutop/photo-blog/internal/infra/storage"
+	"github.com/vearutop/photo-blog/pkg/gazetteer"
+)
+
+type placesOutput struct {
+	Title  string               `json:"title,omitempty" description:"Title suggestion based on common place of images."`
+	Facets []storage.PlaceFacet `json:"facets"`
+}
+
+// Places creates use case interactor to count images by reverse geocoded places.
+func Places(deps getAlbumImagesDeps) usecase.Interactor {
+	type placesInput struct {
+		Album string `query:"album" description:"Album name to count images of."`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, in placesInput, out *placesOutput) error {
+		deps.StatsTracker().Add(ctx, "places", 1)
+
+		q := deps.ImageSelector().Select()
+
+		if !auth.IsAdmin(ctx) {
+			q.OnlyPublic()
+		}
+
+		if in.Album != "" {
+			q.ByAlbumName(in.Album)
+		}
+
+		facets, err := q.PlaceFacets(ctx)
+		if err != nil {
+			return fmt.Errorf("count places: %w", err)
+		}
+
+		out.Facets = facets
+		out.Title = placesTitle(facets)
+
+		return nil
+	})
+
+	u.SetTags("Search")
+	u.SetExpectedErrors(status.Unknown)
+
+	return u
+}
+
+// placesTitle names the most specific place that is common for all facets.
+func placesTitle(facets []storage.PlaceFacet) string {
+	if len(facets) == 0 {
+		return ""
+	}
+
+	common := gazetteer.Location{
+		City:    facets[0].City,
+		Region:  facets[0].Region,
+		Country: facets[0].Country,
+	}
+
+	for _, f := range facets[1:] {
+		if f.Country != common.Country {
+			// Multiple countries are listed by number of images.
+			var countries []string
+
+			seen := map[string]bool{}
+
+			for _, f := range facets {
+				if !seen[f.Country] && len(countries) < 3 {
+					seen[f.Country] = true
+					countries = append(countries, f.Country)
+				}
+			}
+
+			return strings.Join(countries, ", ")
+		}
+
+		if f.Region != common.Region {
+			common.Region = ""
+		}
+
+		if f.City != common.City {
+			common.City = ""
+		}
+	}
+
+	if common.City != "" {
+		// City and country are enough for a title.
+		common.Region = ""
+	}
+
+	return common.Label()
+}
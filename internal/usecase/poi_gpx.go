@@ -30,6 +30,7 @@ func DownloadImagesPoiGpx(deps getAlbumImagesDeps) usecase.Interactor {
 		}
 
 		gpxDoc := gpx.GPX{}
+		base := baseURL(deps.Settings().Appearance(), in.Request())
 
 		for _, i := range cont.Images {
 			if i.Gps != nil {
@@ -39,7 +40,7 @@ func DownloadImagesPoiGpx(deps getAlbumImagesDeps) usecase.Interactor {
 				if i.Description != "" {
 					p.Description += i.Description + "\n\n"
 				}
-				p.Description += fmt.Sprintf(`<img src="https://p1cs.1337.cx/thumb/300w/%s.jpg" />`, i.Hash)
+				p.Description += fmt.Sprintf(`<img src="%s/thumb/300w/%s.jpg" />`, base, i.Hash)
 				p.Latitude = g.Latitude
 				p.Longitude = g.Longitude
 
@@ -0,0 +1,452 @@
+package usecase
+
+import (
+	"cmp"
+	"context"
+	"crypto/subtle"
+	"encoding/csv"
+	"errors"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bool64/ctxd"
+	"github.com/swaggest/rest/response"
+	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/auth"
+	"github.com/vearutop/photo-blog/internal/infra/notifier"
+	"github.com/vearutop/photo-blog/internal/infra/service"
+	"github.com/vearutop/photo-blog/pkg/notify"
+)
+
+type proofingDeps interface {
+	CtxdLogger() ctxd.Logger
+	PhotoAlbumFinder() uniq.Finder[photo.Album]
+	PhotoAlbumImageFinder() photo.AlbumImageFinder
+	Notifier() *notifier.Service
+
+	service.ProofingRepositoryProvider
+	service.FavoriteRepositoryProvider
+}
+
+type proofingInput struct {
+	Name        string `path:"name"`
+	ProofingKey string `query:"proofing_key" description:"Client access key, not needed for admin."`
+}
+
+var (
+	errProofingClosed    = status.Wrap(errors.New("proofing deadline has passed"), status.FailedPrecondition)
+	errProofingSubmitted = status.Wrap(errors.New("selection is already submitted"), status.FailedPrecondition)
+)
+
+// proofingAlbum finds album and checks access with proofing key.
+func proofingAlbum(ctx context.Context, deps proofingDeps, in proofingInput) (photo.Album, error) {
+	album, err := deps.PhotoAlbumFinder().FindByHash(ctx, photo.AlbumHash(in.Name))
+	if err != nil {
+		return album, err
+	}
+
+	if auth.IsAdmin(ctx) {
+		return album, nil
+	}
+
+	if !validProofingKey(album, in.ProofingKey) {
+		return album, status.PermissionDenied
+	}
+
+	return album, nil
+}
+
+// validProofingKey checks key in constant time to not leak matching prefix of album key.
+func validProofingKey(album photo.Album, key string) bool {
+	k := album.Settings.ProofingKey
+
+	return k != "" && subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1
+}
+
+// proofingOpen fails if client can not change selection anymore.
+func proofingOpen(ctx context.Context, deps proofingDeps, album photo.Album) error {
+	if d := album.Settings.ProofingDeadline; d != nil && time.Now().After(*d) {
+		return errProofingClosed
+	}
+
+	_, err := deps.ProofingRepository().FindSubmission(ctx, album.Hash)
+	if err == nil {
+		return errProofingSubmitted
+	}
+
+	if !errors.Is(err, status.NotFound) {
+		return err
+	}
+
+	return nil
+}
+
+// albumImages returns images of album by hash.
+func albumImages(ctx context.Context, deps proofingDeps, album photo.Album) (map[uniq.Hash]photo.Image, error) {
+	images, err := deps.PhotoAlbumImageFinder().FindImages(ctx, album.Hash)
+	if err != nil && !errors.Is(err, status.NotFound) {
+		return nil, err
+	}
+
+	res := make(map[uniq.Hash]photo.Image, len(images))
+	for _, img := range images {
+		res[img.Hash] = img
+	}
+
+	return res, nil
+}
+
+// ProofingSummary describes client selection of an album.
+type ProofingSummary struct {
+	Deadline   *time.Time                `json:"deadline,omitempty"`
+	Open       bool                      `json:"open" description:"Selection can be changed."`
+	Submission *photo.ProofingSubmission `json:"submission,omitempty"`
+	Total      int                       `json:"total" description:"Images in album."`
+	Selected   int                       `json:"selected"`
+	Rejected   int                       `json:"rejected"`
+	Notes      int                       `json:"notes"`
+	Marks      []photo.ProofingMark      `json:"marks"`
+}
+
+// GetProofing returns client selection of an album.
+func GetProofing(deps proofingDeps) usecase.Interactor {
+	u := usecase.NewInteractor(func(ctx context.Context, in proofingInput, out *ProofingSummary) error {
+		album, err := proofingAlbum(ctx, deps, in)
+		if err != nil {
+			return err
+		}
+
+		images, err := albumImages(ctx, deps, album)
+		if err != nil {
+			return err
+		}
+
+		marks, err := deps.ProofingRepository().FindMarks(ctx, album.Hash)
+		if err != nil {
+			return err
+		}
+
+		sub, err := deps.ProofingRepository().FindSubmission(ctx, album.Hash)
+		if err == nil {
+			out.Submission = &sub
+		} else if !errors.Is(err, status.NotFound) {
+			return err
+		}
+
+		out.Deadline = album.Settings.ProofingDeadline
+		out.Open = out.Submission == nil && (out.Deadline == nil || time.Now().Before(*out.Deadline))
+		out.Total = len(images)
+		out.Marks = make([]photo.ProofingMark, 0, len(marks))
+
+		for _, m := range marks {
+			// Images removed from album after marking are not reported.
+			if _, ok := images[m.ImageHash]; !ok {
+				continue
+			}
+
+			switch m.State {
+			case photo.ProofingSelected:
+				out.Selected++
+			case photo.ProofingRejected:
+				out.Rejected++
+			}
+
+			if m.Note != "" {
+				out.Notes++
+			}
+
+			out.Marks = append(out.Marks, m)
+		}
+
+		return nil
+	})
+
+	u.SetTags("Proofing")
+	u.SetExpectedErrors(status.PermissionDenied, status.NotFound)
+
+	return u
+}
+
+// MarkProofingImage selects or rejects an image and updates its note.
+func MarkProofingImage(deps proofingDeps) usecase.Interactor {
+	type markInput struct {
+		proofingInput
+		ImageHash uniq.Hash           `json:"image_hash" required:"true"`
+		State     photo.ProofingState `json:"state,omitempty" description:"Empty state clears decision."`
+		Note      string              `json:"note,omitempty" maxLength:"2000"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, in markInput, out *photo.ProofingMark) error {
+		album, err := proofingAlbum(ctx, deps, in.proofingInput)
+		if err != nil {
+			return err
+		}
+
+		if err := proofingOpen(ctx, deps, album); err != nil {
+			return err
+		}
+
+		images, err := albumImages(ctx, deps, album)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := images[in.ImageHash]; !ok {
+			return status.Wrap(errors.New("image is not in album"), status.InvalidArgument)
+		}
+
+		m := photo.ProofingMark{
+			AlbumHash: album.Hash,
+			ImageHash: in.ImageHash,
+			State:     in.State,
+			Note:      strings.TrimSpace(in.Note),
+			UpdatedAt: time.Now(),
+		}
+
+		if err := deps.ProofingRepository().SetMarks(ctx, m); err != nil {
+			return err
+		}
+
+		*out = m
+
+		return nil
+	})
+
+	u.SetTags("Proofing")
+	u.SetExpectedErrors(status.PermissionDenied, status.NotFound, status.InvalidArgument, status.FailedPrecondition)
+
+	return u
+}
+
+// ImportProofingFavorites selects album images from a favorite list of current visitor, notes are copied.
+func ImportProofingFavorites(deps proofingDeps) usecase.Interactor {
+	type importInput struct {
+		proofingInput
+		ListHash uniq.Hash `json:"list_hash,omitempty" description:"Named list of favorites, default list is used if empty."`
+	}
+
+	type importOutput struct {
+		Selected int `json:"selected"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, in importInput, out *importOutput) error {
+		album, err := proofingAlbum(ctx, deps, in.proofingInput)
+		if err != nil {
+			return err
+		}
+
+		if err := proofingOpen(ctx, deps, album); err != nil {
+			return err
+		}
+
+		visitorHash := auth.VisitorFromContext(ctx)
+		if visitorHash == 0 {
+			return errors.New("missing visitor hash")
+		}
+
+		if err := checkFavoriteList(ctx, deps, visitorHash, in.ListHash); err != nil {
+			return err
+		}
+
+		hashes, err := deps.FavoriteRepository().FindImageHashes(ctx, visitorHash, in.ListHash, album.Hash)
+		if err != nil {
+			return err
+		}
+
+		notes, err := deps.FavoriteRepository().FindNotes(ctx, visitorHash, in.ListHash)
+		if err != nil {
+			return err
+		}
+
+		marks := make([]photo.ProofingMark, 0, len(hashes))
+		for _, h := range hashes {
+			marks = append(marks, photo.ProofingMark{
+				AlbumHash: album.Hash,
+				ImageHash: h,
+				State:     photo.ProofingSelected,
+				Note:      notes[h],
+			})
+		}
+
+		out.Selected = len(marks)
+
+		return deps.ProofingRepository().SetMarks(ctx, marks...)
+	})
+
+	u.SetTags("Proofing")
+	u.SetExpectedErrors(status.PermissionDenied, status.NotFound, status.FailedPrecondition)
+
+	return u
+}
+
+// SubmitProofing finalizes client selection.
+func SubmitProofing(deps proofingDeps) usecase.Interactor {
+	type submitInput struct {
+		proofingInput
+		ClientName string `json:"name,omitempty" maxLength:"200"`
+		Comment    string `json:"comment,omitempty" maxLength:"5000"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, in submitInput, out *photo.ProofingSubmission) error {
+		album, err := proofingAlbum(ctx, deps, in.proofingInput)
+		if err != nil {
+			return err
+		}
+
+		if err := proofingOpen(ctx, deps, album); err != nil {
+			return err
+		}
+
+		selected, err := proofingSelection(ctx, deps, album)
+		if err != nil {
+			return err
+		}
+
+		s := photo.ProofingSubmission{
+			AlbumHash:   album.Hash,
+			SubmittedAt: time.Now(),
+			Name:        strings.TrimSpace(in.ClientName),
+			Comment:     strings.TrimSpace(in.Comment),
+			Selected:    len(selected),
+		}
+
+		if err := deps.ProofingRepository().Submit(ctx, s); err != nil {
+			return err
+		}
+
+		deps.CtxdLogger().Important(ctx, "proofing submitted", "album", album.Name, "selected", s.Selected)
+
+		deps.Notifier().Notify(ctx, notify.Notification{
+			Event: notifier.ProofingSubmit,
+			Title: cmp.Or(s.Name, "Client") + " selected " + strconv.Itoa(s.Selected) + " images in " + album.Title,
+			Text:  s.Comment,
+			URL:   deps.Notifier().URL("/proofing/" + album.Name + ".json"),
+		})
+
+		*out = s
+
+		return nil
+	})
+
+	u.SetTags("Proofing")
+	u.SetExpectedErrors(status.PermissionDenied, status.NotFound, status.FailedPrecondition, status.AlreadyExists)
+
+	return u
+}
+
+// ReopenProofing allows client to change submitted selection.
+func ReopenProofing(deps proofingDeps) usecase.Interactor {
+	u := usecase.NewInteractor(func(ctx context.Context, in proofingInput, out *struct{}) error {
+		album, err := deps.PhotoAlbumFinder().FindByHash(ctx, photo.AlbumHash(in.Name))
+		if err != nil {
+			return err
+		}
+
+		return deps.ProofingRepository().Reopen(ctx, album.Hash)
+	})
+
+	u.SetTags("Proofing")
+	u.SetExpectedErrors(status.NotFound)
+
+	return u
+}
+
+// proofingSelection returns selected album images.
+func proofingSelection(ctx context.Context, deps proofingDeps, album photo.Album) ([]photo.Image, error) {
+	images, err := albumImages(ctx, deps, album)
+	if err != nil {
+		return nil, err
+	}
+
+	marks, err := deps.ProofingRepository().FindMarks(ctx, album.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []photo.Image
+
+	for _, m := range marks {
+		if img, ok := images[m.ImageHash]; ok && m.State == photo.ProofingSelected {
+			res = append(res, img)
+		}
+	}
+
+	return res, nil
+}
+
+// ExportProofing serves file names of selected images.
+func ExportProofing(deps proofingDeps) usecase.Interactor {
+	type exportInput struct {
+		Name   string `path:"name"`
+		Format string `path:"format" enum:"csv,txt" description:"CSV with notes or comma separated names for Lightroom text filter."`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, in exportInput, out *response.EmbeddedSetter) error {
+		album, err := deps.PhotoAlbumFinder().FindByHash(ctx, photo.AlbumHash(in.Name))
+		if err != nil {
+			return err
+		}
+
+		selected, err := proofingSelection(ctx, deps, album)
+		if err != nil {
+			return err
+		}
+
+		rw := out.ResponseWriter()
+		fileName := album.Name + "-selected." + in.Format
+
+		rw.Header().Set("Content-Disposition", `attachment; filename="`+fileName+`"`)
+
+		if in.Format == "txt" {
+			// Lightroom Library Filter, Text, Filename, Contains matches any of the comma separated words.
+			names := make([]string, 0, len(selected))
+			for _, img := range selected {
+				base := path.Base(img.Path)
+				names = append(names, strings.TrimSuffix(base, path.Ext(base)))
+			}
+
+			rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			_, err := rw.Write([]byte(strings.Join(names, ", ") + "\n"))
+
+			return err
+		}
+
+		marks, err := deps.ProofingRepository().FindMarks(ctx, album.Hash)
+		if err != nil {
+			return err
+		}
+
+		notes := make(map[uniq.Hash]string, len(marks))
+		for _, m := range marks {
+			notes[m.ImageHash] = m.Note
+		}
+
+		rw.Header().Set("Content-Type", "text/csv; charset=utf-8")
+
+		w := csv.NewWriter(rw)
+
+		if err := w.Write([]string{"file", "hash", "note"}); err != nil {
+			return err
+		}
+
+		for _, img := range selected {
+			if err := w.Write([]string{csvCell(path.Base(img.Path)), img.Hash.String(), csvCell(notes[img.Hash])}); err != nil {
+				return err
+			}
+		}
+
+		w.Flush()
+
+		return w.Error()
+	})
+
+	u.SetTags("Proofing")
+	u.SetExpectedErrors(status.NotFound)
+
+	return u
+}
@@ -0,0 +1,103 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/infra/auth"
+	"github.com/vearutop/photo-blog/internal/usecase"
+)
+
+func TestProofing(t *testing.T) {
+	st := testStorage(t)
+	deps := newTestDeps(t, st)
+	images := addImages(t, st, "a.jpg", "b.jpg", "=HYPERLINK(1).jpg", "other.jpg")
+
+	album := photo.Album{Name: "wedding", Title: "Wedding"}
+	album.Settings.ProofingKey = "secret"
+	album = addAlbum(t, deps, album, images[:3]...)
+
+	client := auth.ContextWithVisitor(context.Background(), 1)
+	admin := auth.SetAdmin(context.Background())
+	key := map[string]any{"Name": "wedding", "ProofingKey": "secret"}
+
+	mark := func(ctx context.Context, in map[string]any) error {
+		in["Name"] = "wedding"
+		in["ProofingKey"] = "secret"
+
+		_, _, err := interact(ctx, usecase.MarkProofingImage(deps), in)
+
+		return err
+	}
+
+	// Key is required for client.
+	_, _, err := interact(client, usecase.GetProofing(deps), map[string]any{"Name": "wedding", "ProofingKey": "wrong"})
+	assert.ErrorIs(t, err, status.PermissionDenied)
+
+	_, _, err = interact(client, usecase.MarkProofingImage(deps),
+		map[string]any{"Name": "wedding", "image_hash": images[0], "state": photo.ProofingSelected})
+	assert.ErrorIs(t, err, status.PermissionDenied)
+
+	require.NoError(t, mark(client, map[string]any{"image_hash": images[0], "state": photo.ProofingSelected}))
+	require.NoError(t, mark(client, map[string]any{"image_hash": images[1], "state": photo.ProofingRejected, "note": "Eyes closed"}))
+	require.NoError(t, mark(client, map[string]any{"image_hash": images[2], "state": photo.ProofingSelected, "note": "=1+1"}))
+	assert.ErrorIs(t, mark(client, map[string]any{"image_hash": images[3], "state": photo.ProofingSelected}), status.InvalidArgument)
+
+	res, _, err := interact(client, usecase.GetProofing(deps), key)
+	require.NoError(t, err)
+
+	var summary usecase.ProofingSummary
+	require.NoError(t, json.Unmarshal([]byte(res), &summary))
+	assert.True(t, summary.Open)
+	assert.Equal(t, 3, summary.Total)
+	assert.Equal(t, 2, summary.Selected)
+	assert.Equal(t, 1, summary.Rejected)
+	assert.Equal(t, 2, summary.Notes)
+
+	_, rec, err := interact(admin, usecase.ExportProofing(deps), map[string]any{"Name": "wedding", "Format": "csv"})
+	require.NoError(t, err)
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "file,hash,note\n")
+	assert.Contains(t, rec.Body.String(), "a.jpg,"+images[0].String()+",\n")
+	assert.Contains(t, rec.Body.String(), "'=HYPERLINK(1).jpg,"+images[2].String()+",'=1+1\n", "formula must be escaped")
+	assert.NotContains(t, rec.Body.String(), "b.jpg")
+
+	_, rec, err = interact(admin, usecase.ExportProofing(deps), map[string]any{"Name": "wedding", "Format": "txt"})
+	require.NoError(t, err)
+	assert.Equal(t, "a, =HYPERLINK(1)\n", rec.Body.String())
+
+	// Submitted selection is frozen until admin reopens it.
+	res, _, err = interact(client, usecase.SubmitProofing(deps), map[string]any{"Name": "wedding", "ProofingKey": "secret", "name": " Ann "})
+	require.NoError(t, err)
+
+	var submission photo.ProofingSubmission
+	require.NoError(t, json.Unmarshal([]byte(res), &submission))
+	assert.Equal(t, "Ann", submission.Name)
+	assert.Equal(t, 2, submission.Selected)
+
+	assert.ErrorIs(t, mark(client, map[string]any{"image_hash": images[0]}), status.FailedPrecondition)
+
+	_, _, err = interact(admin, usecase.ReopenProofing(deps), map[string]any{"Name": "wedding"})
+	require.NoError(t, err)
+
+	require.NoError(t, mark(client, map[string]any{"image_hash": images[0]}))
+
+	// Client can not change selection after deadline.
+	deadline := time.Now().Add(-time.Hour)
+	album.Settings.ProofingDeadline = &deadline
+	require.NoError(t, deps.AlbumRepository.Update(context.Background(), album))
+
+	assert.ErrorIs(t, mark(client, map[string]any{"image_hash": images[0], "state": photo.ProofingSelected}), status.FailedPrecondition)
+
+	res, _, err = interact(client, usecase.GetProofing(deps), key)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(res), &summary))
+	assert.False(t, summary.Open)
+	assert.Equal(t, 1, summary.Selected)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"html/template"
+	"strings"
 
 	"github.com/docker/go-units"
 	"github.com/swaggest/rest/request"
@@ -28,6 +29,10 @@ func SearchImages(deps getAlbumImagesDeps) usecase.Interactor {
 		Lens   *string `query:"lens"`
 		Camera *string `query:"camera"`
 		Offset uint64  `query:"offset"`
+
+		CountryCode string `query:"country" description:"ISO country code of reverse geocoded place."`
+		Region      string `query:"region"`
+		City        string `query:"city"`
 	}
 
 	u := usecase.NewInteractor(func(ctx context.Context, in searchInput, out *web.Page) error {
@@ -57,6 +62,19 @@ func SearchImages(deps getAlbumImagesDeps) usecase.Interactor {
 			q.ByCamera(*in.Camera)
 		}
 
+		if in.CountryCode != "" || in.Region != "" || in.City != "" {
+			var place []string
+
+			for _, p := range []string{in.City, in.Region, in.CountryCode} {
+				if p != "" {
+					place = append(place, p)
+				}
+			}
+
+			title += " Place: " + strings.Join(place, ", ")
+			q.ByPlace(in.CountryCode, in.Region, in.City)
+		}
+
 		q.Limit(500)
 		q.Offset(in.Offset)
 
@@ -108,7 +126,7 @@ func SearchImages(deps getAlbumImagesDeps) usecase.Interactor {
 		maps := deps.Settings().Maps()
 
 		d.MapTiles = maps.Tiles
-		if maps.Cache {
+		if maps.Cache || maps.LocalTiles != "" {
 			d.MapTiles = "/map-tile/{s}/{r}/{z}/{x}/{y}.png"
 		}
 
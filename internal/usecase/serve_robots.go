@@ -0,0 +1,33 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+
+	"github.com/swaggest/rest/response"
+	"github.com/swaggest/usecase"
+	"github.com/vearutop/photo-blog/internal/infra/settings"
+)
+
+// ServeRobots creates use case interactor to serve robots.txt generated from bot settings.
+func ServeRobots(deps interface {
+	Settings() settings.Values
+},
+) usecase.Interactor {
+	u := usecase.NewInteractor(func(ctx context.Context, in struct{}, out *response.EmbeddedSetter) error {
+		sitemap := ""
+		if baseURL := deps.Settings().Appearance().CanonicalBaseURL; baseURL != "" {
+			sitemap = strings.TrimSuffix(baseURL, "/") + "/sitemap.xml"
+		}
+
+		rw := out.ResponseWriter()
+		rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+		_, err := rw.Write([]byte(deps.Settings().Bots().Policy().Robots(sitemap)))
+
+		return err
+	})
+	u.SetTags("Site")
+
+	return u
+}
@@ -30,9 +30,10 @@ type showAlbumAtImageInput struct {
 type showAlbumInput struct {
 	request.EmbeddedSetter
 
-	Name      string `path:"name"`
-	CollabKey string `query:"collab_key" description:"Access key to enable content upload and management."`
-	imgHash   uniq.Hash
+	Name        string `path:"name"`
+	CollabKey   string `query:"collab_key" description:"Access key to enable content upload and management."`
+	ProofingKey string `query:"proofing_key" description:"Access key to enable client selection of images."`
+	imgHash     uniq.Hash
 }
 
 func ShowAlbumAtImage(up usecase.IOInteractorOf[showAlbumInput, web.Page]) usecase.Interactor {
@@ -58,9 +59,13 @@ type albumPageData struct {
 	Name        string
 	CoverImage  string
 	CollabKey   string
-	Public      bool
-	NewestFirst bool
-	Hash        string
+	ProofingKey string
+	Proofing    bool
+
+	CollabCanDelete bool
+	Public          bool
+	NewestFirst     bool
+	Hash            string
 
 	Images    []Image
 	Panoramas []Image
@@ -69,6 +74,7 @@ type albumPageData struct {
 	TotalSize      string
 	Visits         string
 	EnableFavorite bool
+	EnableComments bool
 
 	MapTiles       string
 	MapAttribution string
@@ -199,10 +205,15 @@ func ShowAlbum(deps interface {
 			return fmt.Errorf("get album contents: %w", err)
 		}
 
-		if in.CollabKey != "" && in.CollabKey != cont.Album.Settings.CollabKey {
+		collab, isCollab := cont.Album.Settings.Collaborator(in.CollabKey, time.Now())
+		if in.CollabKey != "" && !isCollab {
 			return status.Wrap(errors.New("wrong collab_key"), status.PermissionDenied)
 		}
 
+		if in.ProofingKey != "" && !validProofingKey(cont.Album, in.ProofingKey) {
+			return status.Wrap(errors.New("wrong proofing_key"), status.PermissionDenied)
+		}
+
 		if cont.Album.Settings.Redirect != "" {
 			http.Redirect(out.ResponseWriter(), in.Request(), cont.Album.Settings.Redirect, http.StatusMovedPermanently)
 		}
@@ -216,12 +227,16 @@ func ShowAlbum(deps interface {
 		d.Description = template.HTML(album.Settings.Description)
 		d.Name = album.Name
 		d.CollabKey = in.CollabKey
+		d.CollabCanDelete = collab.CanDelete()
+		d.ProofingKey = in.ProofingKey
 		d.Public = album.Public
 		d.Hash = album.Hash.String()
 		d.Count = len(cont.Images)
 		d.AlbumData = cont
 		d.AlbumData.Images = append([]Image(nil), cont.Images...)
 		d.AlbumData.Album.Settings.CollabKey = ""
+		d.AlbumData.Album.Settings.Collaborators = nil
+		d.AlbumData.Album.Settings.ProofingKey = ""
 		d.Timeline = buildAlbumTimeline(cont.Images, cont.Album.Settings.Texts, cont.Album.Settings.NewestFirst)
 		d.Featured = deps.Settings().Appearance().FeaturedAlbumName
 
@@ -234,6 +249,10 @@ func ShowAlbum(deps interface {
 		}
 
 		d.fill(ctx, deps.TxtRenderer(), deps.Settings())
+
+		// Proofing panel is shown to client with a valid key and to admin.
+		d.Proofing = album.Settings.ProofingKey != "" && (d.IsAdmin || d.ProofingKey != "")
+
 		if len(cont.Images) > 1 {
 			d.OGTitle = fmt.Sprintf("%s (%d photos)", album.Title, len(cont.Images))
 		} else {
@@ -259,7 +278,7 @@ func ShowAlbum(deps interface {
 		maps := deps.Settings().Maps()
 
 		d.MapTiles = maps.Tiles
-		if maps.Cache {
+		if maps.Cache || maps.LocalTiles != "" {
 			d.MapTiles = "/map-tile/{s}/{r}/{z}/{x}/{y}.png"
 		}
 
@@ -287,6 +306,7 @@ func ShowAlbum(deps interface {
 
 		if deps.Settings().Visitors().Tag {
 			d.EnableFavorite = true
+			d.EnableComments = deps.Settings().Comments().Enabled && !d.IsBot
 		}
 
 		if d.IsAdmin {
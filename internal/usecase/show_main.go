@@ -45,6 +45,8 @@ type pageCommon struct {
 	IsAdmin         bool
 	IsBot           bool
 	ShowLoginButton bool
+	Cookieless      bool
+	CookieDays      int
 
 	ThumbBaseURL     string
 	ImageBaseURL     string
@@ -91,6 +93,8 @@ func (p *pageCommon) fill(ctx context.Context, r *txt.Renderer, a settings.Value
 	p.IsBot = auth.IsBot(ctx)
 	p.Secure = !a.Security().Disabled()
 	p.ShowLoginButton = !a.Privacy().HideLoginButton
+	p.Cookieless = a.Visitors().Cookieless
+	p.CookieDays = a.Visitors().RetentionDays
 
 	for _, i := range ap.MainMenu {
 		if i.AdminOnly && !p.IsAdmin {
@@ -0,0 +1,244 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/auth"
+	"github.com/vearutop/photo-blog/internal/infra/image/sprite"
+	"github.com/vearutop/photo-blog/pkg/geocluster"
+	"github.com/vearutop/photo-blog/pkg/web"
+	"github.com/vearutop/photo-blog/resources/static"
+)
+
+const (
+	// mapName is a pseudo album name to own marker sprites of all photos map.
+	mapName = "map"
+
+	// mapClusterCell is a size of cluster grid cell in pixels.
+	mapClusterCell = 64
+
+	// mapClusterHashes limits number of hashes listed in a cluster.
+	mapClusterHashes = 20
+)
+
+type mapPageData struct {
+	pageCommon
+
+	MapTiles       string
+	MapAttribution string
+}
+
+// ShowMap creates use case interactor to show all geotagged photos on a map.
+func ShowMap(deps getAlbumImagesDeps) usecase.IOInteractorOf[struct{}, web.Page] {
+	tmpl, err := static.Template("map.gohtml")
+	if err != nil {
+		panic(err)
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, in struct{}, out *web.Page) error {
+		deps.StatsTracker().Add(ctx, "show_map", 1)
+
+		d := mapPageData{}
+		d.fill(ctx, deps.TxtRenderer(), deps.Settings())
+
+		maps := deps.Settings().Maps()
+
+		d.MapTiles = maps.Tiles
+		if maps.Cache || maps.LocalTiles != "" {
+			d.MapTiles = "/map-tile/{s}/{r}/{z}/{x}/{y}.png"
+		}
+
+		d.MapAttribution = maps.Attribution
+
+		return out.Render(tmpl, d)
+	})
+
+	u.SetTags("Map")
+	u.SetExpectedErrors(status.Unknown)
+
+	return u
+}
+
+type mapPhotosInput struct {
+	MinLat float64 `query:"min_lat" required:"true" minimum:"-90" maximum:"90"`
+	MinLon float64 `query:"min_lon" required:"true" minimum:"-180" maximum:"180"`
+	MaxLat float64 `query:"max_lat" required:"true" minimum:"-90" maximum:"90"`
+	MaxLon float64 `query:"max_lon" required:"true" minimum:"-180" maximum:"180"`
+	Zoom   int     `query:"zoom" required:"true" minimum:"0" maximum:"22" description:"Map zoom level to size cluster grid."`
+}
+
+type mapCluster struct {
+	Hash   uniq.Hash   `json:"hash" description:"Latest photo in cluster."`
+	Width  int64       `json:"width"`
+	Height int64       `json:"height"`
+	Lat    float64     `json:"lat"`
+	Lon    float64     `json:"lon"`
+	Count  int         `json:"count"`
+	Bounds [4]float64  `json:"bounds" description:"Bounding box of cluster photos: min lat, min lon, max lat, max lon."`
+	Hashes []uniq.Hash `json:"hashes,omitempty" description:"Photos in small cluster."`
+}
+
+type mapPhotosOutput struct {
+	Clusters      []mapCluster                `json:"clusters"`
+	MarkerSprites map[string]*sprite.ViewItem `json:"marker_sprites,omitempty"`
+	SpriteSheets  map[string]sprite.Sheet     `json:"sprite_sheets,omitempty"`
+}
+
+// MapPhotos creates use case interactor to find clustered photos within map viewport.
+func MapPhotos(deps interface {
+	getAlbumImagesDeps
+	showAlbumSpriteDeps
+},
+) usecase.IOInteractorOf[mapPhotosInput, mapPhotosOutput] {
+	u := usecase.NewInteractor(func(ctx context.Context, in mapPhotosInput, out *mapPhotosOutput) error {
+		deps.StatsTracker().Add(ctx, "map_photos", 1)
+
+		out.Clusters = []mapCluster{}
+		isAdmin := auth.IsAdmin(ctx)
+
+		if !isAdmin && deps.Settings().Privacy().HideGeoPosition {
+			return nil
+		}
+
+		q := deps.ImageSelector().Select().InBBox(in.MinLat, in.MinLon, in.MaxLat, in.MaxLon)
+		if !isAdmin {
+			q.OnlyPublic()
+		}
+
+		points, err := q.GeoPoints(ctx)
+		if err != nil {
+			return fmt.Errorf("find geo points: %w", err)
+		}
+
+		gp := make([]geocluster.Point, 0, len(points))
+		for _, p := range points {
+			gp = append(gp, geocluster.Point{Lat: p.Lat, Lon: p.Lon})
+		}
+
+		for _, c := range geocluster.Grid(gp, in.Zoom, mapClusterCell) {
+			p := points[c.Points[0]]
+
+			mc := mapCluster{
+				Hash:   p.Hash,
+				Width:  p.Width,
+				Height: p.Height,
+				Lat:    c.Lat,
+				Lon:    c.Lon,
+				Count:  c.Count,
+				Bounds: [4]float64{c.MinLat, c.MinLon, c.MaxLat, c.MaxLon},
+			}
+
+			if c.Count <= mapClusterHashes {
+				for _, i := range c.Points {
+					mc.Hashes = append(mc.Hashes, points[i].Hash)
+				}
+			}
+
+			out.Clusters = append(out.Clusters, mc)
+		}
+
+		if len(out.Clusters) > 0 && deps.Settings().Appearance().AlbumSpritesEnabled() {
+			mapMarkerSprites(ctx, deps, isAdmin, out)
+		}
+
+		return nil
+	})
+
+	u.SetTags("Map")
+	u.SetExpectedErrors(status.Unknown, status.InvalidArgument)
+
+	return u
+}
+
+// mapMarkerSprites adds marker sprites of cluster photos.
+//
+// Sprites are built for all geotagged photos, so that manifest does not depend on viewport.
+func mapMarkerSprites(ctx context.Context, deps interface {
+	getAlbumImagesDeps
+	showAlbumSpriteDeps
+}, isAdmin bool, out *mapPhotosOutput,
+) {
+	q := deps.ImageSelector().Select()
+	if !isAdmin {
+		q.OnlyPublic()
+	}
+
+	points, err := q.GeoPoints(ctx)
+	if err != nil {
+		deps.CtxdLogger().Error(ctx, "failed to find map sprite images", "error", err)
+
+		return
+	}
+
+	spriteImages := make([]sprite.Image, 0, len(points))
+	for _, p := range points {
+		spriteImages = append(spriteImages, sprite.Image{
+			Hash:   p.Hash,
+			Width:  p.Width,
+			Height: p.Height,
+			HasGPS: true,
+		})
+	}
+
+	manifest, ok, err := deps.AlbumSprites().Ready(ctx, spriteImages)
+	if err != nil {
+		deps.CtxdLogger().Error(ctx, "failed to get map sprite manifest", "error", err)
+
+		return
+	}
+
+	if !ok {
+		return
+	}
+
+	if err := trackMapSprites(ctx, deps, spriteImages); err != nil {
+		deps.CtxdLogger().Error(ctx, "failed to track map sprite manifest", "error", err)
+	}
+
+	markers := deps.AlbumSprites().MarkerView(manifest)
+	out.MarkerSprites = make(map[string]*sprite.ViewItem, len(out.Clusters))
+
+	for _, c := range out.Clusters {
+		h := c.Hash.String()
+		if m, ok := markers[h]; ok {
+			out.MarkerSprites[h] = m
+		}
+	}
+
+	out.SpriteSheets = deps.AlbumSprites().CompactSheets(nil, out.MarkerSprites)
+}
+
+// trackMapSprites retires map sprite manifest when any album changes.
+func trackMapSprites(ctx context.Context, deps interface {
+	getAlbumImagesDeps
+	showAlbumSpriteDeps
+}, spriteImages []sprite.Image,
+) error {
+	retirementKey, err := deps.AlbumSprites().TrackAlbum(ctx, spriteImages, photo.AlbumHash(mapName))
+	if err != nil {
+		return err
+	}
+
+	if err := deps.DepCache().ResetKey(ctx, sprite.RetirementCacheName, retirementKey); err != nil {
+		return err
+	}
+
+	albums, err := deps.PhotoAlbumFinder().FindAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	labels := make([]string, 0, len(albums))
+	for _, a := range albums {
+		labels = append(labels, a.Name)
+	}
+
+	deps.DepCache().AlbumDependency(sprite.RetirementCacheName, retirementKey, labels...)
+
+	return nil
+}
@@ -11,11 +11,16 @@ import (
 	"github.com/swaggest/usecase"
 	"github.com/vearutop/photo-blog/internal/domain/photo"
 	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/auth"
+	"github.com/vearutop/photo-blog/internal/infra/image"
+	"github.com/vearutop/photo-blog/internal/infra/settings"
 )
 
 type showThumbDeps interface {
 	PhotoImageFinder() uniq.Finder[photo.Image]
 	PhotoThumbnailer() photo.Thumbnailer
+	Settings() settings.Values
+	CtxdLogger() ctxd.Logger
 }
 
 type showThumbInput struct {
@@ -28,19 +33,34 @@ func ShowThumb(deps showThumbDeps) usecase.Interactor {
 	u := usecase.NewInteractor(func(ctx context.Context, in showThumbInput, out *response.EmbeddedSetter) error {
 		rw := out.ResponseWriter()
 
-		image, err := deps.PhotoImageFinder().FindByHash(ctx, in.Hash)
+		img, err := deps.PhotoImageFinder().FindByHash(ctx, in.Hash)
 		if err != nil {
 			return err
 		}
 
 		dctx := context.WithoutCancel(ctx)
-		cont, err := deps.PhotoThumbnailer().Thumbnail(dctx, image, in.Size)
+		cont, err := deps.PhotoThumbnailer().Thumbnail(dctx, img, in.Size)
 		if err != nil {
 			return ctxd.WrapError(ctx, err, "getting thumbnail")
 		}
 
 		rw.Header().Set("Cache-Control", "max-age=31536000")
 
+		if wm := deps.Settings().Watermark(); wm.Enabled && auth.IsAdmin(ctx) {
+			// Admin sees clean thumbs, they must not be shared via proxies.
+			rw.Header().Set("Cache-Control", "private, max-age=31536000")
+		} else if wm.Enabled && shouldWatermark(deps, cont, wm) {
+			if fn, err := image.WatermarkThumb(dctx, cont, in.Size, wm); err != nil {
+				deps.CtxdLogger().Error(ctx, "failed to watermark thumb", "error", err)
+			} else {
+				// Watermark can change, so public copies expire sooner.
+				rw.Header().Set("Cache-Control", "max-age=86400")
+				http.ServeFile(rw, in.Request(), fn)
+
+				return nil
+			}
+		}
+
 		if cont.FilePath != "" {
 			if strings.HasPrefix(cont.FilePath, "https://") || strings.HasPrefix(cont.FilePath, "http://") {
 				http.Redirect(rw, in.Request(), cont.FilePath, http.StatusMovedPermanently)
@@ -49,7 +69,7 @@ func ShowThumb(deps showThumbDeps) usecase.Interactor {
 
 			http.ServeFile(rw, in.Request(), cont.FilePath)
 		} else {
-			http.ServeContent(rw, in.Request(), "thumb.jpg", image.CreatedAt, cont.ReadSeeker())
+			http.ServeContent(rw, in.Request(), "thumb.jpg", img.CreatedAt, cont.ReadSeeker())
 		}
 
 		return nil
@@ -58,3 +78,18 @@ func ShowThumb(deps showThumbDeps) usecase.Interactor {
 
 	return u
 }
+
+func shouldWatermark(deps showThumbDeps, th photo.Thumb, wm settings.Watermark) bool {
+	if strings.HasPrefix(th.FilePath, "https://") || strings.HasPrefix(th.FilePath, "http://") {
+		return false
+	}
+
+	minWidth := wm.MinWidth
+
+	// Reduced 1200w and 2400w images replace hidden originals in the lightbox.
+	if deps.Settings().Privacy().HideOriginal && (minWidth == 0 || minWidth > 1200) {
+		minWidth = 1200
+	}
+
+	return th.Width >= minWidth
+}
@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/swaggest/rest/response"
+	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/auth"
+	"github.com/vearutop/photo-blog/internal/infra/settings"
+)
+
+type showVideoDeps interface {
+	PhotoVideoFinder() uniq.Finder[photo.Video]
+	Settings() settings.Values
+}
+
+// ShowVideo serves video clip file with support of range requests.
+func ShowVideo(deps showVideoDeps) usecase.Interactor {
+	u := usecase.NewInteractor(func(ctx context.Context, in hashInPath, out *response.EmbeddedSetter) error {
+		if deps.Settings().Privacy().HideOriginal && !auth.IsAdmin(ctx) {
+			return status.PermissionDenied
+		}
+
+		v, err := deps.PhotoVideoFinder().FindByHash(ctx, in.Hash)
+		if err != nil {
+			return err
+		}
+
+		rw := out.ResponseWriter()
+
+		rw.Header().Set("Cache-Control", "max-age=31536000")
+		rw.Header().Set("Content-Type", v.Mime)
+
+		http.ServeFile(rw, in.Request(), v.Path)
+
+		return nil
+	})
+	u.SetTags("Image")
+	u.SetExpectedErrors(status.NotFound, status.PermissionDenied)
+
+	return u
+}
@@ -0,0 +1,31 @@
+package stats
+
+import (
+	"context"
+	"time"
+
+	"github.com/swaggest/usecase"
+	"github.com/vearutop/photo-blog/internal/infra/auth"
+)
+
+// BotTraffic is a report of bot requests.
+type BotTraffic struct {
+	Since   time.Time         `json:"since" description:"Requests are counted since service start."`
+	Traffic []auth.BotTraffic `json:"traffic"`
+}
+
+// ShowBotTraffic reports allowed, denied and limited bot requests.
+func ShowBotTraffic(deps interface {
+	BotPolicy() *auth.BotPolicy
+},
+) usecase.Interactor {
+	u := usecase.NewInteractor(func(ctx context.Context, in struct{}, out *BotTraffic) error {
+		out.Since, out.Traffic = deps.BotPolicy().Traffic()
+
+		return nil
+	})
+
+	u.SetTags("Stats")
+
+	return u
+}
@@ -0,0 +1,354 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/storage/visitor"
+	"github.com/vearutop/photo-blog/pkg/web"
+	"github.com/vearutop/photo-blog/pkg/webstats"
+	"github.com/vearutop/photo-blog/resources/static"
+)
+
+const (
+	dateLayout           = "2006-01-02"
+	dashboardAlbumsLimit = 10
+	dashboardImagesLimit = 20
+	dashboardDimLimit    = 15
+)
+
+var errInvalidRange = errors.New("start date is after end date")
+
+type dashboardPoint struct {
+	Date  string `json:"date"`
+	Uniq  int    `json:"uniq"`
+	Views int    `json:"views"`
+}
+
+type dashboardImagePoint struct {
+	Date      string  `json:"date"`
+	Views     int     `json:"views"`
+	AvgViewMs int     `json:"avg_view_ms"`
+	ZoomRate  float64 `json:"zoom_rate"`
+}
+
+type dashboardTotals struct {
+	Visitors   int     `json:"visitors"`
+	Views      int     `json:"views"`
+	ImageViews int     `json:"image_views"`
+	AvgViewMs  int     `json:"avg_view_ms"`
+	ZoomRate   float64 `json:"zoom_rate"`
+}
+
+type dashboardRange struct {
+	From   string                `json:"from"`
+	To     string                `json:"to"`
+	Totals dashboardTotals       `json:"totals"`
+	Visits []dashboardPoint      `json:"visits"`
+	Images []dashboardImagePoint `json:"images"`
+}
+
+type dashboardAlbum struct {
+	Name   string           `json:"name"`
+	Views  int              `json:"views"`
+	Uniq   int              `json:"uniq"`
+	Points []dashboardPoint `json:"points"`
+}
+
+type dashboardImage struct {
+	Preview   string  `json:"preview"`
+	Views     int     `json:"views"`
+	AvgViewMs int     `json:"avg_view_ms"`
+	ZoomRate  float64 `json:"zoom_rate"`
+}
+
+type dashboardBreakdown struct {
+	Value    string `json:"value"`
+	Visitors int    `json:"visitors"`
+	Views    int    `json:"views"`
+}
+
+type dashboardData struct {
+	Title      string                          `json:"title"`
+	Period     webstats.Period                 `json:"period"`
+	Current    dashboardRange                  `json:"current"`
+	Previous   *dashboardRange                 `json:"previous,omitempty"`
+	Albums     []dashboardAlbum                `json:"albums"`
+	TopImages  []dashboardImage                `json:"top_images"`
+	Breakdowns map[string][]dashboardBreakdown `json:"breakdowns"`
+}
+
+func avgRate(views, viewMs, zooms int) (int, float64) {
+	if views == 0 {
+		return 0, 0
+	}
+
+	return viewMs / views, float64(int(1000*float64(zooms)/float64(views))) / 1000
+}
+
+// ShowDashboard renders analytics charts from rollups.
+func ShowDashboard(deps showDailyStatsDeps) usecase.Interactor {
+	type dashboardInput struct {
+		Period  webstats.Period `query:"period" default:"d" enum:"d,w,m" description:"Grouping period: day, week or month."`
+		From    string          `query:"from" description:"Start date, YYYY-MM-DD, default depends on period."`
+		To      string          `query:"to" description:"End date, YYYY-MM-DD, default today."`
+		Compare bool            `query:"compare" description:"Compare with previous range of the same length."`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, in dashboardInput, out *web.Page) error {
+		deps.StatsTracker().Add(ctx, "show_dashboard", 1)
+
+		if !in.Period.Valid() {
+			in.Period = webstats.Day
+		}
+
+		now := time.Now().UTC()
+		to := now
+
+		if in.To != "" {
+			t, err := time.Parse(dateLayout, in.To)
+			if err != nil {
+				return status.Wrap(err, status.InvalidArgument)
+			}
+
+			to = t
+		}
+
+		from := map[webstats.Period]time.Time{
+			webstats.Day:   to.AddDate(0, 0, -29),
+			webstats.Week:  to.AddDate(0, 0, -7*11),
+			webstats.Month: to.AddDate(0, -11, 0),
+		}[in.Period]
+
+		if in.From != "" {
+			t, err := time.Parse(dateLayout, in.From)
+			if err != nil {
+				return status.Wrap(err, status.InvalidArgument)
+			}
+
+			from = t
+		}
+
+		from, to = in.Period.Start(from), in.Period.Next(to).Add(-time.Second)
+		if to.Before(from) {
+			return status.Wrap(errInvalidRange, status.InvalidArgument)
+		}
+
+		st := deps.VisitorStats()
+
+		// Fresh visits of today are included.
+		if err := st.RollupPending(ctx, now); err != nil {
+			return err
+		}
+
+		d := dashboardData{
+			Title:      "Dashboard",
+			Period:     in.Period,
+			Breakdowns: map[string][]dashboardBreakdown{},
+		}
+
+		var err error
+
+		if d.Current, err = dashboardRangeData(ctx, st, in.Period, from, to); err != nil {
+			return err
+		}
+
+		if in.Compare {
+			prevTo := from.Add(-time.Second)
+			prevFrom := in.Period.Start(prevTo.Add(-to.Sub(from)))
+
+			prev, err := dashboardRangeData(ctx, st, in.Period, prevFrom, prevTo)
+			if err != nil {
+				return err
+			}
+
+			d.Previous = &prev
+		}
+
+		if d.Albums, err = dashboardAlbums(ctx, deps, in.Period, from, to); err != nil {
+			return err
+		}
+
+		thumbBase := deps.Settings().Appearance().ThumbBaseURL
+		if thumbBase == "" {
+			thumbBase = "/thumb"
+		}
+
+		images, err := st.TopImagesInRange(ctx, from, to, dashboardImagesLimit)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range images {
+			avg, zr := avgRate(row.Views, row.ViewMs, row.Zooms)
+			h := row.Hash.String()
+
+			d.TopImages = append(d.TopImages, dashboardImage{
+				Preview:   `<a href="/list-` + h + `/"><img style="width: 150px" src="` + thumbBase + `/300w/` + h + `.jpg"/></a>`,
+				Views:     row.Views,
+				AvgViewMs: avg,
+				ZoomRate:  zr,
+			})
+		}
+
+		for _, dim := range []string{visitor.DimCountry, visitor.DimDevice, visitor.DimScreen, visitor.DimReferrer} {
+			rows, err := st.Breakdowns(ctx, dim, from, to, dashboardDimLimit)
+			if err != nil {
+				return err
+			}
+
+			bd := make([]dashboardBreakdown, 0, len(rows))
+			for _, r := range rows {
+				bd = append(bd, dashboardBreakdown{Value: r.Value, Visitors: r.Visitors, Views: r.Views})
+			}
+
+			d.Breakdowns[dim] = bd
+		}
+
+		return out.Render(static.MustParseTemplate("stats/dashboard.html"), d)
+	})
+
+	u.SetTags("Stats")
+	u.SetExpectedErrors(status.InvalidArgument)
+
+	return u
+}
+
+func dashboardRangeData(ctx context.Context, st *visitor.StatsRepository, p webstats.Period, from, to time.Time) (dashboardRange, error) {
+	r := dashboardRange{
+		From: from.Format(dateLayout),
+		To:   to.Format(dateLayout),
+	}
+
+	periods, err := st.Periods(ctx, p, from, to)
+	if err != nil {
+		return r, err
+	}
+
+	byStart := make(map[int64]visitor.PeriodStats, len(periods))
+	for _, ps := range periods {
+		byStart[ps.Start] = ps
+	}
+
+	for _, start := range p.Starts(from, to) {
+		ps := byStart[start.Unix()]
+
+		r.Visits = append(r.Visits, dashboardPoint{Date: start.Format(dateLayout), Uniq: ps.Uniq, Views: ps.Views})
+		r.Totals.Visitors += ps.Uniq
+		r.Totals.Views += ps.Views
+	}
+
+	daily, err := st.DailyImages(ctx, from, to)
+	if err != nil {
+		return r, err
+	}
+
+	buckets := map[int64]*visitor.DailyImageStats{}
+	total := visitor.DailyImageStats{}
+
+	for _, row := range daily {
+		k := p.Start(time.Unix(row.Date, 0)).Unix()
+
+		b := buckets[k]
+		if b == nil {
+			b = &visitor.DailyImageStats{}
+			buckets[k] = b
+		}
+
+		for _, s := range []*visitor.DailyImageStats{b, &total} {
+			s.Views += row.Views
+			s.ViewMs += row.ViewMs
+			s.Zooms += row.Zooms
+		}
+	}
+
+	for _, start := range p.Starts(from, to) {
+		pt := dashboardImagePoint{Date: start.Format(dateLayout)}
+
+		if b := buckets[start.Unix()]; b != nil {
+			pt.Views = b.Views
+			pt.AvgViewMs, pt.ZoomRate = avgRate(b.Views, b.ViewMs, b.Zooms)
+		}
+
+		r.Images = append(r.Images, pt)
+	}
+
+	r.Totals.ImageViews = total.Views
+	r.Totals.AvgViewMs, r.Totals.ZoomRate = avgRate(total.Views, total.ViewMs, total.Zooms)
+
+	return r, nil
+}
+
+func dashboardAlbums(ctx context.Context, deps showDailyStatsDeps, p webstats.Period, from, to time.Time) ([]dashboardAlbum, error) {
+	daily, err := deps.VisitorStats().DailyPages(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	type albumPoints struct {
+		dashboardAlbum
+		points map[int64]*dashboardPoint
+	}
+
+	albums := map[uniq.Hash]*albumPoints{}
+
+	for _, row := range daily {
+		a := albums[row.Hash]
+		if a == nil {
+			a = &albumPoints{points: map[int64]*dashboardPoint{}}
+			albums[row.Hash] = a
+		}
+
+		a.Views += row.Views
+		a.Uniq += row.Uniq
+
+		k := p.Start(time.Unix(row.Date, 0)).Unix()
+
+		pt := a.points[k]
+		if pt == nil {
+			pt = &dashboardPoint{}
+			a.points[k] = pt
+		}
+
+		pt.Views += row.Views
+		pt.Uniq += row.Uniq
+	}
+
+	hashes := make([]uniq.Hash, 0, len(albums))
+	for h := range albums {
+		hashes = append(hashes, h)
+	}
+
+	sort.Slice(hashes, func(i, j int) bool {
+		return albums[hashes[i]].Views > albums[hashes[j]].Views
+	})
+
+	if len(hashes) > dashboardAlbumsLimit {
+		hashes = hashes[:dashboardAlbumsLimit]
+	}
+
+	res := make([]dashboardAlbum, 0, len(hashes))
+
+	for _, h := range hashes {
+		a := albums[h]
+		a.Name = albumLink(ctx, h, deps.PhotoAlbumFinder())
+
+		for _, start := range p.Starts(from, to) {
+			pt := dashboardPoint{Date: start.Format(dateLayout)}
+			if v := a.points[start.Unix()]; v != nil {
+				pt.Views, pt.Uniq = v.Views, v.Uniq
+			}
+
+			a.Points = append(a.Points, pt)
+		}
+
+		res = append(res, a.dashboardAlbum)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,103 @@
+package stats
+
+import (
+	"context"
+	"html"
+	"strconv"
+
+	"github.com/bool64/ctxd"
+	"github.com/swaggest/usecase"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/service"
+	"github.com/vearutop/photo-blog/pkg/web"
+	"github.com/vearutop/photo-blog/resources/static"
+)
+
+type favoritesDeps interface {
+	CtxdLogger() ctxd.Logger
+
+	service.SiteVisitorRepositoryProvider
+	service.FavoriteRepositoryProvider
+}
+
+// ShowFavorites lists favorite selections of visitors.
+func ShowFavorites(deps favoritesDeps) usecase.Interactor {
+	type favoriteRow struct {
+		Visitor   string `json:"visitor"`
+		List      string `json:"list"`
+		Images    int    `json:"images"`
+		LastAdded string `json:"last_added"`
+		Export    string `json:"export"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, in struct{}, out *web.Page) error {
+		counts, err := deps.FavoriteRepository().CountByList(ctx)
+		if err != nil {
+			return err
+		}
+
+		lists, err := deps.FavoriteRepository().FindAllLists(ctx)
+		if err != nil {
+			return err
+		}
+
+		listNames := make(map[uniq.Hash]string, len(lists))
+		for _, l := range lists {
+			listNames[l.Hash] = l.Name
+		}
+
+		visitorHashes := make([]uniq.Hash, 0, len(counts))
+		for _, c := range counts {
+			visitorHashes = append(visitorHashes, c.VisitorHash)
+		}
+
+		visitors, err := deps.SiteVisitorRepository().FindByHashes(ctx, visitorHashes...)
+		if err != nil {
+			return err
+		}
+
+		visitorNames := make(map[uniq.Hash]string, len(visitors))
+		for _, v := range visitors {
+			visitorNames[v.Hash] = v.Name
+		}
+
+		d := PageData{
+			Title: "Favorites",
+		}
+
+		rows := make([]favoriteRow, 0, len(counts))
+
+		for _, c := range counts {
+			h := c.VisitorHash.String()
+
+			name := visitorNames[c.VisitorHash]
+			if name == "" {
+				name = h
+			}
+
+			r := favoriteRow{}
+			r.Visitor = `<a href="/stats/visitor/` + h + `.html">` + html.EscapeString(name) + `</a>`
+			r.Images = c.Count
+			r.LastAdded = c.LastAdded
+
+			if c.ListHash == 0 {
+				r.List = "default"
+			} else {
+				lh := c.ListHash.String()
+				r.List = `<a href="/` + photo.FavoriteListPrefix + lh + `/">` + html.EscapeString(listNames[c.ListHash]) + `</a>`
+				r.Export = `<a href="/favorite/list/` + lh + `.csv">CSV, ` + strconv.Itoa(c.Count) + ` files</a>`
+			}
+
+			rows = append(rows, r)
+		}
+
+		d.Tables = append(d.Tables, Table{
+			Rows: rows,
+		})
+
+		return out.Render(static.TableTemplate, d)
+	})
+
+	return u
+}
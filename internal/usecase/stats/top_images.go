@@ -2,21 +2,30 @@ package stats
 
 import (
 	"context"
+	"sort"
 
 	"github.com/swaggest/usecase"
 	"github.com/vearutop/photo-blog/internal/domain/photo"
 	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/engagement"
 	"github.com/vearutop/photo-blog/pkg/web"
 	"github.com/vearutop/photo-blog/resources/static"
 )
 
-func TopImages(deps showDailyStatsDeps) usecase.Interactor {
+type topImagesDeps interface {
+	showDailyStatsDeps
+	Engagement() *engagement.Service
+}
+
+func TopImages(deps topImagesDeps) usecase.Interactor {
 	type dateRow struct {
 		Preview      string  `json:"preview"`
 		Hash         string  `json:"hash"`
 		Uniq         int     `json:"uniq"`
 		Views        int     `json:"views"`
 		Zooms        int     `json:"zooms"`
+		Favorites    int     `json:"favorites"`
+		Score        float64 `json:"score"`
 		ViewTime     float64 `json:"view_minutes"`
 		ThumbTime    float64 `json:"preview_minutes"`
 		ThumbPrtTime float64 `json:"preview_mobile_stripe_minutes"`
@@ -24,6 +33,7 @@ func TopImages(deps showDailyStatsDeps) usecase.Interactor {
 
 	type topImagesFilter struct {
 		AlbumName string `query:"album_name"`
+		ByScore   bool   `query:"by_score" description:"Order by engagement score instead of unique visitors."`
 	}
 
 	u := usecase.NewInteractor(func(ctx context.Context, in topImagesFilter, out *web.Page) error {
@@ -56,6 +66,17 @@ func TopImages(deps showDailyStatsDeps) usecase.Interactor {
 			hashes = append(hashes, row.Hash)
 		}
 
+		scores, err := deps.Engagement().Scores(ctx, hashes...)
+		if err != nil {
+			return err
+		}
+
+		if in.ByScore {
+			sort.SliceStable(st, func(i, j int) bool {
+				return scores[st[i].Hash].Score > scores[st[j].Hash].Score
+			})
+		}
+
 		d := PageData{}
 		d.Title = "Top Images"
 
@@ -78,6 +99,8 @@ func TopImages(deps showDailyStatsDeps) usecase.Interactor {
 			r.Views = row.Views
 			r.Uniq = row.Uniq
 			r.Zooms = row.Zooms
+			r.Favorites = scores[row.Hash].Favorites
+			r.Score = scores[row.Hash].Score
 			r.ViewTime = ms2min(row.ViewMs)
 			r.ThumbTime = ms2min(row.ThumbMs)
 			r.ThumbPrtTime = ms2min(row.ThumbPrtMs)
@@ -0,0 +1,110 @@
+package stats
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bool64/ctxd"
+	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/domain/comment"
+	"github.com/vearutop/photo-blog/internal/domain/site"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/service"
+	"github.com/vearutop/photo-blog/internal/infra/storage"
+	"github.com/vearutop/photo-blog/internal/infra/storage/visitor"
+)
+
+type visitorDataDeps interface {
+	CtxdLogger() ctxd.Logger
+	VisitorStats() *visitor.StatsRepository
+
+	service.SiteVisitorRepositoryProvider
+	service.CommentMessageRepositoryProvider
+	service.FavoriteRepositoryProvider
+}
+
+type visitorInPath struct {
+	Hash uniq.Hash `path:"hash" description:"Visitor hash, value of v cookie."`
+}
+
+// VisitorDataExport contains all data stored about a visitor.
+type VisitorDataExport struct {
+	Stats     visitor.VisitorData `json:"stats"`
+	Profile   *site.Visitor       `json:"profile,omitempty" description:"Commenter profile."`
+	Messages  []VisitorMessage    `json:"messages"`
+	Favorites []uniq.Hash         `json:"favorites" description:"Hashes of favorite images."`
+
+	FavoriteLists []storage.FavoriteList `json:"favorite_lists,omitempty" description:"Named lists of favorite images."`
+}
+
+// VisitorMessage is a message of a visitor with stored IP address.
+type VisitorMessage struct {
+	comment.Message
+	IP string `json:"ip,omitempty" description:"Address the message was sent from, empty after retention period."`
+}
+
+// ExportVisitorData returns all data stored about a visitor.
+func ExportVisitorData(deps visitorDataDeps) usecase.Interactor {
+	u := usecase.NewInteractor(func(ctx context.Context, in visitorInPath, out *VisitorDataExport) (err error) {
+		if out.Stats, err = deps.VisitorStats().VisitorData(ctx, in.Hash); err != nil {
+			return err
+		}
+
+		p, err := deps.SiteVisitorRepository().FindByHash(ctx, in.Hash)
+		if err == nil {
+			out.Profile = &p
+		} else if !errors.Is(err, status.NotFound) {
+			return err
+		}
+
+		messages, err := deps.CommentMessageRepository().FindByVisitor(ctx, in.Hash)
+		if err != nil {
+			return err
+		}
+
+		out.Messages = make([]VisitorMessage, 0, len(messages))
+		for _, m := range messages {
+			out.Messages = append(out.Messages, VisitorMessage{Message: m, IP: m.IP})
+		}
+
+		if out.Favorites, err = deps.FavoriteRepository().FindImageHashes(ctx, in.Hash, 0, 0); err != nil {
+			return err
+		}
+
+		if out.FavoriteLists, err = deps.FavoriteRepository().FindLists(ctx, in.Hash); err != nil {
+			return err
+		}
+
+		return nil
+	})
+
+	u.SetTags("Stats")
+
+	return u
+}
+
+// EraseVisitorData removes all data stored about a visitor.
+func EraseVisitorData(deps visitorDataDeps) usecase.Interactor {
+	u := usecase.NewInteractor(func(ctx context.Context, in visitorInPath, out *struct{}) error {
+		deps.CtxdLogger().Important(ctx, "erasing visitor data", "visitor", in.Hash)
+
+		if err := deps.VisitorStats().EraseVisitor(ctx, in.Hash); err != nil {
+			return err
+		}
+
+		if err := deps.CommentMessageRepository().DeleteByVisitor(ctx, in.Hash); err != nil {
+			return err
+		}
+
+		if err := deps.FavoriteRepository().DeleteVisitor(ctx, in.Hash); err != nil {
+			return err
+		}
+
+		return deps.SiteVisitorRepository().Delete(ctx, in.Hash)
+	})
+
+	u.SetTags("Stats")
+
+	return u
+}
@@ -0,0 +1,121 @@
+package stats
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/bool64/brick/database"
+	"github.com/bool64/ctxd"
+	"github.com/bool64/sqluct"
+	brickstats "github.com/bool64/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/photo-blog/internal/domain/comment"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/storage"
+	"github.com/vearutop/photo-blog/internal/infra/storage/sqlite"
+	"github.com/vearutop/photo-blog/internal/infra/storage/sqlite_stats"
+	"github.com/vearutop/photo-blog/internal/infra/storage/visitor"
+	_ "modernc.org/sqlite"
+)
+
+func testStorage(t *testing.T, migrations fs.FS) *sqluct.Storage {
+	t.Helper()
+
+	cfg := database.Config{
+		DriverName:      "sqlite",
+		DSN:             filepath.Join(t.TempDir(), "db.sqlite") + "?_time_format=sqlite",
+		ApplyMigrations: true,
+		MaxOpen:         1,
+		MaxIdle:         1,
+	}
+
+	st, err := database.SetupStorageDSN(cfg, ctxd.NoOpLogger{}, brickstats.NoOp{}, migrations)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, st.DB().DB.Close())
+	})
+
+	return st
+}
+
+type visitorDataTestDeps struct {
+	*storage.VisitorRepository
+
+	messages  *storage.MessageRepository
+	favorites *storage.FavoriteRepository
+	stats     *visitor.StatsRepository
+}
+
+func (visitorDataTestDeps) CtxdLogger() ctxd.Logger {
+	return ctxd.NoOpLogger{}
+}
+
+func (d visitorDataTestDeps) VisitorStats() *visitor.StatsRepository {
+	return d.stats
+}
+
+func (d visitorDataTestDeps) CommentMessageRepository() *storage.MessageRepository {
+	return d.messages
+}
+
+func (d visitorDataTestDeps) FavoriteRepository() *storage.FavoriteRepository {
+	return d.favorites
+}
+
+func TestEraseVisitorData(t *testing.T) {
+	ctx := context.Background()
+	st := testStorage(t, sqlite.Migrations)
+
+	vs, err := visitor.NewStats(testStorage(t, sqlite_stats.Migrations), ctxd.NoOpLogger{}, nil)
+	require.NoError(t, err)
+
+	deps := visitorDataTestDeps{
+		VisitorRepository: storage.NewVisitorRepository(st),
+		messages:          storage.NewMessageRepository(st),
+		favorites:         storage.NewFavoriteRepository(st),
+		stats:             vs,
+	}
+
+	const (
+		erased = uniq.Hash(1)
+		other  = uniq.Hash(2)
+	)
+
+	// Erased visitor has a message with a reply of other visitor, an own reply to it and a message without replies.
+	_, err = st.DB().ExecContext(ctx, `INSERT INTO message (hash, thread_hash, visitor_hash, approved, text, ip, parent_hash)
+		VALUES (10, 1, ?, 1, 'Hello', '1.2.3.4', 0), (11, 1, ?, 1, 'Hi', '', 10), (12, 1, ?, 1, 'Bye', '', 10),
+		       (20, 1, ?, 1, 'Alone', '1.2.3.4', 0),
+		       (30, 1, ?, 1, 'Nested', '', 0), (31, 1, ?, 1, 'Own', '', 30), (32, 1, ?, 1, 'Deep', '', 31)`,
+		erased, other, erased, erased, erased, erased, other)
+	require.NoError(t, err)
+
+	require.NoError(t, EraseVisitorData(deps).Interact(ctx, visitorInPath{Hash: erased}, &struct{}{}))
+
+	messages, err := deps.messages.FindThread(ctx, 1, 0)
+	require.NoError(t, err)
+
+	texts := map[uniq.Hash]string{}
+	for _, m := range messages {
+		texts[m.Hash] = m.Text
+
+		assert.NotEqual(t, erased, m.VisitorHash)
+		assert.Empty(t, m.IP)
+	}
+
+	// Messages with replies of others keep their place in thread, so that replies are not orphaned.
+	assert.Equal(t, map[uniq.Hash]string{
+		10: comment.DeletedText,
+		11: "Hi",
+		30: comment.DeletedText,
+		31: comment.DeletedText,
+		32: "Deep",
+	}, texts)
+
+	left, err := deps.messages.FindByVisitor(ctx, erased)
+	require.NoError(t, err)
+	assert.Empty(t, left)
+}
@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/pkg/trackstats"
+)
+
+// profilePoints limits the size of elevation profile.
+const profilePoints = 500
+
+type trackProfileOutput struct {
+	photo.GpxSettings
+
+	Points []trackstats.ProfilePoint `json:"points"`
+}
+
+// GetTrackProfile creates use case interactor to get track statistics and elevation profile.
+func GetTrackProfile(deps dlGpxDeps) usecase.IOInteractorOf[hashInPath, trackProfileOutput] {
+	u := usecase.NewInteractor(func(ctx context.Context, in hashInPath, out *trackProfileOutput) error {
+		gpx, err := deps.PhotoGpxFinder().FindByHash(ctx, in.Hash)
+		if err != nil {
+			return err
+		}
+
+		g, err := gpx.Load()
+		if err != nil {
+			return fmt.Errorf("load track: %w", err)
+		}
+
+		out.GpxSettings = gpx.Settings.Val
+		if !out.HasStats() {
+			out.Stats = trackstats.Compute(g)
+			out.Days = trackstats.Days(g)
+		}
+
+		out.Points = trackstats.Profile(g, profilePoints)
+
+		return nil
+	})
+
+	u.SetTags("Gpx")
+	u.SetExpectedErrors(status.Unknown, status.NotFound)
+
+	return u
+}
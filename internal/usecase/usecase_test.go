@@ -0,0 +1,163 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/bool64/brick/database"
+	"github.com/bool64/ctxd"
+	"github.com/bool64/sqluct"
+	"github.com/bool64/stats"
+	"github.com/stretchr/testify/require"
+	"github.com/swaggest/usecase"
+	"github.com/vearutop/photo-blog/internal/domain/photo"
+	"github.com/vearutop/photo-blog/internal/domain/uniq"
+	"github.com/vearutop/photo-blog/internal/infra/notifier"
+	"github.com/vearutop/photo-blog/internal/infra/settings"
+	"github.com/vearutop/photo-blog/internal/infra/storage"
+	"github.com/vearutop/photo-blog/internal/infra/storage/sqlite"
+	_ "modernc.org/sqlite"
+)
+
+// testStorage returns main database with applied migrations.
+func testStorage(t *testing.T) *sqluct.Storage {
+	t.Helper()
+
+	cfg := database.Config{
+		DriverName:      "sqlite",
+		DSN:             filepath.Join(t.TempDir(), "db.sqlite") + "?_time_format=sqlite",
+		ApplyMigrations: true,
+		MaxOpen:         1,
+		MaxIdle:         1,
+	}
+
+	st, err := database.SetupStorageDSN(cfg, ctxd.NoOpLogger{}, stats.NoOp{}, sqlite.Migrations)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, st.DB().DB.Close())
+	})
+
+	return st
+}
+
+// testDeps provides repositories and services of test storage to use cases.
+type testDeps struct {
+	*storage.AlbumRepository
+
+	favorites *storage.FavoriteRepository
+	proofing  *storage.ProofingRepository
+	settings  *settings.Manager
+	notifier  *notifier.Service
+}
+
+func newTestDeps(t *testing.T, st *sqluct.Storage) *testDeps {
+	t.Helper()
+
+	s, err := settings.NewManager(storage.NewSettingsRepository(st), nil)
+	require.NoError(t, err)
+
+	d := &testDeps{
+		AlbumRepository: storage.NewAlbumRepository(st, storage.NewImageRepository(st), storage.NewMetaRepository(st)),
+		favorites:       storage.NewFavoriteRepository(st),
+		proofing:        storage.NewProofingRepository(st),
+		settings:        s,
+		notifier:        notifier.NewService(ctxd.NoOpLogger{}, s, t.TempDir()),
+	}
+
+	t.Cleanup(d.notifier.Close)
+
+	return d
+}
+
+func (d *testDeps) CtxdLogger() ctxd.Logger {
+	return ctxd.NoOpLogger{}
+}
+
+func (d *testDeps) FavoriteRepository() *storage.FavoriteRepository {
+	return d.favorites
+}
+
+func (d *testDeps) ProofingRepository() *storage.ProofingRepository {
+	return d.proofing
+}
+
+func (d *testDeps) Settings() settings.Values {
+	return d.settings
+}
+
+func (d *testDeps) Notifier() *notifier.Service {
+	return d.notifier
+}
+
+// addAlbum stores album with images.
+func addAlbum(t *testing.T, d *testDeps, album photo.Album, images ...uniq.Hash) photo.Album {
+	t.Helper()
+
+	album.Hash = photo.AlbumHash(album.Name)
+
+	require.NoError(t, d.AlbumRepository.Add(context.Background(), album))
+	require.NoError(t, d.AlbumRepository.AddImages(context.Background(), album.Hash, images...))
+
+	return album
+}
+
+// addImages stores images with file names.
+func addImages(t *testing.T, st *sqluct.Storage, names ...string) []uniq.Hash {
+	t.Helper()
+
+	ir := storage.NewImageRepository(st)
+	hashes := make([]uniq.Hash, 0, len(names))
+
+	for _, n := range names {
+		img := photo.Image{}
+		img.Hash = uniq.StringHash(n)
+		img.Path = filepath.Join("photos", n)
+
+		require.NoError(t, ir.Add(context.Background(), img))
+
+		hashes = append(hashes, img.Hash)
+	}
+
+	return hashes
+}
+
+// interact invokes use case with input made from JSON encoding of in.
+//
+// Use case input types are often unexported, so fields are filled by their JSON names
+// or by Go names for fields without JSON tags. Output is returned as JSON, raw responses
+// are written to the returned recorder.
+func interact(ctx context.Context, u usecase.Interactor, in any) (string, *httptest.ResponseRecorder, error) {
+	input := reflect.New(reflect.TypeOf(u.(usecase.HasInputPort).InputPort()))
+
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if err := json.Unmarshal(b, input.Interface()); err != nil {
+			return "", nil, err
+		}
+	}
+
+	output := reflect.New(reflect.TypeOf(u.(usecase.HasOutputPort).OutputPort()).Elem()).Interface()
+	rec := httptest.NewRecorder()
+
+	if s, ok := output.(interface{ SetResponseWriter(rw http.ResponseWriter) }); ok {
+		s.SetResponseWriter(rec)
+	}
+
+	if err := u.Interact(ctx, input.Elem().Interface(), output); err != nil {
+		return "", rec, err
+	}
+
+	b, err := json.Marshal(output)
+
+	return string(b), rec, err
+}
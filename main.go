@@ -5,11 +5,13 @@ import (
 	"flag"
 	"log"
 	"net/http"
+	"path/filepath"
 	"strings"
 
 	qr "github.com/Baozisoftware/qrcode-terminal-go"
 	"github.com/bool64/brick"
 	"github.com/vearutop/photo-blog/internal/infra"
+	"github.com/vearutop/photo-blog/internal/infra/archive"
 	"github.com/vearutop/photo-blog/internal/infra/nethttp"
 	"github.com/vearutop/photo-blog/internal/infra/service"
 	"github.com/vearutop/photo-blog/pkg/net"
@@ -21,6 +23,13 @@ func main() {
 		migrate     = flag.Bool("migrate", false, "Run migrations and exit.")
 		storagePath = flag.String("storage-path", "", "Optional path to data storage, defaults to './photo-blog-data/'.")
 		listen      = flag.String("listen", "127.0.0.1:8008", "Address and port to listen to.")
+
+		exportPath      = flag.String("export", "", "Export site to a backup archive file and exit.")
+		exportOriginals = flag.Bool("export-originals", false, "Include original files in exported archive.")
+		exportThumbs    = flag.Bool("export-thumbs", false, "Include thumbnails in exported archive.")
+		importPath      = flag.String("import", "", "Import site from a backup archive file and exit.")
+		importOriginals = flag.Bool("import-originals", false, "Restore original files from imported archive.")
+		remap           = flag.String("remap", "", "Comma-separated path prefix replacements for import, e.g. /old/photos=/new/photos.")
 	)
 
 	brick.Start(&cfg, func(docsMode bool) (*brick.BaseLocator, http.Handler) {
@@ -42,12 +51,36 @@ func main() {
 			log.Fatalf("failed to init service: %v", err)
 		}
 
+		if *exportPath != "" {
+			exportSite(sl, *exportPath, archive.ExportOptions{Originals: *exportOriginals, Thumbs: *exportThumbs})
+		}
+
+		if *importPath != "" {
+			importSite(sl, *importPath, *importOriginals, *remap)
+		}
+
 		return sl.BaseLocator, nethttp.NewRouter(sl)
 	}, func(o *brick.StartOptions) {
 		if migrate != nil && *migrate {
 			o.NoHTTP = true
 		}
 
+		// Archive paths are resolved before changing dir to storage path.
+		for _, p := range []*string{exportPath, importPath} {
+			if *p == "" {
+				continue
+			}
+
+			o.NoHTTP = true
+
+			abs, err := filepath.Abs(*p)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			*p = abs
+		}
+
 		if storagePath != nil && *storagePath != "" {
 			cfg.StoragePath = *storagePath
 		}
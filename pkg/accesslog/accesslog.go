@@ -0,0 +1,277 @@
+// Package accesslog parses HTTP access logs of this app, nginx/Apache and Caddy.
+package accesslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format is a log format.
+type Format string
+
+// Supported formats.
+const (
+	Auto     Format = "auto"
+	App      Format = "app"      // JSON access log of this app.
+	Combined Format = "combined" // nginx/Apache combined log format.
+	Caddy    Format = "caddy"    // Caddy JSON access log.
+)
+
+// ErrSkip is returned for lines that are not access log records.
+var ErrSkip = errors.New("not an access log record")
+
+// Entry is a request record.
+type Entry struct {
+	Time         time.Time
+	IP           string
+	ForwardedFor string
+	Host         string
+	Method       string
+	URL          string
+	Status       int
+	Referer      string
+	UserAgent    string
+	Lang         string
+	Device       string
+
+	// Visitor and Admin are only available in app format.
+	Visitor string
+	Admin   bool
+}
+
+// Detect guesses format of a line.
+func Detect(line []byte) Format {
+	line = bytes.TrimSpace(line)
+
+	if len(line) == 0 || line[0] != '{' {
+		return Combined
+	}
+
+	if bytes.Contains(line, []byte(`"request":{`)) || bytes.Contains(line, []byte(`"request": {`)) {
+		return Caddy
+	}
+
+	return App
+}
+
+// Parse parses a line in a format, Auto format is detected by line.
+func Parse(line []byte, f Format) (Entry, error) {
+	if f == Auto || f == "" {
+		f = Detect(line)
+	}
+
+	switch f {
+	case App:
+		return parseApp(line)
+	case Combined:
+		return parseCombined(line)
+	case Caddy:
+		return parseCaddy(line)
+	default:
+		return Entry{}, fmt.Errorf("unknown log format: %s", f)
+	}
+}
+
+func parseApp(line []byte) (Entry, error) {
+	var row struct {
+		Msg          string `json:"msg"`
+		Time         string `json:"time"`
+		Visitor      string `json:"visitor"`
+		Host         string `json:"host"`
+		URL          string `json:"url"`
+		UserAgent    string `json:"user_agent"`
+		Device       string `json:"device"`
+		Referer      string `json:"referer"`
+		IP           string `json:"ip"`
+		ForwardedFor string `json:"forwarded_for"`
+		Admin        bool   `json:"admin"`
+		Lang         string `json:"lang"`
+	}
+
+	if err := json.Unmarshal(line, &row); err != nil {
+		return Entry{}, err
+	}
+
+	if row.Msg != "" && row.Msg != "access" || row.URL == "" {
+		return Entry{}, ErrSkip
+	}
+
+	t, err := time.Parse("2006-01-02T15:04:05Z0700", row.Time)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	return Entry{
+		Time:         t,
+		IP:           row.IP,
+		ForwardedFor: row.ForwardedFor,
+		Host:         row.Host,
+		Method:       "GET",
+		URL:          row.URL,
+		Status:       200,
+		Referer:      row.Referer,
+		UserAgent:    row.UserAgent,
+		Lang:         row.Lang,
+		Device:       row.Device,
+		Visitor:      row.Visitor,
+		Admin:        row.Admin,
+	}, nil
+}
+
+const quoted = `"((?:[^"\\]|\\.)*)"`
+
+// combinedRe matches combined log format with optional trailing X-Forwarded-For as in default nginx config.
+var combinedRe = regexp.MustCompile(`^(\S+) \S+ \S+ \[([^\]]+)\] ` + quoted + ` (\d{3}) \S+(?: ` + quoted + ` ` + quoted + `)?(?: ` + quoted + `)?`)
+
+func parseCombined(line []byte) (Entry, error) {
+	m := combinedRe.FindSubmatch(bytes.TrimSpace(line))
+	if m == nil {
+		return Entry{}, ErrSkip
+	}
+
+	t, err := time.Parse("02/Jan/2006:15:04:05 -0700", string(m[2]))
+	if err != nil {
+		return Entry{}, err
+	}
+
+	e := Entry{
+		Time:      t,
+		IP:        string(m[1]),
+		Referer:   dash(unescape(m[5])),
+		UserAgent: dash(unescape(m[6])),
+	}
+
+	e.ForwardedFor = dash(unescape(m[7]))
+	e.Status, _ = strconv.Atoi(string(m[4]))
+
+	req := strings.Fields(unescape(m[3]))
+	if len(req) < 2 {
+		return Entry{}, ErrSkip
+	}
+
+	e.Method, e.URL = req[0], req[1]
+
+	return e, nil
+}
+
+func dash(s string) string {
+	if s == "-" {
+		return ""
+	}
+
+	return s
+}
+
+// unescape decodes \" and \xHH sequences of nginx and Apache.
+func unescape(b []byte) string {
+	if !bytes.ContainsRune(b, '\\') {
+		return string(b)
+	}
+
+	res := make([]byte, 0, len(b))
+
+	for i := 0; i < len(b); i++ {
+		if b[i] != '\\' || i+1 == len(b) {
+			res = append(res, b[i])
+
+			continue
+		}
+
+		i++
+
+		if b[i] == 'x' && i+2 < len(b) {
+			if v, err := strconv.ParseUint(string(b[i+1:i+3]), 16, 8); err == nil {
+				res = append(res, byte(v))
+				i += 2
+
+				continue
+			}
+		}
+
+		res = append(res, b[i])
+	}
+
+	return string(res)
+}
+
+func parseCaddy(line []byte) (Entry, error) {
+	var row struct {
+		Msg     string          `json:"msg"`
+		Ts      json.RawMessage `json:"ts"`
+		Status  int             `json:"status"`
+		Request *struct {
+			RemoteIP string              `json:"remote_ip"`
+			ClientIP string              `json:"client_ip"`
+			Method   string              `json:"method"`
+			Host     string              `json:"host"`
+			URI      string              `json:"uri"`
+			Headers  map[string][]string `json:"headers"`
+		} `json:"request"`
+	}
+
+	if err := json.Unmarshal(line, &row); err != nil {
+		return Entry{}, err
+	}
+
+	if row.Request == nil {
+		return Entry{}, ErrSkip
+	}
+
+	t, err := caddyTime(row.Ts)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	r := row.Request
+
+	header := func(name string) string {
+		if v := r.Headers[name]; len(v) > 0 {
+			return v[0]
+		}
+
+		return ""
+	}
+
+	e := Entry{
+		Time:         t,
+		IP:           r.ClientIP,
+		ForwardedFor: header("X-Forwarded-For"),
+		Host:         r.Host,
+		Method:       r.Method,
+		URL:          r.URI,
+		Status:       row.Status,
+		Referer:      header("Referer"),
+		UserAgent:    header("User-Agent"),
+		Lang:         header("Accept-Language"),
+	}
+
+	if e.IP == "" {
+		e.IP = r.RemoteIP
+	}
+
+	return e, nil
+}
+
+// caddyTime decodes Unix timestamp with fraction or a formatted time.
+func caddyTime(ts json.RawMessage) (time.Time, error) {
+	var f float64
+	if err := json.Unmarshal(ts, &f); err == nil {
+		sec, frac := math.Modf(f)
+
+		return time.Unix(int64(sec), int64(frac*1e9)).UTC(), nil
+	}
+
+	var s string
+	if err := json.Unmarshal(ts, &s); err != nil {
+		return time.Time{}, fmt.Errorf("invalid ts: %w", err)
+	}
+
+	return time.Parse(time.RFC3339Nano, s)
+}
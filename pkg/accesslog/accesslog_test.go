@@ -0,0 +1,102 @@
+package accesslog_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/photo-blog/pkg/accesslog"
+)
+
+func TestParse_app(t *testing.T) {
+	line := []byte(`{"level":"info","time":"2024-03-01T10:11:12.345Z","msg":"access","visitor":"1a2b3c",` +
+		`"host":"example.org","url":"/my-album/","user_agent":"Mozilla/5.0","device":"Pixel 7 Android 14",` +
+		`"referer":"https://t.co/","forwarded_for":"1.2.3.4, 10.0.0.1","ip":"1.2.3.4","admin":true,"lang":"en"}`)
+
+	assert.Equal(t, accesslog.App, accesslog.Detect(line))
+
+	e, err := accesslog.Parse(line, accesslog.Auto)
+	require.NoError(t, err)
+	assert.Equal(t, accesslog.Entry{
+		Time:         time.Date(2024, 3, 1, 10, 11, 12, 345e6, time.UTC),
+		IP:           "1.2.3.4",
+		ForwardedFor: "1.2.3.4, 10.0.0.1",
+		Host:         "example.org",
+		Method:       "GET",
+		URL:          "/my-album/",
+		Status:       200,
+		Referer:      "https://t.co/",
+		UserAgent:    "Mozilla/5.0",
+		Lang:         "en",
+		Device:       "Pixel 7 Android 14",
+		Visitor:      "1a2b3c",
+		Admin:        true,
+	}, e)
+
+	_, err = accesslog.Parse([]byte(`{"level":"info","time":"2024-03-01T10:11:12Z","msg":"started"}`), accesslog.App)
+	assert.ErrorIs(t, err, accesslog.ErrSkip)
+}
+
+func TestParse_combined(t *testing.T) {
+	line := []byte(`203.0.113.9 - - [10/Oct/2023:13:55:36 +0200] "GET /my-album/?lang=en HTTP/1.1" 200 2326 ` +
+		`"https://www.google.com/" "Mozilla/5.0 (X11; Linux x86_64) \"quoted\" \xD0\xB0"`)
+
+	assert.Equal(t, accesslog.Combined, accesslog.Detect(line))
+
+	e, err := accesslog.Parse(line, accesslog.Auto)
+	require.NoError(t, err)
+	assert.Equal(t, "203.0.113.9", e.IP)
+	assert.Equal(t, time.Date(2023, 10, 10, 11, 55, 36, 0, time.UTC), e.Time.UTC())
+	assert.Equal(t, "GET", e.Method)
+	assert.Equal(t, "/my-album/?lang=en", e.URL)
+	assert.Equal(t, 200, e.Status)
+	assert.Equal(t, "https://www.google.com/", e.Referer)
+	assert.Equal(t, `Mozilla/5.0 (X11; Linux x86_64) "quoted" а`, e.UserAgent)
+	assert.Empty(t, e.ForwardedFor)
+
+	// Default nginx format with X-Forwarded-For.
+	e, err = accesslog.Parse([]byte(`10.0.0.1 - - [10/Oct/2023:13:55:36 +0000] "GET /image/abc.jpg HTTP/2.0" 304 0 "-" "curl/8.0" "198.51.100.7"`), accesslog.Combined)
+	require.NoError(t, err)
+	assert.Equal(t, 304, e.Status)
+	assert.Empty(t, e.Referer)
+	assert.Equal(t, "198.51.100.7", e.ForwardedFor)
+
+	// Common log format without headers.
+	e, err = accesslog.Parse([]byte(`10.0.0.1 - frank [10/Oct/2023:13:55:36 +0000] "POST /message HTTP/1.0" 201 10`), accesslog.Combined)
+	require.NoError(t, err)
+	assert.Equal(t, "POST", e.Method)
+	assert.Empty(t, e.UserAgent)
+
+	_, err = accesslog.Parse([]byte(`garbage`), accesslog.Combined)
+	assert.ErrorIs(t, err, accesslog.ErrSkip)
+}
+
+func TestParse_caddy(t *testing.T) {
+	line := []byte(`{"level":"info","ts":1696946136.5,"logger":"http.log.access","msg":"handled request",` +
+		`"request":{"remote_ip":"10.0.0.1","client_ip":"198.51.100.7","proto":"HTTP/2.0","method":"GET",` +
+		`"host":"example.org","uri":"/stats?main=1","headers":{"User-Agent":["Mozilla/5.0"],"Referer":["https://t.co/"],` +
+		`"Accept-Language":["de"]}},"status":200,"size":0}`)
+
+	assert.Equal(t, accesslog.Caddy, accesslog.Detect(line))
+
+	e, err := accesslog.Parse(line, accesslog.Auto)
+	require.NoError(t, err)
+	assert.Equal(t, accesslog.Entry{
+		Time:      time.Date(2023, 10, 10, 13, 55, 36, 5e8, time.UTC),
+		IP:        "198.51.100.7",
+		Host:      "example.org",
+		Method:    "GET",
+		URL:       "/stats?main=1",
+		Status:    200,
+		Referer:   "https://t.co/",
+		UserAgent: "Mozilla/5.0",
+		Lang:      "de",
+	}, e)
+
+	e, err = accesslog.Parse([]byte(`{"ts":"2023-10-10T13:55:36.5Z","request":{"remote_ip":"10.0.0.1","method":"HEAD","uri":"/"},"status":404}`), accesslog.Caddy)
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", e.IP)
+	assert.Equal(t, 404, e.Status)
+	assert.Equal(t, time.Date(2023, 10, 10, 13, 55, 36, 5e8, time.UTC), e.Time)
+}
@@ -0,0 +1,259 @@
+package activitypub_test
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/photo-blog/pkg/activitypub"
+)
+
+// instance is a fake fediverse server with a single actor.
+type instance struct {
+	srv    *httptest.Server
+	key    *rsa.PrivateKey
+	client activitypub.Client
+
+	mu       sync.Mutex
+	received []activitypub.Object
+	errs     []error
+}
+
+func newInstance(t *testing.T) *instance {
+	t.Helper()
+
+	key, err := activitypub.GenerateKey()
+	require.NoError(t, err)
+
+	in := &instance{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users/alice", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", activitypub.ContentType)
+		require.NoError(t, json.NewEncoder(w).Encode(in.actor(t)))
+	})
+	mux.HandleFunc("/inbox", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		in.mu.Lock()
+		defer in.mu.Unlock()
+
+		if _, err := activitypub.VerifyActor(r.Context(), r, body, in.client.FetchActor); err != nil {
+			in.errs = append(in.errs, err)
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		var o activitypub.Object
+		require.NoError(t, json.Unmarshal(body, &o))
+
+		in.received = append(in.received, o)
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	in.srv = httptest.NewServer(mux)
+	t.Cleanup(in.srv.Close)
+
+	in.client = activitypub.Client{KeyID: in.actorID() + "#main-key", Key: key}
+
+	return in
+}
+
+func (in *instance) actorID() string {
+	return in.srv.URL + "/users/alice"
+}
+
+func (in *instance) actor(t *testing.T) activitypub.Actor {
+	t.Helper()
+
+	pub, err := activitypub.EncodePublicKey(&in.key.PublicKey)
+	require.NoError(t, err)
+
+	return activitypub.Actor{
+		Context:           activitypub.ContextActivityStreams,
+		ID:                in.actorID(),
+		Type:              activitypub.TypePerson,
+		PreferredUsername: "alice",
+		Inbox:             in.actorID() + "/inbox",
+		Endpoints:         &activitypub.Endpoints{SharedInbox: in.srv.URL + "/inbox"},
+		PublicKey: &activitypub.PublicKey{
+			ID:           in.actorID() + "#main-key",
+			Owner:        in.actorID(),
+			PublicKeyPem: pub,
+		},
+	}
+}
+
+func TestClient_Post(t *testing.T) {
+	local, remote := newInstance(t), newInstance(t)
+	ctx := context.Background()
+
+	a, err := local.client.FetchActor(ctx, remote.actorID())
+	require.NoError(t, err)
+	assert.Equal(t, remote.srv.URL+"/inbox", a.DeliveryInbox())
+
+	accept := activitypub.Object{
+		Context: activitypub.ContextActivityStreams,
+		ID:      local.actorID() + "#accept-1",
+		Type:    activitypub.TypeAccept,
+		Actor:   local.actorID(),
+		Object: &activitypub.Ref{Object: &activitypub.Object{
+			ID:     remote.actorID() + "#follow-1",
+			Type:   activitypub.TypeFollow,
+			Actor:  remote.actorID(),
+			Object: &activitypub.Ref{ID: local.actorID()},
+		}},
+	}
+
+	require.NoError(t, local.client.Post(ctx, a.DeliveryInbox(), accept))
+
+	require.Len(t, remote.received, 1)
+	assert.Equal(t, activitypub.TypeAccept, remote.received[0].Type)
+	require.NotNil(t, remote.received[0].Object.Object)
+	assert.Equal(t, activitypub.TypeFollow, remote.received[0].Object.Object.Type)
+	assert.Equal(t, local.actorID(), remote.received[0].Object.Object.Object.ID)
+
+	// Key of other actor is rejected.
+	forged := local.client
+	forged.Key = remote.key
+
+	err = forged.Post(ctx, a.DeliveryInbox(), accept)
+
+	var se activitypub.StatusError
+	require.True(t, errors.As(err, &se))
+	assert.Equal(t, http.StatusUnauthorized, se.StatusCode)
+	require.Len(t, remote.errs, 1)
+	assert.Contains(t, remote.errs[0].Error(), "signature mismatch")
+}
+
+func TestVerifyActor_spoofed(t *testing.T) {
+	victim, attacker := newInstance(t), newInstance(t)
+	ctx := context.Background()
+	body := []byte(`{"type":"Create"}`)
+
+	// Attacker signs with own key, but fetching the key yields a document claiming victim's identity.
+	spoofed := attacker.actor(t)
+	spoofed.ID = victim.actorID()
+	spoofed.PublicKey.Owner = victim.actorID()
+
+	req := httptest.NewRequest(http.MethodPost, "https://blog.example/ap/inbox", strings.NewReader(string(body)))
+	require.NoError(t, activitypub.SignRequest(req, attacker.client.KeyID, attacker.key, body))
+
+	fetch := func(_ context.Context, _ string) (activitypub.Actor, error) {
+		return spoofed, nil
+	}
+
+	_, err := activitypub.VerifyActor(ctx, req, body, fetch)
+	assert.EqualError(t, err, "actor id mismatch")
+
+	// Key hosted elsewhere is not trusted.
+	spoofed = attacker.actor(t)
+	spoofed.PublicKey.ID = victim.actorID() + "#main-key"
+
+	_, err = activitypub.VerifyActor(ctx, req, body, fetch)
+	assert.EqualError(t, err, "actor key mismatch")
+
+	a, err := activitypub.VerifyActor(ctx, req, body, attacker.client.FetchActor)
+	require.NoError(t, err)
+	assert.Equal(t, attacker.actorID(), a.ID)
+}
+
+func TestPublicClient(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := newInstance(t).client
+	c.HTTP = activitypub.PublicClient(time.Second)
+	ctx := context.Background()
+
+	_, err := c.FetchActor(ctx, srv.URL+"/users/alice")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not allowed")
+
+	_, err = c.FetchActor(ctx, strings.Replace(srv.URL, "https://", "http://", 1)+"/users/alice")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "only https requests are allowed")
+
+	for _, u := range []string{
+		"https://[::1]/users/alice",
+		"https://10.0.0.1/users/alice",
+		"https://169.254.169.254/latest/meta-data",
+		"https://[fe80::1]/users/alice",
+		"https://100.64.0.1/users/alice",
+	} {
+		_, err = c.FetchActor(ctx, u)
+		require.Error(t, err, u)
+		assert.Contains(t, err.Error(), "is not allowed", u)
+	}
+}
+
+func TestClient_FetchActor_tooLarge(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"id":"` + strings.Repeat("a", 2<<20) + `"}`))
+	}))
+	defer srv.Close()
+
+	_, err := newInstance(t).client.FetchActor(context.Background(), srv.URL)
+	assert.EqualError(t, err, "response is too large")
+}
+
+func TestVerifyRequest_digest(t *testing.T) {
+	in := newInstance(t)
+	body := []byte(`{"type":"Create"}`)
+
+	req := httptest.NewRequest(http.MethodPost, "https://blog.example/ap/inbox", strings.NewReader(string(body)))
+	require.NoError(t, activitypub.SignRequest(req, in.client.KeyID, in.key, body))
+
+	require.NoError(t, activitypub.VerifyRequest(req, body, &in.key.PublicKey))
+	assert.EqualError(t, activitypub.VerifyRequest(req, []byte(`{"type":"Delete"}`), &in.key.PublicKey), "digest mismatch")
+
+	req.Header.Set("Signature", strings.Replace(req.Header.Get("Signature"), "(request-target) ", "", 1))
+	assert.EqualError(t, activitypub.VerifyRequest(req, body, &in.key.PublicKey),
+		"signature must cover (request-target), host and date")
+}
+
+func TestKeys(t *testing.T) {
+	key, err := activitypub.GenerateKey()
+	require.NoError(t, err)
+
+	p, err := activitypub.EncodePrivateKey(key)
+	require.NoError(t, err)
+
+	parsed, err := activitypub.ParsePrivateKey(p)
+	require.NoError(t, err)
+	assert.True(t, key.Equal(parsed))
+
+	pub, err := activitypub.EncodePublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	parsedPub, err := activitypub.ParsePublicKey(pub)
+	require.NoError(t, err)
+	assert.True(t, key.PublicKey.Equal(parsedPub))
+}
+
+func TestRef_UnmarshalJSON(t *testing.T) {
+	var o activitypub.Object
+
+	require.NoError(t, json.Unmarshal([]byte(`{"type":"Undo","actor":"https://a/u","object":{"id":"https://a/f","type":"Follow","object":"https://b/actor"}}`), &o))
+	require.NotNil(t, o.Object.Object)
+	assert.Equal(t, "https://a/f", o.Object.ID)
+	assert.Equal(t, "https://b/actor", o.Object.Object.Object.ID)
+
+	b, err := json.Marshal(o)
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"Undo","actor":"https://a/u","object":{"id":"https://a/f","type":"Follow","object":"https://b/actor"}}`, string(b))
+}
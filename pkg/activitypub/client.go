@@ -0,0 +1,184 @@
+package activitypub
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// StatusError is returned for unsuccessful response status.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e StatusError) Error() string {
+	return fmt.Sprintf("unexpected response status %d: %s", e.StatusCode, e.Body)
+}
+
+// Temporary is true if request may succeed on retry.
+func (e StatusError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode == http.StatusRequestTimeout ||
+		e.StatusCode >= http.StatusInternalServerError
+}
+
+// maxResponseSize limits documents received from remote servers.
+const maxResponseSize = 1 << 20
+
+// Client makes signed requests on behalf of an actor.
+type Client struct {
+	HTTP  *http.Client
+	KeyID string
+	Key   *rsa.PrivateKey
+}
+
+func (c Client) do(req *http.Request, body []byte) ([]byte, error) {
+	if err := SignRequest(req, c.KeyID, c.Key, body); err != nil {
+		return nil, fmt.Errorf("sign request: %w", err)
+	}
+
+	hc := c.HTTP
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	res, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) > maxResponseSize {
+		return nil, errors.New("response is too large")
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if len(res) > 1024 {
+			res = res[:1024]
+		}
+
+		return nil, StatusError{StatusCode: resp.StatusCode, Body: string(res)}
+	}
+
+	return res, nil
+}
+
+// Post delivers activity to inbox.
+func (c Client) Post(ctx context.Context, inbox string, activity any) error {
+	body, err := json.Marshal(activity)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, inbox, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", ContentType)
+	req.Header.Set("Accept", AcceptTypes)
+
+	_, err = c.do(req, body)
+
+	return err
+}
+
+// FetchActor retrieves actor document with a signed request.
+func (c Client) FetchActor(ctx context.Context, iri string) (Actor, error) {
+	var a Actor
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, iri, nil)
+	if err != nil {
+		return a, err
+	}
+
+	req.Header.Set("Accept", AcceptTypes)
+
+	res, err := c.do(req, nil)
+	if err != nil {
+		return a, err
+	}
+
+	if err := json.Unmarshal(res, &a); err != nil {
+		return a, fmt.Errorf("decode actor: %w", err)
+	}
+
+	if a.ID == "" || a.Inbox == "" {
+		return a, errors.New("invalid actor document")
+	}
+
+	return a, nil
+}
+
+// VerifyActor checks signature of incoming request and returns signing actor.
+func VerifyActor(
+	ctx context.Context,
+	r *http.Request,
+	body []byte,
+	fetchActor func(ctx context.Context, iri string) (Actor, error),
+) (Actor, error) {
+	keyID := SignatureKeyID(r)
+	if keyID == "" {
+		return Actor{}, errors.New("missing signature")
+	}
+
+	iri, _, _ := strings.Cut(keyID, "#")
+
+	a, err := fetchActor(ctx, iri)
+	if err != nil {
+		return a, fmt.Errorf("fetch actor: %w", err)
+	}
+
+	// Any server can serve a document with a key of its own, so the actor
+	// must be the one identified by the key.
+	if a.ID != iri {
+		return a, errors.New("actor id mismatch")
+	}
+
+	if a.PublicKey == nil || a.PublicKey.ID != keyID || a.PublicKey.Owner != a.ID ||
+		!sameHost(a.ID, a.PublicKey.ID, a.PublicKey.Owner) {
+		return a, errors.New("actor key mismatch")
+	}
+
+	key, err := ParsePublicKey(a.PublicKey.PublicKeyPem)
+	if err != nil {
+		return a, fmt.Errorf("parse actor key: %w", err)
+	}
+
+	return a, VerifyRequest(r, body, key)
+}
+
+// sameHost is true if all IRIs are valid URLs on the same host.
+func sameHost(iris ...string) bool {
+	host := ""
+
+	for _, iri := range iris {
+		u, err := url.Parse(iri)
+		if err != nil || u.Host == "" {
+			return false
+		}
+
+		if host == "" {
+			host = u.Host
+		} else if u.Host != host {
+			return false
+		}
+	}
+
+	return true
+}
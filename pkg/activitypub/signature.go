@@ -0,0 +1,166 @@
+package activitypub
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// MaxClockSkew limits difference of request Date header and local time.
+const MaxClockSkew = 12 * time.Hour
+
+// Digest returns value of Digest header for request body.
+func Digest(body []byte) string {
+	h := sha256.Sum256(body)
+
+	return "SHA-256=" + base64.StdEncoding.EncodeToString(h[:])
+}
+
+func signingString(r *http.Request, headers []string) (string, error) {
+	lines := make([]string, 0, len(headers))
+
+	for _, h := range headers {
+		var v string
+
+		switch h {
+		case "(request-target)":
+			v = strings.ToLower(r.Method) + " " + r.URL.RequestURI()
+		case "host":
+			v = r.Host
+			if v == "" {
+				v = r.URL.Host
+			}
+		default:
+			v = r.Header.Get(h)
+			if v == "" {
+				return "", fmt.Errorf("missing signed header %s", h)
+			}
+		}
+
+		lines = append(lines, h+": "+v)
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// SignRequest adds Date, Digest and Signature headers using rsa-sha256 algorithm of HTTP Signatures draft.
+func SignRequest(r *http.Request, keyID string, key *rsa.PrivateKey, body []byte) error {
+	headers := []string{"(request-target)", "host", "date"}
+
+	r.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+
+	if body != nil {
+		r.Header.Set("Digest", Digest(body))
+
+		headers = append(headers, "digest")
+	}
+
+	s, err := signingString(r, headers)
+	if err != nil {
+		return err
+	}
+
+	h := sha256.Sum256([]byte(s))
+
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, h[:])
+	if err != nil {
+		return err
+	}
+
+	r.Header.Set("Signature", fmt.Sprintf(`keyId="%s",algorithm="rsa-sha256",headers="%s",signature="%s"`,
+		keyID, strings.Join(headers, " "), base64.StdEncoding.EncodeToString(sig)))
+
+	return nil
+}
+
+func parseSignature(v string) map[string]string {
+	params := map[string]string{}
+
+	for _, p := range strings.Split(v, ",") {
+		k, val, ok := strings.Cut(strings.TrimSpace(p), "=")
+		if !ok {
+			continue
+		}
+
+		params[k] = strings.Trim(val, `"`)
+	}
+
+	return params
+}
+
+// SignatureKeyID returns keyId of request signature.
+func SignatureKeyID(r *http.Request) string {
+	return parseSignature(r.Header.Get("Signature"))["keyId"]
+}
+
+// VerifyRequest checks request signature with public key of keyId.
+//
+// Signature must cover (request-target), host and date, and digest when body is not empty.
+func VerifyRequest(r *http.Request, body []byte, key *rsa.PublicKey) error {
+	params := parseSignature(r.Header.Get("Signature"))
+	if params["signature"] == "" {
+		return errors.New("missing signature")
+	}
+
+	if alg := params["algorithm"]; alg != "" && alg != "rsa-sha256" && alg != "hs2019" {
+		return fmt.Errorf("unsupported signature algorithm %s", alg)
+	}
+
+	headers := strings.Fields(strings.ToLower(params["headers"]))
+	if len(headers) == 0 {
+		headers = []string{"date"}
+	}
+
+	signed := map[string]bool{}
+	for _, h := range headers {
+		signed[h] = true
+	}
+
+	if !signed["(request-target)"] || !signed["host"] || !signed["date"] {
+		return errors.New("signature must cover (request-target), host and date")
+	}
+
+	date, err := http.ParseTime(r.Header.Get("Date"))
+	if err != nil {
+		return fmt.Errorf("invalid date: %w", err)
+	}
+
+	if d := time.Since(date); d > MaxClockSkew || d < -MaxClockSkew {
+		return errors.New("date is out of allowed clock skew")
+	}
+
+	if len(body) > 0 {
+		if !signed["digest"] {
+			return errors.New("signature must cover digest")
+		}
+
+		if r.Header.Get("Digest") != Digest(body) {
+			return errors.New("digest mismatch")
+		}
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(params["signature"])
+	if err != nil {
+		return fmt.Errorf("decode signature: %w", err)
+	}
+
+	s, err := signingString(r, headers)
+	if err != nil {
+		return err
+	}
+
+	h := sha256.Sum256([]byte(s))
+
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, h[:], sig); err != nil {
+		return fmt.Errorf("signature mismatch: %w", err)
+	}
+
+	return nil
+}
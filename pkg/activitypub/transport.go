@@ -0,0 +1,62 @@
+package activitypub
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// cgnat is shared address space of carrier-grade NAT, RFC 6598.
+var cgnat = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// PublicClient returns HTTP client that only makes https requests to public addresses.
+//
+// URLs of actors and inboxes come from remote servers, so requests to loopback,
+// private and link-local networks are refused to keep internal services unreachable.
+// Addresses are checked when connecting, after name resolution.
+func PublicClient(timeout time.Duration) *http.Client {
+	d := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("address %s is not allowed", host)
+			}
+
+			return nil
+		},
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = nil
+	t.DialContext = d.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: httpsOnly{rt: t},
+	}
+}
+
+type httpsOnly struct {
+	rt http.RoundTripper
+}
+
+// RoundTrip also applies to redirects.
+func (h httpsOnly) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "https" {
+		return nil, errors.New("only https requests are allowed")
+	}
+
+	return h.rt.RoundTrip(req)
+}
+
+func isPublicIP(ip net.IP) bool {
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !ip.IsLoopback() && !ip.IsLinkLocalUnicast() && !cgnat.Contains(ip)
+}
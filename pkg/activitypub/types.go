@@ -0,0 +1,148 @@
+// Package activitypub implements a minimal subset of ActivityPub and WebFinger for a single actor.
+package activitypub
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+// Well-known values.
+const (
+	ContextActivityStreams = "https://www.w3.org/ns/activitystreams"
+	ContextSecurity        = "https://w3id.org/security/v1"
+	Public                 = "https://www.w3.org/ns/activitystreams#Public"
+
+	ContentType = "application/activity+json"
+	AcceptTypes = `application/activity+json, application/ld+json; profile="https://www.w3.org/ns/activitystreams"`
+)
+
+// Object and activity types.
+const (
+	TypePerson            = "Person"
+	TypeService           = "Service"
+	TypeNote              = "Note"
+	TypeImage             = "Image"
+	TypeCreate            = "Create"
+	TypeFollow            = "Follow"
+	TypeAccept            = "Accept"
+	TypeUndo              = "Undo"
+	TypeDelete            = "Delete"
+	TypeOrderedCollection = "OrderedCollection"
+)
+
+// Ref is an IRI or an embedded object.
+type Ref struct {
+	ID     string
+	Object *Object
+}
+
+// MarshalJSON encodes embedded object or IRI string.
+func (r Ref) MarshalJSON() ([]byte, error) {
+	if r.Object != nil {
+		return json.Marshal(r.Object)
+	}
+
+	return json.Marshal(r.ID)
+}
+
+// UnmarshalJSON decodes embedded object or IRI string.
+func (r *Ref) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(`"`)) {
+		r.Object = nil
+
+		return json.Unmarshal(data, &r.ID)
+	}
+
+	r.Object = &Object{}
+	if err := json.Unmarshal(data, r.Object); err != nil {
+		return err
+	}
+
+	r.ID = r.Object.ID
+
+	return nil
+}
+
+// Object is an ActivityStreams object or activity.
+type Object struct {
+	Context      any        `json:"@context,omitempty"`
+	ID           string     `json:"id,omitempty"`
+	Type         string     `json:"type"`
+	Actor        string     `json:"actor,omitempty"`
+	Object       *Ref       `json:"object,omitempty"`
+	AttributedTo string     `json:"attributedTo,omitempty"`
+	Name         string     `json:"name,omitempty"`
+	Summary      string     `json:"summary,omitempty"`
+	Content      string     `json:"content,omitempty"`
+	URL          string     `json:"url,omitempty"`
+	MediaType    string     `json:"mediaType,omitempty"`
+	Width        int        `json:"width,omitempty"`
+	Height       int        `json:"height,omitempty"`
+	InReplyTo    string     `json:"inReplyTo,omitempty"`
+	Published    *time.Time `json:"published,omitempty"`
+	To           []string   `json:"to,omitempty"`
+	Cc           []string   `json:"cc,omitempty"`
+	Attachment   []Object   `json:"attachment,omitempty"`
+}
+
+// PublicKey describes actor key.
+type PublicKey struct {
+	ID           string `json:"id"`
+	Owner        string `json:"owner"`
+	PublicKeyPem string `json:"publicKeyPem"`
+}
+
+// Endpoints of actor.
+type Endpoints struct {
+	SharedInbox string `json:"sharedInbox,omitempty"`
+}
+
+// Actor is a Person or Service that can be followed.
+type Actor struct {
+	Context           any        `json:"@context,omitempty"`
+	ID                string     `json:"id"`
+	Type              string     `json:"type"`
+	PreferredUsername string     `json:"preferredUsername"`
+	Name              string     `json:"name,omitempty"`
+	Summary           string     `json:"summary,omitempty"`
+	URL               string     `json:"url,omitempty"`
+	Icon              *Object    `json:"icon,omitempty"`
+	Inbox             string     `json:"inbox"`
+	Outbox            string     `json:"outbox,omitempty"`
+	Followers         string     `json:"followers,omitempty"`
+	Endpoints         *Endpoints `json:"endpoints,omitempty"`
+	PublicKey         *PublicKey `json:"publicKey,omitempty"`
+}
+
+// DeliveryInbox returns shared inbox if available.
+func (a Actor) DeliveryInbox() string {
+	if a.Endpoints != nil && a.Endpoints.SharedInbox != "" {
+		return a.Endpoints.SharedInbox
+	}
+
+	return a.Inbox
+}
+
+// Collection is an ordered collection of objects.
+type Collection struct {
+	Context      any      `json:"@context,omitempty"`
+	ID           string   `json:"id"`
+	Type         string   `json:"type"`
+	TotalItems   int      `json:"totalItems"`
+	OrderedItems []Object `json:"orderedItems,omitempty"`
+}
+
+// Link is a WebFinger link.
+type Link struct {
+	Rel  string `json:"rel"`
+	Type string `json:"type,omitempty"`
+	Href string `json:"href,omitempty"`
+}
+
+// WebFinger is a JSON Resource Descriptor.
+type WebFinger struct {
+	Subject string   `json:"subject"`
+	Aliases []string `json:"aliases,omitempty"`
+	Links   []Link   `json:"links"`
+}
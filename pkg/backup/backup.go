@@ -0,0 +1,231 @@
+// Package backup reads and writes portable site archives.
+//
+// Archive is a zip file with a manifest, a JSON lines file per database table and optional files.
+package backup
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Version is the current archive format version.
+const Version = 1
+
+const (
+	manifestName = "manifest.json"
+	tablesDir    = "tables/"
+	filesDir     = "files/"
+	relFilesDir  = "files-relative/"
+)
+
+// Manifest describes archive contents.
+type Manifest struct {
+	Version   int            `json:"version"`
+	App       string         `json:"app,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	Source    string         `json:"source,omitempty" description:"Storage path of exported site."`
+	Tables    map[string]int `json:"tables" description:"Number of rows by table name."`
+	Files     int            `json:"files"`
+	FilesSize int64          `json:"files_size"`
+}
+
+// Writer creates an archive.
+type Writer struct {
+	zw *zip.Writer
+	m  Manifest
+}
+
+// NewWriter creates archive writer.
+func NewWriter(w io.Writer, app, source string) *Writer {
+	return &Writer{
+		zw: zip.NewWriter(w),
+		m: Manifest{
+			Version:   Version,
+			App:       app,
+			CreatedAt: time.Now().UTC(),
+			Source:    source,
+			Tables:    map[string]int{},
+		},
+	}
+}
+
+// WriteFile adds a file by its path.
+//
+// Absolute paths are kept as is, relative paths stay relative to working directory of the site.
+// File contents are stored without compression, as media files are already compressed.
+func (w *Writer) WriteFile(name string, modified time.Time, r io.Reader) error {
+	entry, err := fileEntry(name)
+	if err != nil {
+		return err
+	}
+
+	f, err := w.zw.CreateHeader(&zip.FileHeader{
+		Name:     entry,
+		Method:   zip.Store,
+		Modified: modified,
+	})
+	if err != nil {
+		return err
+	}
+
+	n, err := io.Copy(f, r)
+	if err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+
+	w.m.Files++
+	w.m.FilesSize += n
+
+	return nil
+}
+
+// fileEntry returns archive entry name for a file path.
+func fileEntry(name string) (string, error) {
+	if path.IsAbs(name) {
+		return filesDir + strings.TrimPrefix(path.Clean(name), "/"), nil
+	}
+
+	name = path.Clean(name)
+	if !IsSafePath(name) || name == "." {
+		return "", errors.New("invalid relative file path: " + name)
+	}
+
+	return relFilesDir + name, nil
+}
+
+// IsSafePath checks that path has no ".." elements.
+func IsSafePath(p string) bool {
+	for _, e := range strings.Split(p, "/") {
+		if e == ".." {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Close writes manifest and finishes archive.
+func (w *Writer) Close() error {
+	f, err := w.zw.Create(manifestName)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", " ")
+
+	if err := enc.Encode(w.m); err != nil {
+		return err
+	}
+
+	return w.zw.Close()
+}
+
+// Reader reads an archive.
+type Reader struct {
+	Manifest Manifest
+
+	zr *zip.Reader
+}
+
+// NewReader opens an archive and reads its manifest.
+func NewReader(r io.ReaderAt, size int64) (*Reader, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("open archive: %w", err)
+	}
+
+	br := &Reader{zr: zr}
+
+	f, err := zr.Open(manifestName)
+	if err != nil {
+		return nil, fmt.Errorf("open manifest: %w", err)
+	}
+	defer f.Close()
+
+	if err := json.NewDecoder(f).Decode(&br.Manifest); err != nil {
+		return nil, fmt.Errorf("decode manifest: %w", err)
+	}
+
+	if br.Manifest.Version < 1 || br.Manifest.Version > Version {
+		return nil, fmt.Errorf("unsupported archive version %d, max supported %d", br.Manifest.Version, Version)
+	}
+
+	return br, nil
+}
+
+// File is a file stored in archive.
+type File struct {
+	Name     string
+	Size     int64
+	Modified time.Time
+
+	f *zip.File
+}
+
+// Open returns file contents.
+func (f File) Open() (io.ReadCloser, error) {
+	return f.f.Open()
+}
+
+// Files lists files stored in archive with their paths on source machine.
+//
+// Names are absolute paths or paths relative to working directory of the site,
+// entries with ".." elements are skipped.
+func (r *Reader) Files() []File {
+	var res []File
+
+	for _, f := range r.zr.File {
+		var name string
+
+		switch {
+		case strings.HasSuffix(f.Name, "/"):
+			continue
+		case strings.HasPrefix(f.Name, filesDir):
+			name = "/" + strings.TrimPrefix(f.Name, filesDir)
+		case strings.HasPrefix(f.Name, relFilesDir):
+			name = strings.TrimPrefix(f.Name, relFilesDir)
+		default:
+			continue
+		}
+
+		if name == "" || !IsSafePath(name) {
+			continue
+		}
+
+		res = append(res, File{
+			Name:     name,
+			Size:     int64(f.UncompressedSize64),
+			Modified: f.Modified,
+			f:        f,
+		})
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+
+	return res
+}
+
+// HasTable checks if archive contains table.
+func (r *Reader) HasTable(name string) bool {
+	_, ok := r.Manifest.Tables[name]
+
+	return ok
+}
+
+func (r *Reader) openTable(name string) (io.ReadCloser, error) {
+	if !r.HasTable(name) {
+		return nil, errors.New("missing table " + name)
+	}
+
+	return r.zr.Open(tablesDir + name + ".jsonl")
+}
@@ -0,0 +1,179 @@
+package backup_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/photo-blog/pkg/backup"
+	_ "modernc.org/sqlite" // SQLite3 driver.
+)
+
+func openDB(t *testing.T, schema string) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", ":memory:?_time_format=sqlite")
+	require.NoError(t, err)
+
+	db.SetMaxOpenConns(1)
+
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+
+	_, err = db.Exec(schema)
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestWriter_WriteTable(t *testing.T) {
+	ctx := context.Background()
+	ts := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+
+	src := openDB(t, "CREATE TABLE image (`hash` INTEGER PRIMARY KEY, `path` TEXT, `created_at` DATETIME, "+
+		"`ratio` REAL, `data` BLOB, `legacy` TEXT)")
+
+	_, err := src.Exec("INSERT INTO image VALUES (?, ?, ?, ?, ?, ?), (?, ?, ?, ?, ?, ?)",
+		-123, "/home/me/photos/a.jpg", ts, 1.5, []byte{0, 1, 2}, "x",
+		456, "/srv/other/b.jpg", ts, nil, nil, "y")
+	require.NoError(t, err)
+
+	buf := bytes.NewBuffer(nil)
+	w := backup.NewWriter(buf, "test", "/data")
+	require.NoError(t, w.WriteTable(ctx, src, "image"))
+	require.NoError(t, w.WriteFile("/home/me/photos/a.jpg", ts, strings.NewReader("jpeg")))
+	require.NoError(t, w.Close())
+
+	r, err := backup.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	assert.Equal(t, backup.Version, r.Manifest.Version)
+	assert.Equal(t, "/data", r.Manifest.Source)
+	assert.Equal(t, map[string]int{"image": 2}, r.Manifest.Tables)
+	assert.Equal(t, 1, r.Manifest.Files)
+	assert.True(t, r.HasTable("image"))
+	assert.False(t, r.HasTable("album"))
+
+	files := r.Files()
+	require.Len(t, files, 1)
+	assert.Equal(t, "/home/me/photos/a.jpg", files[0].Name)
+
+	f, err := files[0].Open()
+	require.NoError(t, err)
+
+	b, err := io.ReadAll(f)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	assert.Equal(t, "jpeg", string(b))
+
+	// Destination has a newer schema without legacy column.
+	dst := openDB(t, "CREATE TABLE image (`hash` INTEGER PRIMARY KEY, `path` TEXT, `created_at` DATETIME, "+
+		"`ratio` REAL, `data` BLOB, `added` INTEGER NOT NULL DEFAULT 7)")
+
+	pm, err := backup.ParsePathMap("/home/me/photos=/mnt/photos")
+	require.NoError(t, err)
+
+	cnt, err := r.RestoreTable(ctx, dst, "image", func(row backup.Row) bool {
+		row["path"] = pm.Apply(row["path"].(string))
+
+		return row["path"] == "/srv/other/b.jpg"
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, cnt)
+
+	var (
+		hash      int64
+		path      string
+		createdAt time.Time
+		ratio     float64
+		data      []byte
+		added     int
+	)
+
+	require.NoError(t, dst.QueryRow("SELECT * FROM image").Scan(&hash, &path, &createdAt, &ratio, &data, &added))
+	assert.Equal(t, int64(-123), hash)
+	assert.Equal(t, "/mnt/photos/a.jpg", path)
+	assert.True(t, ts.Equal(createdAt))
+	assert.Equal(t, 1.5, ratio)
+	assert.Equal(t, []byte{0, 1, 2}, data)
+	assert.Equal(t, 7, added)
+
+	_, err = r.RestoreTable(ctx, dst, "album", nil)
+	require.Error(t, err)
+}
+
+func TestReader_Files(t *testing.T) {
+	ts := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+
+	buf := bytes.NewBuffer(nil)
+	w := backup.NewWriter(buf, "test", "/data")
+	require.NoError(t, w.WriteFile("/home/me/photos/a.jpg", ts, strings.NewReader("a")))
+	require.NoError(t, w.WriteFile("album/trip/b.jpg", ts, strings.NewReader("b")))
+	require.Error(t, w.WriteFile("../c.jpg", ts, strings.NewReader("c")))
+	require.Error(t, w.WriteFile("album/../../c.jpg", ts, strings.NewReader("c")))
+	require.NoError(t, w.Close())
+
+	// Crafted entries must not escape destination.
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	crafted := bytes.NewBuffer(nil)
+	zw := zip.NewWriter(crafted)
+
+	for _, f := range zr.File {
+		require.NoError(t, zw.Copy(f))
+	}
+
+	for _, name := range []string{"files/../../etc/passwd", "files-relative/../x", "files-relative/a/../../x"} {
+		_, err := zw.Create(name)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, zw.Close())
+
+	r, err := backup.NewReader(bytes.NewReader(crafted.Bytes()), int64(crafted.Len()))
+	require.NoError(t, err)
+
+	files := r.Files()
+	require.Len(t, files, 2)
+	assert.Equal(t, "/home/me/photos/a.jpg", files[0].Name)
+	assert.Equal(t, "album/trip/b.jpg", files[1].Name, "relative path stays relative")
+
+	assert.True(t, backup.IsSafePath("/a/b..c/d"))
+	assert.False(t, backup.IsSafePath("/a/../d"))
+}
+
+func TestNewReader_version(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	zw := zip.NewWriter(buf)
+	f, err := zw.Create("manifest.json")
+	require.NoError(t, err)
+	_, err = f.Write([]byte(`{"version":9}`))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	_, err = backup.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported archive version 9")
+}
+
+func TestPathMap_Apply(t *testing.T) {
+	pm, err := backup.ParsePathMap("/home/me=/srv", "/home/me/photos/=/mnt/photos", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, "/mnt/photos/a.jpg", pm.Apply("/home/me/photos/a.jpg"))
+	assert.Equal(t, "/srv/docs/b.gpx", pm.Apply("/home/me/docs/b.gpx"))
+	assert.Equal(t, "/home/meow/c.jpg", pm.Apply("/home/meow/c.jpg"))
+	assert.Equal(t, "/srv", pm.Apply("/home/me"))
+
+	_, err = backup.ParsePathMap("/home/me")
+	require.Error(t, err)
+}
@@ -0,0 +1,51 @@
+package backup
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// PathMap replaces path prefixes to restore files on a machine with different layout.
+type PathMap map[string]string
+
+// ParsePathMap parses "old=new" pairs.
+func ParsePathMap(pairs ...string) (PathMap, error) {
+	m := PathMap{}
+
+	for _, p := range pairs {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+
+		from, to, ok := strings.Cut(p, "=")
+		if !ok || from == "" || to == "" {
+			return nil, errors.New("invalid path mapping, old=new expected: " + p)
+		}
+
+		m[strings.TrimRight(from, "/")] = strings.TrimRight(to, "/")
+	}
+
+	return m, nil
+}
+
+// Apply replaces the longest matching prefix of a path.
+func (m PathMap) Apply(p string) string {
+	prefixes := make([]string, 0, len(m))
+	for from := range m {
+		prefixes = append(prefixes, from)
+	}
+
+	sort.Slice(prefixes, func(i, j int) bool {
+		return len(prefixes[i]) > len(prefixes[j])
+	})
+
+	for _, from := range prefixes {
+		if p == from || strings.HasPrefix(p, from+"/") {
+			return m[from] + strings.TrimPrefix(p, from)
+		}
+	}
+
+	return p
+}
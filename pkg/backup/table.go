@@ -0,0 +1,236 @@
+package backup
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Row is a table row by column names.
+type Row map[string]any
+
+// WriteTable dumps all rows of a table as JSON lines.
+func (w *Writer) WriteTable(ctx context.Context, db *sql.DB, table string) error {
+	rows, err := db.QueryContext(ctx, "SELECT * FROM "+quote(table))
+	if err != nil {
+		return fmt.Errorf("query %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+
+	f, err := w.zw.Create(tablesDir + table + ".jsonl")
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(f)
+	enc := json.NewEncoder(bw)
+	values := make([]any, len(types))
+	ptrs := make([]any, len(types))
+
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+
+	cnt := 0
+
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return fmt.Errorf("scan %s: %w", table, err)
+		}
+
+		row := make(Row, len(types))
+
+		for i, t := range types {
+			v := values[i]
+
+			// Only blob columns keep binary values, that are encoded as base64.
+			if b, ok := v.([]byte); ok && !isBlob(t.DatabaseTypeName()) {
+				v = string(b)
+			}
+
+			row[t.Name()] = v
+		}
+
+		if err := enc.Encode(row); err != nil {
+			return fmt.Errorf("encode %s: %w", table, err)
+		}
+
+		cnt++
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("read %s: %w", table, err)
+	}
+
+	w.m.Tables[table] = cnt
+
+	return bw.Flush()
+}
+
+// RestoreTable inserts or replaces table rows from archive.
+//
+// Columns missing in destination table are ignored, so that archives of older and newer
+// schema versions can be restored. Optional prepare callback can change or skip a row.
+func (r *Reader) RestoreTable(ctx context.Context, db *sql.DB, table string, prepare func(row Row) (skip bool)) (int, error) {
+	columns, err := tableColumns(ctx, db, table)
+	if err != nil {
+		return 0, err
+	}
+
+	f, err := r.openTable(table)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	dec := json.NewDecoder(f)
+	dec.UseNumber()
+
+	cnt := 0
+
+	for {
+		row := Row{}
+
+		if err := dec.Decode(&row); err != nil {
+			if err == io.EOF {
+				break
+			}
+
+			return cnt, fmt.Errorf("decode %s: %w", table, err)
+		}
+
+		if prepare != nil && prepare(row) {
+			continue
+		}
+
+		var (
+			names []string
+			args  []any
+		)
+
+		for name, v := range row {
+			typ, ok := columns[name]
+			if !ok {
+				continue
+			}
+
+			v, err := columnValue(typ, v)
+			if err != nil {
+				return cnt, fmt.Errorf("%s.%s: %w", table, name, err)
+			}
+
+			names = append(names, quote(name))
+			args = append(args, v)
+		}
+
+		if len(names) == 0 {
+			continue
+		}
+
+		q := "INSERT OR REPLACE INTO " + quote(table) + " (" + strings.Join(names, ", ") +
+			") VALUES (?" + strings.Repeat(", ?", len(names)-1) + ")"
+
+		if _, err := tx.ExecContext(ctx, q, args...); err != nil {
+			return cnt, fmt.Errorf("insert %s: %w", table, err)
+		}
+
+		cnt++
+	}
+
+	return cnt, tx.Commit()
+}
+
+func tableColumns(ctx context.Context, db *sql.DB, table string) (map[string]string, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name, type FROM pragma_table_info(?)", table)
+	if err != nil {
+		return nil, fmt.Errorf("columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	columns := map[string]string{}
+
+	for rows.Next() {
+		var name, typ string
+		if err := rows.Scan(&name, &typ); err != nil {
+			return nil, err
+		}
+
+		columns[name] = typ
+	}
+
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("missing table %s", table)
+	}
+
+	return columns, rows.Err()
+}
+
+func columnValue(typ string, v any) (any, error) {
+	switch vv := v.(type) {
+	case json.Number:
+		if i, err := strconv.ParseInt(vv.String(), 10, 64); err == nil {
+			return i, nil
+		}
+
+		return vv.Float64()
+	case bool:
+		if vv {
+			return 1, nil
+		}
+
+		return 0, nil
+	case string:
+		switch {
+		case isBlob(typ):
+			return base64.StdEncoding.DecodeString(vv)
+		case isTime(typ):
+			if t, err := time.Parse(time.RFC3339Nano, vv); err == nil {
+				return t, nil
+			}
+		}
+
+		return vv, nil
+	case nil:
+		return nil, nil
+	}
+
+	// Nested JSON values are stored as text.
+	b, err := json.Marshal(v)
+
+	return string(b), err
+}
+
+func isBlob(typ string) bool {
+	return strings.EqualFold(typ, "BLOB")
+}
+
+func isTime(typ string) bool {
+	typ = strings.ToUpper(typ)
+
+	return strings.Contains(typ, "DATE") || strings.Contains(typ, "TIME")
+}
+
+func quote(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
@@ -0,0 +1,68 @@
+package botpolicy
+
+import (
+	"sync"
+	"time"
+)
+
+// maxBuckets triggers removal of idle buckets.
+const maxBuckets = 10000
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter is a token bucket rate limiter by key.
+type Limiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// Allow takes a token from bucket of key, rate is in tokens per minute.
+func (l *Limiter) Allow(key string, rate float64, burst int, now time.Time) bool {
+	if burst < 1 {
+		burst = 1
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.buckets == nil {
+		l.buckets = map[string]*bucket{}
+	}
+
+	b := l.buckets[key]
+	if b == nil {
+		if len(l.buckets) >= maxBuckets {
+			l.prune(rate, burst, now)
+		}
+
+		b = &bucket{tokens: float64(burst), last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens += now.Sub(b.last).Minutes() * rate
+	if b.tokens > float64(burst) {
+		b.tokens = float64(burst)
+	}
+
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+
+	return true
+}
+
+// prune removes buckets that are refilled to burst.
+func (l *Limiter) prune(rate float64, burst int, now time.Time) {
+	for k, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Minutes()*rate >= float64(burst) {
+			delete(l.buckets, k)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+// Package botpolicy decides how crawlers are served and renders robots.txt.
+package botpolicy
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Action is a way to serve a bot.
+type Action string
+
+// Actions.
+const (
+	Allow Action = "allow"
+	Deny  Action = "deny"
+	Limit Action = "limit"
+)
+
+// Any matches all bots.
+const Any = "*"
+
+// AICrawlers are user agent tokens of crawlers that collect content for AI models.
+var AICrawlers = []string{
+	"GPTBot",
+	"ChatGPT-User",
+	"OAI-SearchBot",
+	"ClaudeBot",
+	"Claude-Web",
+	"anthropic-ai",
+	"CCBot",
+	"Google-Extended",
+	"Applebot-Extended",
+	"PerplexityBot",
+	"Bytespider",
+	"Amazonbot",
+	"meta-externalagent",
+	"FacebookBot",
+	"cohere-ai",
+	"Diffbot",
+	"ImagesiftBot",
+	"Omgilibot",
+	"Timpibot",
+}
+
+// Rule defines how to serve matching bots.
+type Rule struct {
+	Agent  string  `json:"agent" title:"Bot" description:"User agent token (case-insensitive substring) or ASN bot name, * matches any bot." required:"true"`
+	Action Action  `json:"action" title:"Action" enum:"allow,deny,limit" default:"limit"`
+	Rate   float64 `json:"rate,omitempty" title:"Downloads per minute" description:"Rate of full-size image, video and archive downloads per bot for limit action, 0 denies downloads." minimum:"0"`
+	Burst  int     `json:"burst,omitempty" title:"Burst" description:"Downloads allowed at once for limit action." minimum:"0"`
+}
+
+func (r Rule) matches(ua, name string) bool {
+	if r.Agent == Any {
+		return true
+	}
+
+	if r.Agent == "" {
+		return false
+	}
+
+	if name != "" && strings.EqualFold(r.Agent, name) {
+		return true
+	}
+
+	return strings.Contains(strings.ToLower(ua), strings.ToLower(r.Agent))
+}
+
+// Policy matches bots to rules.
+type Policy struct {
+	Rules    []Rule
+	BlockAI  bool
+	Disallow []string
+}
+
+// Match finds a rule for a bot by user agent and optional name.
+//
+// Specific rules are checked in order, AI crawlers are denied if blocked, wildcard rule applies last.
+func (p Policy) Match(ua, name string) (Rule, bool) {
+	var (
+		fallback Rule
+		found    bool
+	)
+
+	for _, r := range p.Rules {
+		if r.Agent == Any {
+			if !found {
+				fallback, found = r, true
+			}
+
+			continue
+		}
+
+		if r.matches(ua, name) {
+			return r, true
+		}
+	}
+
+	if p.BlockAI {
+		for _, a := range AICrawlers {
+			r := Rule{Agent: a, Action: Deny}
+			if r.matches(ua, name) {
+				return r, true
+			}
+		}
+	}
+
+	return fallback, found
+}
+
+// Robots renders robots.txt, sitemap URL is optional.
+func (p Policy) Robots(sitemap string) string {
+	var (
+		sb      strings.Builder
+		anyRule *Rule
+	)
+
+	group := func(agent string, lines ...string) {
+		sb.WriteString("User-agent: " + agent + "\n")
+
+		for _, l := range lines {
+			sb.WriteString(l + "\n")
+		}
+
+		sb.WriteString("\n")
+	}
+
+	denied := map[string]bool{}
+
+	for i, r := range p.Rules {
+		switch {
+		case r.Agent == Any:
+			if anyRule == nil {
+				anyRule = &p.Rules[i]
+			}
+		case r.Agent == "" || denied[strings.ToLower(r.Agent)]:
+			continue
+		case r.Action == Deny:
+			denied[strings.ToLower(r.Agent)] = true
+
+			group(r.Agent, "Disallow: /")
+		case r.Action == Limit && r.Rate > 0:
+			denied[strings.ToLower(r.Agent)] = true
+
+			group(r.Agent, append(p.disallow(), crawlDelay(r.Rate))...)
+		}
+	}
+
+	if p.BlockAI {
+		for _, a := range AICrawlers {
+			if !denied[strings.ToLower(a)] {
+				group(a, "Disallow: /")
+			}
+		}
+	}
+
+	switch {
+	case anyRule != nil && anyRule.Action == Deny:
+		group(Any, "Disallow: /")
+	case anyRule != nil && anyRule.Action == Limit && anyRule.Rate > 0:
+		group(Any, append(p.disallow(), crawlDelay(anyRule.Rate))...)
+	default:
+		group(Any, p.disallow()...)
+	}
+
+	if sitemap != "" {
+		sb.WriteString("Sitemap: " + sitemap + "\n")
+	}
+
+	return strings.TrimRight(sb.String(), "\n") + "\n"
+}
+
+func (p Policy) disallow() []string {
+	if len(p.Disallow) == 0 {
+		return []string{"Allow: /"}
+	}
+
+	lines := make([]string, 0, len(p.Disallow))
+	for _, d := range p.Disallow {
+		lines = append(lines, "Disallow: "+d)
+	}
+
+	return lines
+}
+
+// crawlDelay converts rate per minute to delay between requests in seconds.
+func crawlDelay(rate float64) string {
+	d := int(60/rate + 0.5)
+	if d < 1 {
+		d = 1
+	}
+
+	return "Crawl-delay: " + strconv.Itoa(d)
+}
@@ -0,0 +1,100 @@
+package botpolicy_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vearutop/photo-blog/pkg/botpolicy"
+)
+
+func TestPolicy_Match(t *testing.T) {
+	p := botpolicy.Policy{
+		BlockAI: true,
+		Rules: []botpolicy.Rule{
+			{Agent: botpolicy.Any, Action: botpolicy.Limit, Rate: 6},
+			{Agent: "googlebot", Action: botpolicy.Allow},
+			{Agent: "GPTBot", Action: botpolicy.Limit, Rate: 1},
+			{Agent: "AhrefsBot", Action: botpolicy.Deny},
+		},
+	}
+
+	r, ok := p.Match("Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", "")
+	assert.True(t, ok)
+	assert.Equal(t, botpolicy.Allow, r.Action)
+
+	// Explicit rule has priority over AI block.
+	r, ok = p.Match("Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko; compatible; GPTBot/1.1; +https://openai.com/gptbot)", "")
+	assert.True(t, ok)
+	assert.Equal(t, botpolicy.Limit, r.Action)
+
+	r, ok = p.Match("Mozilla/5.0 (compatible; ClaudeBot/1.0; +claudebot@anthropic.com)", "")
+	assert.True(t, ok)
+	assert.Equal(t, botpolicy.Rule{Agent: "ClaudeBot", Action: botpolicy.Deny}, r)
+
+	r, ok = p.Match("curl/8.0", "ahrefsbot")
+	assert.True(t, ok)
+	assert.Equal(t, botpolicy.Deny, r.Action)
+
+	r, ok = p.Match("curl/8.0", "")
+	assert.True(t, ok)
+	assert.Equal(t, botpolicy.Any, r.Agent)
+
+	_, ok = botpolicy.Policy{}.Match("curl/8.0", "")
+	assert.False(t, ok)
+}
+
+func TestPolicy_Robots(t *testing.T) {
+	assert.Equal(t, "User-agent: *\nAllow: /\n", botpolicy.Policy{}.Robots(""))
+
+	p := botpolicy.Policy{
+		Disallow: []string{"/album/", "/image/"},
+		Rules: []botpolicy.Rule{
+			{Agent: "AhrefsBot", Action: botpolicy.Deny},
+			{Agent: "Googlebot", Action: botpolicy.Allow},
+			{Agent: "bingbot", Action: botpolicy.Limit, Rate: 4},
+			{Agent: botpolicy.Any, Action: botpolicy.Limit, Rate: 0.5},
+		},
+	}
+
+	assert.Equal(t, `User-agent: AhrefsBot
+Disallow: /
+
+User-agent: bingbot
+Disallow: /album/
+Disallow: /image/
+Crawl-delay: 15
+
+User-agent: *
+Disallow: /album/
+Disallow: /image/
+Crawl-delay: 120
+
+Sitemap: https://example.org/sitemap.xml
+`, p.Robots("https://example.org/sitemap.xml"))
+
+	p = botpolicy.Policy{BlockAI: true}
+	s := p.Robots("")
+	assert.Contains(t, s, "User-agent: GPTBot\nDisallow: /\n\n")
+	assert.Contains(t, s, "User-agent: CCBot\nDisallow: /\n\n")
+	assert.Contains(t, s, "User-agent: *\nAllow: /\n")
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	l := botpolicy.Limiter{}
+	now := time.Now()
+
+	assert.True(t, l.Allow("a", 2, 2, now))
+	assert.True(t, l.Allow("a", 2, 2, now))
+	assert.False(t, l.Allow("a", 2, 2, now))
+	assert.True(t, l.Allow("b", 2, 2, now))
+
+	assert.False(t, l.Allow("a", 2, 2, now.Add(20*time.Second)))
+	assert.True(t, l.Allow("a", 2, 2, now.Add(30*time.Second)))
+	assert.False(t, l.Allow("a", 2, 2, now.Add(30*time.Second)))
+
+	// Burst is not exceeded after a long pause.
+	assert.True(t, l.Allow("a", 2, 2, now.Add(time.Hour)))
+	assert.True(t, l.Allow("a", 2, 2, now.Add(time.Hour)))
+	assert.False(t, l.Allow("a", 2, 2, now.Add(time.Hour)))
+}
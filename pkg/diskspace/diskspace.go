@@ -0,0 +1,7 @@
+// Package diskspace reports available disk space.
+package diskspace
+
+// Free returns number of bytes available to unprivileged user on a file system that contains the path.
+func Free(path string) (uint64, error) {
+	return free(path)
+}
@@ -0,0 +1,18 @@
+package diskspace_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/photo-blog/pkg/diskspace"
+)
+
+func TestFree(t *testing.T) {
+	f, err := diskspace.Free(t.TempDir())
+	require.NoError(t, err)
+	assert.Positive(t, f)
+
+	_, err = diskspace.Free("/path/that/does/not/exist")
+	assert.Error(t, err)
+}
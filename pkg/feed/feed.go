@@ -0,0 +1,199 @@
+// Package feed renders syndication feeds in Atom and JSON Feed formats.
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"html"
+	"time"
+)
+
+// Content types of feeds.
+const (
+	AtomContentType = "application/atom+xml; charset=utf-8"
+	JSONContentType = "application/feed+json; charset=utf-8"
+)
+
+// Feed describes a list of items.
+type Feed struct {
+	ID       string
+	Title    string
+	Subtitle string
+	Language string
+	Link     string // Absolute URL of HTML page.
+	FeedURL  string // Absolute URL of the feed itself.
+	Icon     string
+	Updated  time.Time
+	Items    []Item
+}
+
+// Item is a feed entry.
+type Item struct {
+	ID        string
+	Title     string
+	Link      string
+	Summary   string // Plain text.
+	Content   string // HTML.
+	Image     string // Absolute URL of a preview image.
+	Published time.Time
+	Updated   time.Time
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr,omitempty"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	Published string     `xml:"published,omitempty"`
+	Updated   string     `xml:"updated"`
+	Summary   *atomText  `xml:"summary,omitempty"`
+	Content   *atomText  `xml:"content,omitempty"`
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Lang     string      `xml:"xml:lang,attr,omitempty"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Icon     string      `xml:"icon,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+func atomTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+// Atom renders feed as Atom 1.0 XML document.
+func (f Feed) Atom() ([]byte, error) {
+	af := atomFeed{
+		Lang:     f.Language,
+		ID:       f.ID,
+		Title:    f.Title,
+		Subtitle: f.Subtitle,
+		Icon:     f.Icon,
+		Updated:  atomTime(f.Updated),
+		Links: []atomLink{
+			{Rel: "alternate", Type: "text/html", Href: f.Link},
+			{Rel: "self", Type: "application/atom+xml", Href: f.FeedURL},
+		},
+	}
+
+	for _, it := range f.Items {
+		e := atomEntry{
+			ID:        it.ID,
+			Title:     it.Title,
+			Links:     []atomLink{{Rel: "alternate", Type: "text/html", Href: it.Link}},
+			Published: atomTime(it.Published),
+			Updated:   atomTime(it.Updated),
+		}
+
+		if e.Updated == "" {
+			e.Updated = e.Published
+		}
+
+		if it.Image != "" {
+			e.Links = append(e.Links, atomLink{Rel: "enclosure", Type: "image/jpeg", Href: it.Image})
+		}
+
+		if it.Summary != "" {
+			e.Summary = &atomText{Type: "text", Body: it.Summary}
+		}
+
+		content := it.Content
+		if it.Image != "" {
+			content = `<p><a href="` + html.EscapeString(it.Link) + `"><img src="` + html.EscapeString(it.Image) + `" alt="` +
+				html.EscapeString(it.Title) + `"/></a></p>` + content
+		}
+
+		if content != "" {
+			e.Content = &atomText{Type: "html", Body: content}
+		}
+
+		af.Entries = append(af.Entries, e)
+	}
+
+	res, err := xml.MarshalIndent(af, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), res...), nil
+}
+
+type jsonItem struct {
+	ID            string     `json:"id"`
+	URL           string     `json:"url,omitempty"`
+	Title         string     `json:"title,omitempty"`
+	ContentHTML   string     `json:"content_html,omitempty"`
+	Summary       string     `json:"summary,omitempty"`
+	Image         string     `json:"image,omitempty"`
+	DatePublished *time.Time `json:"date_published,omitempty"`
+	DateModified  *time.Time `json:"date_modified,omitempty"`
+}
+
+type jsonFeed struct {
+	Version     string     `json:"version"`
+	Title       string     `json:"title"`
+	Description string     `json:"description,omitempty"`
+	HomePageURL string     `json:"home_page_url,omitempty"`
+	FeedURL     string     `json:"feed_url,omitempty"`
+	Icon        string     `json:"icon,omitempty"`
+	Language    string     `json:"language,omitempty"`
+	Items       []jsonItem `json:"items"`
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	t = t.UTC()
+
+	return &t
+}
+
+// JSON renders feed as JSON Feed 1.1 document.
+func (f Feed) JSON() ([]byte, error) {
+	jf := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		Description: f.Subtitle,
+		HomePageURL: f.Link,
+		FeedURL:     f.FeedURL,
+		Icon:        f.Icon,
+		Language:    f.Language,
+		Items:       make([]jsonItem, 0, len(f.Items)),
+	}
+
+	for _, it := range f.Items {
+		jf.Items = append(jf.Items, jsonItem{
+			ID:            it.ID,
+			URL:           it.Link,
+			Title:         it.Title,
+			ContentHTML:   it.Content,
+			Summary:       it.Summary,
+			Image:         it.Image,
+			DatePublished: timePtr(it.Published),
+			DateModified:  timePtr(it.Updated),
+		})
+	}
+
+	return json.MarshalIndent(jf, "", "  ")
+}
@@ -0,0 +1,78 @@
+package feed_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swaggest/assertjson"
+	"github.com/vearutop/photo-blog/pkg/feed"
+)
+
+func sample() feed.Feed {
+	ts := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	return feed.Feed{
+		ID:       "https://example.com/",
+		Title:    "Photos & Trips",
+		Language: "en",
+		Link:     "https://example.com/",
+		FeedURL:  "https://example.com/feed.atom",
+		Updated:  ts,
+		Items: []feed.Item{
+			{
+				ID:        "https://example.com/alps/",
+				Title:     "Alps",
+				Link:      "https://example.com/alps/",
+				Summary:   "Mountains",
+				Content:   "<p>Mountains</p>",
+				Image:     "https://example.com/thumb/1200w/abc.jpg",
+				Published: ts.Add(-time.Hour),
+				Updated:   ts,
+			},
+		},
+	}
+}
+
+func TestFeed_Atom(t *testing.T) {
+	b, err := sample().Atom()
+	require.NoError(t, err)
+
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xml:lang="en">
+  <id>https://example.com/</id>
+  <title>Photos &amp; Trips</title>
+  <updated>2026-10-19T12:00:00Z</updated>
+  <link rel="alternate" type="text/html" href="https://example.com/"></link>
+  <link rel="self" type="application/atom+xml" href="https://example.com/feed.atom"></link>
+  <entry>
+    <id>https://example.com/alps/</id>
+    <title>Alps</title>
+    <link rel="alternate" type="text/html" href="https://example.com/alps/"></link>
+    <link rel="enclosure" type="image/jpeg" href="https://example.com/thumb/1200w/abc.jpg"></link>
+    <published>2026-10-19T11:00:00Z</published>
+    <updated>2026-10-19T12:00:00Z</updated>
+    <summary type="text">Mountains</summary>
+    <content type="html">&lt;p&gt;&lt;a href=&#34;https://example.com/alps/&#34;&gt;&lt;img src=&#34;https://example.com/thumb/1200w/abc.jpg&#34; alt=&#34;Alps&#34;/&gt;&lt;/a&gt;&lt;/p&gt;&lt;p&gt;Mountains&lt;/p&gt;</content>
+  </entry>
+</feed>`, string(b))
+}
+
+func TestFeed_JSON(t *testing.T) {
+	b, err := sample().JSON()
+	require.NoError(t, err)
+
+	assertjson.Equal(t, []byte(`{
+	  "version":"https://jsonfeed.org/version/1.1","title":"Photos & Trips",
+	  "home_page_url":"https://example.com/","feed_url":"https://example.com/feed.atom","language":"en",
+	  "items":[
+		{
+		  "id":"https://example.com/alps/","url":"https://example.com/alps/","title":"Alps",
+		  "content_html":"<p>Mountains</p>","summary":"Mountains",
+		  "image":"https://example.com/thumb/1200w/abc.jpg",
+		  "date_published":"2026-10-19T11:00:00Z","date_modified":"2026-10-19T12:00:00Z"
+		}
+	  ]
+	}`), b)
+}
@@ -0,0 +1,320 @@
+// Package gazetteer implements offline reverse geocoding with GeoNames datasets.
+//
+// See https://download.geonames.org/export/dump/readme.txt.
+package gazetteer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const earthRadiusKm = 6371
+
+// Place is a populated place.
+type Place struct {
+	ID          int64
+	Name        string
+	Lat, Lon    float64
+	CountryCode string
+	Admin1Code  string
+	Population  int64
+}
+
+// Location is a result of reverse geocoding.
+type Location struct {
+	City        string  `json:"city,omitempty"`
+	Region      string  `json:"region,omitempty"`
+	Country     string  `json:"country,omitempty"`
+	CountryCode string  `json:"country_code,omitempty"`
+	Distance    float64 `json:"distance_km,omitempty"`
+}
+
+// Label returns human-readable location.
+func (l Location) Label() string {
+	parts := make([]string, 0, 3)
+
+	for _, p := range []string{l.City, l.Region, l.Country} {
+		if p == "" || (len(parts) > 0 && parts[len(parts)-1] == p) {
+			continue
+		}
+
+		parts = append(parts, p)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+type cell struct {
+	lat, lon int16
+}
+
+type named struct {
+	id   int64
+	name string
+}
+
+// Gazetteer is an in-memory spatial index of places.
+type Gazetteer struct {
+	places    []Place
+	grid      map[cell][]int32
+	admin1    map[string]named
+	countries map[string]named
+
+	// names holds localized names by geonameid and language.
+	names map[int64]map[string]string
+}
+
+// New creates an empty gazetteer.
+func New() *Gazetteer {
+	return &Gazetteer{
+		grid:      map[cell][]int32{},
+		admin1:    map[string]named{},
+		countries: map[string]named{},
+		names:     map[int64]map[string]string{},
+	}
+}
+
+// Len returns number of places.
+func (g *Gazetteer) Len() int {
+	return len(g.places)
+}
+
+func cellOf(lat, lon float64) cell {
+	return cell{lat: int16(math.Floor(lat)), lon: int16(math.Floor(lon))}
+}
+
+func scan(r io.Reader, minCols int, fn func(cols []string) error) error {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 1e6), 1e6)
+
+	for s.Scan() {
+		line := s.Text()
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		cols := strings.Split(line, "\t")
+		if len(cols) < minCols {
+			continue
+		}
+
+		if err := fn(cols); err != nil {
+			return err
+		}
+	}
+
+	return s.Err()
+}
+
+// LoadPlaces reads places from cities*.txt or allCountries.txt, only populated places (feature class P) are used.
+func (g *Gazetteer) LoadPlaces(r io.Reader) error {
+	return scan(r, 15, func(cols []string) error {
+		if cols[6] != "P" {
+			return nil
+		}
+
+		id, err := strconv.ParseInt(cols[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("parse geonameid %q: %w", cols[0], err)
+		}
+
+		p := Place{
+			ID:          id,
+			Name:        cols[1],
+			CountryCode: cols[8],
+			Admin1Code:  cols[10],
+		}
+
+		if p.Lat, err = strconv.ParseFloat(cols[4], 64); err != nil {
+			return fmt.Errorf("parse latitude of %d: %w", id, err)
+		}
+
+		if p.Lon, err = strconv.ParseFloat(cols[5], 64); err != nil {
+			return fmt.Errorf("parse longitude of %d: %w", id, err)
+		}
+
+		p.Population, _ = strconv.ParseInt(cols[14], 10, 64)
+
+		c := cellOf(p.Lat, p.Lon)
+		g.grid[c] = append(g.grid[c], int32(len(g.places)))
+		g.places = append(g.places, p)
+
+		return nil
+	})
+}
+
+// LoadAdmin1 reads region names from admin1CodesASCII.txt.
+func (g *Gazetteer) LoadAdmin1(r io.Reader) error {
+	return scan(r, 4, func(cols []string) error {
+		id, _ := strconv.ParseInt(cols[3], 10, 64)
+		g.admin1[cols[0]] = named{id: id, name: cols[1]}
+
+		return nil
+	})
+}
+
+// LoadCountries reads country names from countryInfo.txt.
+func (g *Gazetteer) LoadCountries(r io.Reader) error {
+	return scan(r, 17, func(cols []string) error {
+		id, _ := strconv.ParseInt(cols[16], 10, 64)
+		g.countries[cols[0]] = named{id: id, name: cols[4]}
+
+		return nil
+	})
+}
+
+// LoadAlternateNames reads localized names from alternateNamesV2.txt for given languages.
+//
+// Names are only kept for loaded places, regions and countries, so this should be called last.
+func (g *Gazetteer) LoadAlternateNames(r io.Reader, langs ...string) error {
+	if len(langs) == 0 {
+		return nil
+	}
+
+	wantLang := map[string]bool{}
+	for _, l := range langs {
+		wantLang[l] = true
+	}
+
+	known := make(map[int64]bool, len(g.places)+len(g.admin1)+len(g.countries))
+	for _, p := range g.places {
+		known[p.ID] = true
+	}
+
+	for _, n := range g.admin1 {
+		known[n.id] = true
+	}
+
+	for _, n := range g.countries {
+		known[n.id] = true
+	}
+
+	preferred := map[int64]map[string]bool{}
+
+	return scan(r, 4, func(cols []string) error {
+		lang := cols[2]
+		if !wantLang[lang] {
+			return nil
+		}
+
+		// Malformed or unknown entries are skipped.
+		id, _ := strconv.ParseInt(cols[1], 10, 64)
+		if !known[id] {
+			return nil
+		}
+
+		// Historic and colloquial names are not suitable for labels.
+		if (len(cols) > 6 && cols[6] == "1") || (len(cols) > 7 && cols[7] == "1") {
+			return nil
+		}
+
+		isPreferred := len(cols) > 4 && cols[4] == "1"
+
+		names := g.names[id]
+		if names == nil {
+			names = map[string]string{}
+			g.names[id] = names
+			preferred[id] = map[string]bool{}
+		}
+
+		if _, ok := names[lang]; ok && (preferred[id][lang] || !isPreferred) {
+			return nil
+		}
+
+		names[lang] = cols[3]
+		preferred[id][lang] = isPreferred
+
+		return nil
+	})
+}
+
+func (g *Gazetteer) localized(id int64, name, lang string) string {
+	if lang == "" {
+		return name
+	}
+
+	if n, ok := g.names[id][lang]; ok {
+		return n
+	}
+
+	return name
+}
+
+// Nearest finds the closest place within maxDistKm.
+func (g *Gazetteer) Nearest(lat, lon, maxDistKm float64) (Place, float64, bool) {
+	dLat := maxDistKm / (earthRadiusKm * math.Pi / 180)
+	dLon := math.Min(dLat/math.Max(math.Cos(lat*math.Pi/180), 0.01), 180)
+
+	var (
+		best  = -1
+		bestD = maxDistKm
+	)
+
+	for la := math.Floor(lat - dLat); la <= math.Floor(lat+dLat); la++ {
+		for lo := math.Floor(lon - dLon); lo <= math.Floor(lon+dLon); lo++ {
+			// Wrap around antimeridian.
+			wlo := lo
+			if wlo < -180 {
+				wlo += 360
+			} else if wlo >= 180 {
+				wlo -= 360
+			}
+
+			for _, i := range g.grid[cell{lat: int16(la), lon: int16(wlo)}] {
+				p := g.places[i]
+				if d := Distance(lat, lon, p.Lat, p.Lon); d <= bestD {
+					best = int(i)
+					bestD = d
+				}
+			}
+		}
+	}
+
+	if best == -1 {
+		return Place{}, 0, false
+	}
+
+	return g.places[best], bestD, true
+}
+
+// Reverse returns location of the closest place within maxDistKm with names in a given language.
+//
+// Empty lang means default GeoNames names.
+func (g *Gazetteer) Reverse(lat, lon, maxDistKm float64, lang string) (Location, bool) {
+	p, d, ok := g.Nearest(lat, lon, maxDistKm)
+	if !ok {
+		return Location{}, false
+	}
+
+	l := Location{
+		City:        g.localized(p.ID, p.Name, lang),
+		CountryCode: p.CountryCode,
+		Distance:    math.Round(d*100) / 100,
+	}
+
+	if a, ok := g.admin1[p.CountryCode+"."+p.Admin1Code]; ok {
+		l.Region = g.localized(a.id, a.name, lang)
+	}
+
+	if c, ok := g.countries[p.CountryCode]; ok {
+		l.Country = g.localized(c.id, c.name, lang)
+	}
+
+	return l, true
+}
+
+// Distance returns great-circle distance between two points in kilometers.
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
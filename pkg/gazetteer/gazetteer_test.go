@@ -0,0 +1,73 @@
+package gazetteer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/photo-blog/pkg/gazetteer"
+)
+
+const (
+	cities = "2950159\tBerlin\tBerlin\tBerlino,Berlín\t52.52437\t13.41053\tP\tPPLC\tDE\t\t16\t00\t11000\t11000000\t3426354\t74\t43\tEurope/Berlin\t2022-03-09\n" +
+		"2867714\tMunich\tMunich\tMuenchen,München\t48.13743\t11.57549\tP\tPPLA\tDE\t\t02\t091\t09162\t09162000\t1260391\t\t524\tEurope/Berlin\t2023-10-12\n" +
+		"2643743\tLondon\tLondon\tLondres\t51.50853\t-0.12574\tP\tPPLC\tGB\t\tENG\tGLA\t\t\t8961989\t\t25\tEurope/London\t2023-01-12\n" +
+		"2911297\tHamburg Airport\tHamburg Airport\t\t53.63040\t9.98823\tS\tAIRP\tDE\t\t04\t\t\t\t0\t\t11\tEurope/Berlin\t2022-01-01\n"
+
+	admin1 = "DE.16\tState of Berlin\tState of Berlin\t2950157\n" +
+		"DE.02\tBavaria\tBavaria\t2951839\n" +
+		"GB.ENG\tEngland\tEngland\t6269131\n"
+
+	countries = "#ISO\tISO3\tISO-Numeric\tfips\tCountry\tCapital\tArea(in sq km)\tPopulation\tContinent\ttld\tCurrencyCode\tCurrencyName\tPhone\tPostal Code Format\tPostal Code Regex\tLanguages\tgeonameid\tneighbours\tEquivalentFipsCode\n" +
+		"DE\tDEU\t276\tGM\tGermany\tBerlin\t357021\t82927922\tEU\t.de\tEUR\tEuro\t49\t#####\t^(\\d{5})$\tde\t2921044\tCH,PL\t\n" +
+		"GB\tGBR\t826\tUK\tUnited Kingdom\tLondon\t244820\t66488991\tEU\t.uk\tGBP\tPound\t44\t\t\ten-GB\t2635167\tIE\t\n"
+
+	alternateNames = "1\t2867714\tde\tMünchen\t1\t\t\t\t\t\n" +
+		"2\t2867714\tde\tMuenchen\t\t\t\t\t\t\n" +
+		"3\t2921044\tde\tDeutschland\t1\t\t\t\t\t\n" +
+		"4\t2951839\tde\tBayern\t\t\t\t\t\t\n" +
+		"5\t2951839\tde\tKönigreich Bayern\t\t\t\t1\t\t\n" +
+		"6\t2867714\tfr\tMunich\t\t\t\t\t\t\n"
+)
+
+func load(t *testing.T) *gazetteer.Gazetteer {
+	t.Helper()
+
+	g := gazetteer.New()
+	require.NoError(t, g.LoadPlaces(strings.NewReader(cities)))
+	require.NoError(t, g.LoadAdmin1(strings.NewReader(admin1)))
+	require.NoError(t, g.LoadCountries(strings.NewReader(countries)))
+	require.NoError(t, g.LoadAlternateNames(strings.NewReader(alternateNames), "de"))
+
+	return g
+}
+
+func TestGazetteer_Reverse(t *testing.T) {
+	g := load(t)
+	assert.Equal(t, 3, g.Len())
+
+	l, ok := g.Reverse(48.1519, 11.5595, 30, "")
+	require.True(t, ok)
+	assert.Equal(t, "Munich, Bavaria, Germany", l.Label())
+	assert.Equal(t, "DE", l.CountryCode)
+	assert.InDelta(t, 2.05, l.Distance, 0.1)
+
+	l, ok = g.Reverse(48.1519, 11.5595, 30, "de")
+	require.True(t, ok)
+	assert.Equal(t, "München, Bayern, Deutschland", l.Label())
+
+	// Missing translations fall back to default names.
+	l, ok = g.Reverse(51.5, -0.1, 30, "de")
+	require.True(t, ok)
+	assert.Equal(t, "London, England, United Kingdom", l.Label())
+
+	// Non-populated features are ignored.
+	_, ok = g.Reverse(53.63, 9.99, 30, "")
+	assert.False(t, ok)
+}
+
+func TestLocation_Label(t *testing.T) {
+	assert.Equal(t, "Singapore", gazetteer.Location{City: "Singapore", Country: "Singapore"}.Label())
+	assert.Equal(t, "", gazetteer.Location{}.Label())
+}
@@ -0,0 +1,83 @@
+// Package geocluster groups map points into screen grid cells.
+package geocluster
+
+import (
+	"math"
+)
+
+// TileSize is a size of a web map tile in pixels.
+const TileSize = 256
+
+// Point is a position of an item.
+type Point struct {
+	Lat, Lon float64
+}
+
+// Cluster is a group of points that share a grid cell.
+type Cluster struct {
+	// Points are indexes of cluster points, the first one can be used as a representative.
+	Points []int
+
+	Count    int
+	Lat, Lon float64
+
+	MinLat, MinLon, MaxLat, MaxLon float64
+}
+
+// pixel returns Web Mercator pixel coordinates on a zoom level.
+func pixel(lat, lon float64, zoom int) (x, y float64) {
+	scale := TileSize * math.Exp2(float64(zoom))
+	lat = math.Max(-85.05112878, math.Min(85.05112878, lat))
+	sin := math.Sin(lat * math.Pi / 180)
+
+	x = (lon + 180) / 360 * scale
+	y = (0.5 - math.Log((1+sin)/(1-sin))/(4*math.Pi)) * scale
+
+	return x, y
+}
+
+// Grid groups points by cells of cellSize pixels on a zoom level.
+//
+// Clusters are ordered by first appearance of their points, cluster position is an average of its points.
+func Grid(points []Point, zoom int, cellSize float64) []Cluster {
+	type cellKey struct {
+		x, y int64
+	}
+
+	idx := make(map[cellKey]int)
+	res := make([]Cluster, 0)
+
+	for i, p := range points {
+		x, y := pixel(p.Lat, p.Lon, zoom)
+		k := cellKey{x: int64(math.Floor(x / cellSize)), y: int64(math.Floor(y / cellSize))}
+
+		ci, ok := idx[k]
+		if !ok {
+			idx[k] = len(res)
+			res = append(res, Cluster{
+				MinLat: p.Lat, MinLon: p.Lon,
+				MaxLat: p.Lat, MaxLon: p.Lon,
+			})
+
+			ci = len(res) - 1
+		}
+
+		c := &res[ci]
+		c.Points = append(c.Points, i)
+		c.Count++
+		c.Lat += p.Lat
+		c.Lon += p.Lon
+		c.MinLat = math.Min(c.MinLat, p.Lat)
+		c.MinLon = math.Min(c.MinLon, p.Lon)
+		c.MaxLat = math.Max(c.MaxLat, p.Lat)
+		c.MaxLon = math.Max(c.MaxLon, p.Lon)
+	}
+
+	for i := range res {
+		c := &res[i]
+		c.Lat /= float64(c.Count)
+		c.Lon /= float64(c.Count)
+	}
+
+	return res
+}
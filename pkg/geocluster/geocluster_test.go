@@ -0,0 +1,37 @@
+package geocluster_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/photo-blog/pkg/geocluster"
+)
+
+func TestGrid(t *testing.T) {
+	points := []geocluster.Point{
+		{Lat: 52.52, Lon: 13.40},  // Berlin.
+		{Lat: 48.85, Lon: 2.35},   // Paris.
+		{Lat: 52.50, Lon: 13.42},  // Berlin.
+		{Lat: -33.86, Lon: 151.2}, // Sydney.
+	}
+
+	// World view, Europe is within a single cell.
+	cc := geocluster.Grid(points, 0, 64)
+	require.Len(t, cc, 2)
+	assert.Equal(t, []int{0, 1, 2}, cc[0].Points)
+	assert.Equal(t, 3, cc[0].Count)
+	assert.Equal(t, 48.85, cc[0].MinLat)
+	assert.Equal(t, 13.42, cc[0].MaxLon)
+	assert.Equal(t, []int{3}, cc[1].Points)
+	assert.Equal(t, 1, cc[1].Count)
+
+	// City view, only close points are grouped.
+	cc = geocluster.Grid(points, 10, 64)
+	require.Len(t, cc, 3)
+	assert.Equal(t, 2, cc[0].Count)
+	assert.InDelta(t, 52.51, cc[0].Lat, 1e-9)
+	assert.InDelta(t, 13.41, cc[0].Lon, 1e-9)
+	assert.Equal(t, []int{0, 2}, cc[0].Points)
+	assert.Equal(t, []int{1}, cc[1].Points)
+}
@@ -0,0 +1,252 @@
+// Package geoexport encodes photo positions and tracks as GeoJSON and KML.
+package geoexport
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Position is a geographic coordinate.
+type Position struct {
+	Lat float64
+	Lon float64
+	Alt float64
+}
+
+// Place is a named point, e.g. a photo.
+type Place struct {
+	Position
+
+	Name        string
+	Description string
+	URL         string
+	Thumb       string
+	Time        time.Time
+
+	// Properties are added to GeoJSON feature properties.
+	Properties map[string]any
+}
+
+// Track is a named line, each segment is drawn separately.
+type Track struct {
+	Name     string
+	Segments [][]Position
+}
+
+// Collection is a set of places and tracks.
+type Collection struct {
+	Name   string
+	Places []Place
+	Tracks []Track
+}
+
+type geoJSONFeature struct {
+	Type       string         `json:"type"`
+	Geometry   geoJSONGeom    `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+type geoJSONGeom struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+func coords(p Position) []float64 {
+	if p.Alt != 0 {
+		return []float64{p.Lon, p.Lat, p.Alt}
+	}
+
+	return []float64{p.Lon, p.Lat}
+}
+
+// GeoJSON encodes collection as a FeatureCollection, see RFC 7946.
+func (c Collection) GeoJSON() ([]byte, error) {
+	features := make([]geoJSONFeature, 0, len(c.Places)+len(c.Tracks))
+
+	for _, p := range c.Places {
+		props := map[string]any{}
+
+		for k, v := range p.Properties {
+			props[k] = v
+		}
+
+		if p.Name != "" {
+			props["name"] = p.Name
+		}
+
+		if p.Description != "" {
+			props["description"] = p.Description
+		}
+
+		if p.URL != "" {
+			props["url"] = p.URL
+		}
+
+		if p.Thumb != "" {
+			props["thumb"] = p.Thumb
+		}
+
+		if !p.Time.IsZero() {
+			props["time"] = p.Time.Format(time.RFC3339)
+		}
+
+		features = append(features, geoJSONFeature{
+			Type:       "Feature",
+			Geometry:   geoJSONGeom{Type: "Point", Coordinates: coords(p.Position)},
+			Properties: props,
+		})
+	}
+
+	for _, t := range c.Tracks {
+		lines := make([][][]float64, 0, len(t.Segments))
+
+		for _, s := range t.Segments {
+			if len(s) < 2 {
+				continue
+			}
+
+			line := make([][]float64, 0, len(s))
+			for _, p := range s {
+				line = append(line, coords(p))
+			}
+
+			lines = append(lines, line)
+		}
+
+		if len(lines) == 0 {
+			continue
+		}
+
+		features = append(features, geoJSONFeature{
+			Type:       "Feature",
+			Geometry:   geoJSONGeom{Type: "MultiLineString", Coordinates: lines},
+			Properties: map[string]any{"name": t.Name},
+		})
+	}
+
+	return json.Marshal(struct {
+		Type     string           `json:"type"`
+		Name     string           `json:"name,omitempty"`
+		Features []geoJSONFeature `json:"features"`
+	}{
+		Type:     "FeatureCollection",
+		Name:     c.Name,
+		Features: features,
+	})
+}
+
+type kmlDoc struct {
+	XMLName  xml.Name `xml:"kml"`
+	XMLNS    string   `xml:"xmlns,attr"`
+	Document struct {
+		Name       string         `xml:"name,omitempty"`
+		Placemarks []kmlPlacemark `xml:"Placemark"`
+	} `xml:"Document"`
+}
+
+type kmlPlacemark struct {
+	Name          string            `xml:"name,omitempty"`
+	Description   *kmlCDATA         `xml:"description,omitempty"`
+	TimeStamp     *kmlTimeStamp     `xml:"TimeStamp,omitempty"`
+	Point         *kmlPoint         `xml:"Point,omitempty"`
+	MultiGeometry *kmlMultiGeometry `xml:"MultiGeometry,omitempty"`
+}
+
+type kmlCDATA struct {
+	Text string `xml:",cdata"`
+}
+
+type kmlTimeStamp struct {
+	When string `xml:"when"`
+}
+
+type kmlPoint struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+type kmlMultiGeometry struct {
+	LineStrings []kmlPoint `xml:"LineString"`
+}
+
+func kmlCoords(p Position) string {
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+
+	return f(p.Lon) + "," + f(p.Lat) + "," + f(p.Alt)
+}
+
+// KML encodes collection as KML 2.2 document.
+func (c Collection) KML() ([]byte, error) {
+	doc := kmlDoc{XMLNS: "http://www.opengis.net/kml/2.2"}
+	doc.Document.Name = c.Name
+
+	for _, p := range c.Places {
+		pm := kmlPlacemark{
+			Name:  p.Name,
+			Point: &kmlPoint{Coordinates: kmlCoords(p.Position)},
+		}
+
+		desc := p.Description
+		if p.Thumb != "" {
+			img := `<img src="` + xmlEscape(p.Thumb) + `" />`
+			if p.URL != "" {
+				img = `<a href="` + xmlEscape(p.URL) + `">` + img + `</a>`
+			}
+
+			if desc != "" {
+				desc += "<br/>"
+			}
+
+			desc += img
+		}
+
+		if desc != "" {
+			pm.Description = &kmlCDATA{Text: desc}
+		}
+
+		if !p.Time.IsZero() {
+			pm.TimeStamp = &kmlTimeStamp{When: p.Time.Format(time.RFC3339)}
+		}
+
+		doc.Document.Placemarks = append(doc.Document.Placemarks, pm)
+	}
+
+	for _, t := range c.Tracks {
+		mg := &kmlMultiGeometry{}
+
+		for _, s := range t.Segments {
+			if len(s) < 2 {
+				continue
+			}
+
+			cc := make([]string, 0, len(s))
+			for _, p := range s {
+				cc = append(cc, kmlCoords(p))
+			}
+
+			mg.LineStrings = append(mg.LineStrings, kmlPoint{Coordinates: strings.Join(cc, " ")})
+		}
+
+		if len(mg.LineStrings) == 0 {
+			continue
+		}
+
+		doc.Document.Placemarks = append(doc.Document.Placemarks, kmlPlacemark{Name: t.Name, MultiGeometry: mg})
+	}
+
+	res, err := xml.MarshalIndent(doc, "", " ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), res...), nil
+}
+
+func xmlEscape(s string) string {
+	b := strings.Builder{}
+	_ = xml.EscapeText(&b, []byte(s))
+
+	return b.String()
+}
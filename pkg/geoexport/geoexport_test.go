@@ -0,0 +1,76 @@
+package geoexport_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/photo-blog/pkg/geoexport"
+)
+
+func collection() geoexport.Collection {
+	return geoexport.Collection{
+		Name: "Trip",
+		Places: []geoexport.Place{
+			{
+				Position:   geoexport.Position{Lat: 52.5, Lon: 13.4},
+				Name:       "IMG_1",
+				URL:        "https://example.org/trip/photo-abc.html",
+				Thumb:      "https://example.org/thumb/300w/abc.jpg",
+				Time:       time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+				Properties: map[string]any{"hash": "abc"},
+			},
+		},
+		Tracks: []geoexport.Track{
+			{
+				Name: "Walk",
+				Segments: [][]geoexport.Position{
+					{{Lat: 52.5, Lon: 13.4, Alt: 30}, {Lat: 52.6, Lon: 13.5, Alt: 35}},
+					{{Lat: 1, Lon: 1}}, // Single point segments are skipped.
+				},
+			},
+		},
+	}
+}
+
+func TestCollection_GeoJSON(t *testing.T) {
+	j, err := collection().GeoJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"FeatureCollection","name":"Trip","features":[
+{"type":"Feature","geometry":{"type":"Point","coordinates":[13.4,52.5]},
+ "properties":{"hash":"abc","name":"IMG_1","url":"https://example.org/trip/photo-abc.html",
+ "thumb":"https://example.org/thumb/300w/abc.jpg","time":"2024-05-01T10:00:00Z"}},
+{"type":"Feature","geometry":{"type":"MultiLineString","coordinates":[[[13.4,52.5,30],[13.5,52.6,35]]]},
+ "properties":{"name":"Walk"}}
+]}`, string(j))
+}
+
+func TestCollection_KML(t *testing.T) {
+	k, err := collection().KML()
+	require.NoError(t, err)
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2">
+ <Document>
+  <name>Trip</name>
+  <Placemark>
+   <name>IMG_1</name>
+   <description><![CDATA[<a href="https://example.org/trip/photo-abc.html"><img src="https://example.org/thumb/300w/abc.jpg" /></a>]]></description>
+   <TimeStamp>
+    <when>2024-05-01T10:00:00Z</when>
+   </TimeStamp>
+   <Point>
+    <coordinates>13.4,52.5,0</coordinates>
+   </Point>
+  </Placemark>
+  <Placemark>
+   <name>Walk</name>
+   <MultiGeometry>
+    <LineString>
+     <coordinates>13.4,52.5,30 13.5,52.6,35</coordinates>
+    </LineString>
+   </MultiGeometry>
+  </Placemark>
+ </Document>
+</kml>`, string(k))
+}
@@ -0,0 +1,117 @@
+// Package geotrack finds positions on GPS tracks by time.
+package geotrack
+
+import (
+	"sort"
+	"time"
+
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+// Point is a timed position.
+type Point struct {
+	Time time.Time
+	Lat  float64
+	Lon  float64
+	Alt  float64
+}
+
+// Index is a time-ordered collection of track points, it is not safe for concurrent use.
+type Index struct {
+	points []Point
+	sorted bool
+}
+
+// Add adds a point.
+func (idx *Index) Add(p Point) {
+	idx.points = append(idx.points, p)
+	idx.sorted = false
+}
+
+// AddGPX adds timed points of all tracks.
+func (idx *Index) AddGPX(g *gpx.GPX) {
+	for _, t := range g.Tracks {
+		for _, s := range t.Segments {
+			for _, p := range s.Points {
+				if p.Timestamp.IsZero() {
+					continue
+				}
+
+				idx.Add(Point{
+					Time: p.Timestamp,
+					Lat:  p.Latitude,
+					Lon:  p.Longitude,
+					Alt:  p.Elevation.Value(),
+				})
+			}
+		}
+	}
+}
+
+// Len returns number of points.
+func (idx *Index) Len() int {
+	return len(idx.points)
+}
+
+// Lookup returns position at a given time.
+//
+// Position is interpolated between neighbor points if they are not more than maxGap apart,
+// otherwise the closest point is used if it is within maxGap.
+func (idx *Index) Lookup(t time.Time, maxGap time.Duration) (Point, bool) {
+	if !idx.sorted {
+		sort.SliceStable(idx.points, func(i, j int) bool {
+			return idx.points[i].Time.Before(idx.points[j].Time)
+		})
+
+		idx.sorted = true
+	}
+
+	pp := idx.points
+	i := sort.Search(len(pp), func(i int) bool { return !pp[i].Time.Before(t) })
+
+	if i < len(pp) && pp[i].Time.Equal(t) {
+		return pp[i], true
+	}
+
+	var prev, next *Point
+
+	if i > 0 {
+		prev = &pp[i-1]
+	}
+
+	if i < len(pp) {
+		next = &pp[i]
+	}
+
+	if prev != nil && next != nil && next.Time.Sub(prev.Time) <= maxGap {
+		k := float64(t.Sub(prev.Time)) / float64(next.Time.Sub(prev.Time))
+
+		return Point{
+			Time: t,
+			Lat:  prev.Lat + (next.Lat-prev.Lat)*k,
+			Lon:  prev.Lon + (next.Lon-prev.Lon)*k,
+			Alt:  prev.Alt + (next.Alt-prev.Alt)*k,
+		}, true
+	}
+
+	var (
+		closest *Point
+		gap     time.Duration
+	)
+
+	if prev != nil {
+		closest = prev
+		gap = t.Sub(prev.Time)
+	}
+
+	if next != nil && (closest == nil || next.Time.Sub(t) < gap) {
+		closest = next
+		gap = next.Time.Sub(t)
+	}
+
+	if closest == nil || gap > maxGap {
+		return Point{}, false
+	}
+
+	return *closest, true
+}
@@ -0,0 +1,56 @@
+package geotrack_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkrajina/gpxgo/gpx"
+	"github.com/vearutop/photo-blog/pkg/geotrack"
+)
+
+func TestIndex_Lookup(t *testing.T) {
+	g, err := gpx.ParseBytes([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test">
+<trk><trkseg>
+<trkpt lat="52.0" lon="13.0"><ele>100</ele><time>2024-05-01T10:00:00Z</time></trkpt>
+<trkpt lat="52.1" lon="13.2"><ele>200</ele><time>2024-05-01T10:10:00Z</time></trkpt>
+<trkpt lat="53.0" lon="14.0"><ele>300</ele><time>2024-05-01T12:00:00Z</time></trkpt>
+<trkpt lat="60.0" lon="20.0"></trkpt>
+</trkseg></trk>
+</gpx>`))
+	require.NoError(t, err)
+
+	idx := geotrack.Index{}
+	idx.AddGPX(g)
+	assert.Equal(t, 3, idx.Len())
+
+	ts := func(s string) time.Time {
+		tt, err := time.Parse(time.RFC3339, s)
+		require.NoError(t, err)
+
+		return tt
+	}
+
+	p, ok := idx.Lookup(ts("2024-05-01T10:05:00Z"), 15*time.Minute)
+	require.True(t, ok)
+	assert.InDelta(t, 52.05, p.Lat, 1e-9)
+	assert.InDelta(t, 13.1, p.Lon, 1e-9)
+	assert.InDelta(t, 150, p.Alt, 1e-9)
+
+	// Gap between points is too large for interpolation, closest point is used.
+	p, ok = idx.Lookup(ts("2024-05-01T10:15:00Z"), 15*time.Minute)
+	require.True(t, ok)
+	assert.InDelta(t, 52.1, p.Lat, 1e-9)
+
+	_, ok = idx.Lookup(ts("2024-05-01T11:00:00Z"), 15*time.Minute)
+	assert.False(t, ok)
+
+	_, ok = idx.Lookup(ts("2024-05-01T09:00:00Z"), 15*time.Minute)
+	assert.False(t, ok)
+
+	p, ok = idx.Lookup(ts("2024-05-01T12:00:00Z"), 0)
+	require.True(t, ok)
+	assert.InDelta(t, 53.0, p.Lat, 1e-9)
+}
@@ -0,0 +1,243 @@
+// Package imgedit implements non-destructive image adjustments.
+package imgedit
+
+import (
+	"image"
+	"image/draw"
+	"math"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+)
+
+// Edits is a stack of adjustments, they are applied in order: rotate, straighten, crop, tone, watermark.
+type Edits struct {
+	Rotate      int        `json:"rotate,omitempty" title:"Rotate" description:"Clockwise rotation, degrees." enum:"[0,90,180,270]"`
+	Straighten  float64    `json:"straighten,omitempty" title:"Straighten" description:"Fine rotation, degrees, image is cropped to hide corners." minimum:"-45" maximum:"45"`
+	Crop        *Crop      `json:"crop,omitempty" title:"Crop"`
+	Exposure    float64    `json:"exposure,omitempty" title:"Exposure" description:"Exposure compensation, EV." minimum:"-3" maximum:"3"`
+	Contrast    float64    `json:"contrast,omitempty" title:"Contrast" minimum:"-1" maximum:"1"`
+	Temperature float64    `json:"temperature,omitempty" title:"Temperature" description:"White balance, negative is cooler, positive is warmer." minimum:"-1" maximum:"1"`
+	Tint        float64    `json:"tint,omitempty" title:"Tint" description:"White balance, negative is greener, positive is more magenta." minimum:"-1" maximum:"1"`
+	Watermark   *Watermark `json:"watermark,omitempty" title:"Watermark"`
+}
+
+// Crop defines a box as fractions of image dimensions.
+type Crop struct {
+	Left   float64 `json:"left" minimum:"0" maximum:"1"`
+	Top    float64 `json:"top" minimum:"0" maximum:"1"`
+	Width  float64 `json:"width" minimum:"0" maximum:"1"`
+	Height float64 `json:"height" minimum:"0" maximum:"1"`
+}
+
+func (c *Crop) valid() bool {
+	return c != nil && c.Width > 0 && c.Height > 0 &&
+		c.Left >= 0 && c.Top >= 0 && c.Left+c.Width <= 1.0001 && c.Top+c.Height <= 1.0001 &&
+		(c.Width < 1 || c.Height < 1)
+}
+
+// IsZero returns true if edits do not change the image.
+func (e *Edits) IsZero() bool {
+	return e == nil || (e.Rotate%360 == 0 && e.Straighten == 0 && !e.Crop.valid() && !e.hasTone() && e.Watermark == nil)
+}
+
+func (e *Edits) hasTone() bool {
+	return e.Exposure != 0 || e.Contrast != 0 || e.Temperature != 0 || e.Tint != 0
+}
+
+// Size returns dimensions of edited image.
+func (e *Edits) Size(w, h int64) (int64, int64) {
+	if e == nil {
+		return w, h
+	}
+
+	if r := normRotate(e.Rotate); r == 90 || r == 270 {
+		w, h = h, w
+	}
+
+	if e.Straighten != 0 {
+		s := straightenScale(float64(w), float64(h), e.Straighten)
+		w, h = int64(math.Round(float64(w)*s)), int64(math.Round(float64(h)*s))
+	}
+
+	if e.Crop.valid() {
+		w, h = int64(math.Round(float64(w)*e.Crop.Width)), int64(math.Round(float64(h)*e.Crop.Height))
+	}
+
+	return w, h
+}
+
+// Apply returns edited image, source image is not modified.
+func (e *Edits) Apply(img image.Image) (image.Image, error) {
+	if e.IsZero() {
+		return img, nil
+	}
+
+	switch normRotate(e.Rotate) {
+	case 90:
+		img = rotate90(img)
+	case 180:
+		img = rotate180(img)
+	case 270:
+		img = rotate180(rotate90(img))
+	}
+
+	if e.Straighten != 0 {
+		img = straighten(img, e.Straighten)
+	}
+
+	if e.Crop.valid() {
+		img = crop(img, *e.Crop)
+	}
+
+	if e.hasTone() {
+		img = e.tone(img)
+	}
+
+	if e.Watermark != nil {
+		dst := toRGBA(img, true)
+		if err := e.Watermark.Draw(dst); err != nil {
+			return nil, err
+		}
+
+		img = dst
+	}
+
+	return img, nil
+}
+
+func normRotate(r int) int {
+	r %= 360
+	if r < 0 {
+		r += 360
+	}
+
+	return r
+}
+
+// toRGBA converts image to RGBA with zero origin, copy enforces a new image even if source is RGBA.
+func toRGBA(img image.Image, copyRGBA bool) *image.RGBA {
+	b := img.Bounds()
+
+	if rgba, ok := img.(*image.RGBA); ok && !copyRGBA && b.Min == (image.Point{}) {
+		return rgba
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+
+	return dst
+}
+
+func rotate90(img image.Image) *image.RGBA {
+	src := toRGBA(img, false)
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, h, w))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			si := src.PixOffset(x, y)
+			di := dst.PixOffset(h-1-y, x)
+			copy(dst.Pix[di:di+4], src.Pix[si:si+4])
+		}
+	}
+
+	return dst
+}
+
+func rotate180(img image.Image) *image.RGBA {
+	src := toRGBA(img, false)
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			si := src.PixOffset(x, y)
+			di := dst.PixOffset(w-1-x, h-1-y)
+			copy(dst.Pix[di:di+4], src.Pix[si:si+4])
+		}
+	}
+
+	return dst
+}
+
+// straightenScale returns the scale of the largest rectangle with the same aspect ratio
+// that fits into the image rotated by angle degrees.
+func straightenScale(w, h, angle float64) float64 {
+	a := math.Abs(angle) * math.Pi / 180
+	c, s := math.Cos(a), math.Sin(a)
+
+	return math.Min(w/(w*c+h*s), h/(w*s+h*c))
+}
+
+func straighten(img image.Image, angle float64) image.Image {
+	b := img.Bounds()
+	w, h := float64(b.Dx()), float64(b.Dy())
+	scale := straightenScale(w, h, angle)
+	dw, dh := math.Round(w*scale), math.Round(h*scale)
+
+	dst := image.NewRGBA(image.Rect(0, 0, int(dw), int(dh)))
+
+	a := angle * math.Pi / 180
+	c, s := math.Cos(a), math.Sin(a)
+	csx, csy := float64(b.Min.X)+w/2, float64(b.Min.Y)+h/2
+	cdx, cdy := dw/2, dh/2
+
+	// Source to destination transformation: rotation around the center.
+	s2d := f64.Aff3{
+		c, -s, cdx - (c*csx - s*csy),
+		s, c, cdy - (s*csx + c*csy),
+	}
+
+	xdraw.BiLinear.Transform(dst, s2d, img, b, xdraw.Src, nil)
+
+	return dst
+}
+
+func crop(img image.Image, c Crop) image.Image {
+	b := img.Bounds()
+	w, h := float64(b.Dx()), float64(b.Dy())
+
+	r := image.Rect(
+		b.Min.X+int(math.Round(w*c.Left)),
+		b.Min.Y+int(math.Round(h*c.Top)),
+		b.Min.X+int(math.Round(w*(c.Left+c.Width))),
+		b.Min.Y+int(math.Round(h*(c.Top+c.Height))),
+	).Intersect(b)
+
+	dst := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, r.Min, draw.Src)
+
+	return dst
+}
+
+// tone applies white balance, exposure and contrast with per-channel lookup tables.
+func (e *Edits) tone(img image.Image) image.Image {
+	gains := [3]float64{
+		1 + 0.2*e.Temperature,
+		1 - 0.2*e.Tint,
+		1 - 0.2*e.Temperature,
+	}
+	ev := math.Pow(2, e.Exposure)
+
+	var lut [3][256]uint8
+
+	for ch := 0; ch < 3; ch++ {
+		for i := 0; i < 256; i++ {
+			v := float64(i) / 255 * gains[ch] * ev
+			v = (v-0.5)*(1+e.Contrast) + 0.5
+			v = math.Max(0, math.Min(1, v))
+			lut[ch][i] = uint8(math.Round(v * 255))
+		}
+	}
+
+	dst := toRGBA(img, true)
+
+	for i := 0; i < len(dst.Pix); i += 4 {
+		dst.Pix[i] = lut[0][dst.Pix[i]]
+		dst.Pix[i+1] = lut[1][dst.Pix[i+1]]
+		dst.Pix[i+2] = lut[2][dst.Pix[i+2]]
+	}
+
+	return dst
+}
@@ -0,0 +1,104 @@
+package imgedit_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/photo-blog/pkg/imgedit"
+)
+
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 100, A: 255})
+		}
+	}
+
+	return img
+}
+
+func TestEdits_Apply(t *testing.T) {
+	src := testImage(200, 100)
+
+	e := imgedit.Edits{
+		Rotate: 90,
+		Crop:   &imgedit.Crop{Left: 0.25, Top: 0.5, Width: 0.5, Height: 0.5},
+	}
+
+	w, h := e.Size(200, 100)
+	assert.Equal(t, int64(50), w)
+	assert.Equal(t, int64(100), h)
+
+	res, err := e.Apply(src)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 50, 100), res.Bounds())
+
+	// Top left pixel of result comes from bottom left quadrant of rotated source.
+	r, g, _, _ := res.At(0, 0).RGBA()
+	assert.Equal(t, uint32(100), r>>8)
+	assert.Equal(t, uint32(74), g>>8)
+
+	// Source is not modified.
+	assert.Equal(t, color.RGBA{R: 0, G: 0, B: 100, A: 255}, src.At(0, 0))
+}
+
+func TestEdits_Apply_straighten(t *testing.T) {
+	e := imgedit.Edits{Straighten: 5}
+
+	w, h := e.Size(400, 300)
+	assert.Less(t, w, int64(400))
+	assert.InDelta(t, 400.0/300.0, float64(w)/float64(h), 0.01)
+
+	res, err := e.Apply(testImage(400, 300))
+	require.NoError(t, err)
+	assert.Equal(t, int(w), res.Bounds().Dx())
+	assert.Equal(t, int(h), res.Bounds().Dy())
+
+	// Corners must not be transparent.
+	_, _, _, a := res.At(0, 0).RGBA()
+	assert.Equal(t, uint32(0xffff), a)
+}
+
+func TestEdits_Apply_tone(t *testing.T) {
+	e := imgedit.Edits{Exposure: 1}
+
+	res, err := e.Apply(testImage(10, 10))
+	require.NoError(t, err)
+
+	assert.Equal(t, color.RGBA{R: 10, G: 10, B: 200, A: 255}, res.At(5, 5))
+}
+
+func TestEdits_IsZero(t *testing.T) {
+	var e *imgedit.Edits
+
+	assert.True(t, e.IsZero())
+	assert.True(t, (&imgedit.Edits{Rotate: 360, Crop: &imgedit.Crop{Width: 1, Height: 1}}).IsZero())
+	assert.False(t, (&imgedit.Edits{Contrast: 0.1}).IsZero())
+}
+
+func TestWatermark_Draw(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 400, 300))
+
+	w := imgedit.Watermark{Text: "© photo-blog", Position: imgedit.BottomRight, Opacity: 1, Scale: 0.5}
+	require.NoError(t, w.Draw(img))
+
+	drawn := 0
+
+	for y := 150; y < 300; y++ {
+		for x := 200; x < 400; x++ {
+			if r, _, _, _ := img.At(x, y).RGBA(); r > 0 {
+				drawn++
+			}
+		}
+	}
+
+	assert.Positive(t, drawn)
+
+	r, _, _, _ := img.At(10, 10).RGBA()
+	assert.Zero(t, r)
+}
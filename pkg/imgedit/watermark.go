@@ -0,0 +1,177 @@
+package imgedit
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg" // JPEG watermarks.
+	_ "image/png"  // PNG watermarks.
+	"math"
+	"os"
+	"sync"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Watermark positions.
+const (
+	TopLeft     = "top-left"
+	TopRight    = "top-right"
+	BottomLeft  = "bottom-left"
+	BottomRight = "bottom-right"
+	Center      = "center"
+)
+
+// Watermark is a text or an image drawn over the picture.
+type Watermark struct {
+	Text     string  `json:"text,omitempty" title:"Text" description:"Text of watermark, ignored if image is set."`
+	Image    string  `json:"image,omitempty" title:"Image" description:"Path to PNG or JPEG watermark image."`
+	Position string  `json:"position,omitempty" title:"Position" enum:"[\"bottom-right\",\"bottom-left\",\"top-right\",\"top-left\",\"center\"]" default:"bottom-right"`
+	Opacity  float64 `json:"opacity,omitempty" title:"Opacity" minimum:"0" maximum:"1" default:"0.5"`
+	Scale    float64 `json:"scale,omitempty" title:"Scale" description:"Width of watermark as a fraction of picture width." minimum:"0" maximum:"1" default:"0.2"`
+}
+
+var (
+	boldFont     *opentype.Font
+	boldFontErr  error
+	boldFontOnce sync.Once
+)
+
+// Draw renders watermark over the image.
+func (w *Watermark) Draw(dst draw.Image) error {
+	if w == nil || (w.Text == "" && w.Image == "") {
+		return nil
+	}
+
+	opacity := w.Opacity
+	if opacity <= 0 || opacity > 1 {
+		opacity = 0.5
+	}
+
+	scale := w.Scale
+	if scale <= 0 || scale > 1 {
+		scale = 0.2
+	}
+
+	b := dst.Bounds()
+	targetW := int(math.Round(float64(b.Dx()) * scale))
+
+	if targetW < 1 {
+		return nil
+	}
+
+	if w.Image != "" {
+		return w.drawImage(dst, targetW, opacity)
+	}
+
+	return w.drawText(dst, targetW, opacity)
+}
+
+func (w *Watermark) origin(b image.Rectangle, mw, mh int) image.Point {
+	margin := min(b.Dx(), b.Dy()) / 50
+
+	switch w.Position {
+	case TopLeft:
+		return image.Pt(b.Min.X+margin, b.Min.Y+margin)
+	case TopRight:
+		return image.Pt(b.Max.X-margin-mw, b.Min.Y+margin)
+	case BottomLeft:
+		return image.Pt(b.Min.X+margin, b.Max.Y-margin-mh)
+	case Center:
+		return image.Pt(b.Min.X+(b.Dx()-mw)/2, b.Min.Y+(b.Dy()-mh)/2)
+	default:
+		return image.Pt(b.Max.X-margin-mw, b.Max.Y-margin-mh)
+	}
+}
+
+func (w *Watermark) drawImage(dst draw.Image, targetW int, opacity float64) error {
+	f, err := os.Open(w.Image)
+	if err != nil {
+		return fmt.Errorf("open watermark: %w", err)
+	}
+	defer f.Close()
+
+	src, _, err := image.Decode(f)
+	if err != nil {
+		return fmt.Errorf("decode watermark: %w", err)
+	}
+
+	sb := src.Bounds()
+	if sb.Dx() == 0 || sb.Dy() == 0 {
+		return errors.New("empty watermark image")
+	}
+
+	targetH := int(math.Round(float64(targetW) * float64(sb.Dy()) / float64(sb.Dx())))
+	if targetH < 1 {
+		return nil
+	}
+
+	scaled := image.NewRGBA(image.Rect(0, 0, targetW, targetH))
+	xdraw.CatmullRom.Scale(scaled, scaled.Bounds(), src, sb, xdraw.Src, nil)
+
+	o := w.origin(dst.Bounds(), targetW, targetH)
+	mask := image.NewUniform(color.Alpha{A: uint8(math.Round(opacity * 255))})
+
+	draw.DrawMask(dst, image.Rectangle{Min: o, Max: o.Add(image.Pt(targetW, targetH))}, scaled, image.Point{}, mask, image.Point{}, draw.Over)
+
+	return nil
+}
+
+func (w *Watermark) drawText(dst draw.Image, targetW int, opacity float64) error {
+	boldFontOnce.Do(func() {
+		boldFont, boldFontErr = opentype.Parse(gobold.TTF)
+	})
+
+	if boldFontErr != nil {
+		return fmt.Errorf("parse font: %w", boldFontErr)
+	}
+
+	// Measure text at reference size to find size that fits target width.
+	const refSize = 100.0
+
+	face, err := opentype.NewFace(boldFont, &opentype.FaceOptions{Size: refSize, DPI: 72, Hinting: font.HintingNone})
+	if err != nil {
+		return fmt.Errorf("font face: %w", err)
+	}
+
+	refW := font.MeasureString(face, w.Text).Round()
+	_ = face.Close()
+
+	if refW == 0 {
+		return nil
+	}
+
+	size := refSize * float64(targetW) / float64(refW)
+
+	face, err = opentype.NewFace(boldFont, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return fmt.Errorf("font face: %w", err)
+	}
+	defer face.Close()
+
+	m := face.Metrics()
+	textW := font.MeasureString(face, w.Text).Round()
+	textH := (m.Ascent + m.Descent).Ceil()
+	o := w.origin(dst.Bounds(), textW, textH)
+
+	a := uint8(math.Round(opacity * 255))
+	shadow := max(1, textH/30)
+
+	d := font.Drawer{Dst: dst, Face: face}
+
+	d.Src = image.NewUniform(color.NRGBA{A: a / 2})
+	d.Dot = fixed.P(o.X+shadow, o.Y+m.Ascent.Ceil()+shadow)
+	d.DrawString(w.Text)
+
+	d.Src = image.NewUniform(color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: a})
+	d.Dot = fixed.P(o.X, o.Y+m.Ascent.Ceil())
+	d.DrawString(w.Text)
+
+	return nil
+}
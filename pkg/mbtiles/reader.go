@@ -0,0 +1,79 @@
+// Package mbtiles reads tiles from MBTiles SQLite archives.
+//
+// See https://github.com/mapbox/mbtiles-spec/blob/master/1.3/spec.md.
+package mbtiles
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+
+	_ "modernc.org/sqlite" // SQLite3 driver.
+)
+
+// ErrNotFound is returned for a missing tile.
+var ErrNotFound = errors.New("tile not found")
+
+// Reader reads tiles from archive.
+type Reader struct {
+	// Format is a tile format from metadata: pbf, jpg, png or webp.
+	Format string
+
+	db *sql.DB
+}
+
+// Open opens archive file in read-only mode.
+func Open(fn string) (*Reader, error) {
+	db, err := sql.Open("sqlite", "file:"+fn+"?mode=ro")
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Reader{db: db}
+
+	err = db.QueryRow("SELECT value FROM metadata WHERE name = 'format'").Scan(&r.Format)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.Join(fmt.Errorf("read metadata: %w", err), db.Close())
+	}
+
+	return r, nil
+}
+
+// Close closes database.
+func (r *Reader) Close() error {
+	return r.db.Close()
+}
+
+// Tile returns tile data, gzip compression of vector tiles is removed.
+func (r *Reader) Tile(ctx context.Context, z uint8, x, y uint32) ([]byte, error) {
+	// MBTiles use TMS scheme with Y axis going north.
+	row := (uint32(1) << z) - 1 - y
+
+	var data []byte
+
+	err := r.db.QueryRowContext(ctx,
+		"SELECT tile_data FROM tiles WHERE zoom_level = ? AND tile_column = ? AND tile_row = ?",
+		z, x, row).Scan(&data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	if len(data) > 2 && data[0] == 0x1f && data[1] == 0x8b {
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+
+		return io.ReadAll(gz)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,280 @@
+// Package mp4meta reads basic metadata from ISO BMFF (MP4/MOV/M4V) containers.
+package mp4meta
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Meta describes video container.
+type Meta struct {
+	CreatedAt time.Time
+	Duration  time.Duration
+	Width     int64
+	Height    int64
+
+	// HasLocation is true if Latitude, Longitude and Altitude are read from ISO 6709 location.
+	HasLocation bool
+	Latitude    float64
+	Longitude   float64
+	Altitude    float64
+}
+
+// ErrNoMovie is returned when container has no moov box.
+var ErrNoMovie = errors.New("moov box not found")
+
+var epoch1904 = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// ReadFile reads metadata from a file.
+func ReadFile(fn string) (Meta, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return Meta{}, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return Meta{}, err
+	}
+
+	return Read(f, fi.Size())
+}
+
+// Read reads metadata from container of size bytes.
+func Read(r io.ReaderAt, size int64) (Meta, error) {
+	m := Meta{}
+	found := false
+
+	err := walk(r, 0, size, func(typ string, offset, size int64) error {
+		if typ != "moov" {
+			return nil
+		}
+
+		found = true
+
+		return m.readMovie(r, offset, size)
+	})
+	if err != nil {
+		return m, err
+	}
+
+	if !found {
+		return m, ErrNoMovie
+	}
+
+	return m, nil
+}
+
+// walk iterates boxes within [offset, offset+size) and calls fn with payload bounds.
+func walk(r io.ReaderAt, offset, size int64, fn func(typ string, offset, size int64) error) error {
+	end := offset + size
+	hdr := make([]byte, 16)
+
+	for offset+8 <= end {
+		if _, err := r.ReadAt(hdr[:8], offset); err != nil {
+			return fmt.Errorf("read box header at %d: %w", offset, err)
+		}
+
+		boxSize := int64(binary.BigEndian.Uint32(hdr[:4]))
+		typ := string(hdr[4:8])
+		hdrSize := int64(8)
+
+		switch boxSize {
+		case 0:
+			boxSize = end - offset
+		case 1:
+			if _, err := r.ReadAt(hdr[8:16], offset+8); err != nil {
+				return fmt.Errorf("read large box size at %d: %w", offset, err)
+			}
+
+			boxSize = int64(binary.BigEndian.Uint64(hdr[8:16]))
+			hdrSize = 16
+		}
+
+		if boxSize < hdrSize || offset+boxSize > end {
+			return fmt.Errorf("malformed %q box at %d", typ, offset)
+		}
+
+		if err := fn(typ, offset+hdrSize, boxSize-hdrSize); err != nil {
+			return err
+		}
+
+		offset += boxSize
+	}
+
+	return nil
+}
+
+func (m *Meta) readMovie(r io.ReaderAt, offset, size int64) error {
+	return walk(r, offset, size, func(typ string, offset, size int64) error {
+		switch typ {
+		case "mvhd":
+			return m.readMovieHeader(r, offset, size)
+		case "trak":
+			return walk(r, offset, size, func(typ string, offset, size int64) error {
+				if typ == "tkhd" {
+					return m.readTrackHeader(r, offset, size)
+				}
+
+				return nil
+			})
+		case "udta":
+			return walk(r, offset, size, func(typ string, offset, size int64) error {
+				if typ == "\xa9xyz" {
+					return m.readLocation(r, offset, size)
+				}
+
+				return nil
+			})
+		}
+
+		return nil
+	})
+}
+
+func readPayload(r io.ReaderAt, offset, size, limit int64) ([]byte, error) {
+	if size > limit {
+		size = limit
+	}
+
+	b := make([]byte, size)
+	if size == 0 {
+		return b, nil
+	}
+
+	if _, err := r.ReadAt(b, offset); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func (m *Meta) readMovieHeader(r io.ReaderAt, offset, size int64) error {
+	b, err := readPayload(r, offset, size, 32)
+	if err != nil {
+		return fmt.Errorf("read mvhd: %w", err)
+	}
+
+	var (
+		created   uint64
+		timescale uint32
+		duration  uint64
+	)
+
+	if len(b) >= 32 && b[0] == 1 {
+		created = binary.BigEndian.Uint64(b[4:12])
+		timescale = binary.BigEndian.Uint32(b[20:24])
+		duration = binary.BigEndian.Uint64(b[24:32])
+	} else if len(b) >= 20 {
+		created = uint64(binary.BigEndian.Uint32(b[4:8]))
+		timescale = binary.BigEndian.Uint32(b[12:16])
+		duration = uint64(binary.BigEndian.Uint32(b[16:20]))
+	} else {
+		return errors.New("mvhd is too short")
+	}
+
+	if created != 0 {
+		m.CreatedAt = epoch1904.Add(time.Duration(created) * time.Second)
+	}
+
+	if timescale != 0 {
+		m.Duration = time.Duration(float64(duration) / float64(timescale) * float64(time.Second))
+	}
+
+	return nil
+}
+
+func (m *Meta) readTrackHeader(r io.ReaderAt, offset, size int64) error {
+	b, err := readPayload(r, offset, size, 92)
+	if err != nil {
+		return fmt.Errorf("read tkhd: %w", err)
+	}
+
+	if len(b) == 0 {
+		return errors.New("tkhd is too short")
+	}
+
+	// Matrix and dimensions are at the end of the box, version 1 has 12 more bytes of wider times.
+	pos := 40
+	if b[0] == 1 {
+		pos = 52
+	}
+
+	if len(b) < pos+44 {
+		return errors.New("tkhd is too short")
+	}
+
+	w := int64(binary.BigEndian.Uint32(b[pos+36:pos+40]) >> 16)
+	h := int64(binary.BigEndian.Uint32(b[pos+40:pos+44]) >> 16)
+
+	// Audio tracks have zero dimensions.
+	if w == 0 || h == 0 {
+		return nil
+	}
+
+	// Matrix {a, b, u, c, d, v, x, y, w}, a == 0 means rotation by 90 or 270 degrees.
+	if binary.BigEndian.Uint32(b[pos:pos+4]) == 0 {
+		w, h = h, w
+	}
+
+	m.Width = w
+	m.Height = h
+
+	return nil
+}
+
+var iso6709 = regexp.MustCompile(`^([+-]\d+(?:\.\d+)?)([+-]\d+(?:\.\d+)?)([+-]\d+(?:\.\d+)?)?`)
+
+func (m *Meta) readLocation(r io.ReaderAt, offset, size int64) error {
+	b, err := readPayload(r, offset, size, 256)
+	if err != nil {
+		return fmt.Errorf("read location: %w", err)
+	}
+
+	// 2 bytes string length, 2 bytes language code.
+	if len(b) < 4 {
+		return nil
+	}
+
+	// Malformed location is treated as missing.
+	_ = m.ParseISO6709(string(b[4:]))
+
+	return nil
+}
+
+// ParseISO6709 parses location string, e.g. "+37.7749-122.4194+010.000/".
+func (m *Meta) ParseISO6709(s string) error {
+	matches := iso6709.FindStringSubmatch(s)
+	if matches == nil {
+		return fmt.Errorf("unexpected location: %q", s)
+	}
+
+	lat, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return err
+	}
+
+	lon, err := strconv.ParseFloat(matches[2], 64)
+	if err != nil {
+		return err
+	}
+
+	if matches[3] != "" {
+		if m.Altitude, err = strconv.ParseFloat(matches[3], 64); err != nil {
+			return err
+		}
+	}
+
+	m.Latitude = lat
+	m.Longitude = lon
+	m.HasLocation = true
+
+	return nil
+}
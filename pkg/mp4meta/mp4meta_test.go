@@ -0,0 +1,125 @@
+package mp4meta_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/photo-blog/pkg/mp4meta"
+)
+
+func box(typ string, payload ...[]byte) []byte {
+	b := bytes.NewBuffer(nil)
+	size := 8
+	for _, p := range payload {
+		size += len(p)
+	}
+
+	_ = binary.Write(b, binary.BigEndian, uint32(size))
+	b.WriteString(typ)
+
+	for _, p := range payload {
+		b.Write(p)
+	}
+
+	return b.Bytes()
+}
+
+func u32(v uint32) []byte {
+	return binary.BigEndian.AppendUint32(nil, v)
+}
+
+func tkhd(w, h uint32, rotated bool) []byte {
+	b := make([]byte, 84)
+	// Version 0, flags, times, track id, reserved, duration, reserved, layer, group, volume, reserved.
+	matrix := b[40:76]
+	if rotated {
+		copy(matrix[4:], u32(0x00010000))
+		copy(matrix[12:], u32(0xffff0000))
+	} else {
+		copy(matrix[0:], u32(0x00010000))
+		copy(matrix[16:], u32(0x00010000))
+	}
+
+	copy(matrix[32:], u32(0x40000000))
+	copy(b[76:], u32(w<<16))
+	copy(b[80:], u32(h<<16))
+
+	return box("tkhd", b)
+}
+
+func TestRead(t *testing.T) {
+	created := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+
+	mvhd := make([]byte, 100)
+	copy(mvhd[4:], u32(uint32(created.Sub(time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC))/time.Second)))
+	copy(mvhd[12:], u32(600))
+	copy(mvhd[16:], u32(600*12+300))
+
+	loc := []byte("+37.7749-122.4194+010.000/")
+	xyz := append(u32(uint32(len(loc))<<16|0x15c7), loc...)
+
+	data := append(box("ftyp", []byte("isom\x00\x00\x02\x00")),
+		box("moov",
+			box("mvhd", mvhd),
+			box("trak", tkhd(0, 0, false)),
+			box("trak", tkhd(1920, 1080, true)),
+			box("udta", box("\xa9xyz", xyz)),
+		)...,
+	)
+
+	m, err := mp4meta.Read(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	assert.Equal(t, created, m.CreatedAt)
+	assert.Equal(t, 12500*time.Millisecond, m.Duration)
+	assert.Equal(t, int64(1080), m.Width)
+	assert.Equal(t, int64(1920), m.Height)
+	assert.True(t, m.HasLocation)
+	assert.InDelta(t, 37.7749, m.Latitude, 1e-6)
+	assert.InDelta(t, -122.4194, m.Longitude, 1e-6)
+	assert.InDelta(t, 10.0, m.Altitude, 1e-6)
+}
+
+func TestRead_noMovie(t *testing.T) {
+	data := box("ftyp", []byte("isom"))
+
+	_, err := mp4meta.Read(bytes.NewReader(data), int64(len(data)))
+	require.ErrorIs(t, err, mp4meta.ErrNoMovie)
+}
+
+func TestRead_shortTrackHeader(t *testing.T) {
+	data := box("moov", box("trak", box("tkhd")))
+
+	_, err := mp4meta.Read(bytes.NewReader(data), int64(len(data)))
+	require.EqualError(t, err, "tkhd is too short")
+}
+
+func TestRead_badLocation(t *testing.T) {
+	loc := []byte("somewhere")
+	xyz := append(u32(uint32(len(loc))<<16|0x15c7), loc...)
+
+	data := box("moov",
+		box("trak", tkhd(1920, 1080, false)),
+		box("udta", box("\xa9xyz", xyz)),
+	)
+
+	m, err := mp4meta.Read(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1920), m.Width)
+	assert.False(t, m.HasLocation)
+}
+
+func FuzzRead(f *testing.F) {
+	f.Add(box("moov", box("trak", box("tkhd"))))
+	f.Add(box("moov", box("mvhd", u32(0)), box("udta", box("\xa9xyz", u32(0)))))
+	f.Add(box("moov", box("trak", tkhd(1920, 1080, true))))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, _ = mp4meta.Read(bytes.NewReader(data), int64(len(data)))
+	})
+}
@@ -0,0 +1,207 @@
+// Package notify delivers notifications by email and webhooks.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Notification describes an event worth attention.
+type Notification struct {
+	Event string    `json:"event"`
+	Title string    `json:"title"`
+	Text  string    `json:"text,omitempty"`
+	URL   string    `json:"url,omitempty"`
+	Time  time.Time `json:"time"`
+}
+
+// Channel delivers a batch of notifications.
+type Channel interface {
+	Send(ctx context.Context, notifications []Notification) error
+}
+
+// Subject returns a summary line for a batch of notifications.
+func Subject(notifications []Notification) string {
+	if len(notifications) == 1 {
+		return notifications[0].Title
+	}
+
+	return fmt.Sprintf("%d notifications", len(notifications))
+}
+
+// Text renders a batch of notifications as plain text.
+func Text(notifications []Notification) string {
+	b := strings.Builder{}
+
+	for i, n := range notifications {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+
+		b.WriteString(n.Time.Format("2006-01-02 15:04:05") + " " + headerLine(n.Title) + "\n")
+
+		if n.Text != "" {
+			b.WriteString(n.Text + "\n")
+		}
+
+		if n.URL != "" {
+			b.WriteString(n.URL + "\n")
+		}
+	}
+
+	return b.String()
+}
+
+// SMTP sends notifications by email.
+type SMTP struct {
+	Addr     string   `json:"addr" title:"SMTP server" description:"Address with port, for example smtp.example.com:587." example:"smtp.example.com:587"`
+	Username string   `json:"username,omitempty" title:"Username"`
+	Password string   `json:"password,omitempty" title:"Password"`
+	From     string   `json:"from" title:"From" description:"Sender email address."`
+	To       []string `json:"to,omitempty" title:"To" description:"Recipient email addresses."`
+	Prefix   string   `json:"prefix,omitempty" title:"Subject prefix" example:"[photos] "`
+}
+
+// Enabled is true when SMTP is configured.
+func (s SMTP) Enabled() bool {
+	return s.Addr != "" && s.From != "" && len(s.To) > 0
+}
+
+// Send implements Channel.
+func (s SMTP) Send(_ context.Context, notifications []Notification) error {
+	if !s.Enabled() {
+		return errors.New("smtp is not configured")
+	}
+
+	host := s.Addr
+	if i := strings.LastIndex(host, ":"); i > 0 {
+		host = host[:i]
+	}
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, host)
+	}
+
+	to := make([]string, 0, len(s.To))
+	for _, addr := range s.To {
+		to = append(to, headerLine(addr))
+	}
+
+	msg := bytes.NewBuffer(nil)
+	msg.WriteString("From: " + headerLine(s.From) + "\r\n")
+	msg.WriteString("To: " + strings.Join(to, ", ") + "\r\n")
+	msg.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", headerLine(s.Prefix+Subject(notifications))) + "\r\n")
+	msg.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(Text(notifications), "\n", "\r\n"))
+
+	return smtp.SendMail(s.Addr, auth, s.From, s.To, msg.Bytes())
+}
+
+// headerLine joins lines of a value, line breaks in mail header would inject extra headers.
+func headerLine(s string) string {
+	return strings.Join(strings.FieldsFunc(s, func(r rune) bool {
+		return r == '\r' || r == '\n'
+	}), " ")
+}
+
+// Webhook posts notifications as JSON.
+type Webhook struct {
+	URL    string       `json:"url" title:"Webhook URL" description:"Receives POST request with JSON body {\"notifications\":[...]}."`
+	Client *http.Client `json:"-"`
+}
+
+// Enabled is true when webhook is configured.
+func (w Webhook) Enabled() bool {
+	return w.URL != ""
+}
+
+// Send implements Channel.
+func (w Webhook) Send(ctx context.Context, notifications []Notification) error {
+	body, err := json.Marshal(struct {
+		Subject       string         `json:"subject"`
+		Notifications []Notification `json:"notifications"`
+	}{
+		Subject:       Subject(notifications),
+		Notifications: notifications,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	c := w.Client
+	if c == nil {
+		c = http.DefaultClient
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1000))
+
+		return fmt.Errorf("unexpected webhook response status %d: %s", resp.StatusCode, string(b))
+	}
+
+	return nil
+}
+
+// Digest accumulates notifications to send them in batches.
+type Digest struct {
+	mu      sync.Mutex
+	pending []Notification
+	since   time.Time
+}
+
+// Add queues a notification.
+func (d *Digest) Add(n Notification) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.pending) == 0 {
+		d.since = time.Now()
+	}
+
+	d.pending = append(d.pending, n)
+}
+
+// Due takes pending notifications if the oldest of them waits longer than interval.
+func (d *Digest) Due(interval time.Duration) []Notification {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.pending) == 0 || time.Since(d.since) < interval {
+		return nil
+	}
+
+	res := d.pending
+	d.pending = nil
+
+	return res
+}
@@ -0,0 +1,194 @@
+package notify_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/photo-blog/pkg/notify"
+)
+
+// smtpStandIn accepts a single mail and sends its data to a channel.
+func smtpStandIn(t *testing.T) (string, <-chan string) {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, l.Close())
+	})
+
+	mail := make(chan string, 1)
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(s string) {
+			_, _ = conn.Write([]byte(s + "\r\n"))
+		}
+
+		reply("220 localhost ESMTP")
+
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(cmd, "MAIL"), strings.HasPrefix(cmd, "RCPT"):
+				reply("250 OK")
+			case cmd == "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+
+				data := strings.Builder{}
+
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+
+					if l == ".\r\n" {
+						break
+					}
+
+					data.WriteString(l)
+				}
+
+				mail <- data.String()
+
+				reply("250 OK")
+			case cmd == "QUIT":
+				reply("221 Bye")
+
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	return l.Addr().String(), mail
+}
+
+func TestSMTP_Send(t *testing.T) {
+	addr, mail := smtpStandIn(t)
+
+	s := notify.SMTP{
+		Addr:   addr,
+		From:   "blog@example.com",
+		To:     []string{"admin@example.com"},
+		Prefix: "[photos] ",
+	}
+	require.True(t, s.Enabled())
+
+	tm := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, s.Send(context.Background(), []notify.Notification{
+		{Event: "message_pending", Title: "New message", Text: "Nice photo!", URL: "http://localhost/comments/inbox.html", Time: tm},
+		{Event: "collab_upload", Title: "File uploaded", Text: "IMG_0001.jpg to trip", Time: tm},
+	}))
+
+	select {
+	case m := <-mail:
+		assert.Contains(t, m, "Subject: [photos] 2 notifications\r\n")
+		assert.Contains(t, m, "To: admin@example.com\r\n")
+		assert.Contains(t, m, "2026-10-19 12:00:00 New message\r\nNice photo!\r\nhttp://localhost/comments/inbox.html\r\n")
+		assert.Contains(t, m, "2026-10-19 12:00:00 File uploaded\r\nIMG_0001.jpg to trip\r\n")
+	case <-time.After(5 * time.Second):
+		t.Fatal("mail not received")
+	}
+}
+
+func TestSMTP_Send_headerInjection(t *testing.T) {
+	addr, mail := smtpStandIn(t)
+
+	s := notify.SMTP{
+		Addr: addr,
+		From: "blog@example.com",
+		To:   []string{"admin@example.com"},
+	}
+
+	require.NoError(t, s.Send(context.Background(), []notify.Notification{
+		{Title: "New message from Mallory\r\nBcc: victim@example.com", Text: "Hi"},
+	}))
+
+	select {
+	case m := <-mail:
+		assert.Contains(t, m, "Subject: New message from Mallory Bcc: victim@example.com\r\n")
+		assert.NotContains(t, m, "\r\nBcc:")
+	case <-time.After(5 * time.Second):
+		t.Fatal("mail not received")
+	}
+
+	addr, mail = smtpStandIn(t)
+	s.Addr = addr
+
+	require.NoError(t, s.Send(context.Background(), []notify.Notification{{Title: "Сообщение"}}))
+
+	select {
+	case m := <-mail:
+		assert.Contains(t, m, "Subject: =?utf-8?q?")
+	case <-time.After(5 * time.Second):
+		t.Fatal("mail not received")
+	}
+}
+
+func TestWebhook_Send(t *testing.T) {
+	var received struct {
+		Subject       string                `json:"subject"`
+		Notifications []notify.Notification `json:"notifications"`
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer srv.Close()
+
+	w := notify.Webhook{URL: srv.URL}
+	require.NoError(t, w.Send(context.Background(), []notify.Notification{{Event: "job_failed", Title: "Job failed"}}))
+
+	assert.Equal(t, "Job failed", received.Subject)
+	require.Len(t, received.Notifications, 1)
+	assert.Equal(t, "job_failed", received.Notifications[0].Event)
+
+	w.URL = srv.URL + "/missing"
+	srv.Config.Handler = http.NotFoundHandler()
+	assert.EqualError(t, w.Send(context.Background(), []notify.Notification{{Title: "x"}}),
+		"unexpected webhook response status 404: 404 page not found\n")
+}
+
+func TestDigest_Due(t *testing.T) {
+	d := notify.Digest{}
+
+	assert.Nil(t, d.Due(0))
+
+	d.Add(notify.Notification{Title: "a"})
+	d.Add(notify.Notification{Title: "b"})
+
+	assert.Nil(t, d.Due(time.Hour))
+	assert.Len(t, d.Due(0), 2)
+	assert.Nil(t, d.Due(0))
+}
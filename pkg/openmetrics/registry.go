@@ -0,0 +1,247 @@
+// Package openmetrics collects application metrics in memory and exposes them in OpenMetrics text format.
+package openmetrics
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bool64/stats"
+)
+
+// ContentType is a media type of exposition.
+const ContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// Metric types.
+const (
+	typeCounter = "counter"
+	typeGauge   = "gauge"
+	typeSummary = "summary"
+)
+
+// SummarySuffix marks metric names that receive observations, such metrics are exposed as summaries with sum and count.
+const SummarySuffix = "_seconds"
+
+var _ stats.Tracker = &Registry{}
+
+type series struct {
+	labels string
+	value  float64
+	count  float64
+}
+
+type family struct {
+	typ    string
+	series map[string]*series
+}
+
+// Registry is a stats.Tracker that keeps metric values for exposition.
+//
+// Add collects counters (or summaries for names with SummarySuffix), Set collects gauges.
+type Registry struct {
+	// Namespace is an optional prefix of metric names.
+	Namespace string
+
+	// Upstream is an optional tracker that receives all values too.
+	Upstream stats.Tracker
+
+	mu         sync.Mutex
+	families   map[string]*family
+	collectors []func(ctx context.Context, s stats.Setter)
+}
+
+// NewRegistry creates metrics registry.
+func NewRegistry(namespace string, upstream stats.Tracker) *Registry {
+	return &Registry{
+		Namespace: namespace,
+		Upstream:  upstream,
+		families:  map[string]*family{},
+	}
+}
+
+// Add collects additional or observable value.
+func (r *Registry) Add(ctx context.Context, name string, increment float64, labelsAndValues ...string) {
+	if r.Upstream != nil {
+		r.Upstream.Add(ctx, name, increment, labelsAndValues...)
+	}
+
+	typ := typeCounter
+	if strings.HasSuffix(name, SummarySuffix) {
+		typ = typeSummary
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.series(strings.TrimSuffix(name, "_total"), typ, labelsAndValues)
+	s.value += increment
+	s.count++
+}
+
+// Set collects absolute value.
+func (r *Registry) Set(ctx context.Context, name string, absolute float64, labelsAndValues ...string) {
+	if r.Upstream != nil {
+		r.Upstream.Set(ctx, name, absolute, labelsAndValues...)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.series(name, typeGauge, labelsAndValues).value = absolute
+}
+
+// StatsTracker is a provider.
+func (r *Registry) StatsTracker() stats.Tracker {
+	return r
+}
+
+// Collect adds a callback to set gauges right before exposition, e.g. sizes of storages.
+func (r *Registry) Collect(fn func(ctx context.Context, s stats.Setter)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.collectors = append(r.collectors, fn)
+}
+
+func (r *Registry) series(name, typ string, labelsAndValues []string) *series {
+	if r.families == nil {
+		r.families = map[string]*family{}
+	}
+
+	name = sanitize(name)
+	if r.Namespace != "" {
+		name = r.Namespace + "_" + name
+	}
+
+	f := r.families[name]
+	if f == nil {
+		// Type is defined by the first value.
+		f = &family{typ: typ, series: map[string]*series{}}
+		r.families[name] = f
+	}
+
+	l := labels(labelsAndValues)
+
+	s := f.series[l]
+	if s == nil {
+		s = &series{labels: l}
+		f.series[l] = s
+	}
+
+	return s
+}
+
+// WriteTo renders metrics in OpenMetrics text format.
+func (r *Registry) WriteTo(ctx context.Context, w io.Writer) error {
+	r.mu.Lock()
+	collectors := append([]func(ctx context.Context, s stats.Setter){}, r.collectors...)
+	r.mu.Unlock()
+
+	for _, c := range collectors {
+		c(ctx, stats.SetterFunc(func(ctx context.Context, name string, absolute float64, labelsAndValues ...string) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+
+			r.series(name, typeGauge, labelsAndValues).value = absolute
+		}))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	bw := bufio.NewWriter(w)
+
+	for _, name := range names {
+		f := r.families[name]
+
+		keys := make([]string, 0, len(f.series))
+		for k := range f.series {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		_, _ = bw.WriteString("# TYPE " + name + " " + f.typ + "\n")
+
+		for _, k := range keys {
+			s := f.series[k]
+
+			switch f.typ {
+			case typeCounter:
+				writeSample(bw, name+"_total", s.labels, s.value)
+			case typeSummary:
+				writeSample(bw, name+"_sum", s.labels, s.value)
+				writeSample(bw, name+"_count", s.labels, s.count)
+			default:
+				writeSample(bw, name, s.labels, s.value)
+			}
+		}
+	}
+
+	_, _ = bw.WriteString("# EOF\n")
+
+	return bw.Flush()
+}
+
+// ServeHTTP exposes metrics.
+func (r *Registry) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", ContentType)
+
+	_ = r.WriteTo(req.Context(), rw)
+}
+
+func writeSample(w *bufio.Writer, name, labels string, value float64) {
+	_, _ = w.WriteString(name)
+
+	if labels != "" {
+		_, _ = w.WriteString("{" + labels + "}")
+	}
+
+	_, _ = w.WriteString(" " + strconv.FormatFloat(value, 'g', -1, 64) + "\n")
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labels renders label pairs sorted by name.
+func labels(labelsAndValues []string) string {
+	if len(labelsAndValues) < 2 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(labelsAndValues)/2)
+
+	for i := 0; i+1 < len(labelsAndValues); i += 2 {
+		pairs = append(pairs, sanitize(labelsAndValues[i])+`="`+escaper.Replace(labelsAndValues[i+1])+`"`)
+	}
+
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+// sanitize replaces characters that are not allowed in metric and label names.
+func sanitize(name string) string {
+	b := []byte(name)
+
+	for i, c := range b {
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c >= '0' && c <= '9' && i > 0 {
+			continue
+		}
+
+		b[i] = '_'
+	}
+
+	return string(b)
+}
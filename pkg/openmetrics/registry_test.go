@@ -0,0 +1,67 @@
+package openmetrics_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bool64/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/photo-blog/pkg/openmetrics"
+)
+
+func TestRegistry_WriteTo(t *testing.T) {
+	ctx := context.Background()
+	up := &stats.TrackerMock{}
+	r := openmetrics.NewRegistry("app", up)
+
+	r.Add(ctx, "search_images", 1)
+	r.Add(ctx, "search_images", 2)
+	r.Add(ctx, "thumb_requests_total", 1, "size", "300w", "result", "hit")
+	r.Add(ctx, "thumb_requests", 1, "result", "miss", "size", "300w")
+	r.Set(ctx, "dl_in_progress", 2)
+	r.Set(ctx, "dl_in_progress", 1)
+	r.Add(ctx, "index_step_seconds", 0.5, "step", "exif")
+	r.Add(ctx, "index_step_seconds", 1.5, "step", "exif")
+	r.Add(ctx, "weird-name.x", 1, "label", "quote \" and \\ slash\nnewline")
+
+	r.Collect(func(ctx context.Context, s stats.Setter) {
+		s.Set(ctx, "queue_depth", 7, "topic", "index_image")
+	})
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, r.WriteTo(ctx, buf))
+
+	assert.Equal(t, `# TYPE app_dl_in_progress gauge
+app_dl_in_progress 1
+# TYPE app_index_step_seconds summary
+app_index_step_seconds_sum{step="exif"} 2
+app_index_step_seconds_count{step="exif"} 2
+# TYPE app_queue_depth gauge
+app_queue_depth{topic="index_image"} 7
+# TYPE app_search_images counter
+app_search_images_total 3
+# TYPE app_thumb_requests counter
+app_thumb_requests_total{result="hit",size="300w"} 1
+app_thumb_requests_total{result="miss",size="300w"} 1
+# TYPE app_weird_name_x counter
+app_weird_name_x_total{label="quote \" and \\ slash\nnewline"} 1
+# EOF
+`, buf.String())
+
+	assert.Equal(t, 3.0, up.Value("search_images"))
+}
+
+func TestRegistry_ServeHTTP(t *testing.T) {
+	r := openmetrics.NewRegistry("", nil)
+	r.Add(context.Background(), "hits", 1)
+
+	rw := httptest.NewRecorder()
+	r.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, openmetrics.ContentType, rw.Header().Get("Content-Type"))
+	assert.Equal(t, "# TYPE hits counter\nhits_total 1\n# EOF\n", rw.Body.String())
+}
@@ -0,0 +1,327 @@
+// Package pmtiles reads tiles from PMTiles v3 archives.
+//
+// See https://github.com/protomaps/PMTiles/blob/main/spec/v3/spec.md.
+package pmtiles
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// Compression types.
+const (
+	CompressionUnknown = 0
+	CompressionNone    = 1
+	CompressionGzip    = 2
+)
+
+// Tile types.
+const (
+	TileTypeUnknown = 0
+	TileTypeMVT     = 1
+	TileTypePNG     = 2
+	TileTypeJPEG    = 3
+	TileTypeWebP    = 4
+	TileTypeAVIF    = 5
+)
+
+const (
+	headerSize = 127
+	maxDepth   = 4
+)
+
+// ErrNotFound is returned for a missing tile.
+var ErrNotFound = errors.New("tile not found")
+
+// Header describes archive.
+type Header struct {
+	RootOffset          uint64
+	RootLength          uint64
+	MetadataOffset      uint64
+	MetadataLength      uint64
+	LeafOffset          uint64
+	LeafLength          uint64
+	TileDataOffset      uint64
+	TileDataLength      uint64
+	InternalCompression uint8
+	TileCompression     uint8
+	TileType            uint8
+	MinZoom             uint8
+	MaxZoom             uint8
+}
+
+// Reader reads tiles from archive.
+type Reader struct {
+	Header Header
+
+	r    io.ReaderAt
+	root []entry
+	c    io.Closer
+}
+
+type entry struct {
+	TileID    uint64
+	Offset    uint64
+	Length    uint64
+	RunLength uint64
+}
+
+// Open opens archive file.
+func Open(fn string) (*Reader, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := NewReader(f)
+	if err != nil {
+		return nil, errors.Join(err, f.Close())
+	}
+
+	r.c = f
+
+	return r, nil
+}
+
+// NewReader creates archive reader.
+func NewReader(r io.ReaderAt) (*Reader, error) {
+	h := make([]byte, headerSize)
+	if _, err := r.ReadAt(h, 0); err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+
+	if string(h[0:7]) != "PMTiles" || h[7] != 3 {
+		return nil, errors.New("not a PMTiles v3 archive")
+	}
+
+	u := func(o int) uint64 { return binary.LittleEndian.Uint64(h[o : o+8]) }
+
+	res := &Reader{r: r}
+	res.Header = Header{
+		RootOffset:          u(8),
+		RootLength:          u(16),
+		MetadataOffset:      u(24),
+		MetadataLength:      u(32),
+		LeafOffset:          u(40),
+		LeafLength:          u(48),
+		TileDataOffset:      u(56),
+		TileDataLength:      u(64),
+		InternalCompression: h[97],
+		TileCompression:     h[98],
+		TileType:            h[99],
+		MinZoom:             h[100],
+		MaxZoom:             h[101],
+	}
+
+	root, err := res.readDirectory(res.Header.RootOffset, res.Header.RootLength)
+	if err != nil {
+		return nil, fmt.Errorf("read root directory: %w", err)
+	}
+
+	res.root = root
+
+	return res, nil
+}
+
+// Close closes underlying file.
+func (r *Reader) Close() error {
+	if r.c != nil {
+		return r.c.Close()
+	}
+
+	return nil
+}
+
+// Tile returns tile data, gzip compression is removed.
+func (r *Reader) Tile(z uint8, x, y uint32) ([]byte, error) {
+	if z < r.Header.MinZoom || z > r.Header.MaxZoom {
+		return nil, ErrNotFound
+	}
+
+	id := ZxyToID(z, x, y)
+	dir := r.root
+
+	for depth := 0; depth < maxDepth; depth++ {
+		e, ok := findEntry(dir, id)
+		if !ok {
+			return nil, ErrNotFound
+		}
+
+		if e.RunLength > 0 {
+			data, err := r.read(r.Header.TileDataOffset+e.Offset, e.Length)
+			if err != nil {
+				return nil, fmt.Errorf("read tile: %w", err)
+			}
+
+			return decompress(data, r.Header.TileCompression)
+		}
+
+		leaf, err := r.readDirectory(r.Header.LeafOffset+e.Offset, e.Length)
+		if err != nil {
+			return nil, fmt.Errorf("read leaf directory: %w", err)
+		}
+
+		dir = leaf
+	}
+
+	return nil, errors.New("directory is too deep")
+}
+
+func (r *Reader) read(offset, length uint64) ([]byte, error) {
+	b := make([]byte, length)
+	if _, err := r.r.ReadAt(b, int64(offset)); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func decompress(data []byte, compression uint8) ([]byte, error) {
+	switch compression {
+	case CompressionUnknown, CompressionNone:
+		// Some archives do not declare compression of gzipped vector tiles.
+		if len(data) < 2 || data[0] != 0x1f || data[1] != 0x8b {
+			return data, nil
+		}
+
+		fallthrough
+	case CompressionGzip:
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+
+		return io.ReadAll(gz)
+	default:
+		return nil, fmt.Errorf("unsupported compression: %d", compression)
+	}
+}
+
+func (r *Reader) readDirectory(offset, length uint64) ([]entry, error) {
+	data, err := r.read(offset, length)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err = decompress(data, r.Header.InternalCompression); err != nil {
+		return nil, err
+	}
+
+	return decodeDirectory(data)
+}
+
+func decodeDirectory(data []byte) ([]entry, error) {
+	br := bytes.NewReader(data)
+
+	n, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+
+	if n > uint64(len(data)) {
+		return nil, fmt.Errorf("malformed directory of %d entries", n)
+	}
+
+	entries := make([]entry, n)
+
+	var last uint64
+
+	for i := range entries {
+		v, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+
+		last += v
+		entries[i].TileID = last
+	}
+
+	for i := range entries {
+		if entries[i].RunLength, err = binary.ReadUvarint(br); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range entries {
+		if entries[i].Length, err = binary.ReadUvarint(br); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range entries {
+		v, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+
+		// Zero offset means the entry directly follows the previous one.
+		if v == 0 && i > 0 {
+			entries[i].Offset = entries[i-1].Offset + entries[i-1].Length
+		} else {
+			entries[i].Offset = v - 1
+		}
+	}
+
+	return entries, nil
+}
+
+func findEntry(entries []entry, id uint64) (entry, bool) {
+	i := sort.Search(len(entries), func(i int) bool { return entries[i].TileID > id }) - 1
+	if i < 0 {
+		return entry{}, false
+	}
+
+	e := entries[i]
+
+	// Leaf directory pointer covers all ids after its TileID.
+	if e.RunLength == 0 || id < e.TileID+e.RunLength {
+		return e, true
+	}
+
+	return entry{}, false
+}
+
+// ZxyToID converts tile coordinates to Hilbert tile ID.
+func ZxyToID(z uint8, x, y uint32) uint64 {
+	var acc uint64
+
+	for tz := uint8(0); tz < z; tz++ {
+		acc += (uint64(1) << tz) * (uint64(1) << tz)
+	}
+
+	n := uint64(1) << z
+	tx, ty := uint64(x), uint64(y)
+
+	var d uint64
+
+	for s := n / 2; s > 0; s /= 2 {
+		var rx, ry uint64
+
+		if tx&s > 0 {
+			rx = 1
+		}
+
+		if ty&s > 0 {
+			ry = 1
+		}
+
+		d += s * s * ((3 * rx) ^ ry)
+
+		if ry == 0 {
+			if rx == 1 {
+				tx = n - 1 - tx
+				ty = n - 1 - ty
+			}
+
+			tx, ty = ty, tx
+		}
+	}
+
+	return acc + d
+}
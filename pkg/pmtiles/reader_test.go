@@ -0,0 +1,96 @@
+package pmtiles_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/photo-blog/pkg/pmtiles"
+)
+
+func TestZxyToID(t *testing.T) {
+	assert.Equal(t, uint64(0), pmtiles.ZxyToID(0, 0, 0))
+	assert.Equal(t, uint64(1), pmtiles.ZxyToID(1, 0, 0))
+	assert.Equal(t, uint64(2), pmtiles.ZxyToID(1, 0, 1))
+	assert.Equal(t, uint64(3), pmtiles.ZxyToID(1, 1, 1))
+	assert.Equal(t, uint64(4), pmtiles.ZxyToID(1, 1, 0))
+	assert.Equal(t, uint64(5), pmtiles.ZxyToID(2, 0, 0))
+	assert.Equal(t, uint64(19078479), pmtiles.ZxyToID(12, 3423, 1763))
+}
+
+type dirEntry struct {
+	id, offset, length, runLength uint64
+}
+
+func directory(entries ...dirEntry) []byte {
+	b := binary.AppendUvarint(nil, uint64(len(entries)))
+
+	var last uint64
+	for _, e := range entries {
+		b = binary.AppendUvarint(b, e.id-last)
+		last = e.id
+	}
+
+	for _, e := range entries {
+		b = binary.AppendUvarint(b, e.runLength)
+	}
+
+	for _, e := range entries {
+		b = binary.AppendUvarint(b, e.length)
+	}
+
+	for _, e := range entries {
+		b = binary.AppendUvarint(b, e.offset+1)
+	}
+
+	return b
+}
+
+func TestReader_Tile(t *testing.T) {
+	tiles := []byte("z0tilez2tile")
+	leaf := directory(dirEntry{id: 5, offset: 6, length: 6, runLength: 2})
+	root := directory(
+		dirEntry{id: 0, offset: 0, length: 6, runLength: 1},
+		dirEntry{id: 5, offset: 0, length: uint64(len(leaf))},
+	)
+
+	h := make([]byte, 127)
+	copy(h, "PMTiles")
+	h[7] = 3
+
+	put := func(o int, v int) { binary.LittleEndian.PutUint64(h[o:], uint64(v)) }
+	put(8, 127)
+	put(16, len(root))
+	put(40, 127+len(root))
+	put(48, len(leaf))
+	put(56, 127+len(root)+len(leaf))
+	put(64, len(tiles))
+	h[97] = pmtiles.CompressionNone
+	h[98] = pmtiles.CompressionNone
+	h[99] = pmtiles.TileTypePNG
+	h[100] = 0
+	h[101] = 2
+
+	data := append(append(append(h, root...), leaf...), tiles...)
+
+	r, err := pmtiles.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, uint8(pmtiles.TileTypePNG), r.Header.TileType)
+
+	tile, err := r.Tile(0, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "z0tile", string(tile))
+
+	// Run length of 2 covers tiles 5 and 6.
+	tile, err = r.Tile(2, 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "z2tile", string(tile))
+
+	_, err = r.Tile(1, 0, 0)
+	require.ErrorIs(t, err, pmtiles.ErrNotFound)
+
+	_, err = r.Tile(2, 3, 3)
+	require.ErrorIs(t, err, pmtiles.ErrNotFound)
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/bool64/ctxd"
 	"github.com/bool64/sqluct"
+	"github.com/bool64/stats"
 )
 
 const (
@@ -143,7 +146,7 @@ func (c consumerOf[V]) consumeOnce(ctx context.Context, b *Broker, msg MessageOf
 	c.logger.Debug(ctx, "consumeOnce", "message", msg)
 
 	start := time.Now()
-	err := c.consume(ctx, msg.Payload.Val)
+	err := c.consume(context.WithValue(ctx, triesCtxKey{}, msg.Tries), msg.Payload.Val)
 	if err == nil {
 		msg.ProcessedAt = UnixTime(time.Now().Unix())
 
@@ -158,9 +161,17 @@ func (c consumerOf[V]) consumeOnce(ctx context.Context, b *Broker, msg MessageOf
 		msg.Error = err.Error()
 	}
 
-	msg.Elapsed += time.Since(start).Seconds()
+	elapsed := time.Since(start).Seconds()
+	msg.Elapsed += elapsed
 	msg.Tries++
 
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+
+	b.Stats.Add(ctx, "queue_consume_seconds", elapsed, "topic", msg.Topic, "result", result)
+
 	var er ErrRetryAfter
 	if errors.As(err, &er) {
 		msg.StartedAt = 0
@@ -168,6 +179,10 @@ func (c consumerOf[V]) consumeOnce(ctx context.Context, b *Broker, msg MessageOf
 		msg.ProcessedAt = 0
 	} else {
 		msg.ProcessedAt = UnixTime(time.Now().Unix())
+
+		if err != nil && b.OnDeadLetter != nil {
+			b.OnDeadLetter(ctx, msg.Topic, msg.Payload.Val, err)
+		}
 	}
 
 	if msg.ProcessedAt > 0 {
@@ -195,6 +210,15 @@ func (c consumerOf[V]) consumeOnce(ctx context.Context, b *Broker, msg MessageOf
 	}
 }
 
+type triesCtxKey struct{}
+
+// Tries returns number of previous attempts to consume current message.
+func Tries(ctx context.Context) int {
+	t, _ := ctx.Value(triesCtxKey{}).(int)
+
+	return t
+}
+
 func AddConsumer[V any](b *Broker, topic string, consume func(ctx context.Context, v V) error, options ...func(o *ConsumerOptions)) error {
 	if _, ok := b.pollTopic[topic]; ok {
 		return fmt.Errorf("consumer for topic %s already exists", topic)
@@ -229,6 +253,12 @@ func AddConsumer[V any](b *Broker, topic string, consume func(ctx context.Contex
 type Broker struct {
 	Logger ctxd.Logger
 
+	// OnDeadLetter is called when message processing failed and message is archived without retry.
+	OnDeadLetter func(ctx context.Context, topic string, payload any, err error)
+
+	// Stats receives consumption time by topic and result.
+	Stats stats.Tracker
+
 	st  *sqluct.Storage
 	r   *Message
 	ref *sqluct.Referencer
@@ -247,6 +277,7 @@ func (e ErrRetryAfter) Error() string {
 func NewBroker(storage *sqluct.Storage) *Broker {
 	b := &Broker{
 		Logger:     ctxd.NoOpLogger{},
+		Stats:      stats.NoOp{},
 		st:         storage,
 		r:          &Message{},
 		ref:        storage.MakeReferencer(),
@@ -320,6 +351,52 @@ func (b *Broker) Publish(ctx context.Context, topic string, payloadValue any, op
 	return nil
 }
 
+// TopicDepth is a number of unprocessed messages in a topic.
+type TopicDepth struct {
+	Topic   string `db:"topic" json:"topic"`
+	Pending int    `db:"pending" json:"pending"`
+	Running int    `db:"running" json:"running"`
+}
+
+// Depth returns numbers of unprocessed messages by topic.
+//
+// Topics with consumers are always listed, so that drained topics report zero.
+func (b *Broker) Depth(ctx context.Context) ([]TopicDepth, error) {
+	var res []TopicDepth
+
+	q := b.st.QueryBuilder().
+		Select(
+			b.ref.Col(&b.r.Topic),
+			b.ref.Fmt("SUM(%s = 0) AS pending", &b.r.StartedAt),
+			b.ref.Fmt("SUM(%s > 0) AS running", &b.r.StartedAt),
+		).
+		From(messageTable).
+		Where(b.ref.Fmt("%s = 0", &b.r.ProcessedAt)).
+		GroupBy(b.ref.Col(&b.r.Topic)).
+		OrderBy(b.ref.Col(&b.r.Topic))
+
+	if err := b.st.Select(ctx, q, &res); err != nil {
+		return nil, fmt.Errorf("count messages: %w", err)
+	}
+
+	listed := make(map[string]bool, len(res))
+	for _, d := range res {
+		listed[d.Topic] = true
+	}
+
+	for topic := range b.pollTopic {
+		if !listed[topic] {
+			res = append(res, TopicDepth{Topic: topic})
+		}
+	}
+
+	slices.SortFunc(res, func(a, b TopicDepth) int {
+		return strings.Compare(a.Topic, b.Topic)
+	})
+
+	return res, nil
+}
+
 func (b *Broker) poll() {
 	for <-b.pollAgain {
 		b.Logger.Debug(context.Background(), "poll again")
@@ -0,0 +1,109 @@
+// Package spamscore estimates spam likelihood of short user messages with local heuristics.
+package spamscore
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Heuristic scores messages with simple content rules, it does not need network or training data.
+type Heuristic struct {
+	// BlockedWords are case-insensitive substrings that mark a message as spam.
+	BlockedWords []string
+	// MaxLinks is a number of links tolerated in a message, default 1.
+	MaxLinks int
+}
+
+// Result explains a score.
+type Result struct {
+	// Score is from 0 (clean) to 1 (spam).
+	Score   float64
+	Reasons []string
+}
+
+func (r *Result) add(score float64, reason string) {
+	r.Score += score
+	r.Reasons = append(r.Reasons, reason)
+}
+
+var (
+	linkRe    = regexp.MustCompile(`(?i)(https?://|www\.)\S+`)
+	markupRe  = regexp.MustCompile(`(?i)(<a\s+href|\[url[=\]]|\[link[=\]])`)
+	contactRe = regexp.MustCompile(`(?i)(telegram|whatsapp|t\.me/|wa\.me/|\+\d[\d\s-]{9,})`)
+)
+
+// Score evaluates message text and author name.
+func (h Heuristic) Score(text, name string) Result {
+	res := Result{}
+	lower := strings.ToLower(text + " " + name)
+
+	for _, w := range h.BlockedWords {
+		w = strings.TrimSpace(strings.ToLower(w))
+		if w != "" && strings.Contains(lower, w) {
+			res.add(1, "blocked word: "+w)
+
+			break
+		}
+	}
+
+	maxLinks := h.MaxLinks
+	if maxLinks == 0 {
+		maxLinks = 1
+	}
+
+	links := len(linkRe.FindAllString(text, -1))
+	if links > maxLinks {
+		res.add(0.3+0.1*float64(links-maxLinks), "too many links")
+	} else if links > 0 && len([]rune(strings.TrimSpace(linkRe.ReplaceAllString(text, "")))) < 20 {
+		res.add(0.4, "link without text")
+	}
+
+	if linkRe.MatchString(name) {
+		res.add(0.6, "link in name")
+	}
+
+	if markupRe.MatchString(text) {
+		res.add(0.5, "link markup")
+	}
+
+	if contactRe.MatchString(text) {
+		res.add(0.3, "contact solicitation")
+	}
+
+	var (
+		letters, upper, run int
+		prev                rune
+	)
+
+	for _, r := range text {
+		if r == prev {
+			run++
+		} else {
+			run = 1
+			prev = r
+		}
+
+		if run == 10 {
+			res.add(0.3, "repeated characters")
+		}
+
+		if unicode.IsLetter(r) {
+			letters++
+
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+
+	if letters >= 20 && float64(upper)/float64(letters) > 0.7 {
+		res.add(0.3, "shouting")
+	}
+
+	if res.Score > 1 {
+		res.Score = 1
+	}
+
+	return res
+}
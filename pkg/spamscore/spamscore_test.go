@@ -0,0 +1,36 @@
+package spamscore_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vearutop/photo-blog/pkg/spamscore"
+)
+
+func TestHeuristic_Score(t *testing.T) {
+	h := spamscore.Heuristic{BlockedWords: []string{"casino"}}
+
+	res := h.Score("Beautiful light on the second photo, where was it taken?", "Anna")
+	assert.Equal(t, 0.0, res.Score)
+	assert.Empty(t, res.Reasons)
+
+	res = h.Score("Nice, more photos from this trail at https://example.com/trail", "Bob")
+	assert.Equal(t, 0.0, res.Score)
+
+	res = h.Score("Best online CASINO bonus", "")
+	assert.Equal(t, 1.0, res.Score)
+	assert.Equal(t, []string{"blocked word: casino"}, res.Reasons)
+
+	res = h.Score("https://a.example https://b.example https://c.example", "")
+	assert.InDelta(t, 0.5, res.Score, 1e-9)
+
+	res = h.Score("great", "https://seo.example")
+	assert.InDelta(t, 0.6, res.Score, 1e-9)
+
+	res = h.Score(`[url=https://x.example]cheap[/url] write me in telegram`, "")
+	assert.InDelta(t, 0.8, res.Score, 1e-9)
+	assert.Contains(t, res.Reasons, "link markup")
+
+	res = h.Score("WOW THIS IS SO AMAZING I LOVE IT!!!!!!!!!!!!", "")
+	assert.Equal(t, []string{"repeated characters", "shouting"}, res.Reasons)
+}
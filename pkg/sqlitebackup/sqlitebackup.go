@@ -0,0 +1,245 @@
+// Package sqlitebackup makes consistent copies of live SQLite databases and rotates them.
+package sqlitebackup
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // SQLite3 driver.
+)
+
+const (
+	setLayout = "20060102-150405"
+	tmpSuffix = ".tmp"
+	ext       = ".sqlite"
+)
+
+// File is a database copy in a backup set.
+type File struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+// Set is a group of database copies made at once.
+type Set struct {
+	Name  string    `json:"name"`
+	Time  time.Time `json:"time"`
+	Files []File    `json:"files"`
+	Size  int64     `json:"size"`
+}
+
+// Path returns path to a database copy in the set.
+func (s Set) Path(dir, name string) string {
+	return filepath.Join(dir, s.Name, name+ext)
+}
+
+// VacuumInto writes a consistent copy of a live database to a new file.
+func VacuumInto(ctx context.Context, db *sql.DB, fn string) error {
+	if _, err := db.ExecContext(ctx, "VACUUM INTO ?", fn); err != nil {
+		return fmt.Errorf("vacuum into %s: %w", fn, err)
+	}
+
+	return nil
+}
+
+// Create makes a new backup set in dir with copies of databases by name.
+//
+// Set directory appears only when all copies are complete.
+func Create(ctx context.Context, dir string, t time.Time, dbs map[string]*sql.DB) (Set, error) {
+	s := Set{
+		Name: t.UTC().Format(setLayout),
+		Time: t.UTC().Truncate(time.Second),
+	}
+
+	tmp := filepath.Join(dir, s.Name+tmpSuffix)
+
+	if err := os.RemoveAll(tmp); err != nil {
+		return s, err
+	}
+
+	if err := os.MkdirAll(tmp, 0o700); err != nil {
+		return s, err
+	}
+
+	names := make([]string, 0, len(dbs))
+	for name := range dbs {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		fn := filepath.Join(tmp, name+ext)
+
+		if err := VacuumInto(ctx, dbs[name], fn); err != nil {
+			return s, errors.Join(err, os.RemoveAll(tmp))
+		}
+
+		st, err := os.Stat(fn)
+		if err != nil {
+			return s, errors.Join(err, os.RemoveAll(tmp))
+		}
+
+		s.Files = append(s.Files, File{Name: name, Size: st.Size()})
+		s.Size += st.Size()
+	}
+
+	if err := os.Rename(tmp, filepath.Join(dir, s.Name)); err != nil {
+		return s, errors.Join(err, os.RemoveAll(tmp))
+	}
+
+	return s, nil
+}
+
+// List returns complete backup sets in dir, newest first.
+func List(dir string) ([]Set, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var sets []Set
+
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+
+		t, err := time.Parse(setLayout, e.Name())
+		if err != nil {
+			continue
+		}
+
+		s := Set{Name: e.Name(), Time: t}
+
+		files, err := os.ReadDir(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, f := range files {
+			if f.IsDir() || !strings.HasSuffix(f.Name(), ext) {
+				continue
+			}
+
+			fi, err := f.Info()
+			if err != nil {
+				return nil, err
+			}
+
+			s.Files = append(s.Files, File{Name: strings.TrimSuffix(f.Name(), ext), Size: fi.Size()})
+			s.Size += fi.Size()
+		}
+
+		sets = append(sets, s)
+	}
+
+	sort.Slice(sets, func(i, j int) bool {
+		return sets[i].Time.After(sets[j].Time)
+	})
+
+	return sets, nil
+}
+
+// Prune removes all but keep newest sets and leftovers of incomplete sets, returns names of removed sets.
+func Prune(dir string, keep int) ([]string, error) {
+	sets, err := List(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+
+	if len(sets) > keep {
+		for _, s := range sets[keep:] {
+			if err := os.RemoveAll(filepath.Join(dir, s.Name)); err != nil {
+				return removed, err
+			}
+
+			removed = append(removed, s.Name)
+		}
+	}
+
+	tmp, err := filepath.Glob(filepath.Join(dir, "*"+tmpSuffix))
+	if err != nil {
+		return removed, err
+	}
+
+	for _, fn := range tmp {
+		if err := os.RemoveAll(fn); err != nil {
+			return removed, err
+		}
+	}
+
+	return removed, nil
+}
+
+// Check is a result of database copy verification.
+type Check struct {
+	Name   string `json:"name"`
+	Tables int    `json:"tables"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Verify opens a database copy read-only and checks its integrity.
+func Verify(ctx context.Context, fn string) (tables int, err error) {
+	if _, err := os.Stat(fn); err != nil {
+		return 0, err
+	}
+
+	db, err := sql.Open("sqlite", "file:"+fn+"?mode=ro")
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		err = errors.Join(err, db.Close())
+	}()
+
+	var res string
+	if err := db.QueryRowContext(ctx, "PRAGMA integrity_check").Scan(&res); err != nil {
+		return 0, fmt.Errorf("integrity check: %w", err)
+	}
+
+	if res != "ok" {
+		return 0, errors.New("integrity check: " + res)
+	}
+
+	if err := db.QueryRowContext(ctx, "SELECT count(*) FROM sqlite_master WHERE type = 'table'").Scan(&tables); err != nil {
+		return 0, fmt.Errorf("count tables: %w", err)
+	}
+
+	return tables, nil
+}
+
+// VerifySet checks all database copies of a set.
+func VerifySet(ctx context.Context, dir string, s Set) ([]Check, bool) {
+	ok := true
+	checks := make([]Check, 0, len(s.Files))
+
+	for _, f := range s.Files {
+		c := Check{Name: f.Name}
+
+		tables, err := Verify(ctx, s.Path(dir, f.Name))
+		if err != nil {
+			c.Error = err.Error()
+			ok = false
+		}
+
+		c.Tables = tables
+		checks = append(checks, c)
+	}
+
+	return checks, ok
+}
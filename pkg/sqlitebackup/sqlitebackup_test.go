@@ -0,0 +1,93 @@
+package sqlitebackup_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/photo-blog/pkg/sqlitebackup"
+)
+
+func openDB(t *testing.T, fn string) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", fn)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+
+	_, err = db.Exec("CREATE TABLE item (id INTEGER PRIMARY KEY, name TEXT); INSERT INTO item (name) VALUES ('a'), ('b')")
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestCreate(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	dbs := map[string]*sql.DB{
+		"db":    openDB(t, filepath.Join(t.TempDir(), "db.sqlite")),
+		"stats": openDB(t, filepath.Join(t.TempDir(), "stats.sqlite")),
+	}
+
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		s, err := sqlitebackup.Create(ctx, dir, start.Add(time.Duration(i)*time.Hour), dbs)
+		require.NoError(t, err)
+		require.Len(t, s.Files, 2)
+		assert.Equal(t, "db", s.Files[0].Name)
+		assert.Positive(t, s.Size)
+	}
+
+	// Leftover of an interrupted backup.
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "20240101-000000.tmp"), 0o700))
+
+	sets, err := sqlitebackup.List(dir)
+	require.NoError(t, err)
+	require.Len(t, sets, 3)
+	assert.Equal(t, "20240102-050405", sets[0].Name)
+	assert.Equal(t, start.Add(2*time.Hour), sets[0].Time)
+	assert.Equal(t, []sqlitebackup.File{{Name: "db", Size: sets[0].Files[0].Size}, {Name: "stats", Size: sets[0].Files[1].Size}}, sets[0].Files)
+
+	removed, err := sqlitebackup.Prune(dir, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"20240102-030405"}, removed)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	checks, ok := sqlitebackup.VerifySet(ctx, dir, sets[0])
+	assert.True(t, ok)
+	assert.Equal(t, []sqlitebackup.Check{{Name: "db", Tables: 1}, {Name: "stats", Tables: 1}}, checks)
+
+	restored, err := sql.Open("sqlite", sets[0].Path(dir, "db"))
+	require.NoError(t, err)
+
+	var cnt int
+	require.NoError(t, restored.QueryRow("SELECT count(*) FROM item").Scan(&cnt))
+	require.NoError(t, restored.Close())
+	assert.Equal(t, 2, cnt)
+
+	require.NoError(t, os.WriteFile(sets[0].Path(dir, "stats"), []byte("garbage"), 0o600))
+
+	checks, ok = sqlitebackup.VerifySet(ctx, dir, sets[0])
+	assert.False(t, ok)
+	assert.Empty(t, checks[0].Error)
+	assert.NotEmpty(t, checks[1].Error)
+}
+
+func TestList_missing(t *testing.T) {
+	sets, err := sqlitebackup.List(filepath.Join(t.TempDir(), "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, sets)
+}
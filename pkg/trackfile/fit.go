@@ -0,0 +1,251 @@
+package trackfile
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+// FIT protocol constants, see https://developer.garmin.com/fit/protocol/.
+const (
+	fitMesgRecord = 20
+
+	fitFieldTimestamp        = 253
+	fitFieldPositionLat      = 0
+	fitFieldPositionLong     = 1
+	fitFieldAltitude         = 2
+	fitFieldEnhancedAltitude = 78
+)
+
+// fitEpoch is 1989-12-31T00:00:00Z.
+var fitEpoch = time.Date(1989, 12, 31, 0, 0, 0, 0, time.UTC)
+
+type fitField struct {
+	num  byte
+	size byte
+}
+
+type fitDefinition struct {
+	global    uint16
+	order     binary.ByteOrder
+	fields    []fitField
+	devFields int // Total size of developer fields.
+}
+
+type fitRecord struct {
+	ts             uint32
+	hasTS          bool
+	lat, lon       int32
+	hasLat, hasLon bool
+	alt            float64
+	hasAlt         bool
+	hasEnhancedAlt bool
+}
+
+// ParseFIT reads positions from record messages of Garmin FIT activity file.
+func ParseFIT(r io.Reader) (*gpx.GPX, error) {
+	br := bufio.NewReader(r)
+
+	hdr := make([]byte, 12)
+	if _, err := io.ReadFull(br, hdr); err != nil {
+		return nil, fmt.Errorf("read FIT header: %w", err)
+	}
+
+	if string(hdr[8:12]) != ".FIT" {
+		return nil, errors.New("not a FIT file")
+	}
+
+	if hdr[0] > 12 {
+		if _, err := br.Discard(int(hdr[0]) - 12); err != nil {
+			return nil, fmt.Errorf("read FIT header: %w", err)
+		}
+	}
+
+	dataSize := binary.LittleEndian.Uint32(hdr[4:8])
+	data := make([]byte, dataSize)
+
+	if _, err := io.ReadFull(br, data); err != nil {
+		return nil, fmt.Errorf("read FIT data: %w", err)
+	}
+
+	seg := gpx.GPXTrackSegment{}
+
+	if err := decodeFIT(data, func(rec fitRecord) {
+		if !rec.hasLat || !rec.hasLon || rec.lat == math.MaxInt32 || rec.lon == math.MaxInt32 {
+			return
+		}
+
+		p := newPoint(semicircles(rec.lat), semicircles(rec.lon))
+
+		if rec.hasTS {
+			p.Timestamp = fitEpoch.Add(time.Duration(rec.ts) * time.Second)
+		}
+
+		if rec.hasAlt {
+			p.Elevation.SetValue(rec.alt)
+		}
+
+		seg.Points = append(seg.Points, p)
+	}); err != nil {
+		return nil, err
+	}
+
+	g := newGPX("FIT")
+
+	if len(seg.Points) > 0 {
+		g.Tracks = append(g.Tracks, gpx.GPXTrack{Segments: []gpx.GPXTrackSegment{seg}})
+	}
+
+	return g, nil
+}
+
+func semicircles(v int32) float64 {
+	return float64(v) * 180 / (1 << 31)
+}
+
+func decodeFIT(data []byte, onRecord func(rec fitRecord)) error {
+	var (
+		defs   [16]*fitDefinition
+		lastTS uint32
+		pos    int
+	)
+
+	need := func(n int) error {
+		if pos+n > len(data) {
+			return io.ErrUnexpectedEOF
+		}
+
+		return nil
+	}
+
+	for pos < len(data) {
+		h := data[pos]
+		pos++
+
+		var (
+			local      byte
+			compressed bool
+			offset     uint32
+		)
+
+		switch {
+		case h&0x80 != 0: // Compressed timestamp header.
+			compressed = true
+			local = (h >> 5) & 0x03
+			offset = uint32(h & 0x1f)
+		case h&0x40 != 0: // Definition message.
+			local = h & 0x0f
+
+			if err := need(5); err != nil {
+				return err
+			}
+
+			d := &fitDefinition{order: binary.LittleEndian}
+			if data[pos+1] == 1 {
+				d.order = binary.BigEndian
+			}
+
+			d.global = d.order.Uint16(data[pos+2 : pos+4])
+			n := int(data[pos+4])
+			pos += 5
+
+			if err := need(n * 3); err != nil {
+				return err
+			}
+
+			for i := 0; i < n; i++ {
+				d.fields = append(d.fields, fitField{num: data[pos], size: data[pos+1]})
+				pos += 3
+			}
+
+			if h&0x20 != 0 { // Developer fields.
+				if err := need(1); err != nil {
+					return err
+				}
+
+				n := int(data[pos])
+				pos++
+
+				if err := need(n * 3); err != nil {
+					return err
+				}
+
+				for i := 0; i < n; i++ {
+					d.devFields += int(data[pos+1])
+					pos += 3
+				}
+			}
+
+			defs[local] = d
+
+			continue
+		default:
+			local = h & 0x0f
+		}
+
+		d := defs[local]
+		if d == nil {
+			return fmt.Errorf("missing FIT definition for local message %d at %d", local, pos-1)
+		}
+
+		rec := fitRecord{}
+
+		if compressed {
+			ts := (lastTS &^ 0x1f) + offset
+			if offset < lastTS&0x1f {
+				ts += 0x20
+			}
+
+			lastTS = ts
+			rec.ts, rec.hasTS = ts, true
+		}
+
+		for _, f := range d.fields {
+			if err := need(int(f.size)); err != nil {
+				return err
+			}
+
+			v := data[pos : pos+int(f.size)]
+			pos += int(f.size)
+
+			switch {
+			case f.num == fitFieldTimestamp && f.size == 4:
+				lastTS = d.order.Uint32(v)
+				rec.ts, rec.hasTS = lastTS, true
+			case d.global != fitMesgRecord:
+			case f.num == fitFieldPositionLat && f.size == 4:
+				rec.lat = int32(d.order.Uint32(v))
+				rec.hasLat = true
+			case f.num == fitFieldPositionLong && f.size == 4:
+				rec.lon = int32(d.order.Uint32(v))
+				rec.hasLon = true
+			case f.num == fitFieldAltitude && f.size == 2 && !rec.hasEnhancedAlt:
+				if a := d.order.Uint16(v); a != math.MaxUint16 {
+					rec.alt, rec.hasAlt = float64(a)/5-500, true
+				}
+			case f.num == fitFieldEnhancedAltitude && f.size == 4:
+				if a := d.order.Uint32(v); a != math.MaxUint32 {
+					rec.alt, rec.hasAlt, rec.hasEnhancedAlt = float64(a)/5-500, true, true
+				}
+			}
+		}
+
+		if err := need(d.devFields); err != nil {
+			return err
+		}
+
+		pos += d.devFields
+
+		if d.global == fitMesgRecord {
+			onRecord(rec)
+		}
+	}
+
+	return nil
+}
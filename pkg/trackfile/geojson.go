@@ -0,0 +1,158 @@
+package trackfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+type geoJSONProperties struct {
+	Name string `json:"name"`
+
+	// CoordTimes are point timestamps, an array of arrays for MultiLineString.
+	CoordTimes json.RawMessage `json:"coordTimes"`
+}
+
+// geoJSONObject is a union of GeoJSON object types.
+type geoJSONObject struct {
+	Type        string             `json:"type"`
+	Coordinates json.RawMessage    `json:"coordinates"`
+	Geometry    *geoJSONObject     `json:"geometry"`
+	Geometries  []geoJSONObject    `json:"geometries"`
+	Features    []geoJSONObject    `json:"features"`
+	Properties  *geoJSONProperties `json:"properties"`
+}
+
+// ParseGeoJSON reads line strings as tracks and points as waypoints.
+//
+// Point timestamps are taken from "coordTimes" feature property if available.
+func ParseGeoJSON(r io.Reader) (*gpx.GPX, error) {
+	var o geoJSONObject
+
+	if err := json.NewDecoder(r).Decode(&o); err != nil {
+		return nil, fmt.Errorf("decode GeoJSON: %w", err)
+	}
+
+	g := newGPX("GeoJSON")
+
+	if err := addGeoJSON(g, o, geoJSONProperties{}); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+func addGeoJSON(g *gpx.GPX, o geoJSONObject, props geoJSONProperties) error {
+	switch o.Type {
+	case "FeatureCollection":
+		for _, f := range o.Features {
+			if err := addGeoJSON(g, f, props); err != nil {
+				return err
+			}
+		}
+	case "Feature":
+		if o.Properties != nil {
+			props = *o.Properties
+		}
+
+		if o.Geometry != nil {
+			return addGeoJSON(g, *o.Geometry, props)
+		}
+	case "GeometryCollection":
+		for _, c := range o.Geometries {
+			if err := addGeoJSON(g, c, props); err != nil {
+				return err
+			}
+		}
+	case "Point":
+		var c []float64
+		if err := json.Unmarshal(o.Coordinates, &c); err != nil {
+			return fmt.Errorf("decode Point: %w", err)
+		}
+
+		if p, ok := geoJSONPoint(c, ""); ok {
+			p.Name = props.Name
+			g.AppendWaypoint(&p)
+		}
+	case "LineString":
+		var (
+			c     [][]float64
+			times []string
+		)
+
+		if err := json.Unmarshal(o.Coordinates, &c); err != nil {
+			return fmt.Errorf("decode LineString: %w", err)
+		}
+
+		_ = json.Unmarshal(props.CoordTimes, &times)
+
+		g.Tracks = append(g.Tracks, gpx.GPXTrack{
+			Name:     props.Name,
+			Segments: []gpx.GPXTrackSegment{geoJSONSegment(c, times)},
+		})
+	case "MultiLineString":
+		var (
+			c     [][][]float64
+			times [][]string
+		)
+
+		if err := json.Unmarshal(o.Coordinates, &c); err != nil {
+			return fmt.Errorf("decode MultiLineString: %w", err)
+		}
+
+		_ = json.Unmarshal(props.CoordTimes, &times)
+
+		t := gpx.GPXTrack{Name: props.Name}
+
+		for i, line := range c {
+			var lt []string
+			if i < len(times) {
+				lt = times[i]
+			}
+
+			t.Segments = append(t.Segments, geoJSONSegment(line, lt))
+		}
+
+		g.Tracks = append(g.Tracks, t)
+	}
+
+	return nil
+}
+
+func geoJSONSegment(coords [][]float64, times []string) gpx.GPXTrackSegment {
+	seg := gpx.GPXTrackSegment{}
+
+	for i, c := range coords {
+		var ts string
+		if i < len(times) {
+			ts = times[i]
+		}
+
+		if p, ok := geoJSONPoint(c, ts); ok {
+			seg.Points = append(seg.Points, p)
+		}
+	}
+
+	return seg
+}
+
+func geoJSONPoint(c []float64, ts string) (gpx.GPXPoint, bool) {
+	if len(c) < 2 {
+		return gpx.GPXPoint{}, false
+	}
+
+	p := newPoint(c[1], c[0])
+
+	if len(c) > 2 {
+		p.Elevation.SetValue(c[2])
+	}
+
+	if t, err := time.Parse(time.RFC3339, ts); err == nil {
+		p.Timestamp = t
+	}
+
+	return p, true
+}
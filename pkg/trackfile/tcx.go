@@ -0,0 +1,100 @@
+package trackfile
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+type tcxTrackpoint struct {
+	Time     string   `xml:"Time"`
+	Lat      *float64 `xml:"Position>LatitudeDegrees"`
+	Lon      *float64 `xml:"Position>LongitudeDegrees"`
+	Altitude *float64 `xml:"AltitudeMeters"`
+}
+
+type tcxTrack struct {
+	Points []tcxTrackpoint `xml:"Trackpoint"`
+}
+
+type tcxDatabase struct {
+	Activities []struct {
+		ID   string `xml:"Id"`
+		Laps []struct {
+			Tracks []tcxTrack `xml:"Track"`
+		} `xml:"Lap"`
+	} `xml:"Activities>Activity"`
+	Courses []struct {
+		Name   string     `xml:"Name"`
+		Tracks []tcxTrack `xml:"Track"`
+	} `xml:"Courses>Course"`
+}
+
+// ParseTCX reads activities and courses of Garmin Training Center XML.
+func ParseTCX(r io.Reader) (*gpx.GPX, error) {
+	var db tcxDatabase
+
+	if err := xml.NewDecoder(r).Decode(&db); err != nil {
+		return nil, fmt.Errorf("decode TCX: %w", err)
+	}
+
+	g := newGPX("TCX")
+
+	for _, a := range db.Activities {
+		t := gpx.GPXTrack{Name: a.ID}
+
+		for _, l := range a.Laps {
+			t.Segments = append(t.Segments, tcxSegments(l.Tracks)...)
+		}
+
+		if len(t.Segments) > 0 {
+			g.Tracks = append(g.Tracks, t)
+		}
+	}
+
+	for _, c := range db.Courses {
+		t := gpx.GPXTrack{Name: c.Name, Segments: tcxSegments(c.Tracks)}
+
+		if len(t.Segments) > 0 {
+			g.Tracks = append(g.Tracks, t)
+		}
+	}
+
+	return g, nil
+}
+
+func tcxSegments(tracks []tcxTrack) []gpx.GPXTrackSegment {
+	var res []gpx.GPXTrackSegment
+
+	for _, tr := range tracks {
+		seg := gpx.GPXTrackSegment{}
+
+		for _, tp := range tr.Points {
+			// Trackpoints without position are recorded with indoor sensors.
+			if tp.Lat == nil || tp.Lon == nil {
+				continue
+			}
+
+			p := newPoint(*tp.Lat, *tp.Lon)
+
+			if tp.Altitude != nil {
+				p.Elevation.SetValue(*tp.Altitude)
+			}
+
+			if ts, err := time.Parse(time.RFC3339, tp.Time); err == nil {
+				p.Timestamp = ts
+			}
+
+			seg.Points = append(seg.Points, p)
+		}
+
+		if len(seg.Points) > 0 {
+			res = append(res, seg)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,81 @@
+// Package trackfile reads GPS tracks of different formats as GPX.
+package trackfile
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+// Extensions lists supported track file extensions.
+var Extensions = []string{".gpx", ".fit", ".tcx", ".geojson"}
+
+// Supported checks if file name has a supported track extension.
+func Supported(fn string) bool {
+	ext := strings.ToLower(path.Ext(fn))
+
+	for _, e := range Extensions {
+		if e == ext {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseFile reads track file, format is detected by extension.
+func ParseFile(fn string) (*gpx.GPX, error) {
+	ext := strings.ToLower(path.Ext(fn))
+
+	if ext == ".gpx" {
+		return gpx.ParseFile(fn)
+	}
+
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Parse(f, ext)
+}
+
+// Parse reads track data of a format defined by file extension.
+func Parse(r io.Reader, ext string) (*gpx.GPX, error) {
+	switch strings.ToLower(ext) {
+	case ".gpx":
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return gpx.ParseBytes(data)
+	case ".fit":
+		return ParseFIT(r)
+	case ".tcx":
+		return ParseTCX(r)
+	case ".geojson":
+		return ParseGeoJSON(r)
+	default:
+		return nil, fmt.Errorf("unsupported track format: %q", ext)
+	}
+}
+
+func newPoint(lat, lon float64) gpx.GPXPoint {
+	p := gpx.GPXPoint{}
+	p.Latitude = lat
+	p.Longitude = lon
+
+	return p
+}
+
+func newGPX(creator string) *gpx.GPX {
+	return &gpx.GPX{
+		Version: "1.1",
+		Creator: creator,
+	}
+}
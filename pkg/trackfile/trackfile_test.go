@@ -0,0 +1,141 @@
+package trackfile_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/photo-blog/pkg/trackfile"
+)
+
+func semicircles(deg float64) uint32 {
+	return uint32(int32(deg * (1 << 31) / 180))
+}
+
+func fitFile() []byte {
+	data := bytes.NewBuffer(nil)
+	le := binary.LittleEndian
+
+	// Definition of local message 0: record with timestamp, position and altitude.
+	data.Write([]byte{0x40, 0, 0, 20, 0, 4, 253, 4, 0x86, 0, 4, 0x85, 1, 4, 0x85, 2, 2, 0x84})
+
+	// Definition of local message 1: record without timestamp, with developer field.
+	data.Write([]byte{0x61, 0, 0, 20, 0, 2, 0, 4, 0x85, 1, 4, 0x85, 1, 0, 2, 0})
+
+	ts := uint32(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC).Sub(time.Date(1989, 12, 31, 0, 0, 0, 0, time.UTC)).Seconds())
+
+	data.WriteByte(0x00)
+	_ = binary.Write(data, le, ts)
+	_ = binary.Write(data, le, semicircles(52.5))
+	_ = binary.Write(data, le, semicircles(13.4))
+	_ = binary.Write(data, le, uint16((35+500)*5))
+
+	// Compressed timestamp, 3 seconds later.
+	data.WriteByte(0x80 | 1<<5 | byte((ts+3)&0x1f))
+	_ = binary.Write(data, le, semicircles(52.6))
+	_ = binary.Write(data, le, semicircles(13.5))
+	data.Write([]byte{0xAA, 0xBB})
+
+	// Record without position.
+	data.WriteByte(0x00)
+	_ = binary.Write(data, le, ts+10)
+	_ = binary.Write(data, le, uint32(0x7fffffff))
+	_ = binary.Write(data, le, uint32(0x7fffffff))
+	_ = binary.Write(data, le, uint16(0xffff))
+
+	hdr := []byte{14, 0x10, 0, 0, 0, 0, 0, 0, '.', 'F', 'I', 'T', 0, 0}
+	le.PutUint32(hdr[4:8], uint32(data.Len()))
+
+	return append(append(hdr, data.Bytes()...), 0, 0)
+}
+
+func TestParseFIT(t *testing.T) {
+	g, err := trackfile.Parse(bytes.NewReader(fitFile()), ".FIT")
+	require.NoError(t, err)
+	require.Len(t, g.Tracks, 1)
+	require.Len(t, g.Tracks[0].Segments, 1)
+
+	pp := g.Tracks[0].Segments[0].Points
+	require.Len(t, pp, 2)
+
+	assert.InDelta(t, 52.5, pp[0].Latitude, 1e-6)
+	assert.InDelta(t, 13.4, pp[0].Longitude, 1e-6)
+	assert.Equal(t, 35.0, pp[0].Elevation.Value())
+	assert.Equal(t, "2024-05-01T10:00:00Z", pp[0].Timestamp.Format(time.RFC3339))
+
+	assert.InDelta(t, 52.6, pp[1].Latitude, 1e-6)
+	assert.False(t, pp[1].Elevation.NotNull())
+	assert.Equal(t, "2024-05-01T10:00:03Z", pp[1].Timestamp.Format(time.RFC3339))
+}
+
+func TestParseTCX(t *testing.T) {
+	g, err := trackfile.Parse(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2">
+ <Activities>
+  <Activity Sport="Running">
+   <Id>2024-05-01T10:00:00Z</Id>
+   <Lap StartTime="2024-05-01T10:00:00Z">
+    <Track>
+     <Trackpoint>
+      <Time>2024-05-01T10:00:00Z</Time>
+      <Position><LatitudeDegrees>52.5</LatitudeDegrees><LongitudeDegrees>13.4</LongitudeDegrees></Position>
+      <AltitudeMeters>35.5</AltitudeMeters>
+     </Trackpoint>
+     <Trackpoint>
+      <Time>2024-05-01T10:00:05Z</Time>
+      <HeartRateBpm><Value>120</Value></HeartRateBpm>
+     </Trackpoint>
+     <Trackpoint>
+      <Time>2024-05-01T10:00:10Z</Time>
+      <Position><LatitudeDegrees>52.6</LatitudeDegrees><LongitudeDegrees>13.5</LongitudeDegrees></Position>
+     </Trackpoint>
+    </Track>
+   </Lap>
+  </Activity>
+ </Activities>
+</TrainingCenterDatabase>`), ".tcx")
+	require.NoError(t, err)
+	require.Len(t, g.Tracks, 1)
+	assert.Equal(t, "2024-05-01T10:00:00Z", g.Tracks[0].Name)
+
+	pp := g.Tracks[0].Segments[0].Points
+	require.Len(t, pp, 2)
+	assert.Equal(t, 35.5, pp[0].Elevation.Value())
+	assert.Equal(t, 13.5, pp[1].Longitude)
+	assert.Equal(t, "2024-05-01T10:00:10Z", pp[1].Timestamp.Format(time.RFC3339))
+}
+
+func TestParseGeoJSON(t *testing.T) {
+	g, err := trackfile.Parse(strings.NewReader(`{"type":"FeatureCollection","features":[
+{"type":"Feature","properties":{"name":"Walk","coordTimes":["2024-05-01T10:00:00Z","2024-05-01T10:01:00Z"]},
+ "geometry":{"type":"LineString","coordinates":[[13.4,52.5,30],[13.5,52.6]]}},
+{"type":"Feature","properties":{"name":"Ride"},
+ "geometry":{"type":"MultiLineString","coordinates":[[[1,2],[3,4]],[[5,6]]]}},
+{"type":"Feature","properties":{"name":"Cafe"},"geometry":{"type":"Point","coordinates":[13.45,52.55]}}
+]}`), ".geojson")
+	require.NoError(t, err)
+	require.Len(t, g.Tracks, 2)
+
+	assert.Equal(t, "Walk", g.Tracks[0].Name)
+	pp := g.Tracks[0].Segments[0].Points
+	require.Len(t, pp, 2)
+	assert.Equal(t, 52.5, pp[0].Latitude)
+	assert.Equal(t, 30.0, pp[0].Elevation.Value())
+	assert.Equal(t, "2024-05-01T10:01:00Z", pp[1].Timestamp.Format(time.RFC3339))
+
+	require.Len(t, g.Tracks[1].Segments, 2)
+	assert.Equal(t, 6.0, g.Tracks[1].Segments[1].Points[0].Latitude)
+
+	require.Len(t, g.Waypoints, 1)
+	assert.Equal(t, "Cafe", g.Waypoints[0].Name)
+}
+
+func TestSupported(t *testing.T) {
+	assert.True(t, trackfile.Supported("/a/Track.FIT"))
+	assert.True(t, trackfile.Supported("b.geojson"))
+	assert.False(t, trackfile.Supported("c.jpg"))
+}
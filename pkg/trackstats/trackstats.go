@@ -0,0 +1,160 @@
+// Package trackstats calculates statistics and elevation profile of GPS tracks.
+package trackstats
+
+import (
+	"math"
+	"time"
+
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+// Stats describes a track or its part.
+type Stats struct {
+	Distance      float64 `json:"distance,omitempty" description:"Distance in meters."`
+	MovingTime    float64 `json:"movingTime,omitempty" description:"Moving time in seconds."`
+	ElevationGain float64 `json:"elevationGain,omitempty" description:"Total ascent in meters."`
+	ElevationLoss float64 `json:"elevationLoss,omitempty" description:"Total descent in meters."`
+	MaxSpeed      float64 `json:"maxSpeed,omitempty" description:"Max speed in m/s."`
+}
+
+// Day is a part of track recorded on a single day.
+type Day struct {
+	Stats
+
+	Date  string    `json:"date"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Compute calculates track statistics.
+func Compute(g *gpx.GPX) Stats {
+	md := g.MovingData()
+	ud := g.UphillDownhill()
+
+	return Stats{
+		Distance:      math.Round(g.Length2D()),
+		MovingTime:    math.Round(md.MovingTime),
+		ElevationGain: math.Round(ud.Uphill),
+		ElevationLoss: math.Round(ud.Downhill),
+		MaxSpeed:      math.Round(md.MaxSpeed*100) / 100,
+	}
+}
+
+// SolarOffset approximates time zone offset by longitude.
+func SolarOffset(lon float64) time.Duration {
+	return time.Duration(math.Round(lon/15)) * time.Hour
+}
+
+// Days splits timed track points by days and calculates statistics for each day.
+//
+// Local date is approximated with SolarOffset of the first point.
+func Days(g *gpx.GPX) []Day {
+	var (
+		offset   *time.Duration
+		days     []Day
+		dayTrack []*gpx.GPX
+	)
+
+	for _, t := range g.Tracks {
+		for _, s := range t.Segments {
+			var (
+				date string
+				seg  *gpx.GPXTrackSegment
+			)
+
+			for _, p := range s.Points {
+				if p.Timestamp.IsZero() {
+					continue
+				}
+
+				if offset == nil {
+					o := SolarOffset(p.Longitude)
+					offset = &o
+				}
+
+				d := p.Timestamp.Add(*offset).Format(time.DateOnly)
+
+				if d != date || seg == nil {
+					date = d
+
+					if len(days) == 0 || days[len(days)-1].Date != d {
+						days = append(days, Day{Date: d, Start: p.Timestamp})
+						dayTrack = append(dayTrack, &gpx.GPX{Tracks: []gpx.GPXTrack{{}}})
+					}
+
+					dt := &dayTrack[len(dayTrack)-1].Tracks[0]
+					dt.Segments = append(dt.Segments, gpx.GPXTrackSegment{})
+					seg = &dt.Segments[len(dt.Segments)-1]
+				}
+
+				seg.Points = append(seg.Points, p)
+				days[len(days)-1].End = p.Timestamp
+			}
+		}
+	}
+
+	for i, dg := range dayTrack {
+		days[i].Stats = Compute(dg)
+	}
+
+	return days
+}
+
+// ProfilePoint is a point of elevation profile.
+type ProfilePoint struct {
+	Distance  float64   `json:"d" description:"Distance from start in meters."`
+	Elevation *float64  `json:"e,omitempty" description:"Elevation in meters."`
+	Lat       float64   `json:"lat"`
+	Lon       float64   `json:"lon"`
+	Time      time.Time `json:"t,omitzero"`
+}
+
+// Profile returns elevation profile with at most maxPoints evenly distributed by distance.
+func Profile(g *gpx.GPX, maxPoints int) []ProfilePoint {
+	var (
+		all  []ProfilePoint
+		dist float64
+	)
+
+	for _, t := range g.Tracks {
+		for _, s := range t.Segments {
+			for i, p := range s.Points {
+				if i > 0 {
+					prev := s.Points[i-1]
+					dist += gpx.Distance2D(prev.Latitude, prev.Longitude, p.Latitude, p.Longitude, true)
+				}
+
+				pp := ProfilePoint{
+					Distance: math.Round(dist),
+					Lat:      p.Latitude,
+					Lon:      p.Longitude,
+					Time:     p.Timestamp,
+				}
+
+				if p.Elevation.NotNull() {
+					e := math.Round(p.Elevation.Value()*10) / 10
+					pp.Elevation = &e
+				}
+
+				all = append(all, pp)
+			}
+		}
+	}
+
+	if maxPoints < 2 || len(all) <= maxPoints {
+		return all
+	}
+
+	res := make([]ProfilePoint, 0, maxPoints)
+	step := dist / float64(maxPoints-1)
+	next := 0.0
+
+	for i, p := range all {
+		if (p.Distance >= next && len(res) < maxPoints-1) || i == len(all)-1 {
+			res = append(res, p)
+			next = p.Distance + step
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,71 @@
+package trackstats_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkrajina/gpxgo/gpx"
+	"github.com/vearutop/photo-blog/pkg/trackstats"
+)
+
+func track() *gpx.GPX {
+	start := time.Date(2024, 5, 1, 21, 0, 0, 0, time.UTC)
+	seg := gpx.GPXTrackSegment{}
+
+	// Heading north at 13.4 E (UTC+1) for 4 hours with a point every minute, 100 m each.
+	for i := 0; i <= 240; i++ {
+		p := gpx.GPXPoint{}
+		p.Latitude = 52.5 + float64(i)*0.0009
+		p.Longitude = 13.4
+		p.Timestamp = start.Add(time.Duration(i) * time.Minute)
+
+		// Climb first hour, descend the next one.
+		e := float64(i % 120)
+		if e > 60 {
+			e = 120 - e
+		}
+
+		p.Elevation.SetValue(100 + e)
+
+		seg.Points = append(seg.Points, p)
+	}
+
+	return &gpx.GPX{Tracks: []gpx.GPXTrack{{Segments: []gpx.GPXTrackSegment{seg}}}}
+}
+
+func TestCompute(t *testing.T) {
+	s := trackstats.Compute(track())
+
+	assert.InDelta(t, 24000, s.Distance, 100)
+	assert.InDelta(t, 4*3600, s.MovingTime, 60)
+	assert.InDelta(t, 120, s.ElevationGain, 10)
+	assert.InDelta(t, 120, s.ElevationLoss, 10)
+	assert.InDelta(t, 100.0/60, s.MaxSpeed, 0.1)
+}
+
+func TestDays(t *testing.T) {
+	days := trackstats.Days(track())
+	require.Len(t, days, 2)
+
+	// Local midnight is at 23:00 UTC.
+	assert.Equal(t, "2024-05-01", days[0].Date)
+	assert.Equal(t, "2024-05-01T22:59:00Z", days[0].End.Format(time.RFC3339))
+	assert.InDelta(t, 11900, days[0].Distance, 100)
+
+	assert.Equal(t, "2024-05-02", days[1].Date)
+	assert.Equal(t, "2024-05-01T23:00:00Z", days[1].Start.Format(time.RFC3339))
+	assert.InDelta(t, 12000, days[1].Distance, 100)
+}
+
+func TestProfile(t *testing.T) {
+	p := trackstats.Profile(track(), 50)
+	require.Len(t, p, 49) // Points are sampled by distance, not index.
+
+	assert.Equal(t, 0.0, p[0].Distance)
+	assert.Equal(t, 100.0, *p[0].Elevation)
+	assert.InDelta(t, 24000, p[48].Distance, 100)
+
+	assert.Len(t, trackstats.Profile(track(), 0), 241)
+}
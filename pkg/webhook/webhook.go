@@ -0,0 +1,123 @@
+// Package webhook delivers signed JSON payloads over HTTP.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Request headers.
+const (
+	HeaderEvent     = "X-Photo-Blog-Event"
+	HeaderDelivery  = "X-Photo-Blog-Delivery"
+	HeaderTimestamp = "X-Photo-Blog-Timestamp"
+	HeaderSignature = "X-Photo-Blog-Signature"
+)
+
+// Sign returns signature of a payload in "sha256=<hex>" format.
+//
+// Signature is HMAC-SHA256 of timestamp, a dot and request body.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks signature and timestamp of a received request.
+//
+// Zero tolerance disables timestamp check.
+func Verify(secret string, r *http.Request, body []byte, tolerance time.Duration) error {
+	ts, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp: %w", err)
+	}
+
+	if tolerance > 0 {
+		if d := time.Since(time.Unix(ts, 0)); d > tolerance || d < -tolerance {
+			return errors.New("timestamp is out of tolerance")
+		}
+	}
+
+	if !hmac.Equal([]byte(Sign(secret, ts, body)), []byte(r.Header.Get(HeaderSignature))) {
+		return errors.New("signature mismatch")
+	}
+
+	return nil
+}
+
+// Delivery is a single webhook call.
+type Delivery struct {
+	URL    string
+	Secret string
+	Event  string
+	ID     string
+	Body   []byte
+}
+
+// StatusError is returned for unsuccessful response status.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e StatusError) Error() string {
+	return fmt.Sprintf("unexpected response status %d: %s", e.StatusCode, e.Body)
+}
+
+// Temporary is true if request may succeed on retry.
+func (e StatusError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode == http.StatusRequestTimeout ||
+		e.StatusCode >= http.StatusInternalServerError
+}
+
+// Send posts signed delivery, non-2xx response status is returned as StatusError.
+func Send(ctx context.Context, client *http.Client, d Delivery) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Body))
+	if err != nil {
+		return err
+	}
+
+	ts := time.Now().Unix()
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, d.Event)
+	req.Header.Set(HeaderDelivery, d.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+
+	if d.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(d.Secret, ts, d.Body))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		return StatusError{StatusCode: resp.StatusCode, Body: string(b)}
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/photo-blog/pkg/webhook"
+)
+
+func TestSign(t *testing.T) {
+	// echo -n '1700000000.{"a":1}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t, "sha256=49f24e537407743fa4a0242bb63b94b9a47ee99cbbe071ccd8a22550ae411686",
+		webhook.Sign("secret", 1700000000, []byte(`{"a":1}`)))
+}
+
+func TestSend(t *testing.T) {
+	var verifyErr error
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		assert.Equal(t, "album.created", r.Header.Get(webhook.HeaderEvent))
+		assert.Equal(t, "123", r.Header.Get(webhook.HeaderDelivery))
+		assert.Equal(t, `{"name":"foo"}`, string(body))
+
+		verifyErr = webhook.Verify("secret", r, body, time.Minute)
+
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("try later"))
+		}
+	}))
+	defer srv.Close()
+
+	d := webhook.Delivery{
+		URL:    srv.URL,
+		Secret: "secret",
+		Event:  "album.created",
+		ID:     "123",
+		Body:   []byte(`{"name":"foo"}`),
+	}
+
+	require.NoError(t, webhook.Send(context.Background(), nil, d))
+	require.NoError(t, verifyErr)
+
+	d.Secret = "other"
+	require.NoError(t, webhook.Send(context.Background(), nil, d))
+	assert.EqualError(t, verifyErr, "signature mismatch")
+
+	d.URL = srv.URL + "/fail"
+	err := webhook.Send(context.Background(), nil, d)
+
+	var se webhook.StatusError
+	require.True(t, errors.As(err, &se))
+	assert.Equal(t, http.StatusServiceUnavailable, se.StatusCode)
+	assert.Equal(t, "try later", se.Body)
+	assert.True(t, se.Temporary())
+}
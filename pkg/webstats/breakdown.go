@@ -0,0 +1,47 @@
+package webstats
+
+import (
+	"net/url"
+	"strings"
+)
+
+// DeviceClass returns a coarse device type by user agent: mobile, tablet or desktop.
+func DeviceClass(ua string) string {
+	switch {
+	case strings.Contains(ua, "iPad"), strings.Contains(ua, "Tablet"),
+		strings.Contains(ua, "Android") && !strings.Contains(ua, "Mobile"):
+		return "tablet"
+	case strings.Contains(ua, "Mobi"), strings.Contains(ua, "iPhone"):
+		return "mobile"
+	default:
+		return "desktop"
+	}
+}
+
+// ScreenClass returns screen width bucket aligned with common layout breakpoints.
+func ScreenClass(width int) string {
+	switch {
+	case width <= 0:
+		return "unknown"
+	case width < 576:
+		return "<576"
+	case width < 992:
+		return "576-991"
+	case width < 1400:
+		return "992-1399"
+	case width < 2560:
+		return "1400-2559"
+	default:
+		return "2560+"
+	}
+}
+
+// RefererDomain returns host of a referer URL without www. prefix.
+func RefererDomain(ref string) string {
+	u, err := url.Parse(ref)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
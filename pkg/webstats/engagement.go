@@ -0,0 +1,51 @@
+package webstats
+
+import "math"
+
+// Engagement weights.
+const (
+	maxDwellSeconds  = 60.0 // Longer views are likely idle tabs.
+	dwellUnitSeconds = 20.0 // Dwell time that doubles value of a viewer.
+	zoomWeight       = 2.0
+	favoriteWeight   = 5.0
+	exposureUnitMs   = 60_000.0
+)
+
+// Engagement describes visitor interest in an image.
+type Engagement struct {
+	Uniq       int     `json:"uniq" description:"Unique viewers."`
+	Views      int     `json:"views" description:"Focused views."`
+	ViewMs     int     `json:"view_ms" description:"Total focused view time in ms."`
+	Zooms      int     `json:"zooms" description:"Zoom-ins."`
+	Favorites  int     `json:"favorites" description:"Visitors that added image to favorites."`
+	ExposureMs int     `json:"exposure_ms" description:"Total time of thumbnail on screen in ms."`
+	Score      float64 `json:"score" description:"Engagement score, comparable between images."`
+}
+
+// CalcScore updates Score from counters.
+//
+// Each unique viewer is worth more with longer average dwell time, zoom-ins and favorites add value.
+// Sum is divided by square root of exposure in minutes, so that images that were visible longer on album pages
+// do not win just because they are placed first.
+func (e *Engagement) CalcScore() float64 {
+	views := e.Views
+	if views < e.Uniq {
+		views = e.Uniq
+	}
+
+	dwell := 0.0
+	if views > 0 {
+		dwell = math.Min(float64(e.ViewMs)/float64(views)/1000, maxDwellSeconds)
+	}
+
+	zooms := e.Zooms
+	if zooms > views {
+		zooms = views
+	}
+
+	value := float64(e.Uniq)*(1+dwell/dwellUnitSeconds) + zoomWeight*float64(zooms) + favoriteWeight*float64(e.Favorites)
+
+	e.Score = math.Round(100*value/math.Sqrt(1+float64(e.ExposureMs)/exposureUnitMs)) / 100
+
+	return e.Score
+}
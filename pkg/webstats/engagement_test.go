@@ -0,0 +1,33 @@
+package webstats_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vearutop/photo-blog/pkg/webstats"
+)
+
+func TestEngagement_CalcScore(t *testing.T) {
+	e := webstats.Engagement{}
+	assert.Equal(t, 0.0, e.CalcScore())
+
+	e = webstats.Engagement{Uniq: 10, Views: 20, ViewMs: 200_000}
+	assert.Equal(t, 15.0, e.CalcScore())
+	assert.Equal(t, 15.0, e.Score)
+
+	// Idle views are capped.
+	e = webstats.Engagement{Uniq: 10, Views: 10, ViewMs: 10_000_000}
+	assert.Equal(t, 40.0, e.CalcScore())
+
+	// Zooms and favorites add value.
+	e = webstats.Engagement{Uniq: 10, Views: 20, ViewMs: 200_000, Zooms: 5, Favorites: 2}
+	assert.Equal(t, 35.0, e.CalcScore())
+
+	// Longer exposure reduces score.
+	e = webstats.Engagement{Uniq: 10, Views: 20, ViewMs: 200_000, ExposureMs: 180_000}
+	assert.Equal(t, 7.5, e.CalcScore())
+
+	// Favorites count without focused views.
+	e = webstats.Engagement{Favorites: 1}
+	assert.Equal(t, 5.0, e.CalcScore())
+}
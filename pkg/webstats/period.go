@@ -0,0 +1,59 @@
+package webstats
+
+import "time"
+
+// Period is a reporting interval.
+type Period string
+
+// Reporting intervals.
+const (
+	Day   = Period("d")
+	Week  = Period("w")
+	Month = Period("m")
+)
+
+// Valid checks if period is known.
+func (p Period) Valid() bool {
+	return p == Day || p == Week || p == Month
+}
+
+// Start returns beginning of a period (UTC) that contains t, weeks start on Monday.
+func (p Period) Start(t time.Time) time.Time {
+	t = t.UTC().Truncate(24 * time.Hour)
+
+	switch p {
+	case Week:
+		wd := (int(t.Weekday()) + 6) % 7
+
+		return t.AddDate(0, 0, -wd)
+	case Month:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return t
+	}
+}
+
+// Next returns beginning of the following period.
+func (p Period) Next(t time.Time) time.Time {
+	t = p.Start(t)
+
+	switch p {
+	case Week:
+		return t.AddDate(0, 0, 7)
+	case Month:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+// Starts returns beginnings of all periods that intersect with [from, to].
+func (p Period) Starts(from, to time.Time) []time.Time {
+	var res []time.Time
+
+	for t := p.Start(from); !t.After(to); t = p.Next(t) {
+		res = append(res, t)
+	}
+
+	return res
+}
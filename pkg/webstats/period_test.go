@@ -0,0 +1,53 @@
+package webstats_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vearutop/photo-blog/pkg/webstats"
+)
+
+func TestPeriod_Start(t *testing.T) {
+	ts := time.Date(2024, 3, 14, 15, 9, 26, 0, time.UTC) // Thursday.
+
+	assert.Equal(t, time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC), webstats.Day.Start(ts))
+	assert.Equal(t, time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC), webstats.Week.Start(ts))
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), webstats.Month.Start(ts))
+
+	sunday := time.Date(2024, 3, 17, 23, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC), webstats.Week.Start(sunday))
+	assert.Equal(t, time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC), webstats.Week.Next(sunday))
+
+	assert.Equal(t, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), webstats.Month.Next(ts))
+	assert.Equal(t, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), webstats.Day.Next(ts))
+}
+
+func TestPeriod_Starts(t *testing.T) {
+	from := time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, []time.Time{
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+	}, webstats.Month.Starts(from, to))
+
+	assert.Len(t, webstats.Day.Starts(from, to), 42)
+	assert.False(t, webstats.Period("y").Valid())
+}
+
+func TestBreakdown(t *testing.T) {
+	assert.Equal(t, "mobile", webstats.DeviceClass("Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) Mobile/15E148"))
+	assert.Equal(t, "tablet", webstats.DeviceClass("Mozilla/5.0 (iPad; CPU OS 17_0 like Mac OS X)"))
+	assert.Equal(t, "tablet", webstats.DeviceClass("Mozilla/5.0 (Linux; Android 13; SM-X700) Safari/537.36"))
+	assert.Equal(t, "mobile", webstats.DeviceClass("Mozilla/5.0 (Linux; Android 13; Pixel 7) Mobile Safari/537.36"))
+	assert.Equal(t, "desktop", webstats.DeviceClass("Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) Safari/605.1.15"))
+
+	assert.Equal(t, "unknown", webstats.ScreenClass(0))
+	assert.Equal(t, "<576", webstats.ScreenClass(390))
+	assert.Equal(t, "1400-2559", webstats.ScreenClass(1920))
+
+	assert.Equal(t, "google.com", webstats.RefererDomain("https://www.google.com/search?q=x"))
+	assert.Equal(t, "", webstats.RefererDomain("android-app"))
+}
@@ -0,0 +1,77 @@
+package webstats
+
+import (
+	"crypto/rand"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// AnonymizeIP removes host part of an address, keeping /24 network for IPv4 and /48 for IPv6.
+//
+// Empty string is returned for invalid address.
+func AnonymizeIP(ip string) string {
+	pip := net.ParseIP(ip)
+	if pip == nil {
+		return ""
+	}
+
+	if v4 := pip.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String()
+	}
+
+	return pip.Mask(net.CIDRMask(48, 128)).String()
+}
+
+// DailySalt is a random secret that is replaced every day (UTC), previous values are not kept.
+//
+// Salted hashes can be used to count unique values within a day without an ability to link them across days.
+type DailySalt struct {
+	// Now is an optional time source.
+	Now func() time.Time
+
+	mu   sync.Mutex
+	day  int64
+	salt [16]byte
+}
+
+// Hash returns salted hash of values.
+func (s *DailySalt) Hash(values ...string) uint64 {
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+
+	day := now().Unix() / 86400
+
+	s.mu.Lock()
+	if s.day != day {
+		s.day = day
+		_, _ = rand.Read(s.salt[:])
+	}
+
+	d := xxhash.New()
+	_, _ = d.Write(s.salt[:])
+	s.mu.Unlock()
+
+	for _, v := range values {
+		_, _ = d.WriteString(v)
+		_, _ = d.Write([]byte{0})
+	}
+
+	return d.Sum64()
+}
+
+// HashIP returns salted hash of network part of an address, so that address can not be recovered.
+//
+// Empty string is returned for invalid address.
+func (s *DailySalt) HashIP(ip string) string {
+	if ip = AnonymizeIP(ip); ip == "" {
+		return ""
+	}
+
+	return strconv.FormatUint(s.Hash(ip), 36)
+}
@@ -0,0 +1,43 @@
+package webstats_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vearutop/photo-blog/pkg/webstats"
+)
+
+func TestAnonymizeIP(t *testing.T) {
+	assert.Equal(t, "192.168.17.0", webstats.AnonymizeIP("192.168.17.42"))
+	assert.Equal(t, "2001:db8:85a3::", webstats.AnonymizeIP("2001:db8:85a3:8d3:1319:8a2e:370:7348"))
+	assert.Equal(t, "", webstats.AnonymizeIP("unknown"))
+}
+
+func TestDailySalt_Hash(t *testing.T) {
+	now := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+
+	s := webstats.DailySalt{Now: func() time.Time { return now }}
+
+	h := s.Hash("1.2.3.4", "Mozilla/5.0")
+	assert.Equal(t, h, s.Hash("1.2.3.4", "Mozilla/5.0"))
+	assert.NotEqual(t, h, s.Hash("1.2.3.4Mozilla/5.0"))
+	assert.NotEqual(t, h, s.Hash("1.2.3.5", "Mozilla/5.0"))
+
+	now = now.Add(15 * time.Hour)
+	assert.NotEqual(t, h, s.Hash("1.2.3.4", "Mozilla/5.0"))
+
+	other := webstats.DailySalt{}
+	assert.NotEqual(t, s.Hash("a"), other.Hash("a"))
+}
+
+func TestDailySalt_HashIP(t *testing.T) {
+	s := webstats.DailySalt{}
+
+	h := s.HashIP("192.168.17.42")
+	assert.NotEmpty(t, h)
+	assert.NotContains(t, h, "192.168")
+	assert.Equal(t, h, s.HashIP("192.168.17.1"), "same network")
+	assert.NotEqual(t, h, s.HashIP("192.168.18.42"))
+	assert.Equal(t, "", s.HashIP("unknown"))
+}
@@ -79,9 +79,11 @@ window.openByHashInGallery = window.openByHashInGallery || function (galleryKey,
  * @property {String} galleryPano - CSS selector for gallery panoramas container
  * @property {String} baseUrl - base address to set on image close
  * @property {Boolean} enableFavorite - allow favorite pictures
+ * @property {Boolean} enableComments - show image comments in image view
  * @property {String} imageBaseUrl - base address to link to full-res images
  * @property {String} thumbBaseUrl - thumbnail base URL
- * @property {String} collabKey - optional collaborator key to remove images
+ * @property {String} collabKey - optional collaborator key to upload images
+ * @property {Boolean} collabCanDelete - collaborator key allows removing images
  * @property {Boolean} showAISays - show AI says in image view
  * @property {Boolean} showEXIFPreview - show EXIF preview in image view
  * @property {Boolean} preRendered - server rendered HTML exists for images
@@ -370,6 +372,9 @@ function loadAlbum(params) {
                 }
                 if (hideOriginal) {
                     a.attr("href", "#")
+                } else if (img.edited) {
+                    // Original file does not have edits applied.
+                    a.attr("href", thumbBase + "/2400w/" + img.hash + ".jpg")
                 } else {
                     a.attr("href", imageBase + "/" + img.hash + ".jpg")
                 }
@@ -386,7 +391,7 @@ function loadAlbum(params) {
                 }
 
                 if (img.width > 0 && img.height > 0) {
-                    if (!hideOriginal && !visitorData.lowRes) {
+                    if (!hideOriginal && !visitorData.lowRes && !img.edited) {
                         srcSet += ", " + imageBase + "/" + img.hash + ".jpg " + img.width + "w"
                     }
 
@@ -396,11 +401,23 @@ function loadAlbum(params) {
                 a.attr("data-pswp-srcset", srcSet)
                 a.attr("data-ts", img.utime)
 
+                if (typeof img.video !== "undefined") {
+                    a.attr("href", "/video/" + img.hash)
+                    a.attr("data-pswp-type", "video")
+                    a.attr("data-pswp-video-src", "/video/" + img.hash)
+                    a.addClass("video")
+
+                    if (img.video.width > 0 && img.video.height > 0) {
+                        a.attr("data-pswp-width", img.video.width)
+                        a.attr("data-pswp-height", img.video.height)
+                    }
+                }
+
                 var img_description = '<a title="Edit details" class="control-panel ctrl-btn edit-icon" href="/edit/image/' + img.hash + '.html"></a>'
                 if (result.album.name !== featured) {
                     img_description += '<a title="Add to featured" class="control-panel ctrl-btn star-icon" href="#" onclick="addToFeatured(\'' + img.hash + '\');return false"></a>'
                 }
-                if (params.collabKey) {
+                if (params.collabKey && params.collabCanDelete) {
                     img_description += '<a title="Remove from album" class="ctrl-btn trash-icon" href="#" onclick="removeImage(\'' + params.albumName + '\',\'' + img.hash + '\',\'' + params.collabKey + '\');return false"></a>'
                 } else {
                     img_description += '<a title="Remove from album" class="control-panel ctrl-btn trash-icon" href="#" onclick="removeImage(\'' + params.albumName + '\',\'' + img.hash + '\');return false"></a>'
@@ -794,10 +811,28 @@ function loadAlbum(params) {
                     return '';
                 }
                 const captionEl = document.querySelector('.pswp-caption-content[data-hash="' + hash + '"]');
-                return captionEl ? captionEl.innerHTML : '';
+                var caption = captionEl ? captionEl.innerHTML : '';
+
+                if (params.enableComments) {
+                    caption += '<div class="image-comments" data-hash="' + hash + '"></div>';
+                }
+
+                return caption;
             }
         });
 
+        if (params.enableComments) {
+            // Caption is rendered by plugin, comments are loaded into it once it is in the document.
+            lightbox.on('change', function () {
+                setTimeout(function () {
+                    $('.pswp .image-comments:not(.loaded)').each(function () {
+                        $(this).addClass('loaded')
+                        loadComments(this, {type: 'image', relatedHash: $(this).data('hash')})
+                    })
+                }, 100)
+            })
+        }
+
         lightbox.on('contentResize', ({content, width, height}) => {
             if (width > currentImage.w) {
                 currentImage.mw = width
@@ -908,6 +943,15 @@ function loadAlbum(params) {
 
             $(params.gallery).append('<div id="map"></div>')
 
+            if (params.albumName !== 'search') {
+                var geoName = encodeURIComponent(params.albumName)
+                $(params.gallery).append('<div class="map-export">Export: ' +
+                    '<a href="/poi/photos-' + geoName + '.gpx">GPX</a> | ' +
+                    '<a href="/geo/' + geoName + '.geojson">GeoJSON</a> | ' +
+                    '<a href="/geo/' + geoName + '.kml">KML</a> | ' +
+                    '<a href="/map/">All photos on map</a></div>')
+            }
+
             $('#map').show()
             var map = L.map('map', {
                 fullscreenControl: true,
@@ -1082,6 +1126,10 @@ function loadAlbum(params) {
                 gpxLayer.bindPopup(gpx.name).addTo(map);
                 gpxLayer.on('popupopen', gpxPopupHandler(gpx.name));
 
+                renderTrackProfile(map, gpx, gpsMarkers, function (hash) {
+                    openByHashInGallery(galleryKey, hash)
+                })
+
                 overlayMaps[gpx.name] = gpxLayer
             }
 
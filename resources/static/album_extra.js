@@ -36,6 +36,17 @@ function reindexAlbum(name) {
     })
 }
 
+function geotagAlbum(name, method) {
+    fetch('/album/' + name + '/geotag', {method: method}).then(function (resp) {
+        return resp.json()
+    }).then(function (res) {
+        if (res.error) {
+            alert("Failed to geotag album: " + res.error)
+        } else {
+            alert(JSON.stringify(res))
+        }
+    })
+}
 
 function beforeUploadRequest(req, file, allFiles) {
     console.log("before upload", req, file, allFiles)
@@ -107,13 +118,16 @@ function addToFeatured(imageHash) {
 function fillFavorite(albumHash) {
     var b = new Backend('');
     b.getFavorite({
-        albumHash: albumHash
+        albumHash: albumHash,
+        listHash: favoriteListHash || null
     }, function (res) {
         var idx = {}
         for (var i in res) {
             idx[res[i]] = true;
         }
 
+        $('.pswp-caption-content > a[data-favorite]').remove()
+
         $('.pswp-caption-content').each(function () {
             var h = $(this).data('hash')
 
@@ -134,13 +148,12 @@ function toggleFavorite(imageHash, a) {
     var b = new Backend('');
 
     var req = {
-        imageHash: imageHash
+        imageHash: imageHash,
+        listHash: favoriteListHash || null
     }
 
     if ($(a).data("favorite") === 'yes') {
-        b.deleteFavorite({
-            imageHash: imageHash
-        }, function () {
+        b.deleteFavorite(req, function () {
             $(a).attr("title", "Add to favorite").data('favorite', 'no').removeClass('heart-icon').addClass('heart-empty-icon')
             console.log("favorite deleted")
         })
@@ -289,7 +302,9 @@ function collectStats(params) {
     params.sw = screen.width
     params.sh = screen.height
     params.px = window.devicePixelRatio
-    params.v = window.visitorData.id
+    if (window.visitorData.id) {
+        params.v = window.visitorData.id
+    }
 
     if (document.referrer && new URL(document.referrer).hostname !== window.location.hostname) {
         params.ref = document.referrer
@@ -367,3 +382,162 @@ function distance(lat1, lon1, lat2, lon2) {
 
     return 6371000 * c
 }
+
+/**
+ * Formats track statistics.
+ *
+ * @param {Object} s - track or day statistics
+ * @returns {string}
+ */
+function trackStatsText(s) {
+    var parts = []
+
+    if (s.distance) {
+        parts.push((s.distance / 1000).toFixed(1) + ' km')
+    }
+
+    if (s.movingTime) {
+        var m = Math.round(s.movingTime / 60)
+        parts.push(Math.floor(m / 60) + 'h ' + (m % 60) + 'm moving')
+    }
+
+    if (s.elevationGain || s.elevationLoss) {
+        parts.push('+' + Math.round(s.elevationGain || 0) + ' m / -' + Math.round(s.elevationLoss || 0) + ' m')
+    }
+
+    if (s.maxSpeed) {
+        parts.push('max ' + (s.maxSpeed * 3.6).toFixed(1) + ' km/h')
+    }
+
+    return parts.join(', ')
+}
+
+/**
+ * Renders elevation profile of a track below the map.
+ *
+ * Hovering the profile shows position on the map, photos are placed on the profile by the closest track point.
+ *
+ * @param {Object} map - Leaflet map
+ * @param {Object} track - album track with hash and name
+ * @param {Array} photos - images with gps
+ * @param {Function} openPhoto - called with image hash
+ */
+function renderTrackProfile(map, track, photos, openPhoto) {
+    var container = $('<div class="track-profile"></div>')
+    $('#map').parent().find('.track-profile').last().after(container)
+    if (!container.parent().length) {
+        $('#map').after(container)
+    }
+
+    $.getJSON('/track/' + track.hash + '/profile.json', function (p) {
+        var points = p.points || []
+        var withEle = points.filter(function (pt) {
+            return pt.e !== undefined
+        })
+
+        var title = '<div class="track-profile-title"><b>' + $('<span>').text(track.name).html() + '</b> ' + trackStatsText(p)
+        if (p.days && p.days.length > 1) {
+            for (var i = 0; i < p.days.length; i++) {
+                title += '<br/>' + p.days[i].date + ': ' + trackStatsText(p.days[i])
+            }
+        }
+        title += '</div>'
+        container.append(title)
+
+        if (withEle.length < 2) {
+            return
+        }
+
+        var width = 1000, height = 120, pad = 4
+        var maxD = points[points.length - 1].d || 1
+        var minE = withEle[0].e, maxE = withEle[0].e
+
+        for (var i = 0; i < withEle.length; i++) {
+            minE = Math.min(minE, withEle[i].e)
+            maxE = Math.max(maxE, withEle[i].e)
+        }
+
+        if (maxE - minE < 10) {
+            maxE = minE + 10
+        }
+
+        function x(d) {
+            return (d / maxD * width).toFixed(1)
+        }
+
+        function y(e) {
+            return (pad + (height - 2 * pad) * (1 - (e - minE) / (maxE - minE))).toFixed(1)
+        }
+
+        var path = 'M0,' + height
+        for (var i = 0; i < withEle.length; i++) {
+            path += ' L' + x(withEle[i].d) + ',' + y(withEle[i].e)
+        }
+        path += ' L' + width + ',' + height + ' Z'
+
+        var svg = '<svg viewBox="0 0 ' + width + ' ' + height + '" preserveAspectRatio="none">' +
+            '<path d="' + path + '" class="track-profile-area"/>' +
+            '<line class="track-profile-cursor" x1="-10" x2="-10" y1="0" y2="' + height + '"/>'
+
+        // Place photos on profile by the closest track point.
+        for (var j = 0; j < photos.length; j++) {
+            var img = photos[j], best = null, bestDist = 200
+
+            for (var i = 0; i < withEle.length; i++) {
+                var dist = distance(img.gps.latitude, img.gps.longitude, withEle[i].lat, withEle[i].lon)
+                if (dist < bestDist) {
+                    best = withEle[i]
+                    bestDist = dist
+                }
+            }
+
+            if (best !== null) {
+                svg += '<circle class="track-profile-photo" data-hash="' + img.hash + '" cx="' + x(best.d) +
+                    '" cy="' + y(best.e) + '" r="5"><title>' + img.name + '</title></circle>'
+            }
+        }
+
+        svg += '</svg>'
+        container.append('<div class="track-profile-chart">' + svg + '<span class="track-profile-label"></span></div>')
+
+        var chart = container.find('.track-profile-chart')
+        var cursor = container.find('.track-profile-cursor')
+        var label = container.find('.track-profile-label')
+        var marker = null
+
+        chart.on('mousemove', function (e) {
+            var d = (e.pageX - chart.offset().left) / chart.width() * maxD
+            var pt = withEle[0]
+
+            for (var i = 0; i < withEle.length; i++) {
+                if (Math.abs(withEle[i].d - d) < Math.abs(pt.d - d)) {
+                    pt = withEle[i]
+                }
+            }
+
+            cursor.attr('x1', x(pt.d)).attr('x2', x(pt.d))
+            label.text((pt.d / 1000).toFixed(1) + ' km, ' + Math.round(pt.e) + ' m' +
+                (pt.t ? ', ' + new Date(pt.t).toLocaleTimeString() : ''))
+
+            if (marker === null) {
+                marker = L.circleMarker([pt.lat, pt.lon], {radius: 6}).addTo(map)
+            } else {
+                marker.setLatLng([pt.lat, pt.lon])
+            }
+        })
+
+        chart.on('mouseleave', function () {
+            cursor.attr('x1', -10).attr('x2', -10)
+            label.text('')
+
+            if (marker !== null) {
+                marker.remove()
+                marker = null
+            }
+        })
+
+        chart.find('.track-profile-photo').on('click', function () {
+            openPhoto($(this).data('hash'))
+        })
+    })
+}
@@ -1,5 +1,7 @@
 (function () {
     var visitorCookie = Cookies.get("v")
+    var tracking = window.visitorTracking || {cookieless: false, cookieDays: 0}
+    var cookieDays = tracking.cookieDays || 3*365
 
     function randomInt() {
         return Math.floor(Math.random()*(9223372036854775807-1+1)+1);
@@ -14,9 +16,19 @@
      */
     var visitorData= JSON.parse(localStorage.getItem("visitorData"))
 
-    if (visitorData && visitorData.id) {
+    if (tracking.cookieless) {
+        // Visitor is identified by server without storing an id in browser.
+        visitorData = visitorData || {}
+        delete visitorData.id
+
+        if (visitorCookie) {
+            Cookies.remove("v")
+        }
+
+        localStorage.setItem("visitorData", JSON.stringify(visitorData))
+    } else if (visitorData && visitorData.id) {
         if (visitorCookie !== visitorData.id) {
-            Cookies.set("v", visitorData.id, {expires:3*365, sameSite: "Strict"})
+            Cookies.set("v", visitorData.id, {expires: cookieDays, sameSite: "Strict"})
         }
     } else {
         visitorData = {}
@@ -27,7 +39,7 @@
             visitorData.id = randomInt().toString(36)
         }
 
-        Cookies.set("v", visitorData.id, {expires:3*365, sameSite: "Strict"})
+        Cookies.set("v", visitorData.id, {expires: cookieDays, sameSite: "Strict"})
         localStorage.setItem("visitorData", JSON.stringify(visitorData))
     }
 
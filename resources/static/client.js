@@ -1761,6 +1761,9 @@
         if (req.imageHash != null) {
             url += 'image_hash=' + encodeURIComponent(req.imageHash) + '&';
         }
+        if (req.listHash != null) {
+            url += 'list_hash=' + encodeURIComponent(req.listHash) + '&';
+        }
         url = url.slice(0, -1);
 
         x.open("DELETE", url, true);
@@ -1798,6 +1801,9 @@
         if (req.albumHash != null) {
             url += 'album_hash=' + encodeURIComponent(req.albumHash) + '&';
         }
+        if (req.listHash != null) {
+            url += 'list_hash=' + encodeURIComponent(req.listHash) + '&';
+        }
         url = url.slice(0, -1);
 
         x.open("GET", url, true);
@@ -1835,6 +1841,9 @@
         if (req.albumHash != null) {
             url += 'album_hash=' + encodeURIComponent(req.albumHash) + '&';
         }
+        if (req.listHash != null) {
+            url += 'list_hash=' + encodeURIComponent(req.listHash) + '&';
+        }
         url = url.slice(0, -1);
 
         x.open("HEAD", url, true);
@@ -1872,6 +1881,9 @@
         if (req.imageHash != null) {
             url += 'image_hash=' + encodeURIComponent(req.imageHash) + '&';
         }
+        if (req.listHash != null) {
+            url += 'list_hash=' + encodeURIComponent(req.listHash) + '&';
+        }
         url = url.slice(0, -1);
 
         x.open("POST", url, true);
@@ -0,0 +1,171 @@
+/**
+ * @typedef ThreadMessage
+ * @type {Object}
+ * @property {String} hash
+ * @property {String} name
+ * @property {String} text
+ * @property {String} created_at
+ * @property {String} edited_at
+ * @property {Boolean} pending - message is waiting for moderation
+ * @property {Boolean} own - message can be edited or deleted
+ * @property {Array<ThreadMessage>} replies
+ */
+
+/**
+ * @typedef loadCommentsParams
+ * @type {Object}
+ * @property {String} type - album or image
+ * @property {String} relatedHash - hash of album or image
+ * @property {String} title - optional header
+ */
+
+function escapeCommentText(s) {
+    return $('<div>').text(s || '').html().replace(/\n/g, '<br/>')
+}
+
+/**
+ * Renders comments thread with a message form.
+ *
+ * @param {String|HTMLElement} container
+ * @param {loadCommentsParams} params
+ */
+function loadComments(container, params) {
+    "use strict";
+
+    var el = $(container)
+    var url = '/comments.json?type=' + encodeURIComponent(params.type) + '&related_hash=' + encodeURIComponent(params.relatedHash)
+
+    function send(method, url, body, onOK) {
+        fetch(url, {
+            method: method,
+            headers: {'Content-Type': 'application/json'},
+            body: body ? JSON.stringify(body) : undefined
+        }).then(function (resp) {
+            if (resp.ok) {
+                onOK()
+
+                return
+            }
+
+            resp.json().then(function (e) {
+                el.find('.comments-error').text(e.error || e.status || 'Failed to save message.').show()
+            }, function () {
+                el.find('.comments-error').text('Failed to save message.').show()
+            })
+        })
+    }
+
+    function messageForm(parentHash) {
+        var f = $('<form class="pure-form comment-form">' +
+            '<input type="text" name="name" placeholder="Your name (optional)" maxlength="100"/>' +
+            '<textarea name="text" rows="3" placeholder="Leave a message" required></textarea>' +
+            '<button type="submit" class="pure-button">Send</button>' +
+            '</form>')
+
+        f.find('[name=name]').val(localStorage.getItem('commentName') || '')
+
+        f.on('submit', function (e) {
+            e.preventDefault()
+
+            var name = f.find('[name=name]').val()
+            localStorage.setItem('commentName', name)
+
+            send('POST', '/message', {
+                type: params.type,
+                related_hash: params.relatedHash,
+                parent_hash: parentHash || undefined,
+                name: name,
+                text: f.find('[name=text]').val()
+            }, render)
+        })
+
+        return f
+    }
+
+    /**
+     * @param {ThreadMessage} m
+     * @param {Boolean} isReply
+     */
+    function renderMessage(m, isReply) {
+        var d = $('<div class="comment"></div>')
+        var head = $('<div class="comment-head"></div>')
+
+        head.append($('<b></b>').text(m.name || 'Guest'))
+        head.append(' <span class="comment-date">' + new Date(m.created_at).toLocaleString() +
+            (m.edited_at ? ' (edited)' : '') + '</span>')
+
+        if (m.pending) {
+            head.append(' <span class="comment-pending">awaiting moderation</span>')
+        }
+
+        d.append(head)
+
+        var text = $('<div class="comment-text"></div>').html(escapeCommentText(m.text))
+        d.append(text)
+
+        var actions = $('<div class="comment-actions"></div>')
+
+        if (!isReply) {
+            $('<a href="#">Reply</a>').on('click', function (e) {
+                e.preventDefault()
+                d.find('> .comment-form').remove()
+                d.append(messageForm(m.hash))
+            }).appendTo(actions)
+        }
+
+        if (m.own) {
+            $('<a href="#">Edit</a>').on('click', function (e) {
+                e.preventDefault()
+
+                var f = $('<form class="pure-form comment-form"><textarea name="text" rows="3"></textarea>' +
+                    '<button type="submit" class="pure-button">Save</button></form>')
+                f.find('textarea').val(m.text)
+                f.on('submit', function (e) {
+                    e.preventDefault()
+                    send('PUT', '/message/' + m.hash, {text: f.find('textarea').val()}, render)
+                })
+                text.replaceWith(f)
+            }).appendTo(actions)
+
+            $('<a href="#">Delete</a>').on('click', function (e) {
+                e.preventDefault()
+
+                if (confirm('Delete message?')) {
+                    send('DELETE', '/message/' + m.hash, null, render)
+                }
+            }).appendTo(actions)
+        }
+
+        d.append(actions)
+
+        if (m.replies) {
+            var replies = $('<div class="comment-replies"></div>')
+            for (var i = 0; i < m.replies.length; i++) {
+                replies.append(renderMessage(m.replies[i], true))
+            }
+            d.append(replies)
+        }
+
+        return d
+    }
+
+    function render() {
+        $.getJSON(url, function (data) {
+            el.empty().addClass('comments')
+
+            if (params.title) {
+                el.append($('<h3></h3>').text(params.title))
+            }
+
+            var messages = data.messages || []
+            for (var i = 0; i < messages.length; i++) {
+                el.append(renderMessage(messages[i], false))
+            }
+
+            el.append('<div class="comments-error" style="display: none"></div>')
+            el.append(messageForm(''))
+        })
+    }
+
+    render()
+}
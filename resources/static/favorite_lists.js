@@ -0,0 +1,153 @@
+/**
+ * @typedef FavoriteList
+ * @type {Object}
+ * @property {String} hash
+ * @property {String} name
+ * @property {String} link - read-only link to share
+ */
+
+/**
+ * Hash of a named list that receives favorite photos, empty for default list.
+ *
+ * @type {String}
+ */
+var favoriteListHash = localStorage.getItem('favoriteList') || ''
+
+/**
+ * Renders selector of favorite lists with controls to create, share, export and remove them.
+ *
+ * @param {String|HTMLElement} container
+ * @param {String} albumHash - album to refresh favorite marks for
+ */
+function loadFavoriteLists(container, albumHash) {
+    "use strict";
+
+    var el = $(container)
+
+    function fail(resp) {
+        resp.json().then(function (e) {
+            alert('Failed: ' + (e.error || e.status))
+        }, function () {
+            alert('Failed: ' + resp.status)
+        })
+    }
+
+    function send(method, url, body, onOK) {
+        fetch(url, {
+            method: method,
+            headers: {'Content-Type': 'application/json'},
+            body: body ? JSON.stringify(body) : undefined
+        }).then(function (resp) {
+            if (!resp.ok) {
+                fail(resp)
+
+                return
+            }
+
+            resp.text().then(function (t) {
+                onOK(t ? JSON.parse(t) : null)
+            })
+        })
+    }
+
+    function selectList(hash) {
+        favoriteListHash = hash
+        localStorage.setItem('favoriteList', hash)
+        fillFavorite(albumHash)
+        render()
+    }
+
+    /**
+     * @param {Array<FavoriteList>} lists
+     */
+    function renderLists(lists) {
+        el.empty().addClass('favorite-lists')
+
+        var current = null
+        var sel = $('<select title="Favorite photos are added to this list"></select>')
+        sel.append($('<option value=""></option>').text('Favorites'))
+
+        for (var i = 0; i < lists.length; i++) {
+            sel.append($('<option></option>').attr('value', lists[i].hash).text(lists[i].name))
+
+            if (lists[i].hash === favoriteListHash) {
+                current = lists[i]
+            }
+        }
+
+        // Removed or foreign list falls back to default.
+        if (current === null && favoriteListHash !== '') {
+            favoriteListHash = ''
+            localStorage.removeItem('favoriteList')
+        }
+
+        sel.val(favoriteListHash).on('change', function () {
+            selectList($(this).val())
+        })
+
+        el.append($('<span class="ctrl-btn heart-icon"></span>'), sel)
+
+        var actions = $('<span class="favorite-list-actions"></span>')
+
+        $('<a href="#">New list</a>').on('click', function (e) {
+            e.preventDefault()
+
+            var name = prompt('Name of the new list')
+            if (name) {
+                send('POST', '/favorite/list', {name: name}, function (l) {
+                    selectList(l.hash)
+                })
+            }
+        }).appendTo(actions)
+
+        if (current !== null) {
+            var link = window.location.origin + current.link
+
+            $('<a href="#" title="Copy read-only link to this list">Share</a>').on('click', function (e) {
+                e.preventDefault()
+
+                if (navigator.clipboard) {
+                    navigator.clipboard.writeText(link).then(function () {
+                        alert('Link copied: ' + link)
+                    }, function () {
+                        prompt('Link to share', link)
+                    })
+                } else {
+                    prompt('Link to share', link)
+                }
+            }).appendTo(actions)
+
+            $('<a title="Open list"></a>').attr('href', current.link).text('Open').appendTo(actions)
+            $('<a title="File names and notes"></a>').attr('href', '/favorite/list/' + current.hash + '.csv').text('CSV').appendTo(actions)
+
+            $('<a href="#">Rename</a>').on('click', function (e) {
+                e.preventDefault()
+
+                var name = prompt('Name of the list', current.name)
+                if (name) {
+                    send('POST', '/favorite/list', {list_hash: current.hash, name: name}, render)
+                }
+            }).appendTo(actions)
+
+            $('<a href="#">Delete</a>').on('click', function (e) {
+                e.preventDefault()
+
+                if (confirm('Delete list "' + current.name + '" with its photos?')) {
+                    send('DELETE', '/favorite/list?list_hash=' + encodeURIComponent(current.hash), null, function () {
+                        selectList('')
+                    })
+                }
+            }).appendTo(actions)
+        }
+
+        el.append(actions)
+    }
+
+    function render() {
+        $.getJSON('/favorite/lists', function (lists) {
+            renderLists(lists || [])
+        })
+    }
+
+    render()
+}
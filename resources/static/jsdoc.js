@@ -427,12 +427,14 @@
  * @typedef DeleteFavoriteRequest
  * @type {Object}
  * @property {String} imageHash
+ * @property {String} listHash
  */
 
 /**
  * @typedef GetFavoriteRequest
  * @type {Object}
  * @property {String} albumHash
+ * @property {String} listHash
  */
 
 /**
@@ -450,6 +452,7 @@
  * @typedef AddFavoriteRequest
  * @type {Object}
  * @property {String} imageHash
+ * @property {String} listHash
  */
 
 /**
@@ -0,0 +1,167 @@
+/**
+ * @typedef {Object} MapParams
+ * @property {String} mapTiles - map tiles URL template
+ * @property {String} mapAttribution - map tiles attribution
+ * @property {String} thumbBaseUrl - thumbnail base URL
+ */
+
+/**
+ * Shows clustered geotagged photos, clusters are loaded for visible area.
+ *
+ * @param {MapParams} params
+ */
+function loadMap(params) {
+    var thumbBase = params.thumbBaseUrl
+    if (!thumbBase) {
+        thumbBase = "/thumb"
+    }
+
+    var thumbSize = "200h"
+    if (window.devicePixelRatio > 1) {
+        thumbSize = "400h"
+    }
+
+    var markerSprites = {}
+    var spriteSheets = {}
+
+    function markerSpriteStyle(markerSprite) {
+        var markerSheet = spriteSheets[markerSprite.sheet]
+        if (!markerSheet) {
+            return ''
+        }
+
+        var oneX = "/thumb-sprite/" + markerSheet.chunk_1x + ".jpg"
+        var twoX = "/thumb-sprite/" + markerSheet.chunk_2x + ".jpg"
+
+        return 'width:' + markerSprite.width + 'px;height:' + markerSprite.height + 'px;' +
+            'background-image:url(\'' + oneX + '\');' +
+            'background-image:-webkit-image-set(url(\'' + oneX + '\') 1x, url(\'' + twoX + '\') 2x);' +
+            'background-image:image-set(url(\'' + oneX + '\') 1x, url(\'' + twoX + '\') 2x);' +
+            'background-position:0 -' + markerSprite.offset_y + 'px;' +
+            'background-repeat:no-repeat;' +
+            'background-size:' + markerSprite.background_width + 'px ' + markerSprite.background_height + 'px;' +
+            'display:block;border-radius:20%;'
+    }
+
+    function clusterIcon(c) {
+        var markerSprite = markerSprites[c.hash]
+        var img, size
+
+        if (markerSprite) {
+            size = [markerSprite.width, markerSprite.height]
+            img = '<span class="image-marker" style="' + markerSpriteStyle(markerSprite) + '"></span>'
+        } else {
+            size = [40, 40]
+            img = '<img class="image-marker" style="width:40px;height:40px;object-fit:cover" src="' +
+                thumbBase + '/' + thumbSize + '/' + c.hash + '.jpg" />'
+        }
+
+        if (c.count > 1) {
+            img += '<span class="map-cluster-count">' + c.count + '</span>'
+        }
+
+        return L.divIcon({
+            className: 'map-marker-icon',
+            iconSize: size,
+            html: img
+        })
+    }
+
+    function listUrl(hashes) {
+        return '/list-' + hashes.join(',') + '/photo-' + hashes[0] + '.html'
+    }
+
+    $('#map').show()
+
+    var map = L.map('map', {
+        fullscreenControl: true,
+        worldCopyJump: true
+    }).setView([20, 0], 2);
+
+    L.tileLayer(params.mapTiles, {
+        maxZoom: 19,
+        attribution: params.mapAttribution
+    }).addTo(map);
+
+    L.control.scale().addTo(map);
+    L.control.locate().addTo(map);
+
+    var layer = L.layerGroup().addTo(map)
+    var request = null
+    var fitted = false
+
+    function render(result) {
+        markerSprites = result.marker_sprites || {}
+        spriteSheets = result.sprite_sheets || {}
+
+        layer.clearLayers()
+
+        for (var i = 0; i < result.clusters.length; i++) {
+            (function (c) {
+                var marker = L.marker([c.lat, c.lon], {icon: clusterIcon(c)})
+                var b = c.bounds
+                var single = b[0] === b[2] && b[1] === b[3]
+
+                marker.on('click', function () {
+                    if (c.count > 1 && !single && map.getZoom() < map.getMaxZoom()) {
+                        map.fitBounds([[b[0], b[1]], [b[2], b[3]]], {padding: [40, 40]})
+
+                        return
+                    }
+
+                    if (c.hashes) {
+                        window.location = listUrl(c.hashes)
+                    } else {
+                        map.setView([c.lat, c.lon], map.getZoom() + 2)
+                    }
+                })
+
+                marker.addTo(layer)
+            })(result.clusters[i])
+        }
+    }
+
+    function load() {
+        var bounds = map.getBounds()
+        var sw = bounds.getSouthWest().wrap()
+        var ne = bounds.getNorthEast().wrap()
+        var minLon = sw.lng, maxLon = ne.lng
+
+        // Whole world is visible.
+        if (bounds.getEast() - bounds.getWest() >= 360) {
+            minLon = -180
+            maxLon = 180
+        }
+
+        if (request) {
+            request.abort()
+        }
+
+        request = $.getJSON('/map/photos.json', {
+            min_lat: Math.max(-90, sw.lat),
+            min_lon: minLon,
+            max_lat: Math.min(90, ne.lat),
+            max_lon: maxLon,
+            zoom: map.getZoom()
+        }, function (result) {
+            request = null
+            render(result)
+
+            // Initial view shows all photos.
+            if (!fitted && result.clusters.length > 0) {
+                fitted = true
+
+                var all = L.latLngBounds([])
+                for (var i = 0; i < result.clusters.length; i++) {
+                    var b = result.clusters[i].bounds
+                    all.extend([b[0], b[1]]).extend([b[2], b[3]])
+                }
+
+                map.fitBounds(all, {padding: [40, 40], maxZoom: 14})
+            }
+        })
+    }
+
+    map.on('moveend', load)
+    load()
+}
@@ -0,0 +1,259 @@
+/**
+ * @typedef ProofingMark
+ * @type {Object}
+ * @property {String} image_hash
+ * @property {String} state - selected, rejected or empty
+ * @property {String} note
+ */
+
+/**
+ * @typedef ProofingSummary
+ * @type {Object}
+ * @property {String} deadline
+ * @property {Boolean} open - selection can be changed
+ * @property {Object} submission
+ * @property {Number} total
+ * @property {Number} selected
+ * @property {Number} rejected
+ * @property {Number} notes
+ * @property {Array<ProofingMark>} marks
+ */
+
+/**
+ * @typedef loadProofingParams
+ * @type {Object}
+ * @property {String} albumName
+ * @property {String} proofingKey - client access key, empty for admin
+ * @property {Boolean} isAdmin
+ */
+
+var proofing = {
+    /** @type {loadProofingParams} */
+    params: null,
+    /** @type {Object<String, ProofingMark>} */
+    marks: {},
+    open: false,
+    render: null
+}
+
+function proofingURL(suffix) {
+    return '/proofing/' + encodeURIComponent(proofing.params.albumName) + suffix +
+        (proofing.params.proofingKey ? '?proofing_key=' + encodeURIComponent(proofing.params.proofingKey) : '')
+}
+
+function proofingSend(method, suffix, body, onOK) {
+    fetch(proofingURL(suffix), {
+        method: method,
+        headers: {'Content-Type': 'application/json'},
+        body: body ? JSON.stringify(body) : undefined
+    }).then(function (resp) {
+        if (resp.ok) {
+            resp.text().then(function (t) {
+                onOK(t ? JSON.parse(t) : null)
+            })
+
+            return
+        }
+
+        resp.json().then(function (e) {
+            alert('Failed: ' + (e.error || e.status))
+        }, function () {
+            alert('Failed: ' + resp.status)
+        })
+    })
+}
+
+/**
+ * Updates controls of an image in gallery and in opened caption.
+ *
+ * @param {String} hash
+ */
+function proofingShowMark(hash) {
+    var m = proofing.marks[hash] || {}
+
+    $('#img' + hash).closest('figure')
+        .toggleClass('proofing-selected', m.state === 'selected')
+        .toggleClass('proofing-rejected', m.state === 'rejected')
+
+    $('.proofing-controls[data-hash="' + hash + '"]').each(function () {
+        $(this).find('[data-state]').each(function () {
+            $(this).toggleClass('active', $(this).data('state') === m.state)
+        })
+
+        $(this).find('.proofing-note').text(m.note || '')
+    })
+}
+
+/**
+ * Toggles decision or edits note of an image.
+ *
+ * @param {String} hash
+ * @param {String} state - selected, rejected or "note" to edit note
+ */
+function proofingMark(hash, state) {
+    if (!proofing.open) {
+        alert('Selection can not be changed anymore.')
+
+        return
+    }
+
+    var m = proofing.marks[hash] || {image_hash: hash, state: '', note: ''}
+    var req = {image_hash: hash, state: m.state || '', note: m.note || ''}
+
+    if (state === 'note') {
+        var note = prompt('Note for this image', req.note)
+        if (note === null) {
+            return
+        }
+
+        req.note = note
+    } else {
+        req.state = req.state === state ? '' : state
+    }
+
+    proofingSend('PUT', '/mark', req, function () {
+        proofing.marks[hash] = req
+        proofingShowMark(hash)
+        proofing.render()
+    })
+}
+
+/**
+ * Renders client proofing panel and adds selection controls to image captions.
+ *
+ * @param {String|HTMLElement} container
+ * @param {loadProofingParams} params
+ */
+function loadProofing(container, params) {
+    "use strict";
+
+    proofing.params = params
+
+    var el = $(container)
+
+    /**
+     * @param {ProofingSummary} s
+     */
+    function renderSummary(s) {
+        el.empty().addClass('proofing')
+
+        el.append($('<h3>Selection</h3>'))
+
+        var info = $('<p></p>').text(s.selected + ' selected, ' + s.rejected + ' rejected, ' + s.notes + ' with notes, ' + s.total + ' images in album.')
+        el.append(info)
+
+        if (s.deadline) {
+            el.append($('<p class="proofing-deadline"></p>').text('Please submit before ' + new Date(s.deadline).toLocaleString() + '.'))
+        }
+
+        if (s.submission) {
+            el.append($('<p></p>').text('Submitted ' + new Date(s.submission.submitted_at).toLocaleString() +
+                (s.submission.name ? ' by ' + s.submission.name : '') + ', ' + s.submission.selected + ' images selected.'))
+        } else if (!s.open) {
+            el.append('<p>Deadline has passed, selection can not be changed.</p>')
+        }
+
+        if (params.isAdmin) {
+            var links = $('<p class="proofing-actions"></p>')
+            links.append($('<a>Export CSV</a>').attr('href', proofingURL('/selected.csv')))
+            links.append($('<a title="Comma separated file names for Lightroom filter">Export names</a>').attr('href', proofingURL('/selected.txt')))
+
+            if (s.submission) {
+                $('<a href="#">Reopen</a>').on('click', function (e) {
+                    e.preventDefault()
+
+                    if (confirm('Allow client to change the selection?')) {
+                        proofingSend('DELETE', '/submit', null, render)
+                    }
+                }).appendTo(links)
+            }
+
+            el.append(links)
+        }
+
+        if (!s.open) {
+            return
+        }
+
+        $('<p class="proofing-actions"><a href="#">Select my favorite photos</a></p>').find('a').on('click', function (e) {
+            e.preventDefault()
+
+            proofingSend('POST', '/import-favorites', {list_hash: favoriteListHash || undefined}, render)
+        }).end().appendTo(el)
+
+        var f = $('<form class="pure-form comment-form">' +
+            '<input type="text" name="name" placeholder="Your name" maxlength="200"/>' +
+            '<textarea name="comment" rows="3" placeholder="Comment (optional)" maxlength="5000"></textarea>' +
+            '<button type="submit" class="pure-button">Submit selection</button>' +
+            '</form>')
+
+        f.on('submit', function (e) {
+            e.preventDefault()
+
+            if (!confirm('Selection can not be changed after submission, submit ' + s.selected + ' images?')) {
+                return
+            }
+
+            proofingSend('POST', '/submit', {
+                name: f.find('[name=name]').val(),
+                comment: f.find('[name=comment]').val()
+            }, render)
+        })
+
+        el.append(f)
+    }
+
+    function addControls() {
+        $('.pswp-caption-content').each(function () {
+            var h = $(this).data('hash')
+
+            if (!h || $(this).find('.proofing-controls').length > 0) {
+                return
+            }
+
+            $(this).prepend('<div class="proofing-controls" data-hash="' + h + '">' +
+                '<a href="#" data-state="selected" onclick="proofingMark(\'' + h + '\', \'selected\');return false">Select</a>' +
+                '<a href="#" data-state="rejected" onclick="proofingMark(\'' + h + '\', \'rejected\');return false">Reject</a>' +
+                '<a href="#" onclick="proofingMark(\'' + h + '\', \'note\');return false">Note</a>' +
+                '<div class="proofing-note"></div>' +
+                '</div>')
+        })
+    }
+
+    function render() {
+        fetch(proofingURL('.json')).then(function (resp) {
+            return resp.json()
+        }).then(function (/** ProofingSummary */ s) {
+            if (s.error) {
+                el.text('Failed to load selection: ' + s.error)
+
+                return
+            }
+
+            proofing.open = s.open
+
+            var old = proofing.marks
+            proofing.marks = {}
+
+            for (var i = 0; i < s.marks.length; i++) {
+                proofing.marks[s.marks[i].image_hash] = s.marks[i]
+            }
+
+            addControls()
+
+            for (var h in old) {
+                proofingShowMark(h)
+            }
+
+            for (h in proofing.marks) {
+                proofingShowMark(h)
+            }
+
+            renderSummary(s)
+        })
+    }
+
+    proofing.render = render
+
+    render()
+}